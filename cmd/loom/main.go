@@ -1,15 +1,22 @@
 package main
 
 import (
-	"log"
+	"fmt"
 	"os"
 
 	addCmd "loom/internal/cli/add"
 	configCmd "loom/internal/cli/config" // Added for config command
 	initCmd "loom/internal/cli/init"
 	listCmd "loom/internal/cli/list"
+	manifestCmd "loom/internal/cli/manifest"
+	ownerCmd "loom/internal/cli/owner"
+	publishCmd "loom/internal/cli/publish"
 	removeCmd "loom/internal/cli/remove"
+	searchCmd "loom/internal/cli/search"
+	tidyCmd "loom/internal/cli/tidy"
 	weaveCmd "loom/internal/cli/weave"
+	"loom/internal/core/exitcode"
+	"loom/internal/core/output"
 
 	"github.com/urfave/cli/v2"
 )
@@ -27,6 +34,26 @@ func main() {
 				Name: "Loom Team",
 			},
 		},
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "force-unlock",
+				Usage: "Break a loom.yaml lock left behind by an operation that has been running for over 10 minutes",
+			},
+			&cli.StringFlag{
+				Name:  "config",
+				Usage: "Use the loom.yaml at `PATH` instead of the one in the current directory; its directory is treated as the project root",
+			},
+			&cli.BoolFlag{
+				Name:  "no-color",
+				Usage: "Disable colored output (also disabled by NO_COLOR or when stdout is not a terminal)",
+			},
+		},
+		Before: func(c *cli.Context) error {
+			if c.Bool("no-color") {
+				output.SetColorEnabled(false)
+			}
+			return nil
+		},
 		Commands: []*cli.Command{
 			initCmd.Command(),
 			addCmd.Command(),
@@ -34,27 +61,41 @@ func main() {
 			{
 				Name:  "list",
 				Usage: "List threads in the project",
-				Action: func(c *cli.Context) error {
-					listCmd.ExecuteListCommand()
-					return nil
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "store",
+						Usage: "Only list threads from the named store, or 'project' for the project's .loom store",
+					},
+					&cli.BoolFlag{
+						Name:  "active",
+						Usage: "Only list the threads active in the project (loom.yaml)",
+					},
+					&cli.BoolFlag{
+						Name:  "available",
+						Usage: "Only list the threads available from the configured stores and the project store",
+					},
+					&cli.BoolFlag{
+						Name:  "check",
+						Usage: "Also hash each active thread's files to flag those modified since they were copied from the source (slower)",
+					},
 				},
-			},
-			{
-				Name:    "weave",
-				Aliases: []string{"install"},
-				Usage:   "Install or re-apply threads to the project. Optionally specify a thread name to weave only that thread.",
 				Action: func(c *cli.Context) error {
-					threadName := "" // Default to empty, meaning all threads
-					if c.Args().Len() > 0 {
-						threadName = c.Args().First()
-					}
-					if err := weaveCmd.Weave(threadName); err != nil {
-						log.Printf("Error during weave: %v", err)
-						return err
-					}
-					return nil
+					return listCmd.ExecuteListCommand(listCmd.Options{
+						Store:      c.String("store"),
+						Active:     c.Bool("active"),
+						Available:  c.Bool("available"),
+						Check:      c.Bool("check"),
+						ConfigPath: c.String("config"),
+					})
 				},
 			},
+			manifestCmd.Command(),
+			ownerCmd.Command(),
+			publishCmd.Command(),
+			searchCmd.Command(),
+			tidyCmd.Command(),
+			weaveCmd.Command(),
+			weaveCmd.ReweaveCommand(),
 			configCmd.Command(), // Added the config command
 			{
 				Name:  "version",
@@ -67,8 +108,25 @@ func main() {
 		},
 	}
 
-	err := app.Run(os.Args)
-	if err != nil {
-		log.Fatal(err)
+	// Flag parsing mistakes are user errors, whichever command they were made on.
+	app.OnUsageError = onUsageError
+	setOnUsageError(app.Commands)
+
+	if err := app.Run(os.Args); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(exitcode.For(err))
+	}
+}
+
+// onUsageError classifies flag parsing errors as user errors.
+func onUsageError(_ *cli.Context, err error, _ bool) error {
+	return exitcode.UsageError(err)
+}
+
+// setOnUsageError installs onUsageError on commands and all their subcommands.
+func setOnUsageError(commands []*cli.Command) {
+	for _, command := range commands {
+		command.OnUsageError = onUsageError
+		setOnUsageError(command.Subcommands)
 	}
 }
@@ -1,14 +1,24 @@
 package add
 
 import (
-	"bufio"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
+	"strconv"
 	"strings"
 
+	listCmd "loom/internal/cli/list"
+	"loom/internal/core/exitcode"
 	"loom/internal/core/globalconfig" // Import the globalconfig package
-	"loom/internal/core/project"      // Import the project package
+	"loom/internal/core/output"
+	"loom/internal/core/project" // Import the project package
+	"loom/internal/core/threadconfig"
+	"loom/internal/core/threadstore"
 
 	"github.com/urfave/cli/v2"
 	"gopkg.in/yaml.v3"
@@ -20,7 +30,7 @@ import (
 // It returns the target store name, thread name, and an error if parsing fails.
 func parseAddArgs(fullThreadArg string) (string, string, error) {
 	if fullThreadArg == "" {
-		return "", "", fmt.Errorf("thread name or store/thread is required")
+		return "", "", exitcode.Usagef("thread name or store/thread is required")
 	}
 
 	var targetStoreName string
@@ -30,44 +40,59 @@ func parseAddArgs(fullThreadArg string) (string, string, error) {
 		targetStoreName = parts[0]
 		threadName = parts[1]
 		if targetStoreName == "" || threadName == "" {
-			return "", "", fmt.Errorf("invalid format for store/thread: '%s'. Both store name and thread name must be specified", fullThreadArg)
+			return "", "", exitcode.Usagef("invalid format for store/thread: '%s'. Both store name and thread name must be specified", fullThreadArg)
 		}
 	} else {
 		threadName = fullThreadArg
 	}
+	// Names are joined onto store and project paths, so they must stay inside them. Only a thread
+	// in a named store may be nested in category directories (store/frontend/button).
+	if targetStoreName != "" {
+		if err := project.ValidateName("store", targetStoreName); err != nil {
+			return "", "", exitcode.UsageError(err)
+		}
+		if err := project.ValidateThreadPath(threadName); err != nil {
+			return "", "", exitcode.UsageError(err)
+		}
+		return targetStoreName, threadName, nil
+	}
+	if err := project.ValidateName("thread", threadName); err != nil {
+		return "", "", exitcode.UsageError(err)
+	}
 	return targetStoreName, threadName, nil
 }
 
-// loadProjectLoomConfig loads the loom.yaml configuration from the project root.
+// loadProjectLoomConfig loads the loom.yaml configuration at loomConfigPath.
 // If the file doesn't exist, it initializes an empty configuration.
-// It returns the loaded config, the path to the config file, and an error if any.
-func loadProjectLoomConfig(projectRoot string) (project.LoomConfig, string, error) {
-	loomConfigPath := filepath.Join(projectRoot, project.YamlFileName)
+func loadProjectLoomConfig(loomConfigPath string) (project.LoomConfig, error) {
 	var loomConfig project.LoomConfig
 	configData, err := os.ReadFile(loomConfigPath)
 	if err != nil {
 		if !os.IsNotExist(err) {
-			return loomConfig, loomConfigPath, fmt.Errorf("failed to read %s: %w", project.YamlFileName, err)
+			return loomConfig, fmt.Errorf("failed to read %s: %w", project.YamlFileName, err)
 		}
 		// Initialize empty config if loom.yaml doesn't exist
 		loomConfig = project.LoomConfig{Version: "1", Threads: []project.Thread{}}
 	} else {
-		err = yaml.Unmarshal(configData, &loomConfig)
+		loomConfig, err = project.ParseLoomConfig(configData)
 		if err != nil {
-			return loomConfig, loomConfigPath, fmt.Errorf("failed to parse %s: %w", project.YamlFileName, err)
+			return loomConfig, fmt.Errorf("failed to parse %s: %w", project.YamlFileName, err)
 		}
 	}
-	return loomConfig, loomConfigPath, nil
+	return loomConfig, nil
 }
 
 // findThreadInProjectStore searches for a thread in the project's .loom directory.
 // It returns the thread path, thread source, a boolean indicating if found, and an error.
 func findThreadInProjectStore(projectRoot, threadName string) (string, string, bool, error) {
-	projectThreadPath := filepath.Join(projectRoot, ".loom", threadName, "_thread")
-	_, err := os.Stat(projectThreadPath)
+	source := project.ProjectSource(threadName)
+	projectThreadPath, err := project.ResolveSource(source, threadName, projectRoot, nil)
+	if err != nil {
+		return "", "", false, err
+	}
+	_, err = os.Stat(projectThreadPath)
 	if err == nil {
-		threadSource := fmt.Sprintf("project:.loom/%s", threadName)
-		return projectThreadPath, threadSource, true, nil
+		return projectThreadPath, project.EncodeSource(source), true, nil
 	}
 	if os.IsNotExist(err) {
 		return "", "", false, nil
@@ -75,33 +100,47 @@ func findThreadInProjectStore(projectRoot, threadName string) (string, string, b
 	return "", "", false, err
 }
 
-// findThreadInLocalStores searches for a thread in the configured local PC stores.
+// findThreadInLocalStores searches for a thread in the configured local PC stores, the default store
+// first. threadName may be a slash-separated path to a thread nested in category directories.
 // It returns the thread path, thread source, a boolean indicating if found, and an error.
-func findThreadInLocalStores(targetStoreName, threadName string, gConf *globalconfig.GlobalLoomConfig) (string, string, bool, error) {
-	for _, store := range gConf.Stores {
+func findThreadInLocalStores(projectRoot, targetStoreName, threadName string, gConf *globalconfig.GlobalLoomConfig) (string, string, bool, error) {
+	for _, store := range gConf.StoresInSearchOrder() {
 		if targetStoreName != "" && store.Name != targetStoreName {
 			continue
 		}
-		if store.Type == "local" {
-			potentialThreadPath := filepath.Join(store.Path, threadName, "_thread")
-			fileInfo, err := os.Stat(potentialThreadPath)
-			if err == nil {
-				if fileInfo.IsDir() {
-					return potentialThreadPath, store.Name, true, nil
-				} else {
-					// If the path exists but is not a directory, it's a malformed thread.
-					return "", "", false, fmt.Errorf("thread path '%s' in store '%s' is a file, not a directory", potentialThreadPath, store.Name)
-				}
-			} else if !os.IsNotExist(err) {
-				return "", "", false, fmt.Errorf("error accessing thread '%s' in store '%s' (%s): %w", threadName, store.Name, potentialThreadPath, err)
-			}
+		resolver, err := threadstore.New(store)
+		if errors.Is(err, threadstore.ErrUnsupportedType) {
+			continue // Only local stores can be searched for now
+		} else if err != nil && targetStoreName == "" {
+			// An archive that cannot be extracted should not keep threads of other stores from resolving.
+			fmt.Fprintf(os.Stderr, "Warning: skipping store '%s': %v\n", store.Name, err)
+			continue
+		} else if err != nil {
+			return "", "", false, err
 		}
+		threadPath, err := resolver.ResolveThread(threadName)
+		if errors.Is(err, threadstore.ErrThreadNotFound) {
+			continue
+		} else if err != nil {
+			return "", "", false, err
+		}
+		// A store at or above the project root would have loom copy files into its own source tree.
+		if project.PathContains(store.Path, projectRoot) {
+			return "", "", false, fmt.Errorf("store '%s' (%s) contains the project root %s; refusing to add threads from it", store.Name, store.Path, projectRoot)
+		}
+		source := project.ThreadSource{Kind: project.SourceKindStore, Location: store.Name}
+		if strings.Contains(threadName, "/") {
+			source.Thread = threadName
+		}
+		return threadPath, project.EncodeSource(source), true, nil
 	}
 	return "", "", false, nil
 }
 
-// handleThreadSearch orchestrates the search for a thread, first in the project store, then in local stores.
-func handleThreadSearch(projectRoot, targetStoreName, threadName string) (string, string, error) {
+// handleThreadSearch orchestrates the search for a thread, first in the project store, then in local
+// stores, for the project whose loom.yaml is at loomConfigPath.
+func handleThreadSearch(loomConfigPath, targetStoreName, threadName string) (string, string, error) {
+	projectRoot := filepath.Dir(loomConfigPath)
 	// Try project store first only if no specific store is targeted
 	if targetStoreName == "" {
 		threadPath, threadSource, foundInProject, err := findThreadInProjectStore(projectRoot, threadName)
@@ -113,12 +152,12 @@ func handleThreadSearch(projectRoot, targetStoreName, threadName string) (string
 		}
 	}
 
-	gConf, err := globalconfig.LoadGlobalConfig()
+	gConf, err := project.LoadStoreConfig(loomConfigPath)
 	if err != nil {
-		return "", "", fmt.Errorf("failed to load global loom configuration: %w", err)
+		return "", "", err
 	}
 
-	threadPath, threadSource, foundInLocal, err := findThreadInLocalStores(targetStoreName, threadName, gConf)
+	threadPath, threadSource, foundInLocal, err := findThreadInLocalStores(projectRoot, targetStoreName, threadName, gConf)
 	if err != nil {
 		return "", "", fmt.Errorf("error searching in local stores: %w", err)
 	}
@@ -137,77 +176,987 @@ func handleThreadSearch(projectRoot, targetStoreName, threadName string) (string
 			}
 		}
 		if !storeExists {
-			return "", "", fmt.Errorf("specified store '%s' not found in global configuration", targetStoreName)
+			return "", "", exitcode.Usagef("specified store '%s' not found in %s or the global configuration", targetStoreName, project.YamlFileName)
+		}
+		return "", "", exitcode.Usagef("thread '%s' not found in specified store '%s'", threadName, targetStoreName)
+	}
+	return "", "", exitcode.Usagef("thread '%s' not found in project's .loom folder or any configured local PC stores", threadName)
+}
+
+// addOptions holds the flags that apply to every thread added in one invocation.
+type addOptions struct {
+	// prefix is a project-relative directory (forward slashes, no trailing slash) under which
+	// the thread's files are installed. Empty means the project root.
+	prefix string
+	// yes answers every overwrite prompt with yes. Conflicts are still detected and reported.
+	yes bool
+	// force overwrites every existing file without looking for conflicts or prompting.
+	force bool
+	// overwritePolicy is --overwrite-policy as the conflict policy for every existing file
+	// (threadconfig.PolicyOverwrite or PolicySkip), taking precedence over config.yml policies and yes,
+	// or "" for prompting as usual.
+	overwritePolicy string
+	// policyFor returns the config.yml conflict policy for a source file path, or "". It is set per
+	// thread by copyDir.
+	policyFor func(srcPath string) string
+	// journal records the files written so a failed add can be undone; nil records nothing.
+	journal *addJournal
+	// lineEndings is config.yml's line_endings, applied to text files. It is set per thread by copyDir.
+	lineEndings string
+	// includes reports whether config.yml's files filter installs a source file path. It is set per
+	// thread by copyDir; nil installs every file.
+	includes func(srcPath string) bool
+	// rename, if set, is the name the thread is recorded under in loom.yaml instead of its own.
+	rename string
+	// withDeps adds missing dependencies without prompting; noDeps never adds them.
+	withDeps bool
+	noDeps   bool
+	// recordModes records the permission bits of every copied file in loom.yaml so weave restores them.
+	recordModes bool
+	// modes collects the recorded permission bits, keyed by project-relative path. It is set per
+	// thread by addThread when recordModes is on.
+	modes map[string]string
+	// dotfiles, if set, overrides config.yml's dotfiles setting (threadconfig.DotfilesInclude or
+	// DotfilesExclude) and is recorded on the thread's loom.yaml entry.
+	dotfiles string
+	// sourceConfigPath is the loom.yaml of the project that thread arguments are resolved against:
+	// its .loom store and the stores it declares. With --output-dir it is not the manifest written.
+	sourceConfigPath string
+	// asCopy installs the files as untracked copies: the thread is not recorded in loom.yaml, so
+	// weave and remove leave them alone.
+	asCopy bool
+	// manifestOnly records the thread's files that already exist in the project as owned by it,
+	// without writing anything; files missing from the project are reported and not recorded.
+	manifestOnly bool
+	// showProgress shows a progress line for every thread, not only those with at least
+	// output.ProgressThreshold files.
+	showProgress bool
+	// progress reports the files of the thread being copied, or is nil. It is set per thread by copyDir.
+	progress *output.Progress
+	// out receives progress messages and prompts: stdout, or stderr when stdout carries --json output.
+	out io.Writer
+}
+
+// normalizePrefix validates a --prefix value and returns it in manifest form.
+// Prefixes must be relative and must not escape the project root.
+func normalizePrefix(prefix string) (string, error) {
+	if strings.TrimSpace(prefix) == "" {
+		return "", nil
+	}
+	if filepath.IsAbs(prefix) || strings.HasPrefix(prefix, "/") || strings.HasPrefix(prefix, "\\") {
+		return "", exitcode.Usagef("invalid prefix '%s': must be a path relative to the project root", prefix)
+	}
+	for _, segment := range strings.FieldsFunc(prefix, func(r rune) bool { return r == '/' || r == '\\' }) {
+		if segment == ".." {
+			return "", exitcode.Usagef("invalid prefix '%s': must not escape the project root", prefix)
 		}
-		return "", "", fmt.Errorf("thread '%s' not found in specified store '%s'", threadName, targetStoreName)
 	}
-	return "", "", fmt.Errorf("thread '%s' not found in project's .loom folder or any configured local PC stores", threadName)
+	cleaned := filepath.ToSlash(filepath.Clean(prefix))
+	if cleaned == "." {
+		return "", nil
+	}
+	return strings.TrimSuffix(cleaned, "/"), nil
+}
+
+// copyStats counts what happened to each file while copying a thread into the project.
+type copyStats struct {
+	Created     int `json:"created"`
+	Overwritten int `json:"overwritten"`
+	Skipped     int `json:"skipped"`
+	// Adopted counts existing files recorded without being written, by --manifest-only.
+	Adopted int `json:"adopted,omitempty"`
+	// Unchanged counts existing files that already matched the thread and were left as they were.
+	Unchanged int `json:"unchanged,omitempty"`
+}
+
+// add accumulates other into s.
+func (s *copyStats) add(other copyStats) {
+	s.Created += other.Created
+	s.Overwritten += other.Overwritten
+	s.Skipped += other.Skipped
+	s.Adopted += other.Adopted
+	s.Unchanged += other.Unchanged
+}
+
+// String formats the counts as "N created, M overwritten, K skipped", followed by ", A adopted" and
+// ", U unchanged" if any files were adopted or left unchanged.
+func (s copyStats) String() string {
+	counts := fmt.Sprintf("%d created, %d overwritten, %d skipped", s.Created, s.Overwritten, s.Skipped)
+	if s.Adopted > 0 {
+		counts += fmt.Sprintf(", %d adopted", s.Adopted)
+	}
+	if s.Unchanged > 0 {
+		counts += fmt.Sprintf(", %d unchanged", s.Unchanged)
+	}
+	return counts
+}
+
+// addedThread records the outcome of adding a single thread for the final summary.
+type addedThread struct {
+	arg       string
+	name      string // Name recorded in loom.yaml
+	sourceDir string // The thread's _thread directory
+	source    string
+	fileCount int
+	stats     copyStats
+	untracked bool // Installed with --as-copy, so not recorded in loom.yaml
+	released  int  // Files an untracked copy took from the threads that owned them
+}
+
+// addResult is the structured outcome of `loom add`, printed by --json.
+type addResult struct {
+	Added  []addResultThread `json:"added"`
+	Failed []string          `json:"failed"`
+	Totals copyStats         `json:"totals"`
+}
+
+// addResultThread is the --json representation of a single added thread.
+type addResultThread struct {
+	Thread    string `json:"thread"`
+	Source    string `json:"source"`
+	FileCount int    `json:"file_count"`
+	Untracked bool   `json:"untracked,omitempty"`
+	copyStats
+}
+
+// newAddResult builds the --json result from the threads added and the ones that failed.
+func newAddResult(added []addedThread, failed []string) addResult {
+	result := addResult{Added: []addResultThread{}, Failed: []string{}}
+	for _, a := range added {
+		result.Added = append(result.Added, addResultThread{Thread: a.arg, Source: a.source, FileCount: a.fileCount, Untracked: a.untracked, copyStats: a.stats})
+		result.Totals.add(a.stats)
+	}
+	result.Failed = append(result.Failed, failed...)
+	return result
 }
 
 func Command() *cli.Command {
 	return &cli.Command{
-		Name:  "add",
-		Usage: "Add a thread to the project. Syntax: loom add <thread_name> OR loom add <store_name>/<thread_name>",
+		Name:      "add",
+		Usage:     "Add one or more threads to the project. Syntax: loom add <thread_name> OR loom add <store_name>/<thread_name> [...]",
+		ArgsUsage: "<thread_name>|<store_name>/<thread_name> [...]",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:    "interactive",
+				Aliases: []string{"i"},
+				Usage:   "Pick the thread to add from a numbered list of available threads",
+			},
+			&cli.BoolFlag{
+				Name:  "continue-on-error",
+				Usage: "Keep adding the remaining threads when one of them fails",
+			},
+			&cli.StringFlag{
+				Name:  "prefix",
+				Usage: "Install the thread's files under this project-relative `DIR` instead of the project root",
+			},
+			&cli.StringFlag{
+				Name:  "rename",
+				Usage: "Record the thread in loom.yaml as `NAME` instead of its own name (single thread only)",
+			},
+			&cli.StringFlag{
+				Name:  "from",
+				Usage: "Add the thread in `DIR` (containing a _thread subdirectory) without searching any store",
+			},
+			&cli.BoolFlag{
+				Name:    "yes",
+				Aliases: []string{"y"},
+				Usage:   "Answer yes to every overwrite prompt; conflicts are still reported",
+			},
+			&cli.BoolFlag{
+				Name:  "force",
+				Usage: "Overwrite every existing file without checking ownership or prompting",
+			},
+			&cli.StringFlag{
+				Name:  "overwrite-policy",
+				Value: "prompt",
+				Usage: "Resolve every conflict with an existing file the same way: `theirs` overwrites it and takes ownership, ours keeps it, prompt asks",
+			},
+			&cli.BoolFlag{
+				Name:  "record-modes",
+				Usage: "Record each file's permission bits in loom.yaml so weave restores them even if the source's drift",
+			},
+			&cli.BoolFlag{
+				Name:  "include-dotfiles",
+				Usage: "Install files and directories whose names start with \".\", even if the thread's config.yml excludes them",
+			},
+			&cli.BoolFlag{
+				Name:  "exclude-dotfiles",
+				Usage: "Skip files and directories whose names start with \".\"; weave keeps skipping them for the thread",
+			},
+			&cli.BoolFlag{
+				Name:  "with-deps",
+				Usage: "Add missing dependencies declared in config.yml without prompting",
+			},
+			&cli.BoolFlag{
+				Name:  "no-deps",
+				Usage: "Never add missing dependencies declared in config.yml",
+			},
+			&cli.BoolFlag{
+				Name:  "json",
+				Usage: "Print the result as JSON on stdout; progress messages and prompts go to stderr",
+			},
+			&cli.BoolFlag{
+				Name:  "as-copy",
+				Usage: "Install the files as untracked copies without recording the thread in loom.yaml",
+			},
+			&cli.BoolFlag{
+				Name:  "progress",
+				Usage: fmt.Sprintf("Show a progress line while copying (automatic for threads with %d or more files)", output.ProgressThreshold),
+			},
+			&cli.BoolFlag{
+				Name:  "manifest-only",
+				Usage: "Record the thread's files that already exist in the project as owned by it, without copying anything",
+			},
+			&cli.StringFlag{
+				Name:  "output-dir",
+				Usage: "Write the threads and a standalone loom.yaml into `DIR` instead of the project, overwriting what is there",
+			},
+		},
 		Action: func(c *cli.Context) error {
-			fullThreadArg := c.Args().First()
-			targetStoreName, threadName, err := parseAddArgs(fullThreadArg)
+			threadArgs := c.Args().Slice()
+			resolve := resolveThreadArg
+			if fromDir := c.String("from"); fromDir != "" {
+				if len(threadArgs) > 0 {
+					return exitcode.Usagef("--from cannot be combined with thread names")
+				}
+				threadArgs = []string{fromDir}
+				resolve = resolveThreadFromDir
+			}
+			interactive := c.Bool("interactive")
+			if interactive && len(threadArgs) > 0 {
+				return exitcode.Usagef("--interactive cannot be combined with thread names or --from")
+			}
+			if len(threadArgs) == 0 && !interactive {
+				_, _, err := parseAddArgs("")
+				return err
+			}
+			continueOnError := c.Bool("continue-on-error")
+			prefix, err := normalizePrefix(c.String("prefix"))
 			if err != nil {
 				return err
 			}
-
-			projectRoot, err := os.Getwd()
+			rename := strings.TrimSpace(c.String("rename"))
+			if c.IsSet("rename") {
+				if len(threadArgs) > 1 {
+					return exitcode.Usagef("--rename can only be used when adding a single thread")
+				}
+				if err := project.ValidateName("thread", rename); err != nil {
+					return exitcode.UsageError(err)
+				}
+			}
+			if c.Bool("with-deps") && c.Bool("no-deps") {
+				return exitcode.Usagef("--with-deps and --no-deps cannot be used together")
+			}
+			dotfiles := ""
+			switch {
+			case c.Bool("include-dotfiles") && c.Bool("exclude-dotfiles"):
+				return exitcode.Usagef("--include-dotfiles and --exclude-dotfiles cannot be used together")
+			case c.Bool("include-dotfiles"):
+				dotfiles = threadconfig.DotfilesInclude
+			case c.Bool("exclude-dotfiles"):
+				dotfiles = threadconfig.DotfilesExclude
+			}
+			asCopy := c.Bool("as-copy")
+			if asCopy {
+				// Both only affect the thread's loom.yaml entry, which an untracked copy does not have.
+				for _, flag := range []string{"rename", "record-modes"} {
+					if c.IsSet(flag) {
+						return exitcode.Usagef("--as-copy cannot be combined with --%s", flag)
+					}
+				}
+			}
+			manifestOnly := c.Bool("manifest-only")
+			if manifestOnly {
+				// There is nothing to adopt in a copy that is not recorded, or in a fresh output directory.
+				for _, flag := range []string{"as-copy", "output-dir"} {
+					if c.IsSet(flag) {
+						return exitcode.Usagef("--manifest-only cannot be combined with --%s", flag)
+					}
+				}
+			}
+			overwritePolicy, err := threadconfig.ParseOverwritePolicy(c.String("overwrite-policy"))
 			if err != nil {
-				return fmt.Errorf("failed to get current directory: %v", err)
+				return exitcode.UsageError(err)
+			}
+			if overwritePolicy != "" {
+				// None of these ever resolves a conflict: they overwrite everything or write nothing.
+				for _, flag := range []string{"force", "output-dir", "manifest-only"} {
+					if c.IsSet(flag) {
+						return exitcode.Usagef("--overwrite-policy cannot be combined with --%s", flag)
+					}
+				}
 			}
+			opts := &addOptions{prefix: prefix, yes: c.Bool("yes"), force: c.Bool("force"), overwritePolicy: overwritePolicy, rename: rename, withDeps: c.Bool("with-deps"), noDeps: c.Bool("no-deps"), recordModes: c.Bool("record-modes"), dotfiles: dotfiles, asCopy: asCopy, manifestOnly: manifestOnly, showProgress: c.Bool("progress"), out: os.Stdout}
 
-			loomConfig, loomConfigPath, err := loadProjectLoomConfig(projectRoot)
-			if err != nil {
-				return err // Error already formatted by loadProjectLoomConfig
+			jsonOutput := c.Bool("json")
+			if jsonOutput {
+				// Progress messages and overwrite prompts go to stderr so stdout carries nothing but
+				// the JSON document.
+				opts.out = os.Stderr
 			}
 
-			threadPath, threadSource, err := handleThreadSearch(projectRoot, targetStoreName, threadName)
+			projectRoot, loomConfigPath, err := project.LocateManifest(c.String("config"))
 			if err != nil {
 				return err
 			}
-			// Safeguard, though handleThreadSearch should error out if not found.
-			if threadPath == "" {
-				return fmt.Errorf("thread '%s' not found after search (unexpected)", fullThreadArg)
+			opts.sourceConfigPath = loomConfigPath
+
+			if len(threadArgs) == 0 {
+				choice, err := pickThread(opts.out, loomConfigPath)
+				if err != nil {
+					return err
+				}
+				if choice == "" {
+					fmt.Fprintln(opts.out, "No thread selected.")
+					return nil
+				}
+				threadArgs = []string{choice}
 			}
 
-			filesByDir, err := copyDir(threadPath, projectRoot, threadName, threadSource, &loomConfig)
+			outputDir := c.String("output-dir")
+			if outputDir != "" {
+				// Threads still resolve against the real project, but nothing is written to it.
+				opts.force = true
+				if projectRoot, err = prepareOutputDir(outputDir); err != nil {
+					return err
+				}
+				loomConfigPath = filepath.Join(projectRoot, project.YamlFileName)
+			}
+
+			lock, err := project.AcquireLock(loomConfigPath, c.Bool("force-unlock"))
 			if err != nil {
-				return fmt.Errorf("failed to copy thread files: %v", err)
+				return err
 			}
+			defer func() { _ = lock.Release() }()
 
-			err = updateLoomConfig(loomConfigPath, threadName, threadSource, filesByDir, &loomConfig)
+			loomConfig, err := loadProjectLoomConfig(loomConfigPath)
 			if err != nil {
-				return fmt.Errorf("failed to update %s: %v", project.YamlFileName, err)
+				return err // Error already formatted by loadProjectLoomConfig
+			}
+			opts.journal = &addJournal{}
+			defer opts.journal.discard()
+			if outputDir != "" {
+				// The scratch directory is treated as empty: a previous preview's loom.yaml is replaced.
+				loomConfig = project.LoomConfig{Version: "1", Threads: []project.Thread{}}
+			}
+
+			var added []addedThread
+			var failed []string
+			var failedErrs []error
+			var addErr error
+			for _, fullThreadArg := range threadArgs {
+				results, err := resolveAndAddThread(projectRoot, fullThreadArg, resolve, opts, &loomConfig)
+				// Dependencies added before a failure stay added, like earlier threads.
+				for _, result := range results {
+					added = append(added, result)
+					if result.untracked {
+						fmt.Fprintf(opts.out, "Installed %d file(s) from '%s' as untracked copies; they will not be managed by loom.\n", result.fileCount, result.arg)
+						if result.released > 0 {
+							fmt.Fprintf(opts.out, "%d of them were owned by other threads and are no longer woven by them.\n", result.released)
+						}
+						continue
+					}
+					if opts.manifestOnly {
+						fmt.Fprintf(opts.out, "Thread '%s' registered from %s without copying files: %d adopted, %d not adopted.\n", result.arg, result.source, result.stats.Adopted, result.stats.Skipped)
+						continue
+					}
+					fmt.Fprintf(opts.out, "Thread '%s' added successfully from %s\n", result.arg, result.source)
+					if result.name != path.Base(filepath.ToSlash(result.arg)) && result.name != opts.rename {
+						fmt.Fprintf(opts.out, "Recorded in %s as '%s', the name its %s declares.\n", project.YamlFileName, result.name, threadconfig.ConfigFileName)
+					}
+				}
+				if err != nil {
+					if len(threadArgs) > 1 {
+						err = fmt.Errorf("failed to add thread '%s': %w", fullThreadArg, err)
+					}
+					if !continueOnError {
+						addErr = err
+						break
+					}
+					fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+					failed = append(failed, fullThreadArg)
+					failedErrs = append(failedErrs, err)
+				}
+			}
+
+			// Persist whatever was added, even if a later thread failed, so copied files stay tracked.
+			if manifestChanged(added) {
+				if err := saveAddedThreads(projectRoot, loomConfigPath, &loomConfig, added, opts.journal); err != nil {
+					return err
+				}
 			}
 
-			fmt.Printf("Thread '%s' added successfully from %s\n", fullThreadArg, threadSource)
+			if jsonOutput {
+				encoder := json.NewEncoder(os.Stdout)
+				encoder.SetIndent("", "  ")
+				if err := encoder.Encode(newAddResult(added, failed)); err != nil {
+					return fmt.Errorf("failed to write JSON output: %v", err)
+				}
+			} else {
+				if len(threadArgs) > 1 {
+					printAddSummary(opts.out, added, failed)
+				}
+				if len(added) > 0 {
+					var totals copyStats
+					for _, a := range added {
+						totals.add(a.stats)
+					}
+					fmt.Fprintf(opts.out, "Files: %s\n", totals)
+				}
+				if outputDir != "" && len(added) > 0 {
+					fmt.Fprintf(opts.out, "Wrote %d thread(s) to %s; the project was not changed.\n", len(added), projectRoot)
+				}
+			}
+
+			if addErr != nil {
+				return addErr
+			}
+			if len(failed) > 0 {
+				err := fmt.Errorf("failed to add %d of %d thread(s): %s", len(failed), len(threadArgs), strings.Join(failed, ", "))
+				// Exit like a single add would when every thread failed for a user error, such as
+				// a name that does not exist; any other failure makes the whole add one.
+				for _, failedErr := range failedErrs {
+					if exitcode.For(failedErr) != exitcode.Usage {
+						return err
+					}
+				}
+				return exitcode.UsageError(err)
+			}
 			return nil
 		},
 	}
 }
 
-// copyDir recursively copies files from src to dest and tracks the files by their directory structure
-// relative to the project root. It returns a map where keys are directory paths (with trailing slash)
-// It now includes conflict resolution.
-func copyDir(src string, dest string, currentThreadName string, displayCurrentThreadSource string, loomConfig *project.LoomConfig) (map[string][]string, error) {
+// threadChoice is an entry in the `loom add --interactive` picker.
+type threadChoice struct {
+	arg   string // Argument that adds the thread
+	label string // How the thread is listed
+}
+
+// availableThreads enumerates the threads in the project store and the configured local stores the
+// way `loom list --available` does, for the project whose loom.yaml is at loomConfigPath. Project
+// threads are added by bare name, which resolves to the project store first; store threads as
+// <store>/<thread>.
+func availableThreads(loomConfigPath string) ([]threadChoice, error) {
+	var choices []threadChoice
+	projectStorePath := filepath.Join(filepath.Dir(loomConfigPath), project.ProjectStoreDirName)
+	if info, err := os.Stat(projectStorePath); err == nil && info.IsDir() {
+		threads, err := listCmd.ListThreadsInProjectStore(projectStorePath)
+		if err != nil {
+			return nil, err
+		}
+		for _, threadName := range threads {
+			choices = append(choices, threadChoice{arg: threadName, label: threadName + " (project)"})
+		}
+	}
+
+	gConf, err := project.LoadStoreConfig(loomConfigPath)
+	if err != nil {
+		return nil, err
+	}
+	for _, store := range gConf.Stores {
+		resolver, err := threadstore.New(store)
+		if errors.Is(err, threadstore.ErrUnsupportedType) { // Only local stores can be enumerated for now
+			continue
+		} else if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: skipping store '%s': %v\n", store.Name, err)
+			continue
+		}
+		threads, err := resolver.ListThreads()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: skipping store '%s': %v\n", store.Name, err)
+			continue
+		}
+		for _, thread := range threads {
+			choices = append(choices, threadChoice{arg: thread.Store + "/" + thread.Name, label: thread.Store + "/" + thread.Name})
+		}
+	}
+	return choices, nil
+}
+
+// pickThread lists the available threads and asks the user to pick one by number. It returns the
+// argument that adds the chosen thread, or "" if the user pressed Enter to cancel.
+func pickThread(out io.Writer, loomConfigPath string) (string, error) {
+	choices, err := availableThreads(loomConfigPath)
+	if err != nil {
+		return "", err
+	}
+	if len(choices) == 0 {
+		return "", exitcode.Usagef("no threads available in the project's .loom folder or any configured local store")
+	}
+
+	fmt.Fprintln(out, "Available threads:")
+	for i, choice := range choices {
+		fmt.Fprintf(out, "  %d) %s\n", i+1, choice.label)
+	}
+	question := fmt.Sprintf("Select a thread to add [1-%d], or press Enter to cancel: ", len(choices))
+	invalid := fmt.Sprintf("Invalid selection. Please enter a number from 1 to %d.", len(choices))
+	return output.Ask(out, question, invalid, func(input string) (string, bool) {
+		if input == "" {
+			return "", true
+		}
+		if n, err := strconv.Atoi(input); err == nil && n >= 1 && n <= len(choices) {
+			return choices[n-1].arg, true
+		}
+		return "", false
+	})
+}
+
+// threadTarget is a resolved thread ready to be copied into the project.
+type threadTarget struct {
+	name   string // Name recorded in loom.yaml
+	path   string // Absolute path of the thread's _thread directory
+	source string // Source recorded in loom.yaml
+}
+
+// resolveAndAddThread resolves a thread argument with resolve and adds it to the project after its
+// missing dependencies. It returns every thread added, dependencies first, even when it fails.
+func resolveAndAddThread(projectRoot, fullThreadArg string, resolve func(loomConfigPath, arg string) (threadTarget, error), opts *addOptions, loomConfig *project.LoomConfig) ([]addedThread, error) {
+	target, err := resolve(opts.sourceConfigPath, fullThreadArg)
+	if err != nil {
+		return nil, err
+	}
+	if opts.rename != "" {
+		if target, err = renameTarget(target, opts.rename, loomConfig); err != nil {
+			return nil, err
+		}
+	}
+	return addWithDependencies(projectRoot, fullThreadArg, target, opts, loomConfig, nil)
+}
+
+// addWithDependencies adds the dependencies declared in target's config.yml depth-first, then target
+// itself, and records the dependency names on target's loom.yaml entry. chain holds the threads
+// whose dependencies are being added above target and is used to detect cycles.
+func addWithDependencies(projectRoot, arg string, target threadTarget, opts *addOptions, loomConfig *project.LoomConfig, chain []threadTarget) ([]addedThread, error) {
+	config, err := threadconfig.LoadThreadConfigForSource(target.path)
+	if err != nil {
+		return nil, err
+	}
+	chain = append(chain[:len(chain):len(chain)], target)
+
+	var added []addedThread
+	var dependsOn []string
+	for _, depArg := range config.Dependencies {
+		depAdded, depName, err := addDependency(projectRoot, depArg, opts, loomConfig, chain)
+		added = append(added, depAdded...)
+		if err != nil {
+			return added, err
+		}
+		dependsOn = append(dependsOn, depName)
+	}
+
+	result, err := addThread(projectRoot, target, opts, loomConfig)
+	if err != nil {
+		return added, err
+	}
+	result.arg = arg
+	for i := range loomConfig.Threads {
+		if loomConfig.Threads[i].Name == target.name {
+			loomConfig.Threads[i].DependsOn = dependsOn
+		}
+	}
+	return append(added, result), nil
+}
+
+// addDependency adds depArg, a dependency of the last thread in chain, unless it is already installed
+// or the user declines. It returns the threads added and the dependency's name in loom.yaml.
+func addDependency(projectRoot, depArg string, opts *addOptions, loomConfig *project.LoomConfig, chain []threadTarget) ([]addedThread, string, error) {
+	dependent := chain[len(chain)-1].name
+	_, depName, err := parseAddArgs(depArg)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid dependency '%s' of thread '%s': %w", depArg, dependent, err)
+	}
+	for _, t := range loomConfig.Threads {
+		if t.Name == depName {
+			return nil, depName, nil
+		}
+	}
+	if opts.noDeps {
+		fmt.Fprintf(opts.out, "Thread '%s' depends on '%s', which is not installed; skipping it (--no-deps).\n", dependent, depArg)
+		return nil, depName, nil
+	}
+
+	depTarget, err := resolveThreadArg(opts.sourceConfigPath, depArg)
+	if err != nil {
+		return nil, "", fmt.Errorf("cannot add dependency '%s' of thread '%s': %w", depArg, dependent, err)
+	}
+	if depTarget.name != depName {
+		// Recorded under the name its config.yml declares, which may already be installed.
+		for _, t := range loomConfig.Threads {
+			if t.Name == depTarget.name {
+				return nil, depTarget.name, nil
+			}
+		}
+	}
+	for i, t := range chain {
+		if t.path == depTarget.path {
+			var names []string
+			for _, c := range chain[i:] {
+				names = append(names, c.name)
+			}
+			return nil, "", fmt.Errorf("dependency cycle: %s -> %s", strings.Join(names, " -> "), depTarget.name)
+		}
+	}
+
+	if !opts.withDeps {
+		confirmed, err := output.Confirm(opts.out, fmt.Sprintf("Thread '%s' depends on '%s', which is not installed. Add it?", dependent, depArg), true)
+		if err != nil {
+			return nil, "", err
+		}
+		if !confirmed {
+			fmt.Fprintf(opts.out, "Skipping dependency '%s'; thread '%s' may not work without it.\n", depArg, dependent)
+			return nil, depName, nil
+		}
+	}
+
+	fmt.Fprintf(opts.out, "Adding dependency '%s' of thread '%s'\n", depArg, dependent)
+	depOpts := *opts
+	depOpts.rename = ""
+	added, err := addWithDependencies(projectRoot, depArg, depTarget, &depOpts, loomConfig, chain)
+	if err != nil {
+		return added, "", fmt.Errorf("failed to add dependency '%s' of thread '%s': %w", depArg, dependent, err)
+	}
+	return added, depTarget.name, nil
+}
+
+// renameTarget returns target recorded under newName. A store source also records the thread's own
+// name so weave can still find it, unless it already records the thread's nested path; project and
+// path sources already name the thread's directory.
+// It fails if a different thread is already recorded under newName.
+func renameTarget(target threadTarget, newName string, loomConfig *project.LoomConfig) (threadTarget, error) {
+	renamed := namedTarget(target, newName)
+	for _, t := range loomConfig.Threads {
+		if t.Name == newName && t.Source != renamed.source {
+			return threadTarget{}, exitcode.Usagef("cannot add '%s' as '%s': a thread named '%s' from '%s' already exists in %s", target.name, newName, newName, t.Source, project.YamlFileName)
+		}
+	}
+	return renamed, nil
+}
+
+// namedTarget returns target recorded under newName, with a store source that still finds the
+// thread's directory (see renameTarget).
+func namedTarget(target threadTarget, newName string) threadTarget {
+	source := project.ParseSource(target.source)
+	if source.Kind == project.SourceKindStore && source.Thread == "" && newName != target.name {
+		source.Thread = target.name
+	}
+	return threadTarget{name: newName, path: target.path, source: project.EncodeSource(source)}
+}
+
+// applyDeclaredName returns target recorded under the name its config.yml declares, if any. When
+// storeRoot is set, threadPath is the thread's path in the store at storeRoot, and no other thread
+// of that store may declare the same name, or the name would not identify one thread.
+func applyDeclaredName(target threadTarget, storeRoot, threadPath string) (threadTarget, error) {
+	config, err := threadconfig.LoadThreadConfigForSource(target.path)
+	if err != nil {
+		return threadTarget{}, err
+	}
+	if config.Name == "" {
+		return target, nil
+	}
+	if err := project.ValidateName("thread", config.Name); err != nil {
+		return threadTarget{}, exitcode.Usagef("thread '%s' declares an invalid name in %s: %v", target.name, threadconfig.ConfigFileName, err)
+	}
+	if storeRoot != "" {
+		store := &threadstore.LocalStore{Path: storeRoot}
+		refs, err := store.ListThreads()
+		if err != nil {
+			return threadTarget{}, fmt.Errorf("failed to check the names declared in %s: %w", storeRoot, err)
+		}
+		for _, ref := range refs {
+			if ref.Name == threadPath {
+				continue
+			}
+			other, err := threadconfig.LoadThreadConfig(filepath.Join(storeRoot, filepath.FromSlash(ref.Name)))
+			if err != nil || other.Name != config.Name {
+				continue // A broken config.yml elsewhere is reported when that thread is added
+			}
+			return threadTarget{}, exitcode.Usagef("threads '%s' and '%s' in %s both declare the name '%s' in %s; rename one of them", threadPath, ref.Name, storeRoot, config.Name, threadconfig.ConfigFileName)
+		}
+	}
+	return namedTarget(target, config.Name), nil
+}
+
+// ResolveThreadInStores resolves threadName against the configured local stores only, including
+// those the loom.yaml at loomConfigPath declares but never the project's .loom store, and returns
+// the thread's _thread directory and the source to record in loom.yaml. A non-empty storeName
+// restricts the search to that store.
+func ResolveThreadInStores(loomConfigPath, storeName, threadName string) (string, string, error) {
+	gConf, err := project.LoadStoreConfig(loomConfigPath)
+	if err != nil {
+		return "", "", err
+	}
+	threadPath, threadSource, found, err := findThreadInLocalStores(filepath.Dir(loomConfigPath), storeName, threadName, gConf)
+	if err != nil {
+		return "", "", fmt.Errorf("error searching in local stores: %w", err)
+	}
+	if !found {
+		if storeName != "" {
+			return "", "", exitcode.Usagef("thread '%s' not found in store '%s'", threadName, storeName)
+		}
+		return "", "", exitcode.Usagef("thread '%s' not found in any configured local store", threadName)
+	}
+	return threadPath, threadSource, nil
+}
+
+// resolveThreadArg resolves a <thread_name> or <store_name>/<thread_name> argument by searching the
+// stores of the project whose loom.yaml is at loomConfigPath.
+func resolveThreadArg(loomConfigPath, fullThreadArg string) (threadTarget, error) {
+	targetStoreName, threadName, err := parseAddArgs(fullThreadArg)
+	if err != nil {
+		return threadTarget{}, err
+	}
+
+	threadPath, threadSource, err := handleThreadSearch(loomConfigPath, targetStoreName, threadName)
+	if err != nil {
+		return threadTarget{}, err
+	}
+	// Safeguard, though handleThreadSearch should error out if not found.
+	if threadPath == "" {
+		return threadTarget{}, fmt.Errorf("thread '%s' not found after search (unexpected)", fullThreadArg)
+	}
+	// A nested thread is recorded under its own name, or the one its config.yml declares; its source
+	// keeps the path within the store.
+	storeRoot := filepath.Dir(threadPath)
+	for range strings.Split(threadName, "/") {
+		storeRoot = filepath.Dir(storeRoot)
+	}
+	return applyDeclaredName(threadTarget{name: path.Base(threadName), path: threadPath, source: threadSource}, storeRoot, threadName)
+}
+
+// ResolveThread resolves a <thread_name> or <store_name>/<thread_name> argument the same way
+// `loom add` does for the loom.yaml at loomConfigPath and returns the thread name and the source to
+// record in it.
+func ResolveThread(loomConfigPath, fullThreadArg string) (string, string, error) {
+	target, err := resolveThreadArg(loomConfigPath, fullThreadArg)
+	if err != nil {
+		return "", "", err
+	}
+	return target.name, target.source, nil
+}
+
+// ResolveThreadDir resolves a thread argument like ResolveThread but returns the thread's directory,
+// the one holding _thread and config.yml, instead of its loom.yaml source.
+func ResolveThreadDir(loomConfigPath, fullThreadArg string) (string, string, error) {
+	target, err := resolveThreadArg(loomConfigPath, fullThreadArg)
+	if err != nil {
+		return "", "", err
+	}
+	return target.name, filepath.Dir(target.path), nil
+}
+
+// resolveThreadFromDir resolves a thread directory given with --from for the project whose loom.yaml
+// is at loomConfigPath. The source is recorded as "path:<absolute dir>" so weave can find it again.
+func resolveThreadFromDir(loomConfigPath string, dir string) (threadTarget, error) {
+	projectRoot := filepath.Dir(loomConfigPath)
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return threadTarget{}, fmt.Errorf("failed to get absolute path for '%s': %w", dir, err)
+	}
+	dirInfo, err := os.Stat(absDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return threadTarget{}, exitcode.Usagef("thread directory '%s' does not exist", absDir)
+		}
+		return threadTarget{}, fmt.Errorf("failed to access thread directory '%s': %w", absDir, err)
+	}
+	if !dirInfo.IsDir() {
+		return threadTarget{}, exitcode.Usagef("'%s' is not a directory", absDir)
+	}
+	threadPath := filepath.Join(absDir, "_thread")
+	threadInfo, err := os.Stat(threadPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return threadTarget{}, exitcode.Usagef("'%s' is not a thread: missing _thread directory", absDir)
+		}
+		return threadTarget{}, fmt.Errorf("failed to access '%s': %w", threadPath, err)
+	}
+	if !threadInfo.IsDir() {
+		return threadTarget{}, fmt.Errorf("thread path '%s' is a file, not a directory", threadPath)
+	}
+	if project.PathContains(threadPath, projectRoot) {
+		return threadTarget{}, fmt.Errorf("thread directory '%s' contains the project root %s; refusing to copy it into itself", threadPath, projectRoot)
+	}
+	source := project.ThreadSource{Kind: project.SourceKindPath, Location: filepath.ToSlash(absDir)}
+	return applyDeclaredName(threadTarget{name: filepath.Base(absDir), path: threadPath, source: project.EncodeSource(source)}, "", "")
+}
+
+// addThread copies a resolved thread's files into the project and records it in loomConfig.
+// The caller is responsible for saving loomConfig.
+func addThread(projectRoot string, target threadTarget, opts *addOptions, loomConfig *project.LoomConfig) (addedThread, error) {
+	threadName, threadPath, threadSource := target.name, target.path, target.source
+	if source := project.ParseSource(threadSource); filepath.Dir(opts.sourceConfigPath) != projectRoot && source.Kind == project.SourceKindProject {
+		// A project source would be resolved against the output directory; point at the thread itself.
+		threadSource = project.EncodeSource(project.ThreadSource{Kind: project.SourceKindPath, Location: filepath.ToSlash(filepath.Dir(threadPath))})
+	}
+
+	threadOpts := *opts
+	if opts.recordModes {
+		threadOpts.modes = make(map[string]string)
+	}
+	var stats copyStats
+	// A thread that fails part way is undone, including any ownership it took from other threads.
+	mark := opts.journal.mark()
+	snapshot := loomConfig.Clone()
+	filesByDir, err := copyDir(threadPath, projectRoot, &threadOpts, threadName, threadSource, loomConfig, &stats)
+	if err != nil {
+		*loomConfig = snapshot
+		if rollbackErr := opts.journal.rollbackTo(mark); rollbackErr != nil {
+			return addedThread{}, fmt.Errorf("failed to copy thread files: %v; rolling back also failed: %v", err, rollbackErr)
+		}
+		return addedThread{}, fmt.Errorf("failed to copy thread files: %v", err)
+	}
+
+	fileCount := 0
+	for _, files := range filesByDir {
+		fileCount += len(files)
+	}
+	if opts.asCopy {
+		// The copy is not recorded, but the files it replaced must not be woven back over it.
+		released := 0
+		for dir, files := range filesByDir {
+			for _, file := range files {
+				if removeFileFromOtherThreads(loomConfig, "", dir, file) {
+					released++
+				}
+			}
+		}
+		return addedThread{name: threadName, sourceDir: threadPath, source: threadSource, fileCount: fileCount, stats: stats, untracked: true, released: released}, nil
+	}
+
+	applyThreadToLoomConfig(threadName, threadSource, opts.prefix, filesByDir, loomConfig)
+	for i := range loomConfig.Threads {
+		if loomConfig.Threads[i].Name == threadName {
+			loomConfig.Threads[i].Dotfiles = opts.dotfiles
+		}
+	}
+	if len(threadOpts.modes) > 0 {
+		for i := range loomConfig.Threads {
+			if loomConfig.Threads[i].Name != threadName {
+				continue
+			}
+			if loomConfig.Threads[i].Modes == nil {
+				loomConfig.Threads[i].Modes = make(map[string]string)
+			}
+			for file, mode := range threadOpts.modes {
+				loomConfig.Threads[i].Modes[file] = mode
+			}
+		}
+	}
+	return addedThread{name: threadName, sourceDir: threadPath, source: threadSource, fileCount: fileCount, stats: stats}, nil
+}
+
+// prepareOutputDir creates the --output-dir directory if needed and returns its absolute path. It
+// must not be a file.
+func prepareOutputDir(dir string) (string, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to get absolute path for '%s': %w", dir, err)
+	}
+	if info, err := os.Stat(absDir); err == nil && !info.IsDir() {
+		return "", exitcode.Usagef("output directory '%s' is a file", absDir)
+	}
+	if err := os.MkdirAll(absDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create output directory '%s': %w", absDir, err)
+	}
+	return absDir, nil
+}
+
+// printAddSummary prints the threads added in a multi-thread invocation and any that failed.
+func printAddSummary(out io.Writer, added []addedThread, failed []string) {
+	fmt.Fprintln(out, "\nSummary:")
+	for _, a := range added {
+		fmt.Fprintf(out, "  - %s (from %s): %d file(s) (%s)\n", a.arg, a.source, a.fileCount, a.stats)
+	}
+	for _, f := range failed {
+		fmt.Fprintf(out, "  - %s: failed\n", f)
+	}
+}
+
+// copyDir recursively copies files from src to projectRoot (or projectRoot/opts.prefix) and tracks the files by their
+// directory structure relative to the project root. It returns a map where keys are directory paths (with trailing slash)
+// It now includes conflict resolution, governed by opts. Per-file outcomes are tallied into stats.
+func copyDir(src string, projectRoot string, opts *addOptions, currentThreadName string, displayCurrentThreadSource string, loomConfig *project.LoomConfig, stats *copyStats) (map[string][]string, error) {
 	// We need to track the original project root to calculate relative paths correctly
+	dest := filepath.Join(projectRoot, filepath.FromSlash(opts.prefix))
 	// Ensure the base destination directory exists
-	if err := os.MkdirAll(dest, os.ModePerm); err != nil {
-		return nil, fmt.Errorf("failed to create base destination directory %s: %w", dest, err)
+	if !opts.manifestOnly {
+		opts.journal.recordDir(dest)
+		if err := os.MkdirAll(dest, os.ModePerm); err != nil {
+			return nil, fmt.Errorf("failed to create base destination directory %s: %w", dest, err)
+		}
+	}
+
+	threadConfig, err := threadconfig.LoadThreadConfigForSource(src)
+	if err != nil {
+		return nil, err
+	}
+	if opts.dotfiles != "" {
+		threadConfig.Dotfiles = opts.dotfiles
+	}
+	// Resolve config.yml path rewrites to absolute paths once so the recursion can look them up directly.
+	renames := make(map[string]string, len(threadConfig.Map))
+	for srcRel, destRel := range threadConfig.Map {
+		renames[filepath.Join(src, filepath.FromSlash(srcRel))] = filepath.Join(dest, filepath.FromSlash(destRel))
+	}
+	threadOpts := *opts
+	threadOpts.lineEndings = threadConfig.LineEndings
+	threadOpts.policyFor = func(srcPath string) string {
+		rel, err := filepath.Rel(src, srcPath)
+		if err != nil {
+			return ""
+		}
+		return threadConfig.PolicyFor(filepath.ToSlash(rel))
 	}
-	return copyDirWithBasePath(src, dest, dest, currentThreadName, displayCurrentThreadSource, loomConfig)
+	if threadConfig.HasFileFilter() {
+		threadOpts.includes = func(srcPath string) bool {
+			rel, err := filepath.Rel(src, srcPath)
+			if err != nil {
+				return false
+			}
+			return threadConfig.Includes(filepath.ToSlash(rel))
+		}
+	}
+	fileCount, err := countThreadFiles(src, threadOpts.includes)
+	if err != nil {
+		return nil, err
+	}
+	threadOpts.progress = output.NewProgressTo(opts.out, fileCount, opts.showProgress)
+	defer threadOpts.progress.Finish()
+	return copyDirWithBasePath(src, dest, projectRoot, currentThreadName, displayCurrentThreadSource, renames, &threadOpts, loomConfig, stats)
+}
+
+// countThreadFiles returns the number of files copyDirWithBasePath processes from src: every file,
+// or with includes set only those it accepts.
+func countThreadFiles(src string, includes func(srcPath string) bool) (int, error) {
+	count := 0
+	err := filepath.WalkDir(src, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.IsDir() && (includes == nil || includes(p)) {
+			count++
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to read source directory %s: %w", src, err)
+	}
+	return count, nil
 }
 
 // handleExistingFileConflict checks if a file at destPath conflicts with the thread being added.
 // It prompts the user if necessary and returns true if the file should be overwritten,
 // false if it should be skipped, and an error if a critical issue occurs (e.g., stat fails unexpectedly, prompt fails).
-func handleExistingFileConflict(destPath, baseProjectPath, displayCurrentThreadSource string, loomConfig *project.LoomConfig) (bool, error) {
+// With opts.force every existing file is overwritten without any checks. Otherwise policy (--overwrite-policy's
+// or the file's config.yml conflict policy, or "") answers the prompts, and failing that opts.yes does.
+// --overwrite-policy ours also keeps files the thread already owns.
+func handleExistingFileConflict(destPath, baseProjectPath, displayCurrentThreadSource, policy string, opts *addOptions, loomConfig *project.LoomConfig) (bool, error) {
 	// Check if the file already exists in the destination
 	_, statErr := os.Stat(destPath)
+	if statErr == nil && opts.force {
+		return true, nil
+	}
 	if statErr == nil { // File exists
 		ownerThreadNameFromConfig, isOwned := loomConfig.IsFileOwned(destPath, baseProjectPath)
 		relDestPath, err := filepath.Rel(baseProjectPath, destPath)
@@ -230,31 +1179,35 @@ func handleExistingFileConflict(destPath, baseProjectPath, displayCurrentThreadS
 			}
 
 			if ownerThreadSourceFromConfig == displayCurrentThreadSource {
+				if opts.overwritePolicy == threadconfig.PolicySkip {
+					fmt.Fprintf(opts.out, "Keeping file '%s' as it is (--overwrite-policy ours).\n", relDestPath)
+					return false, nil
+				}
 				return true, nil
 			}
-			fmt.Printf("File '%s' is currently owned by thread '%s'.\n", relDestPath, ownerThreadSourceFromConfig)
-			choice, promptErr := promptUserForOverwrite(fmt.Sprintf("Do you want thread '%s' to take ownership of '%s' and overwrite it?", displayCurrentThreadSource, relDestPath))
+			output.Fprintf(opts.out, output.StyleTransfer, "File '%s' is currently owned by thread '%s'.\n", relDestPath, ownerThreadSourceFromConfig)
+			choice, promptErr := confirmOverwrite(opts, policy, true, output.StyleTransfer, fmt.Sprintf("Do you want thread '%s' to take ownership of '%s' and overwrite it?", displayCurrentThreadSource, relDestPath))
 			if promptErr != nil {
 				return false, fmt.Errorf("failed to get user input for %s: %w", relDestPath, promptErr)
 			}
 
 			if choice == "yes" {
-				fmt.Printf("Thread '%s' is taking ownership of '%s'.\n", displayCurrentThreadSource, relDestPath)
+				output.Fprintf(opts.out, output.StyleTransfer, "Thread '%s' is taking ownership of '%s'.\n", displayCurrentThreadSource, relDestPath)
 				return true, nil
 			}
-			fmt.Printf("Skipping file '%s'. Thread '%s' retains ownership.\n", relDestPath, ownerThreadSourceFromConfig)
+			fmt.Fprintf(opts.out, "Skipping file '%s'. Thread '%s' retains ownership.\n", relDestPath, ownerThreadSourceFromConfig)
 			return false, nil
 		}
-		fmt.Printf("File '%s' exists but is not currently owned by any Loom thread.\n", relDestPath)
-		choice, promptErr := promptUserForOverwrite(fmt.Sprintf("Do you want thread '%s' to take ownership of '%s' and overwrite it?", displayCurrentThreadSource, relDestPath))
+		output.Fprintf(opts.out, output.StyleOverwrite, "File '%s' exists but is not currently owned by any Loom thread.\n", relDestPath)
+		choice, promptErr := confirmOverwrite(opts, policy, false, output.StyleOverwrite, fmt.Sprintf("Do you want thread '%s' to take ownership of '%s' and overwrite it?", displayCurrentThreadSource, relDestPath))
 		if promptErr != nil {
 			return false, fmt.Errorf("failed to get user input for %s: %w", relDestPath, promptErr)
 		}
 		if choice == "yes" {
-			fmt.Printf("Thread '%s' is taking ownership of '%s'.\n", displayCurrentThreadSource, relDestPath)
+			output.Fprintf(opts.out, output.StyleOverwrite, "Thread '%s' is taking ownership of '%s'.\n", displayCurrentThreadSource, relDestPath)
 			return true, nil
 		}
-		fmt.Printf("Skipping file '%s'. It remains an unmanaged file or user version.\n", relDestPath)
+		fmt.Fprintf(opts.out, "Skipping file '%s'. It remains an unmanaged file or user version.\n", relDestPath)
 		return false, nil
 	} else if os.IsNotExist(statErr) {
 		return true, nil
@@ -264,49 +1217,153 @@ func handleExistingFileConflict(destPath, baseProjectPath, displayCurrentThreadS
 
 // _processFileCopy handles the logic for copying a single file, including conflict resolution.
 // It returns the relative directory path (e.g., "./", "subdir/") and the file name if the file was successfully copied,
-// or empty strings and potentially an error if skipped or an error occurred.
-func _processFileCopy(srcPath, destPath, baseProjectPath, currentThreadName, displayCurrentThreadSource string, srcFileInfo os.FileInfo, loomConfig *project.LoomConfig) (string, string, error) {
+// or empty strings and potentially an error if skipped or an error occurred. The outcome is counted in stats.
+func _processFileCopy(srcPath, destPath, baseProjectPath, currentThreadName, displayCurrentThreadSource string, srcFileInfo os.FileInfo, opts *addOptions, loomConfig *project.LoomConfig, stats *copyStats) (string, string, error) {
 	destFileDir := filepath.Dir(destPath)
+	if opts.manifestOnly {
+		return adoptExistingFile(destPath, baseProjectPath, currentThreadName, opts, loomConfig, stats)
+	}
+	opts.journal.recordDir(destFileDir)
 	if err := os.MkdirAll(destFileDir, os.ModePerm); err != nil {
 		return "", "", fmt.Errorf("failed to create parent directory for destination file %s: %w", destPath, err)
 	}
 
-	shouldOverwrite, conflictErr := handleExistingFileConflict(destPath, baseProjectPath, displayCurrentThreadSource, loomConfig)
+	_, statErr := os.Stat(destPath)
+	existed := statErr == nil
+
+	if existed {
+		// A file that already holds what the thread would write needs neither a prompt nor a copy.
+		identical, err := project.InstalledMatches(srcPath, destPath, opts.lineEndings)
+		if err != nil {
+			return "", "", err
+		}
+		if identical {
+			return keepIdenticalFile(destPath, baseProjectPath, currentThreadName, srcFileInfo, opts, loomConfig, stats)
+		}
+	}
+
+	policy := opts.overwritePolicy
+	if policy == "" && opts.policyFor != nil {
+		policy = opts.policyFor(srcPath)
+	}
+	shouldOverwrite, conflictErr := handleExistingFileConflict(destPath, baseProjectPath, displayCurrentThreadSource, policy, opts, loomConfig)
 	if conflictErr != nil {
 		return "", "", conflictErr
 	}
 
 	if !shouldOverwrite {
+		stats.Skipped++
+		if owner, owned := loomConfig.IsFileOwned(destPath, baseProjectPath); owned && owner == currentThreadName {
+			// Kept as it is on disk, but still the thread's file.
+			relDir, err := manifestDir(baseProjectPath, destFileDir)
+			if err != nil {
+				return "", "", err
+			}
+			return relDir, filepath.Base(destPath), nil
+		}
 		return "", "", nil // Skipped
 	}
 
-	data, err := os.ReadFile(srcPath)
+	if err := opts.journal.recordFile(destPath); err != nil {
+		return "", "", err
+	}
+	if err := project.CopyThreadFile(srcPath, destPath, srcFileInfo.Mode(), opts.lineEndings); err != nil {
+		return "", "", err
+	}
+	if existed {
+		stats.Overwritten++
+	} else {
+		stats.Created++
+	}
+
+	relDir, err := manifestDir(baseProjectPath, destFileDir)
 	if err != nil {
-		return "", "", fmt.Errorf("failed to read source file %s: %w", srcPath, err)
+		return "", "", err
+	}
+	if opts.modes != nil {
+		opts.modes[strings.TrimPrefix(relDir, "./")+filepath.Base(destPath)] = project.FormatMode(srcFileInfo.Mode())
 	}
-	err = os.WriteFile(destPath, data, srcFileInfo.Mode())
+	return relDir, filepath.Base(destPath), nil
+}
+
+// keepIdenticalFile handles a project file at destPath that is identical to the thread's version: it
+// is left untouched and recorded for the thread being added, unless another thread owns it, in which
+// case it stays with that thread. Either way it is counted as unchanged.
+func keepIdenticalFile(destPath, baseProjectPath, currentThreadName string, srcFileInfo os.FileInfo, opts *addOptions, loomConfig *project.LoomConfig, stats *copyStats) (string, string, error) {
+	stats.Unchanged++
+	if owner, owned := loomConfig.IsFileOwned(destPath, baseProjectPath); owned && owner != currentThreadName {
+		return "", "", nil
+	}
+	relDir, err := manifestDir(baseProjectPath, filepath.Dir(destPath))
 	if err != nil {
-		return "", "", fmt.Errorf("failed to write destination file %s: %w", destPath, err)
+		return "", "", err
 	}
+	if opts.modes != nil {
+		opts.modes[strings.TrimPrefix(relDir, "./")+filepath.Base(destPath)] = project.FormatMode(srcFileInfo.Mode())
+	}
+	return relDir, filepath.Base(destPath), nil
+}
 
-	relDir := "./"
-	if destFileDir != baseProjectPath {
-		relPathCurrent, err := filepath.Rel(baseProjectPath, destFileDir)
-		if err != nil {
-			return "", "", fmt.Errorf("failed to get relative path for %s from %s: %w", destFileDir, baseProjectPath, err)
-		}
-		if relPathCurrent == "." {
-			relDir = "./"
-		} else {
-			relDir = filepath.ToSlash(relPathCurrent) + "/"
-		}
+// manifestDir returns the loom.yaml directory key ("./", "subdir/") of destFileDir, a directory in
+// the project at baseProjectPath.
+func manifestDir(baseProjectPath, destFileDir string) (string, error) {
+	if destFileDir == baseProjectPath {
+		return "./", nil
+	}
+	relPathCurrent, err := filepath.Rel(baseProjectPath, destFileDir)
+	if err != nil {
+		return "", fmt.Errorf("failed to get relative path for %s from %s: %w", destFileDir, baseProjectPath, err)
 	}
-	return relDir, srcFileInfo.Name(), nil
+	if relPathCurrent == "." {
+		return "./", nil
+	}
+	return filepath.ToSlash(relPathCurrent) + "/", nil
+}
+
+// adoptExistingFile records, for --manifest-only, the project file at destPath as owned by the
+// thread being added, without writing it. Like _processFileCopy it returns the file's manifest
+// directory and name, or empty strings if the file is missing or owned by another thread, which
+// are reported and counted as skipped.
+func adoptExistingFile(destPath, baseProjectPath, currentThreadName string, opts *addOptions, loomConfig *project.LoomConfig, stats *copyStats) (string, string, error) {
+	relPath, _ := filepath.Rel(baseProjectPath, destPath)
+	relPath = filepath.ToSlash(relPath)
+	info, err := os.Stat(destPath)
+	if os.IsNotExist(err) {
+		output.ClearProgress()
+		fmt.Fprintf(opts.out, "Not installed: '%s' is not in the project.\n", relPath)
+		stats.Skipped++
+		return "", "", nil
+	} else if err != nil {
+		return "", "", fmt.Errorf("failed to stat %s: %w", destPath, err)
+	}
+	if info.IsDir() {
+		output.ClearProgress()
+		fmt.Fprintf(opts.out, "Not installed: '%s' is a directory in the project.\n", relPath)
+		stats.Skipped++
+		return "", "", nil
+	}
+	if owner, owned := loomConfig.IsFileOwned(destPath, baseProjectPath); owned && owner != currentThreadName {
+		output.ClearProgress()
+		fmt.Fprintf(opts.out, "Not adopted: '%s' is owned by thread '%s'.\n", relPath, owner)
+		stats.Skipped++
+		return "", "", nil
+	}
+
+	relDir, err := manifestDir(baseProjectPath, filepath.Dir(destPath))
+	if err != nil {
+		return "", "", err
+	}
+	if opts.modes != nil {
+		opts.modes[strings.TrimPrefix(relDir, "./")+filepath.Base(destPath)] = project.FormatMode(info.Mode())
+	}
+	stats.Adopted++
+	return relDir, filepath.Base(destPath), nil
 }
 
 // copyDirWithBasePath is an internal helper that maintains the base project path during recursion
-// It now includes conflict resolution.
-func copyDirWithBasePath(src string, dest string, baseProjectPath string, currentThreadName string, displayCurrentThreadSource string, loomConfig *project.LoomConfig) (map[string][]string, error) {
+// It now includes conflict resolution. renames maps absolute source file paths to absolute destination
+// paths for files renamed by the thread's config.yml.
+func copyDirWithBasePath(src string, dest string, baseProjectPath string, currentThreadName string, displayCurrentThreadSource string, renames map[string]string, opts *addOptions, loomConfig *project.LoomConfig, stats *copyStats) (map[string][]string, error) {
 	filesByDir := make(map[string][]string)
 	entries, err := os.ReadDir(src)
 	if err != nil {
@@ -323,11 +1380,15 @@ func copyDirWithBasePath(src string, dest string, baseProjectPath string, curren
 		}
 
 		if entry.IsDir() {
-			if err := os.MkdirAll(destPath, srcFileInfo.Mode()); err != nil {
-				return nil, fmt.Errorf("failed to create destination directory %s: %w", destPath, err)
+			// With a files filter, directories are only created for the files copied into them.
+			if opts.includes == nil && !opts.manifestOnly {
+				opts.journal.recordDir(destPath)
+				if err := os.MkdirAll(destPath, srcFileInfo.Mode()); err != nil {
+					return nil, fmt.Errorf("failed to create destination directory %s: %w", destPath, err)
+				}
 			}
 
-			subFilesByDir, err := copyDirWithBasePath(srcPath, destPath, baseProjectPath, currentThreadName, displayCurrentThreadSource, loomConfig)
+			subFilesByDir, err := copyDirWithBasePath(srcPath, destPath, baseProjectPath, currentThreadName, displayCurrentThreadSource, renames, opts, loomConfig, stats)
 			if err != nil {
 				return nil, err // Propagate error from recursive call
 			}
@@ -335,8 +1396,21 @@ func copyDirWithBasePath(src string, dest string, baseProjectPath string, curren
 				filesByDir[dir] = append(filesByDir[dir], files...)
 			}
 		} else {
+			if opts.includes != nil && !opts.includes(srcPath) {
+				continue
+			}
+			if renamedPath, ok := renames[srcPath]; ok {
+				destPath = renamedPath
+			}
+			progressVerb := "copying"
+			if opts.manifestOnly {
+				progressVerb = "adopting"
+			}
+			if rel, err := filepath.Rel(baseProjectPath, destPath); err == nil {
+				opts.progress.Step(progressVerb, filepath.ToSlash(rel))
+			}
 			// Process file using the new helper function
-			relDir, fileName, err := _processFileCopy(srcPath, destPath, baseProjectPath, currentThreadName, displayCurrentThreadSource, srcFileInfo, loomConfig)
+			relDir, fileName, err := _processFileCopy(srcPath, destPath, baseProjectPath, currentThreadName, displayCurrentThreadSource, srcFileInfo, opts, loomConfig, stats)
 			if err != nil {
 				return nil, err // Propagate error from file processing
 			}
@@ -348,31 +1422,34 @@ func copyDirWithBasePath(src string, dest string, baseProjectPath string, curren
 	return filesByDir, nil
 }
 
-// promptUserForOverwrite prompts the user with a message and expects a yes/no/skip response.
-func promptUserForOverwrite(message string) (string, error) {
-	reader := bufio.NewReader(os.Stdin)
-	for {
-		fmt.Printf("%s [Y]es/[N]o/[S]kip [Yes]: ", message)
-		input, err := reader.ReadString('\n')
-		if err != nil {
-			return "", err
-		}
-		input = strings.ToLower(strings.TrimSpace(input))
-		switch input {
-		case "", "yes", "y":
-			return "yes", nil
-		case "no", "n":
-			return "no", nil
-		case "skip", "s":
-			return "skip", nil
-		}
-		fmt.Println("Invalid input. Please enter 'yes', 'no', 'skip', or press Enter for 'yes'.")
+// confirmOverwrite asks the user to confirm an overwrite of a file that another thread owns, if owned
+// is set, or that no thread owns. A policy of overwrite or skip, from --overwrite-policy or config.yml,
+// answers on their behalf, as does opts.yes for files without one. Pressing Enter gives the answer
+// the global configuration's defaults section prefers.
+func confirmOverwrite(opts *addOptions, policy string, owned bool, style output.Style, message string) (string, error) {
+	reason := "config.yml policy"
+	if opts.overwritePolicy != "" {
+		reason = "--overwrite-policy"
+	}
+	switch policy {
+	case threadconfig.PolicyOverwrite:
+		fmt.Fprintf(opts.out, "%s yes (%s)\n", output.Paint(style, message), reason)
+		return "yes", nil
+	case threadconfig.PolicySkip:
+		fmt.Fprintf(opts.out, "%s no (%s)\n", output.Paint(style, message), reason)
+		return "no", nil
+	}
+	if opts.yes {
+		fmt.Fprintf(opts.out, "%s yes (--yes)\n", output.Paint(style, message))
+		return "yes", nil
 	}
+	return output.AskChoice(opts.out, style, message, []string{"yes", "no", "skip"}, globalconfig.ConflictDefault(owned))
 }
 
 // removeFileFromOtherThreads removes a specific file from all threads except the currentThreadName.
-// It modifies the config.Threads in place.
-func removeFileFromOtherThreads(config *project.LoomConfig, currentThreadName, dirToRemove, fileToRemove string) {
+// It modifies the config.Threads in place and reports whether any thread owned the file.
+func removeFileFromOtherThreads(config *project.LoomConfig, currentThreadName, dirToRemove, fileToRemove string) bool {
+	removed := false
 	for i, otherThread := range config.Threads {
 		if otherThread.Name == currentThreadName {
 			continue
@@ -393,6 +1470,7 @@ func removeFileFromOtherThreads(config *project.LoomConfig, currentThreadName, d
 			}
 
 			if fileWasRemoved {
+				removed = true
 				if len(updatedFilesInDir) == 0 {
 					delete(config.Threads[i].Files, dirToRemove)
 					// If the Files map itself becomes empty, nil it out for cleaner YAML
@@ -405,11 +1483,12 @@ func removeFileFromOtherThreads(config *project.LoomConfig, currentThreadName, d
 			}
 		}
 	}
+	return removed
 }
 
-// updateLoomConfig updates the loom.yaml configuration by removing added files from other threads
+// applyThreadToLoomConfig updates the in-memory configuration by removing added files from other threads
 // and then adding or updating the current thread's information.
-func updateLoomConfig(configPath string, threadName string, source string, filesByDir map[string][]string, config *project.LoomConfig) error {
+func applyThreadToLoomConfig(threadName string, source string, prefix string, filesByDir map[string][]string, config *project.LoomConfig) {
 	// Remove the files being added from any other threads
 	for dir, files := range filesByDir {
 		for _, file := range files {
@@ -431,9 +1510,15 @@ func updateLoomConfig(configPath string, threadName string, source string, files
 		}
 	}
 
+	now := project.Timestamp()
 	if foundThreadIndex != -1 {
 		// Update existing thread
 		config.Threads[foundThreadIndex].Source = source
+		config.Threads[foundThreadIndex].Prefix = prefix
+		config.Threads[foundThreadIndex].UpdatedAt = now
+		if config.Threads[foundThreadIndex].InstalledAt == "" {
+			config.Threads[foundThreadIndex].InstalledAt = now
+		}
 		if config.Threads[foundThreadIndex].Files == nil {
 			config.Threads[foundThreadIndex].Files = make(map[string][]string)
 		}
@@ -444,14 +1529,83 @@ func updateLoomConfig(configPath string, threadName string, source string, files
 	} else {
 		// Add new thread
 		newThread := project.Thread{
-			Name:   threadName,
-			Source: source,
-			Files:  filesByDir,
+			Name:        threadName,
+			Source:      source,
+			Prefix:      prefix,
+			InstalledAt: now,
+			UpdatedAt:   now,
+			Files:       filesByDir,
 		}
 		config.Threads = append(config.Threads, newThread)
 	}
+}
+
+// updateLockFile records in loom.lock how the threads in added were resolved, keeping the entries of
+// other threads and dropping those no longer in loomConfig.
+func updateLockFile(projectRoot, loomConfigPath string, loomConfig *project.LoomConfig, added []addedThread) error {
+	lockPath := project.LockFilePath(loomConfigPath)
+	lock, err := project.LoadLockFile(lockPath)
+	if os.IsNotExist(err) {
+		lock = &project.LockFile{}
+	} else if err != nil {
+		return err
+	}
+	for _, a := range added {
+		for i := range loomConfig.Threads {
+			if loomConfig.Threads[i].Name != a.name {
+				continue
+			}
+			locked, err := project.LockThread(&loomConfig.Threads[i], a.sourceDir, projectRoot)
+			if err != nil {
+				return err
+			}
+			lock.Set(locked)
+		}
+	}
+	lock.Retain(loomConfig)
+	return project.SaveLockFile(lockPath, lock)
+}
+
+// manifestChanged reports whether adding added changed loom.yaml: untracked copies only change it
+// when they take files from other threads.
+func manifestChanged(added []addedThread) bool {
+	for _, a := range added {
+		if !a.untracked || a.released > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// saveAddedThreads writes loom.yaml and loom.lock after threads were added. If either cannot be
+// written, every file the add copied is removed or restored, along with both manifests, so the
+// project is left as it was before the add.
+func saveAddedThreads(projectRoot, loomConfigPath string, loomConfig *project.LoomConfig, added []addedThread, journal *addJournal) error {
+	lockPath := project.LockFilePath(loomConfigPath)
+	saveErr := journal.recordFile(loomConfigPath)
+	if saveErr == nil {
+		saveErr = journal.recordFile(lockPath)
+	}
+	if saveErr == nil {
+		if err := saveLoomConfig(loomConfigPath, loomConfig); err != nil {
+			saveErr = fmt.Errorf("failed to update %s: %v", project.YamlFileName, err)
+		} else if err := updateLockFile(projectRoot, loomConfigPath, loomConfig, added); err != nil {
+			saveErr = fmt.Errorf("failed to update %s: %v", project.ResolutionLockFileName, err)
+		}
+	}
+	if saveErr == nil {
+		return nil
+	}
+	if err := journal.rollbackTo(0); err != nil {
+		return fmt.Errorf("%w; rolling back the copied files also failed: %v", saveErr, err)
+	}
+	fmt.Fprintf(os.Stderr, "Rolled back: the %d thread(s) added were removed and overwritten files restored.\n", len(added))
+	return saveErr
+}
 
-	// Marshal and write the updated configuration
+// saveLoomConfig marshals the configuration and writes it to configPath.
+func saveLoomConfig(configPath string, config *project.LoomConfig) error {
+	config.Normalize()
 	updatedData, err := yaml.Marshal(config)
 	if err != nil {
 		return err
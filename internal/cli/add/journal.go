@@ -0,0 +1,122 @@
+package add
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"loom/internal/core/fileutil"
+)
+
+// addJournal records every change `loom add` makes on disk, so a thread that fails part way, or a
+// manifest that cannot be saved, leaves the project as it was. The previous contents of overwritten
+// files are kept in a temporary directory until the journal is discarded. A nil journal records
+// nothing.
+type addJournal struct {
+	backupDir string // Created on the first overwrite
+	entries   []journalEntry
+}
+
+// journalEntry is one change, undone by rollbackTo.
+type journalEntry struct {
+	path   string
+	backup string // Copy of the previous contents of an overwritten file; "" if add created path
+	dir    bool   // path is a directory add created
+}
+
+// recordFile must be called before the file at path is written. An existing file is backed up so
+// rollback can restore it; a missing one is deleted on rollback.
+func (j *addJournal) recordFile(path string) error {
+	if j == nil {
+		return nil
+	}
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		j.entries = append(j.entries, journalEntry{path: path})
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+	if j.backupDir == "" {
+		if j.backupDir, err = os.MkdirTemp("", "loom-add-"); err != nil {
+			return fmt.Errorf("failed to create a directory for backups: %w", err)
+		}
+	}
+	backup := filepath.Join(j.backupDir, fmt.Sprintf("%d", len(j.entries)))
+	if err := fileutil.CopyFile(path, backup, info.Mode()); err != nil {
+		return fmt.Errorf("failed to back up %s: %w", path, err)
+	}
+	j.entries = append(j.entries, journalEntry{path: path, backup: backup})
+	return nil
+}
+
+// recordDir must be called before dir is created with os.MkdirAll. The directories it will create
+// are removed on rollback, innermost first.
+func (j *addJournal) recordDir(dir string) {
+	if j == nil {
+		return
+	}
+	var missing []string
+	for current := filepath.Clean(dir); ; current = filepath.Dir(current) {
+		if _, err := os.Stat(current); err == nil || !os.IsNotExist(err) {
+			break
+		}
+		missing = append(missing, current)
+		if filepath.Dir(current) == current {
+			break
+		}
+	}
+	for i := len(missing) - 1; i >= 0; i-- {
+		j.entries = append(j.entries, journalEntry{path: missing[i], dir: true})
+	}
+}
+
+// mark returns a position that rollbackTo can undo changes back to.
+func (j *addJournal) mark() int {
+	if j == nil {
+		return 0
+	}
+	return len(j.entries)
+}
+
+// rollbackTo undoes the changes recorded since mark, newest first: overwritten files get their
+// previous contents back, and created files and directories are removed. It carries on past
+// failures and reports them together.
+func (j *addJournal) rollbackTo(mark int) error {
+	if j == nil {
+		return nil
+	}
+	var errs []error
+	for i := len(j.entries) - 1; i >= mark; i-- {
+		entry := j.entries[i]
+		switch {
+		case entry.backup != "":
+			info, err := os.Stat(entry.backup)
+			if err == nil {
+				err = fileutil.CopyFile(entry.backup, entry.path, info.Mode())
+			}
+			if err != nil {
+				errs = append(errs, fmt.Errorf("failed to restore %s: %w", entry.path, err))
+			}
+		case entry.dir:
+			// Only empty directories are removed; anything else put there is left alone.
+			_ = os.Remove(entry.path)
+		default:
+			if err := os.Remove(entry.path); err != nil && !os.IsNotExist(err) {
+				errs = append(errs, fmt.Errorf("failed to remove %s: %w", entry.path, err))
+			}
+		}
+	}
+	j.entries = j.entries[:mark]
+	return errors.Join(errs...)
+}
+
+// discard deletes the backups once the add can no longer be rolled back.
+func (j *addJournal) discard() {
+	if j == nil || j.backupDir == "" {
+		return
+	}
+	_ = os.RemoveAll(j.backupDir)
+	j.backupDir = ""
+}
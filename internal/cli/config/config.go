@@ -2,15 +2,31 @@
 package config
 
 import (
-	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/json"
 	"fmt"
+	"net/url"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"time"
 
+	addCmd "loom/internal/cli/add"
+	listCmd "loom/internal/cli/list"
+	"loom/internal/core/exitcode"
+	"loom/internal/core/fileutil"
 	"loom/internal/core/globalconfig"
+	"loom/internal/core/output"
+	"loom/internal/core/project"
+	"loom/internal/core/threadconfig"
+	"loom/internal/core/threadstore"
 
 	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v3"
 )
 
 // Command returns the cli.Command for the "config" group.
@@ -21,9 +37,56 @@ func Command() *cli.Command {
 		Subcommands: []*cli.Command{
 			{
 				Name:      "add",
-				Usage:     "Add a new thread store. Usage: loom config add <path_or_url>",
+				Usage:     "Add a new thread store. Usage: loom config add [--name <name>] <path_or_url> OR loom config add --type <type> --path <path_or_url>",
 				ArgsUsage: "<path_or_url>",
-				Action:    addStoreAction,
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "name",
+						Usage: "Name for the store. Overrides the inferred name and fails instead of prompting if the name is taken.",
+					},
+					&cli.StringFlag{
+						Name:  "type",
+						Usage: "Store type (local, github, git, archive). With --path, skips inferring details from the argument.",
+					},
+					&cli.StringFlag{
+						Name:  "path",
+						Usage: "Store path or URL. Used with --type instead of the positional argument.",
+					},
+					&cli.BoolFlag{
+						Name:  "no-verify",
+						Usage: "With --type local, register the path even if it does not exist yet",
+					},
+					&cli.BoolFlag{
+						Name:  "relative",
+						Usage: "Record a local store's path relative to $LOOM_HOME (or the global config directory) so it survives different mounts",
+					},
+					&cli.IntFlag{
+						Name:  "priority",
+						Usage: "Search priority of the store when adding a thread without a store name; higher is searched first",
+					},
+					&cli.BoolFlag{
+						Name:  "local",
+						Usage: "Register a directory as a local store even if it is a git working tree",
+					},
+					&cli.BoolFlag{
+						Name:    "yes",
+						Aliases: []string{"y"},
+						Usage:   "Register a git working tree as a git store without asking, also when stdin is not a terminal",
+					},
+					&cli.BoolFlag{
+						Name:  "init",
+						Usage: "Create the directory of a new local store if it does not exist yet",
+					},
+					&cli.BoolFlag{
+						Name:  "read-only",
+						Usage: "Mark the store read-only, so Loom never writes threads back to it",
+					},
+					&cli.StringFlag{
+						Name:  "token-env",
+						Usage: "Name of the environment variable holding the access token of a private git or github store; only the name is saved",
+					},
+				},
+				Action: addStoreAction,
 			},
 			{
 				Name:      "remove",
@@ -32,10 +95,138 @@ func Command() *cli.Command {
 				Action:    removeStoreAction,
 			},
 			{
-				Name:   "list",
-				Usage:  "List all configured thread stores. Usage: loom config list",
+				Name:      "set-type",
+				Usage:     "Change the type of a configured thread store. Usage: loom config set-type <name> <type>",
+				ArgsUsage: "<name> <type>",
+				Action:    setStoreTypeAction,
+			},
+			{
+				Name:      "set-priority",
+				Usage:     "Change the search priority of a configured thread store; higher is searched first. Usage: loom config set-priority <name> <n>",
+				ArgsUsage: "<name> <n>",
+				Action:    setStorePriorityAction,
+			},
+			{
+				Name:      "default-store",
+				Usage:     "Show or set the store searched first when adding a thread without a store name. Usage: loom config default-store [<name>] [--clear]",
+				ArgsUsage: "[<name>]",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "clear",
+						Usage: "Unset the default store, so stores are searched in configuration order",
+					},
+				},
+				Action: defaultStoreAction,
+			},
+			{
+				Name:  "list",
+				Usage: "List all configured thread stores. Usage: loom config list [--verify] [--sort name|type|path]",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "verify",
+						Usage: "Check that each store is reachable and show its status (slow for remote stores)",
+					},
+					&cli.StringFlag{
+						Name:  "sort",
+						Usage: "Order the stores by name, type or path instead of configuration order",
+					},
+				},
 				Action: listStoresAction,
 			},
+			{
+				Name:      "test",
+				Usage:     "Check that a configured thread store is reachable and well-formed. Usage: loom config test <name>",
+				ArgsUsage: "<name>",
+				Action:    testStoreAction,
+			},
+			{
+				Name:  "validate",
+				Usage: "Check the global configuration for unknown store types, missing store paths, unreachable remote stores and duplicate stores. Usage: loom config validate [--offline]",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "offline",
+						Usage: "Do not probe git and github stores over the network",
+					},
+				},
+				Action: validateConfigAction,
+			},
+			{
+				Name:      "add-project",
+				Usage:     "Copy a thread from a store into the project's .loom store. Usage: loom config add-project [--force] <thread_name> OR <store_name>/<thread_name>",
+				ArgsUsage: "<thread_name>",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "force",
+						Usage: "Replace an existing .loom/<thread_name> without asking",
+					},
+				},
+				Action: addProjectThreadAction,
+			},
+			{
+				Name:      "move-to-project",
+				Usage:     "Copy a thread and all its dependencies into the project's .loom store and use them from there. Usage: loom config move-to-project [--force] <thread_name> OR <store_name>/<thread_name>",
+				ArgsUsage: "<thread_name>",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "force",
+						Usage: "Replace an existing .loom/<thread_name> of the named thread without asking",
+					},
+				},
+				Action: moveToProjectAction,
+			},
+			{
+				Name:  "migrate",
+				Usage: "Rewrite the paths of local stores that moved. Usage: loom config migrate --from <old_path> --to <new_path> [--dry-run]",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:  "from",
+						Usage: "Old directory; every local store at or under it is migrated",
+					},
+					&cli.StringFlag{
+						Name:  "to",
+						Usage: "New directory that replaces --from in the store paths",
+					},
+					&cli.BoolFlag{
+						Name:  "dry-run",
+						Usage: "Show the rewritten paths without saving them",
+					},
+				},
+				Action: migrateStoresAction,
+			},
+			{
+				Name:  "export",
+				Usage: "Write the configured stores to a portable file for sharing. Usage: loom config export [--output <file>] [--format yaml|json]",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:    "output",
+						Aliases: []string{"o"},
+						Usage:   "Write to `FILE` instead of stdout",
+					},
+					&cli.StringFlag{
+						Name:  "format",
+						Value: "yaml",
+						Usage: "Output format: yaml or json",
+					},
+				},
+				Action: exportStoresAction,
+			},
+			{
+				Name:      "import",
+				Usage:     "Merge stores from a file written by 'loom config export'. Usage: loom config import <file>",
+				ArgsUsage: "<file>",
+				Action:    importStoresAction,
+			},
+			{
+				Name:  "reset",
+				Usage: "Replace the global configuration with one without stores, keeping the old file as loom.yaml.bak. Usage: loom config reset [--force]",
+				Flags: []cli.Flag{
+					&cli.BoolFlag{
+						Name:  "force",
+						Usage: "Reset without asking",
+					},
+				},
+				Action: resetConfigAction,
+			},
 			// Remove subcommand will be added in Task 4.7
 		},
 	}
@@ -64,37 +255,149 @@ func inferStoreDetails(pathOrURL string) (storeType string, storeName string, no
 	fileInfo, err := os.Stat(absPath)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return "", "", "", fmt.Errorf("path \"%s\" does not exist", absPath)
+			return "", "", "", exitcode.Usagef("path \"%s\" does not exist (use --init to create it)", absPath)
 		}
 		return "", "", "", fmt.Errorf("failed to stat path \"%s\": %w", absPath, err)
 	}
 	if !fileInfo.IsDir() {
-		return "", "", "", fmt.Errorf("path \"%s\" is not a directory", absPath)
+		if fileInfo.Mode().IsRegular() && threadstore.IsArchivePath(absPath) {
+			return globalconfig.StoreTypeArchive, threadstore.ArchiveBaseName(absPath), absPath, nil
+		}
+		return "", "", "", exitcode.Usagef("path \"%s\" is not a directory or a .zip, .tar.gz or .tgz archive", absPath)
 	}
 
-	storeName = filepath.Base(absPath)
+	storeName = localStoreName(absPath)
 	normalizedPathOrURL = absPath
 	return
 }
 
-// addStoreAction implements the logic for "loom config add <path_or_url>".
+// localStoreName returns the name a local store at absPath is given unless --name is passed: the
+// directory's name, or for another project's .loom directory, whose threads sit directly in it like
+// in any store, the name of that project's directory.
+func localStoreName(absPath string) string {
+	if filepath.Base(absPath) == project.ProjectStoreDirName {
+		return filepath.Base(filepath.Dir(absPath))
+	}
+	return filepath.Base(absPath)
+}
+
+// explicitStoreDetails builds store details from --type and --path without inference.
+// Only the type is validated; local paths are made absolute and, unless noVerify is set, must be existing directories.
+func explicitStoreDetails(storeType string, pathOrURL string, noVerify bool) (string, string, string, error) {
+	storeType = strings.ToLower(strings.TrimSpace(storeType))
+	if err := globalconfig.ValidateStoreType(storeType); err != nil {
+		return "", "", "", exitcode.UsageError(err)
+	}
+	if strings.TrimSpace(pathOrURL) == "" {
+		return "", "", "", exitcode.Usagef("the --path flag requires a non-empty value")
+	}
+	if storeType == globalconfig.StoreTypeArchive {
+		return explicitArchiveDetails(pathOrURL, noVerify)
+	}
+	if storeType != globalconfig.StoreTypeLocal {
+		if noVerify {
+			return "", "", "", exitcode.Usagef("--no-verify only applies to local and archive stores")
+		}
+		pathOrURL = canonicalizeStoreURL(pathOrURL)
+		trimmed := strings.TrimSuffix(pathOrURL, "/")
+		return storeType, strings.TrimSuffix(trimmed[strings.LastIndexAny(trimmed, "/:")+1:], ".git"), pathOrURL, nil
+	}
+
+	absPath, err := filepath.Abs(pathOrURL)
+	if err != nil {
+		return "", "", "", fmt.Errorf("failed to get absolute path for \"%s\": %w", pathOrURL, err)
+	}
+	if !noVerify {
+		fileInfo, err := os.Stat(absPath)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return "", "", "", exitcode.Usagef("path \"%s\" does not exist (use --no-verify to register it anyway)", absPath)
+			}
+			return "", "", "", fmt.Errorf("failed to stat path \"%s\": %w", absPath, err)
+		}
+		if !fileInfo.IsDir() {
+			return "", "", "", exitcode.Usagef("path \"%s\" is not a directory", absPath)
+		}
+	}
+	return storeType, localStoreName(absPath), absPath, nil
+}
+
+// explicitArchiveDetails builds the details of an archive store from --path. The path is made
+// absolute and, unless noVerify is set, must be an existing .zip, .tar.gz or .tgz file.
+func explicitArchiveDetails(pathOrURL string, noVerify bool) (string, string, string, error) {
+	absPath, err := filepath.Abs(pathOrURL)
+	if err != nil {
+		return "", "", "", fmt.Errorf("failed to get absolute path for \"%s\": %w", pathOrURL, err)
+	}
+	if !threadstore.IsArchivePath(absPath) {
+		return "", "", "", exitcode.Usagef("path \"%s\" is not a .zip, .tar.gz or .tgz archive", absPath)
+	}
+	if !noVerify {
+		fileInfo, err := os.Stat(absPath)
+		if err != nil {
+			if os.IsNotExist(err) {
+				return "", "", "", exitcode.Usagef("path \"%s\" does not exist (use --no-verify to register it anyway)", absPath)
+			}
+			return "", "", "", fmt.Errorf("failed to stat path \"%s\": %w", absPath, err)
+		}
+		if !fileInfo.Mode().IsRegular() {
+			return "", "", "", exitcode.Usagef("path \"%s\" is not a file", absPath)
+		}
+	}
+	return globalconfig.StoreTypeArchive, threadstore.ArchiveBaseName(absPath), absPath, nil
+}
+
+// addStoreAction implements the logic for "loom config add [--name <name>] <path_or_url>"
+// and the explicit "loom config add --type <type> --path <path_or_url>" form.
 func addStoreAction(c *cli.Context) error {
+	var storeType, inferredStoreName, normalizedPathOrURL string
+	var err error
+	if c.IsSet("type") || c.IsSet("path") {
+		if !c.IsSet("type") || !c.IsSet("path") {
+			return exitcode.Usagef("--type and --path must be used together")
+		}
+		if c.NArg() != 0 {
+			return exitcode.Usagef("do not pass a positional <path_or_url> together with --type and --path")
+		}
+		if c.Bool("init") {
+			if !strings.EqualFold(strings.TrimSpace(c.String("type")), globalconfig.StoreTypeLocal) {
+				return exitcode.Usagef("--init only applies to local stores")
+			}
+			if err := initStoreDir(c.String("path")); err != nil {
+				return err
+			}
+		}
+		storeType, inferredStoreName, normalizedPathOrURL, err = explicitStoreDetails(c.String("type"), c.String("path"), c.Bool("no-verify"))
+		if err != nil {
+			return err
+		}
+		storedPath, err := storedStorePath(storeType, normalizedPathOrURL, c.Bool("relative"))
+		if err != nil {
+			return err
+		}
+		return saveNewStore(c, storeType, inferredStoreName, normalizedPathOrURL, storedPath, nil)
+	}
+	if c.Bool("no-verify") {
+		return exitcode.Usagef("--no-verify requires --type local and --path")
+	}
+
 	if c.NArg() != 1 {
-		return fmt.Errorf("incorrect number of arguments. Expected <path_or_url>")
+		return exitcode.Usagef("incorrect number of arguments. Expected <path_or_url>")
 	}
 
 	userInputPathOrURL := c.Args().Get(0)
+	if c.Bool("init") {
+		if err := initStoreDir(userInputPathOrURL); err != nil {
+			return err
+		}
+	}
 
-	storeType, inferredStoreName, normalizedPathOrURL, err := inferStoreDetails(userInputPathOrURL)
+	storeType, inferredStoreName, normalizedPathOrURL, err = inferStoreDetails(userInputPathOrURL)
 	if err != nil {
-		// If inferStoreDetails specifically said GitHub isn't implemented, pass that through.
+		// If inferStoreDetails specifically said GitHub isn't implemented, explain the alternatives.
 		if strings.Contains(err.Error(), "github URL store type not yet fully implemented") {
-			// For now, we treat this as a "not yet supported" rather than a hard error for CLI flow.
-			// This allows local paths to work.
 			// A more robust solution would be to have inferStoreDetails return a specific error type.
-			fmt.Printf("Attempted to add a store that looks like a GitHub URL (%s). This functionality is planned but not yet implemented.\n", userInputPathOrURL)
-			fmt.Println("Please provide a local directory path for now.")
-			return nil // Or a specific error if preferred, but nil to allow local to proceed.
+			return exitcode.Usagef("%s looks like a GitHub URL, which cannot be inferred yet; provide a local directory path, or register it explicitly with --type github --path %s", userInputPathOrURL, userInputPathOrURL)
 		}
 		return err // Other errors from inferStoreDetails (e.g., path not found, not a dir)
 	}
@@ -106,54 +409,248 @@ func addStoreAction(c *cli.Context) error {
 		// If we reach here, it implies a logic flaw or that inferStoreDetails allowed an empty type.
 		return fmt.Errorf("could not determine store type for input: %s", userInputPathOrURL)
 	}
+	if err := globalconfig.ValidateStoreType(storeType); err != nil {
+		return err
+	}
+
+	// A working tree can be registered as a git store instead; --relative only applies to local ones.
+	var tree *gitWorkingTree
+	if storeType == globalconfig.StoreTypeLocal && !c.Bool("local") && !c.Bool("relative") {
+		if tree, err = offerGitStore(normalizedPathOrURL, c.Bool("yes")); err != nil {
+			return err
+		}
+		if tree != nil {
+			storeType, normalizedPathOrURL = globalconfig.StoreTypeGit, canonicalizeStoreURL(tree.remoteURL)
+		}
+	}
+	storedPath, err := storedStorePath(storeType, normalizedPathOrURL, c.Bool("relative"))
+	if err != nil {
+		return err
+	}
+
+	return saveNewStore(c, storeType, inferredStoreName, normalizedPathOrURL, storedPath, tree)
+}
+
+// initStoreDir creates the directory of a new local store for --init and reports it. An existing
+// path is left alone for the usual validation.
+func initStoreDir(pathOrURL string) error {
+	lower := strings.ToLower(pathOrURL)
+	if strings.HasPrefix(lower, "http:") || strings.HasPrefix(lower, "https:") || strings.Contains(lower, "github.com") {
+		return exitcode.Usagef("--init only applies to local stores, not \"%s\"", pathOrURL)
+	}
+	if strings.TrimSpace(pathOrURL) == "" {
+		return exitcode.Usagef("--init needs the path of the directory to create")
+	}
+	absPath, err := filepath.Abs(pathOrURL)
+	if err != nil {
+		return fmt.Errorf("failed to get absolute path for \"%s\": %w", pathOrURL, err)
+	}
+	if _, err := os.Stat(absPath); err == nil {
+		return nil
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("failed to stat path \"%s\": %w", absPath, err)
+	}
+	if threadstore.IsArchivePath(absPath) {
+		return exitcode.Usagef("--init creates directories for local stores; \"%s\" names an archive", absPath)
+	}
+	if err := os.MkdirAll(absPath, os.ModePerm); err != nil {
+		return fmt.Errorf("failed to create store directory \"%s\": %w", absPath, err)
+	}
+	fmt.Printf("Created directory \"%s\"\n", absPath)
+	return nil
+}
+
+// gitWorkingTree is a git working tree being registered as a git store.
+type gitWorkingTree struct {
+	dir       string
+	remoteURL string
+	branch    string // Empty if HEAD is detached
+}
+
+// detectGitWorkingTree returns the remote and branch of dir if it is the top of a git working tree
+// with a remote. A tree whose remote cannot be determined, for instance because git is not
+// installed, is reported with a note and treated as a plain directory.
+func detectGitWorkingTree(dir string) *gitWorkingTree {
+	if _, err := os.Stat(filepath.Join(dir, ".git")); err != nil {
+		return nil
+	}
+	if _, err := exec.LookPath("git"); err != nil {
+		fmt.Printf("Note: \"%s\" is a git working tree, but git is not installed; registering it as a local store.\n", dir)
+		return nil
+	}
+	git := func(args ...string) (string, error) {
+		output, err := exec.Command("git", append([]string{"-C", dir}, args...)...).Output()
+		return strings.TrimSpace(string(output)), err
+	}
+	remotes, err := git("remote")
+	if err != nil || remotes == "" {
+		fmt.Printf("Note: \"%s\" is a git working tree without a remote; registering it as a local store.\n", dir)
+		return nil
+	}
+	remote := strings.Fields(remotes)[0]
+	for _, name := range strings.Fields(remotes) {
+		if name == "origin" {
+			remote = name
+		}
+	}
+	remoteURL, err := git("remote", "get-url", remote)
+	if err != nil || remoteURL == "" {
+		fmt.Printf("Note: could not read the URL of remote \"%s\" in \"%s\"; registering it as a local store.\n", remote, dir)
+		return nil
+	}
+	branch, _ := git("symbolic-ref", "--short", "-q", "HEAD")
+	return &gitWorkingTree{dir: dir, remoteURL: remoteURL, branch: branch}
+}
+
+// offerGitStore asks whether the git working tree at dir should be registered as a git store
+// tracking its remote. It returns nil if dir is not such a tree or the user declines. assumeYes
+// accepts without asking; otherwise nobody can answer when stdin is not a terminal, and the
+// directory is registered as a local store.
+func offerGitStore(dir string, assumeYes bool) (*gitWorkingTree, error) {
+	tree := detectGitWorkingTree(dir)
+	if tree == nil {
+		return nil, nil
+	}
+	branch := tree.branch
+	if branch == "" {
+		branch = "detached HEAD"
+	}
+	fmt.Printf("\"%s\" is a git working tree of %s (%s).\n", dir, tree.remoteURL, branch)
+	if assumeYes {
+		return tree, nil
+	}
+	if !output.StdinIsTerminal() {
+		fmt.Println("Registering it as a local store, since stdin is not a terminal; pass --yes to register it as a git store.")
+		return nil, nil
+	}
+	confirmed, err := output.Confirm(os.Stdout, "Register it as a git store tracking that remote? Threads are still read from this directory. Use --local to skip this question.", true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read user input: %w", err)
+	}
+	if !confirmed {
+		return nil, nil
+	}
+	return tree, nil
+}
+
+// storedStorePath returns the path to write to the global configuration for a new store whose
+// absolute path or URL is normalizedPathOrURL: with relative, a local path relative to
+// globalconfig.RelativeStoreBase, otherwise normalizedPathOrURL itself. It explains where a
+// relative path resolves, since it breaks if that base changes.
+func storedStorePath(storeType, normalizedPathOrURL string, relative bool) (string, error) {
+	if !relative {
+		return normalizedPathOrURL, nil
+	}
+	if storeType != globalconfig.StoreTypeLocal {
+		return "", exitcode.Usagef("--relative only applies to local stores")
+	}
+	base, err := globalconfig.RelativeStoreBase()
+	if err != nil {
+		return "", fmt.Errorf("failed to determine the base for relative store paths: %w", err)
+	}
+	rel, err := filepath.Rel(base, normalizedPathOrURL)
+	if err != nil {
+		return "", exitcode.Usagef("cannot record \"%s\" relative to %s: %v", normalizedPathOrURL, base, err)
+	}
+	rel = filepath.ToSlash(rel)
+	if os.Getenv(globalconfig.LoomHomeEnv) == "" {
+		fmt.Fprintf(os.Stderr, "Warning: %s is not set; the path is recorded as \"%s\", relative to the global config directory %s.\n", globalconfig.LoomHomeEnv, rel, base)
+	} else {
+		fmt.Printf("The path is recorded as \"%s\", relative to %s (%s); it resolves wherever %s points.\n", rel, base, globalconfig.LoomHomeEnv, globalconfig.LoomHomeEnv)
+	}
+	return rel, nil
+}
 
+// saveNewStore checks a new store against the configured ones, resolves its final name and saves it.
+// storedPath is the path written to the configuration file, which may differ from the resolved
+// normalizedPathOrURL (see storedStorePath). tree is the working tree a git store is registered
+// from, or nil.
+func saveNewStore(c *cli.Context, storeType, inferredStoreName, normalizedPathOrURL, storedPath string, tree *gitWorkingTree) error {
 	config, err := globalconfig.LoadGlobalConfig()
 	if err != nil {
 		return fmt.Errorf("failed to load global Loom configuration: %w", err)
 	}
 
+	explicitStoreName := strings.TrimSpace(c.String("name"))
+	if c.IsSet("name") && explicitStoreName == "" {
+		return exitcode.Usagef("the --name flag requires a non-empty value")
+	}
+
 	finalStoreName := inferredStoreName
+	if explicitStoreName != "" {
+		finalStoreName = explicitStoreName
+	}
+	if err := project.ValidateName("store", finalStoreName); err != nil {
+		return exitcode.UsageError(err)
+	}
+	tokenEnv := strings.TrimSpace(c.String("token-env"))
+	if c.IsSet("token-env") {
+		if storeType != globalconfig.StoreTypeGit && storeType != globalconfig.StoreTypeGitHub {
+			return exitcode.Usagef("--token-env only applies to git and github stores, not %s stores", storeType)
+		}
+		if err := globalconfig.ValidateTokenEnv(tokenEnv); err != nil {
+			return exitcode.UsageError(err)
+		}
+	}
 	nameConflictExists := false
 
 	for _, existingStore := range config.Stores {
-		// Path/URL conflict check (case-insensitive for paths, should be for URLs too)
-		// For local paths, ensure OS-specific path comparison if necessary, though Abs should normalize.
-		// For URLs, direct string comparison after normalization (e.g., lowercase, remove trailing slash)
-		if strings.EqualFold(existingStore.Path, normalizedPathOrURL) {
-			return fmt.Errorf("the path/url \"%s\" is already registered as store \"%s\" (type: %s)", normalizedPathOrURL, existingStore.Name, existingStore.Type)
+		if tree != nil && (sameStorePath(existingStore.Path, tree.dir) || sameStorePath(existingStore.Checkout, tree.dir)) {
+			return exitcode.Usagef("the directory \"%s\" is already registered as store \"%s\" (type: %s)", tree.dir, existingStore.Name, existingStore.Type)
+		}
+		// Path/URL conflict check. Local paths are compared after resolving symlinks; see sameStorePath.
+		if sameStorePath(existingStore.Path, normalizedPathOrURL) {
+			// Re-adding exactly the same store is a no-op so provisioning scripts can be re-run.
+			if strings.EqualFold(existingStore.Name, finalStoreName) && existingStore.Type == storeType {
+				fmt.Printf("Store \"%s\" is already registered with path/url \"%s\"; nothing to do.\n", existingStore.Name, existingStore.Path)
+				return nil
+			}
+			return exitcode.Usagef("the path/url \"%s\" is already registered as store \"%s\" (type: %s)", normalizedPathOrURL, existingStore.Name, existingStore.Type)
 		}
-		if strings.EqualFold(existingStore.Name, inferredStoreName) {
+		if strings.EqualFold(existingStore.Name, finalStoreName) {
 			nameConflictExists = true
 		}
 	}
 
+	// An explicitly requested name is never negotiated interactively, so scripts fail fast.
+	if nameConflictExists && explicitStoreName != "" {
+		return exitcode.Usagef("a store named \"%s\" already exists", explicitStoreName)
+	}
+
 	if nameConflictExists {
 		fmt.Printf("A store named \"%s\" already exists. The path \"%s\" is unique.\n", inferredStoreName, normalizedPathOrURL)
-		fmt.Print("Please enter a new name for this store, or press Enter to cancel: ")
-		reader := bufio.NewReader(os.Stdin)
-		input, err := reader.ReadString('\n')
+		customName, err := output.Ask(os.Stdout, "Please enter a new name for this store, or press Enter to cancel: ", "", func(input string) (string, bool) { return input, true })
 		if err != nil {
 			return fmt.Errorf("failed to read user input: %w", err)
 		}
-		customName := strings.TrimSpace(input)
 		if customName == "" {
 			fmt.Println("Store addition cancelled.")
 			return nil
 		}
 		finalStoreName = customName
+		if err := project.ValidateName("store", finalStoreName); err != nil {
+			return exitcode.UsageError(err)
+		}
 
 		// Re-check if the custom name also conflicts
 		for _, existingStore := range config.Stores {
 			if strings.EqualFold(existingStore.Name, finalStoreName) {
-				return fmt.Errorf("the custom name \"%s\" also conflicts with an existing store. Please try again", finalStoreName)
+				return exitcode.Usagef("the custom name \"%s\" also conflicts with an existing store. Please try again", finalStoreName)
 			}
 		}
 	}
 
 	newStore := globalconfig.Store{
-		Name: finalStoreName,
-		Type: storeType,
-		Path: normalizedPathOrURL, // Store the normalized path/URL
+		Name:     finalStoreName,
+		Type:     storeType,
+		Path:     normalizedPathOrURL, // Store the normalized path/URL
+		Priority: c.Int("priority"),
+		TokenEnv: tokenEnv,
+		ReadOnly: c.Bool("read-only"),
+		RawPath:  storedPath,
+	}
+	if tree != nil {
+		newStore.Ref, newStore.Checkout = tree.branch, tree.dir
 	}
 
 	config.Stores = append(config.Stores, newStore)
@@ -162,16 +659,150 @@ func addStoreAction(c *cli.Context) error {
 		return fmt.Errorf("failed to save global Loom configuration: %w", err)
 	}
 
-	fmt.Printf("Successfully added %s store \"%s\" with path/url \"%s\"\n", storeType, finalStoreName, normalizedPathOrURL)
+	readOnly := ""
+	if newStore.ReadOnly {
+		readOnly = "read-only "
+	}
+	fmt.Printf("Successfully added %s%s store \"%s\" with path/url \"%s\"\n", readOnly, storeType, finalStoreName, normalizedPathOrURL)
+	if tree != nil {
+		fmt.Printf("Threads are read from the working tree \"%s\".\n", tree.dir)
+	}
+	if tokenEnv != "" {
+		fmt.Printf("The access token is read from $%s whenever the store is fetched; it is not saved.\n", tokenEnv)
+		if _, err := newStore.Token(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: store \"%s\": %v\n", finalStoreName, err)
+		}
+	}
+	if storeType == globalconfig.StoreTypeLocal && !c.Bool("no-verify") {
+		reportStoreThreadCount(newStore, c.Bool("init"))
+	}
 	configPath, _ := globalconfig.GetGlobalConfigPath()
 	fmt.Printf("Configuration saved to: %s\n", configPath)
 	return nil
 }
 
+// storeAccess describes whether threads may be written back to store, for "loom config list".
+func storeAccess(store globalconfig.Store) string {
+	switch {
+	case store.ReadOnly:
+		return "read-only"
+	case store.Writable():
+		return "writable"
+	default:
+		return "read-only (remote)"
+	}
+}
+
+// reportStoreThreadCount prints how many threads the newly added local store holds, and warns if it
+// holds none, which usually means the path points one level too high or too low. A store directory
+// just created with --init is expected to be empty.
+func reportStoreThreadCount(store globalconfig.Store, created bool) {
+	threads, err := listCmd.ListThreadsInStore(store.Path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not count the threads in store \"%s\": %v\n", store.Name, err)
+		return
+	}
+	fmt.Printf("Found %d thread(s) in store \"%s\".\n", len(threads), store.Name)
+	if len(threads) == 0 && !created {
+		fmt.Fprintf(os.Stderr, "Warning: store \"%s\" appears to contain no threads: no directory in %s has a %s subdirectory. If the path is one level too high or too low, remove the store with 'loom config remove %s' and add the right directory.\n", store.Name, store.Path, threadconfig.SourceDirName, store.Name)
+	}
+}
+
+// sameStorePath reports whether two store paths/URLs refer to the same store. Strings are compared
+// case-insensitively after resolving symlinks for paths that exist, so "/tmp/store" and
+// "/private/tmp/store" match on macOS. Existing directories are also compared by identity, which
+// catches case differences on case-insensitive filesystems. Paths that do not exist yet, and URLs,
+// fall back to the lexical comparison.
+func sameStorePath(a, b string) bool {
+	if isStoreURL(a) && isStoreURL(b) {
+		return strings.EqualFold(storeURLKey(a), storeURLKey(b))
+	}
+	if strings.EqualFold(resolveStorePath(a), resolveStorePath(b)) {
+		return true
+	}
+	infoA, errA := os.Stat(a)
+	infoB, errB := os.Stat(b)
+	return errA == nil && errB == nil && os.SameFile(infoA, infoB)
+}
+
+// isStoreURL reports whether s is a URL ("https://...", "ssh://...") or an scp-like SSH address
+// ("git@host:org/repo") rather than a local path.
+func isStoreURL(s string) bool {
+	if strings.Contains(s, "://") {
+		return true
+	}
+	at, colon := strings.Index(s, "@"), strings.Index(s, ":")
+	return at > 0 && colon > at && !strings.ContainsAny(s[:colon], `/\`)
+}
+
+// canonicalizeStoreURL returns the form of a remote store URL written to the global configuration:
+// the scheme and host lowercased and trailing slashes and a ".git" suffix removed, so
+// "https://GitHub.com/Org/Repo.git/" becomes "https://github.com/Org/Repo". SSH addresses keep their
+// transport, since switching to HTTPS would change how git authenticates. Other values are returned
+// trimmed but otherwise unchanged.
+func canonicalizeStoreURL(raw string) string {
+	s := strings.TrimSpace(raw)
+	if strings.Contains(s, "://") {
+		u, err := url.Parse(s)
+		if err != nil || u.Host == "" {
+			return s
+		}
+		u.Scheme = strings.ToLower(u.Scheme)
+		u.Host = strings.ToLower(u.Host)
+		u.Path = trimRepoPath(u.Path)
+		u.RawPath = ""
+		return u.String()
+	}
+	if !isStoreURL(s) {
+		return s
+	}
+	user, rest, _ := strings.Cut(s, "@")
+	host, repoPath, _ := strings.Cut(rest, ":")
+	return user + "@" + strings.ToLower(host) + ":" + trimRepoPath(repoPath)
+}
+
+// trimRepoPath removes trailing slashes and a ".git" suffix from the repository path of a URL.
+func trimRepoPath(p string) string {
+	p = strings.TrimRight(p, "/")
+	if strings.HasSuffix(strings.ToLower(p), ".git") {
+		p = strings.TrimRight(p[:len(p)-len(".git")], "/")
+	}
+	return p
+}
+
+// storeURLKey returns the key two remote store URLs are compared by: the canonical URL with SSH
+// addresses, and plain HTTP, rewritten to HTTPS without user or port, so
+// "git@github.com:Org/Repo.git" and "https://github.com/Org/Repo/" have the same key.
+func storeURLKey(raw string) string {
+	canonical := canonicalizeStoreURL(raw)
+	if !strings.Contains(canonical, "://") {
+		_, rest, _ := strings.Cut(canonical, "@")
+		host, repoPath, _ := strings.Cut(rest, ":")
+		return "https://" + host + "/" + strings.TrimLeft(repoPath, "/")
+	}
+	u, err := url.Parse(canonical)
+	if err != nil {
+		return canonical
+	}
+	switch u.Scheme {
+	case "ssh", "git+ssh", "http", "https":
+		return "https://" + u.Hostname() + "/" + strings.TrimLeft(u.Path, "/")
+	}
+	return canonical
+}
+
+// resolveStorePath returns p with symlinks resolved if it exists, or p unchanged otherwise.
+func resolveStorePath(p string) string {
+	if resolved, err := filepath.EvalSymlinks(p); err == nil {
+		return resolved
+	}
+	return p
+}
+
 // removeStoreAction implements the logic for "loom config remove <name_or_path>".
 func removeStoreAction(c *cli.Context) error {
 	if c.NArg() != 1 {
-		return fmt.Errorf("incorrect number of arguments. Expected <name_or_path>")
+		return exitcode.Usagef("incorrect number of arguments. Expected <name_or_path>")
 	}
 
 	nameOrPathToRemove := c.Args().Get(0)
@@ -201,7 +832,7 @@ func removeStoreAction(c *cli.Context) error {
 		updatedStores = nil // Reset for path matching pass
 		normalizedInputPath := nameOrPathToRemove
 		// Attempt to normalize if it looks like a local path (not a URL)
-		if !strings.HasPrefix(strings.ToLower(normalizedInputPath), "http:") && !strings.HasPrefix(strings.ToLower(normalizedInputPath), "https:") && !strings.Contains(strings.ToLower(normalizedInputPath), "github.com") {
+		if !isStoreURL(normalizedInputPath) && !strings.Contains(strings.ToLower(normalizedInputPath), "github.com") {
 			absPath, err := filepath.Abs(nameOrPathToRemove)
 			if err == nil { // If Abs path resolution is successful
 				normalizedInputPath = absPath
@@ -210,8 +841,9 @@ func removeStoreAction(c *cli.Context) error {
 		}
 
 		for _, store := range config.Stores {
-			// Compare normalized input with stored path (which is already normalized for local stores)
-			if strings.EqualFold(store.Path, normalizedInputPath) {
+			// Compare normalized input with stored path (which is already normalized for local stores);
+			// equivalent spellings of a remote URL match too.
+			if sameStorePath(store.Path, normalizedInputPath) {
 				found = true
 				removedStoreDetails = fmt.Sprintf("store \"%s\" (type: %s, path/url: %s)", store.Name, store.Type, store.Path)
 				// Skip adding this store to updatedStores
@@ -222,21 +854,158 @@ func removeStoreAction(c *cli.Context) error {
 	}
 
 	if !found {
-		return fmt.Errorf("store with name or path/url \"%s\" not found", nameOrPathToRemove)
+		return exitcode.Usagef("store with name or path/url \"%s\" not found", nameOrPathToRemove)
 	}
 
 	config.Stores = updatedStores
+	clearedDefault := false
+	if _, ok := config.FindStore(config.DefaultStore); config.DefaultStore != "" && !ok {
+		config.DefaultStore = ""
+		clearedDefault = true
+	}
 
 	if err := globalconfig.SaveGlobalConfig(config); err != nil {
 		return fmt.Errorf("failed to save global Loom configuration: %w", err)
 	}
 
 	fmt.Printf("Successfully removed %s\n", removedStoreDetails)
+	if clearedDefault {
+		fmt.Println("It was the default store; no default store is set now.")
+	}
 	configPath, _ := globalconfig.GetGlobalConfigPath()
 	fmt.Printf("Configuration saved to: %s\n", configPath)
 	return nil
 }
 
+// setStoreTypeAction implements the logic for "loom config set-type <name> <type>".
+func setStoreTypeAction(c *cli.Context) error {
+	if c.NArg() != 2 {
+		return exitcode.Usagef("incorrect number of arguments. Expected <name> <type>")
+	}
+
+	storeName := c.Args().Get(0)
+	newType := strings.ToLower(strings.TrimSpace(c.Args().Get(1)))
+	if err := globalconfig.ValidateStoreType(newType); err != nil {
+		return exitcode.UsageError(err)
+	}
+
+	config, err := globalconfig.LoadGlobalConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load global Loom configuration: %w", err)
+	}
+
+	for i, store := range config.Stores {
+		if !strings.EqualFold(store.Name, storeName) {
+			continue
+		}
+		oldType := store.Type
+		config.Stores[i].Type = newType
+		if err := globalconfig.SaveGlobalConfig(config); err != nil {
+			return fmt.Errorf("failed to save global Loom configuration: %w", err)
+		}
+		fmt.Printf("Changed type of store \"%s\" from \"%s\" to \"%s\"\n", store.Name, oldType, newType)
+		return nil
+	}
+	return exitcode.Usagef("store \"%s\" not found", storeName)
+}
+
+// setStorePriorityAction implements the logic for "loom config set-priority <name> <n>".
+func setStorePriorityAction(c *cli.Context) error {
+	if c.NArg() != 2 {
+		return exitcode.Usagef("incorrect number of arguments. Expected <name> <n>")
+	}
+
+	storeName := c.Args().Get(0)
+	priority, err := strconv.Atoi(strings.TrimSpace(c.Args().Get(1)))
+	if err != nil {
+		return exitcode.Usagef("priority must be a whole number, got \"%s\"", c.Args().Get(1))
+	}
+
+	config, err := globalconfig.LoadGlobalConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load global Loom configuration: %w", err)
+	}
+
+	for i, store := range config.Stores {
+		if !strings.EqualFold(store.Name, storeName) {
+			continue
+		}
+		config.Stores[i].Priority = priority
+		if err := globalconfig.SaveGlobalConfig(config); err != nil {
+			return fmt.Errorf("failed to save global Loom configuration: %w", err)
+		}
+		fmt.Printf("Changed priority of store \"%s\" from %d to %d\n", store.Name, store.Priority, priority)
+		return nil
+	}
+	return exitcode.Usagef("store \"%s\" not found", storeName)
+}
+
+// defaultStoreAction implements the logic for "loom config default-store [<name>] [--clear]".
+// Without arguments it shows the current default store.
+func defaultStoreAction(c *cli.Context) error {
+	if c.NArg() > 1 || (c.Bool("clear") && c.NArg() > 0) {
+		return exitcode.Usagef("expected a store name or --clear, not both")
+	}
+
+	config, err := globalconfig.LoadGlobalConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load global Loom configuration: %w", err)
+	}
+
+	switch {
+	case c.Bool("clear"):
+		if config.DefaultStore == "" {
+			fmt.Println("No default store is set.")
+			return nil
+		}
+		config.DefaultStore = ""
+	case c.NArg() == 1:
+		store, ok := config.FindStore(c.Args().First())
+		if !ok {
+			return exitcode.Usagef("store \"%s\" not found", c.Args().First())
+		}
+		config.DefaultStore = store.Name
+	default:
+		if config.DefaultStore == "" {
+			fmt.Println("No default store is set.")
+		} else {
+			fmt.Printf("Default store: %s\n", config.DefaultStore)
+		}
+		return nil
+	}
+
+	if err := globalconfig.SaveGlobalConfig(config); err != nil {
+		return fmt.Errorf("failed to save global Loom configuration: %w", err)
+	}
+	if config.DefaultStore == "" {
+		fmt.Println("Cleared the default store; stores are searched in configuration order.")
+	} else {
+		fmt.Printf("Default store set to \"%s\"\n", config.DefaultStore)
+	}
+	return nil
+}
+
+// sortedStores returns a copy of stores ordered by key: "name" (case-insensitively), "type" or
+// "path", with ties kept in configuration order. An empty key returns stores as they are.
+func sortedStores(stores []globalconfig.Store, key string) ([]globalconfig.Store, error) {
+	var sortKey func(store globalconfig.Store) string
+	switch strings.ToLower(strings.TrimSpace(key)) {
+	case "":
+		return stores, nil
+	case "name":
+		sortKey = func(store globalconfig.Store) string { return strings.ToLower(store.Name) }
+	case "type":
+		sortKey = func(store globalconfig.Store) string { return store.Type }
+	case "path":
+		sortKey = func(store globalconfig.Store) string { return store.Path }
+	default:
+		return nil, exitcode.Usagef("invalid --sort \"%s\": expected name, type or path", key)
+	}
+	sorted := append([]globalconfig.Store(nil), stores...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sortKey(sorted[i]) < sortKey(sorted[j]) })
+	return sorted, nil
+}
+
 // listStoresAction implements the logic for "loom config list".
 func listStoresAction(c *cli.Context) error {
 	config, err := globalconfig.LoadGlobalConfig()
@@ -244,14 +1013,47 @@ func listStoresAction(c *cli.Context) error {
 		return fmt.Errorf("failed to load global Loom configuration: %w", err)
 	}
 
+	stores, err := sortedStores(config.Stores, c.String("sort"))
+	if err != nil {
+		return err
+	}
+	verify := c.Bool("verify")
+	failedStores := 0
 	hasPrintedStore := false
-	if len(config.Stores) > 0 {
+	if len(stores) > 0 {
 		fmt.Println("Configured Thread Stores:")
-		for i, store := range config.Stores {
-			fmt.Printf("  Name:     %s\n", store.Name)
+		for i, store := range stores {
+			if config.DefaultStore != "" && strings.EqualFold(store.Name, config.DefaultStore) {
+				fmt.Printf("  Name:     %s (default)\n", store.Name)
+			} else {
+				fmt.Printf("  Name:     %s\n", store.Name)
+			}
 			fmt.Printf("  Type:     %s\n", store.Type)
+			if err := globalconfig.ValidateStoreType(store.Type); err != nil {
+				fmt.Printf("  Warning:  %v. Fix it with 'loom config set-type %s <type>'.\n", err, store.Name)
+			}
 			fmt.Printf("  Path/URL: %s\n", store.Path)
-			if i < len(config.Stores)-1 {
+			if store.Ref != "" {
+				fmt.Printf("  Ref:      %s\n", store.Ref)
+			}
+			if store.Checkout != "" {
+				fmt.Printf("  Checkout: %s\n", store.Checkout)
+			}
+			if store.TokenEnv != "" {
+				fmt.Printf("  Token:    $%s\n", store.TokenEnv)
+			}
+			fmt.Printf("  Access:   %s\n", storeAccess(store))
+			if store.Priority != 0 {
+				fmt.Printf("  Priority: %d\n", store.Priority)
+			}
+			if verify {
+				status, ok := verifyStore(store)
+				fmt.Printf("  Status:   %s\n", status)
+				if !ok {
+					failedStores++
+				}
+			}
+			if i < len(stores)-1 {
 				fmt.Println() // Add a blank line between store entries
 			}
 			hasPrintedStore = true
@@ -282,6 +1084,803 @@ func listStoresAction(c *cli.Context) error {
 	if !hasPrintedStore {
 		fmt.Println("No configured global stores or project-specific store found.")
 	}
+	if failedStores > 0 {
+		return fmt.Errorf("%d store(s) failed verification", failedStores)
+	}
 
 	return nil
 }
+
+// remoteStoreTestTimeout bounds how long "loom config test" waits for a git or github store to answer.
+const remoteStoreTestTimeout = 30 * time.Second
+
+// testStoreAction implements the logic for "loom config test <name>".
+func testStoreAction(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return exitcode.Usagef("incorrect number of arguments. Expected <name>")
+	}
+	storeName := c.Args().Get(0)
+
+	config, err := globalconfig.LoadGlobalConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load global Loom configuration: %w", err)
+	}
+
+	for _, store := range config.Stores {
+		if !strings.EqualFold(store.Name, storeName) {
+			continue
+		}
+		if err := globalconfig.ValidateStoreType(store.Type); err != nil {
+			return exitcode.Usagef("store \"%s\" has an %v", store.Name, err)
+		}
+		switch store.Type {
+		case globalconfig.StoreTypeLocal:
+			return testLocalStore(store)
+		case globalconfig.StoreTypeArchive:
+			return testArchiveStore(store)
+		}
+		return testRemoteStore(store)
+	}
+	return exitcode.Usagef("store \"%s\" not found", storeName)
+}
+
+// testLocalStore checks that a local store's path is a readable directory and reports its thread count.
+func testLocalStore(store globalconfig.Store) error {
+	fileInfo, err := os.Stat(store.Path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("store \"%s\": path \"%s\" does not exist", store.Name, store.Path)
+		}
+		return fmt.Errorf("store \"%s\": failed to stat path \"%s\": %w", store.Name, store.Path, err)
+	}
+	if !fileInfo.IsDir() {
+		return fmt.Errorf("store \"%s\": path \"%s\" is not a directory", store.Name, store.Path)
+	}
+
+	threads, err := listCmd.ListThreadsInStore(store.Path)
+	if err != nil {
+		return fmt.Errorf("store \"%s\": %w", store.Name, err)
+	}
+	fmt.Printf("Store \"%s\" is reachable: %d thread(s) found in %s\n", store.Name, len(threads), store.Path)
+	return nil
+}
+
+// testArchiveStore checks that an archive store can be extracted and reports its thread count.
+func testArchiveStore(store globalconfig.Store) error {
+	resolver, err := threadstore.New(store)
+	if err != nil {
+		return err
+	}
+	threads, err := resolver.ListThreads()
+	if err != nil {
+		return fmt.Errorf("store \"%s\": %w", store.Name, err)
+	}
+	fmt.Printf("Store \"%s\" is readable: %d thread(s) found in %s\n", store.Name, len(threads), store.Path)
+	return nil
+}
+
+// testRemoteStore checks that a git or github store can be fetched from, using "git ls-remote".
+func testRemoteStore(store globalconfig.Store) error {
+	elapsed, err := probeRemoteStore(store)
+	if err != nil {
+		return fmt.Errorf("store \"%s\": %w", store.Name, err)
+	}
+	fmt.Printf("Store \"%s\" is reachable: fetched %s in %s\n", store.Name, remoteStoreURL(store), elapsed)
+	return nil
+}
+
+// probeRemoteStore runs "git ls-remote" against a git or github store and returns how long it took.
+// Terminal prompts are disabled so a store behind auth fails instead of hanging.
+func probeRemoteStore(store globalconfig.Store) (time.Duration, error) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return 0, fmt.Errorf("git is required to test %s stores: %w", store.Type, err)
+	}
+	url := remoteStoreURL(store)
+	authEnv, err := gitAuthEnv(store)
+	if err != nil {
+		return 0, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), remoteStoreTestTimeout)
+	defer cancel()
+	command := exec.CommandContext(ctx, "git", "ls-remote", "--heads", url)
+	command.Env = append(append(os.Environ(), "GIT_TERMINAL_PROMPT=0"), authEnv...)
+
+	start := time.Now()
+	output, err := command.CombinedOutput()
+	elapsed := time.Since(start).Round(time.Millisecond)
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return elapsed, fmt.Errorf("fetching %s timed out after %s", url, elapsed)
+		}
+		return elapsed, fmt.Errorf("fetching %s failed after %s: %s", url, elapsed, strings.TrimSpace(string(output)))
+	}
+	return elapsed, nil
+}
+
+// gitAuthEnv returns the environment that makes git send the access token of store, read from its
+// TokenEnv, over HTTPS. The token is passed through GIT_CONFIG_* variables rather than the command
+// line or the URL, so it shows up neither in the process list nor in git's error messages.
+func gitAuthEnv(store globalconfig.Store) ([]string, error) {
+	token, err := store.Token()
+	if err != nil || token == "" {
+		return nil, err
+	}
+	credentials := base64.StdEncoding.EncodeToString([]byte("x-access-token:" + token))
+	return []string{
+		"GIT_CONFIG_COUNT=1",
+		"GIT_CONFIG_KEY_0=http.extraHeader",
+		"GIT_CONFIG_VALUE_0=Authorization: Basic " + credentials,
+	}, nil
+}
+
+// verifyStore returns a one-line status for "loom config list --verify" and whether the store is usable.
+// Local stores are checked with os.Stat, archive stores by extracting them, and git and github stores
+// are probed like "loom config test".
+func verifyStore(store globalconfig.Store) (string, bool) {
+	if err := globalconfig.ValidateStoreType(store.Type); err != nil {
+		return "INVALID TYPE", false
+	}
+	if store.Type == globalconfig.StoreTypeArchive {
+		if _, err := threadstore.New(store); err != nil {
+			return fmt.Sprintf("UNREADABLE (%v)", err), false
+		}
+		return "OK", true
+	}
+	if store.Type != globalconfig.StoreTypeLocal {
+		elapsed, err := probeRemoteStore(store)
+		if err != nil {
+			return fmt.Sprintf("UNREACHABLE (%v)", err), false
+		}
+		return fmt.Sprintf("OK (reachable in %s)", elapsed), true
+	}
+	info, err := os.Stat(store.Path)
+	switch {
+	case os.IsNotExist(err):
+		return "MISSING", false
+	case err != nil:
+		return fmt.Sprintf("ERROR (%v)", err), false
+	case !info.IsDir():
+		return "NOT A DIR", false
+	}
+	return "OK", true
+}
+
+// remoteStoreURL returns the URL to fetch a remote store from. github stores may be configured as
+// "owner/repo" shorthand, which is expanded to the GitHub HTTPS URL.
+func remoteStoreURL(store globalconfig.Store) string {
+	if store.Type != globalconfig.StoreTypeGitHub || strings.Contains(store.Path, "://") || strings.Contains(strings.ToLower(store.Path), "github.com") {
+		return store.Path
+	}
+	return "https://github.com/" + strings.Trim(store.Path, "/") + ".git"
+}
+
+// validateConfigAction implements "loom config validate": it checks every store of the global
+// configuration and the default store, prints each problem found, and fails if there is any. A file
+// that does not parse, or whose defaults are invalid, already fails to load.
+func validateConfigAction(c *cli.Context) error {
+	if c.NArg() != 0 {
+		return exitcode.Usagef("validate takes no arguments")
+	}
+	configPath, err := globalconfig.GetGlobalConfigPath()
+	if err != nil {
+		return err
+	}
+	config, err := globalconfig.LoadGlobalConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load global Loom configuration: %w", err)
+	}
+
+	var problems []string
+	for i, store := range config.Stores {
+		for _, problem := range validateStore(store, c.Bool("offline")) {
+			problems = append(problems, fmt.Sprintf("store \"%s\": %s", store.Name, problem))
+		}
+		for _, other := range config.Stores[:i] {
+			if store.Name != "" && strings.EqualFold(store.Name, other.Name) {
+				problems = append(problems, fmt.Sprintf("store \"%s\": another store is also named \"%s\"; only the first is used", store.Name, other.Name))
+			}
+			if store.Path != "" && sameStorePath(store.Path, other.Path) {
+				problems = append(problems, fmt.Sprintf("store \"%s\": path %s is also the path of store \"%s\"", store.Name, store.Path, other.Name))
+			}
+		}
+	}
+	if config.DefaultStore != "" {
+		if _, found := config.FindStore(config.DefaultStore); !found {
+			problems = append(problems, fmt.Sprintf("default_store \"%s\" is not a configured store; change it with 'loom config default-store'", config.DefaultStore))
+		}
+	}
+
+	if len(problems) == 0 {
+		fmt.Printf("Global configuration %s is valid: %d store(s) checked.\n", configPath, len(config.Stores))
+		return nil
+	}
+	for _, problem := range problems {
+		fmt.Printf("  %s\n", problem)
+	}
+	return fmt.Errorf("found %d problem(s) in global configuration %s", len(problems), configPath)
+}
+
+// validateStore returns the problems of a single store for "loom config validate": a missing name,
+// an unknown type, a token variable that is invalid or unset, undefined variables in its path, and a
+// path that cannot be used. Local paths and checkouts must be directories and archives must extract;
+// git and github stores are probed like "loom config test" unless offline is set.
+func validateStore(store globalconfig.Store, offline bool) []string {
+	var problems []string
+	if strings.TrimSpace(store.Name) == "" {
+		problems = append(problems, "the store has no name")
+	}
+	if err := globalconfig.ValidateStoreType(store.Type); err != nil {
+		return append(problems, fmt.Sprintf("%v; fix it with 'loom config set-type %s <type>'", err, store.Name))
+	}
+	if store.TokenEnv != "" {
+		if err := globalconfig.ValidateTokenEnv(store.TokenEnv); err != nil {
+			return append(problems, err.Error())
+		}
+		if _, err := store.Token(); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+	os.Expand(store.RawPath, func(name string) string {
+		if _, ok := os.LookupEnv(name); !ok {
+			problems = append(problems, fmt.Sprintf("path %s references undefined environment variable %s", store.RawPath, name))
+		}
+		return ""
+	})
+	if strings.TrimSpace(store.Path) == "" {
+		return append(problems, "the store has no path")
+	}
+
+	dir := ""
+	switch {
+	case store.Type == globalconfig.StoreTypeLocal:
+		dir = store.Path
+	case store.Type == globalconfig.StoreTypeGit && store.Checkout != "":
+		dir = store.Checkout
+	case store.Type == globalconfig.StoreTypeArchive:
+		if _, err := threadstore.New(store); err != nil {
+			problems = append(problems, err.Error())
+		}
+		return problems
+	default:
+		if offline || len(problems) > 0 {
+			return problems
+		}
+		if _, err := probeRemoteStore(store); err != nil {
+			problems = append(problems, err.Error())
+		}
+		return problems
+	}
+	info, err := os.Stat(dir)
+	switch {
+	case os.IsNotExist(err):
+		problems = append(problems, fmt.Sprintf("directory %s does not exist; recreate it or remove the store with 'loom config remove %s'", dir, store.Name))
+	case err != nil:
+		problems = append(problems, fmt.Sprintf("failed to stat %s: %v", dir, err))
+	case !info.IsDir():
+		problems = append(problems, fmt.Sprintf("%s is not a directory", dir))
+	}
+	return problems
+}
+
+// resetConfigAction implements "loom config reset": it rewrites a clean global configuration, for
+// instance when the file is corrupt and every command touching stores fails to load it. The old file
+// is not parsed, only moved aside.
+func resetConfigAction(c *cli.Context) error {
+	if c.NArg() != 0 {
+		return exitcode.Usagef("reset takes no arguments")
+	}
+	configPath, err := globalconfig.GetGlobalConfigPath()
+	if err != nil {
+		return err
+	}
+	if !c.Bool("force") {
+		confirmed, err := output.Confirm(os.Stdout, fmt.Sprintf("Reset %s? Every configured store is removed; the current file is kept as %s.bak.", configPath, globalconfig.ConfigFileName), false)
+		if err != nil {
+			return fmt.Errorf("failed to read user input: %w", err)
+		}
+		if !confirmed {
+			fmt.Println("Reset cancelled.")
+			return nil
+		}
+	}
+	backupPath, err := globalconfig.ResetGlobalConfig()
+	if err != nil {
+		return err
+	}
+	if backupPath != "" {
+		fmt.Printf("Reset the global configuration at %s; the previous file is at %s.\n", configPath, backupPath)
+	} else {
+		fmt.Printf("Wrote a new global configuration at %s.\n", configPath)
+	}
+	return nil
+}
+
+// addProjectThreadAction implements "loom config add-project <thread>": it copies a thread's whole
+// directory (its _thread and config.yml) from a store into .loom/<thread_name>, so the thread then
+// resolves as a project: source without the store being configured.
+func addProjectThreadAction(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return exitcode.Usagef("incorrect number of arguments. Expected <thread_name> or <store_name>/<thread_name>")
+	}
+	threadArg := c.Args().Get(0)
+
+	projectRoot, loomConfigPath, err := project.LocateManifest(c.String("config"))
+	if err != nil {
+		return err
+	}
+	threadName, threadDir, err := addCmd.ResolveThreadDir(loomConfigPath, threadArg)
+	if err != nil {
+		return err
+	}
+
+	destDir := filepath.Join(projectRoot, project.ProjectStoreDirName, threadName)
+	if sameStorePath(threadDir, destDir) {
+		return exitcode.Usagef("thread \"%s\" is already in the project store; use <store_name>/%s to copy it from a store", threadName, threadName)
+	}
+
+	copied, err := copyThreadToProjectStore(threadName, threadDir, destDir, c.Bool("force"))
+	if err != nil || !copied {
+		return err
+	}
+	fmt.Printf("It now resolves as source '%s'.\n", project.EncodeSource(project.ProjectSource(threadName)))
+	return nil
+}
+
+// copyThreadToProjectStore copies the thread directory threadDir to destDir in the project store. An
+// existing destDir is replaced if force is set or the user confirms; it reports false if they decline.
+func copyThreadToProjectStore(threadName, threadDir, destDir string, force bool) (bool, error) {
+	if _, err := os.Stat(destDir); err == nil {
+		if !force {
+			confirmed, err := output.Confirm(os.Stdout, fmt.Sprintf("%s already exists. Replace it with the thread from %s?", destDir, threadDir), false)
+			if err != nil {
+				return false, fmt.Errorf("failed to read user input: %w", err)
+			}
+			if !confirmed {
+				fmt.Println("Copy cancelled.")
+				return false, nil
+			}
+		}
+		if err := os.RemoveAll(destDir); err != nil {
+			return false, fmt.Errorf("failed to remove existing %s: %w", destDir, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return false, fmt.Errorf("failed to check %s: %w", destDir, err)
+	}
+
+	if err := fileutil.CopyTree(threadDir, destDir); err != nil {
+		return false, fmt.Errorf("failed to copy thread \"%s\" into the project store: %w", threadName, err)
+	}
+	fmt.Printf("Copied thread \"%s\" from %s to %s.\n", threadName, threadDir, destDir)
+	return true, nil
+}
+
+// vendoredThread is a thread copied into the project store by "loom config move-to-project".
+type vendoredThread struct {
+	name   string // Directory name in .loom
+	source string // Source the thread resolved to before it was copied
+}
+
+// threadVendor copies a thread and its dependencies, transitively, into the project store.
+type threadVendor struct {
+	projectRoot    string
+	loomConfigPath string // Its loom.yaml, whose stores threads are resolved from
+	force          bool
+	visited        map[string]bool // Thread directories already handled, to stop at dependency cycles
+	vendored       []vendoredThread
+}
+
+// moveToProjectAction implements "loom config move-to-project <thread>": it copies the thread and
+// every dependency declared in the config.yml files along the way into .loom, points the copied
+// dependency lists at the copies, and rewrites matching loom.yaml sources to project: sources, so the
+// project no longer needs the stores.
+func moveToProjectAction(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return exitcode.Usagef("incorrect number of arguments. Expected <thread_name> or <store_name>/<thread_name>")
+	}
+	projectRoot, loomConfigPath, err := project.LocateManifest(c.String("config"))
+	if err != nil {
+		return err
+	}
+	lock, err := project.AcquireLock(loomConfigPath, false)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = lock.Release() }()
+
+	vendor := &threadVendor{projectRoot: projectRoot, loomConfigPath: loomConfigPath, force: c.Bool("force"), visited: make(map[string]bool)}
+	if _, err := vendor.vendor(c.Args().Get(0), ""); err != nil {
+		return err
+	}
+	if len(vendor.vendored) == 0 {
+		return nil
+	}
+	return rewriteVendoredSources(loomConfigPath, vendor.vendored)
+}
+
+// vendor copies the thread threadArg into the project store after its dependencies and returns the
+// name it has there. dependent is the thread declaring threadArg as a dependency, or "" for the thread
+// named on the command line. A dependency already in the project store is kept as it is.
+func (v *threadVendor) vendor(threadArg, dependent string) (string, error) {
+	threadName, threadDir, err := addCmd.ResolveThreadDir(v.loomConfigPath, threadArg)
+	if err != nil {
+		if dependent != "" {
+			return "", fmt.Errorf("cannot move dependency '%s' of thread '%s' into the project: %w", threadArg, dependent, err)
+		}
+		return "", err
+	}
+	destDir := filepath.Join(v.projectRoot, project.ProjectStoreDirName, threadName)
+	if v.visited[resolveStorePath(threadDir)] {
+		return threadName, nil
+	}
+	v.visited[resolveStorePath(threadDir)] = true
+
+	_, statErr := os.Stat(destDir)
+	switch {
+	case sameStorePath(threadDir, destDir):
+		fmt.Printf("Thread \"%s\" is already in the project store.\n", threadName)
+	case statErr == nil && dependent != "":
+		fmt.Printf("Dependency \"%s\" of thread \"%s\" is already in the project store; keeping %s.\n", threadName, dependent, destDir)
+	default:
+		_, source, err := addCmd.ResolveThread(v.loomConfigPath, threadArg)
+		if err != nil {
+			return "", err
+		}
+		copied, err := copyThreadToProjectStore(threadName, threadDir, destDir, v.force)
+		if err != nil || !copied {
+			return "", err
+		}
+		v.vendored = append(v.vendored, vendoredThread{name: threadName, source: source})
+		v.visited[resolveStorePath(destDir)] = true
+	}
+
+	config, err := threadconfig.LoadThreadConfig(destDir)
+	if err != nil {
+		return "", err
+	}
+	rewrites := make(map[string]string)
+	for _, depArg := range config.Dependencies {
+		depName, err := v.vendor(depArg, threadName)
+		if err != nil {
+			return "", err
+		}
+		rewrites[depArg] = depName
+	}
+	if changed, err := threadconfig.RewriteDependencies(destDir, rewrites); err != nil {
+		return "", err
+	} else if changed {
+		fmt.Printf("Pointed the dependencies of \"%s\" at the project store.\n", threadName)
+	}
+	return threadName, nil
+}
+
+// rewriteVendoredSources records project: sources in loom.yaml for the threads whose source is one
+// the vendored threads were copied from. A project without loom.yaml has nothing to rewrite.
+func rewriteVendoredSources(loomConfigPath string, vendored []vendoredThread) error {
+	data, err := os.ReadFile(loomConfigPath)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to read %s: %w", project.YamlFileName, err)
+	}
+	loomConfig, err := project.ParseLoomConfig(data)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %w", project.YamlFileName, err)
+	}
+	rewritten := 0
+	for i, thread := range loomConfig.Threads {
+		for _, v := range vendored {
+			if !sameThreadSource(thread, v) {
+				continue
+			}
+			newSource := project.EncodeSource(project.ProjectSource(v.name))
+			fmt.Printf("Thread '%s' in %s now uses source '%s' instead of '%s'.\n", thread.Name, project.YamlFileName, newSource, thread.Source)
+			loomConfig.Threads[i].Source = newSource
+			rewritten++
+			break
+		}
+	}
+	if rewritten == 0 {
+		return nil
+	}
+	loomConfig.Normalize()
+	updated, err := yaml.Marshal(&loomConfig)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", project.YamlFileName, err)
+	}
+	if err := os.WriteFile(loomConfigPath, updated, 0644); err != nil {
+		return fmt.Errorf("failed to write updated %s: %w", project.YamlFileName, err)
+	}
+	return nil
+}
+
+// sameThreadSource reports whether the loom.yaml entry thread comes from the store thread v was copied
+// from. A renamed entry records the thread's own path in its source, so the thread paths in the store
+// are compared rather than the encoded sources.
+func sameThreadSource(thread project.Thread, v vendoredThread) bool {
+	entry := project.ParseSource(thread.Source)
+	resolved := project.ParseSource(v.source)
+	if entry.Kind != project.SourceKindStore || resolved.Kind != project.SourceKindStore || entry.Location != resolved.Location {
+		return false
+	}
+	entryPath := entry.Thread
+	if entryPath == "" {
+		entryPath = thread.Name
+	}
+	resolvedPath := resolved.Thread
+	if resolvedPath == "" {
+		resolvedPath = v.name
+	}
+	return entryPath == resolvedPath
+}
+
+// migrateStoresAction implements "loom config migrate --from <old> --to <new>": every local store whose
+// path is --from or lies under it has that prefix replaced by --to. Threads in the project's loom.yaml
+// that come from a migrated store, or from a path: source under --from, are reported afterwards.
+func migrateStoresAction(c *cli.Context) error {
+	if c.NArg() != 0 {
+		return exitcode.Usagef("migrate takes no arguments; use --from and --to")
+	}
+	if strings.TrimSpace(c.String("from")) == "" || strings.TrimSpace(c.String("to")) == "" {
+		return exitcode.Usagef("both --from and --to are required")
+	}
+	fromPath, err := filepath.Abs(c.String("from"))
+	if err != nil {
+		return fmt.Errorf("failed to get absolute path for \"%s\": %w", c.String("from"), err)
+	}
+	toPath, err := filepath.Abs(c.String("to"))
+	if err != nil {
+		return fmt.Errorf("failed to get absolute path for \"%s\": %w", c.String("to"), err)
+	}
+	dryRun := c.Bool("dry-run")
+
+	config, err := globalconfig.LoadGlobalConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load global Loom configuration: %w", err)
+	}
+
+	migrated := make(map[string]bool)
+	for i, store := range config.Stores {
+		if store.Type != globalconfig.StoreTypeLocal {
+			continue
+		}
+		newPath, ok := migratePath(store.Path, fromPath, toPath)
+		if !ok {
+			continue
+		}
+		fmt.Printf("Store \"%s\": %s -> %s\n", store.Name, store.Path, newPath)
+		if _, statErr := os.Stat(newPath); statErr != nil {
+			fmt.Fprintf(os.Stderr, "Warning: new path %s for store \"%s\" is not accessible: %v\n", newPath, store.Name, statErr)
+		}
+		config.Stores[i].Path = newPath
+		migrated[store.Name] = true
+	}
+
+	if len(migrated) == 0 {
+		fmt.Printf("No local stores found under %s.\n", fromPath)
+		return nil
+	}
+	if dryRun {
+		fmt.Printf("Dry run: %d store(s) would be updated; nothing was saved.\n", len(migrated))
+	} else {
+		if err := globalconfig.SaveGlobalConfig(config); err != nil {
+			return fmt.Errorf("failed to save global Loom configuration: %w", err)
+		}
+		fmt.Printf("Updated %d store(s).\n", len(migrated))
+	}
+
+	warnAffectedProjectThreads(c.String("config"), migrated, fromPath)
+	return nil
+}
+
+// migratePath returns p with its prefix oldPrefix replaced by newPrefix, and false if p is neither
+// oldPrefix nor a path inside it.
+func migratePath(p, oldPrefix, newPrefix string) (string, bool) {
+	rel, err := filepath.Rel(oldPrefix, filepath.Clean(p))
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return filepath.Join(newPrefix, rel), true
+}
+
+// warnAffectedProjectThreads prints a warning for each thread in the project's loom.yaml whose source
+// names a migrated store or is a path: source under fromPath. A missing loom.yaml is silently ignored.
+func warnAffectedProjectThreads(configPath string, migratedStores map[string]bool, fromPath string) {
+	_, loomConfigPath, err := project.LocateManifest(configPath)
+	if err != nil {
+		return
+	}
+	data, err := os.ReadFile(loomConfigPath)
+	if err != nil {
+		return
+	}
+	loomConfig, err := project.ParseLoomConfig(data)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: could not check %s for affected threads: %v\n", loomConfigPath, err)
+		return
+	}
+	for _, thread := range loomConfig.Threads {
+		source := project.ParseSource(thread.Source)
+		switch {
+		case source.Kind == project.SourceKindStore && migratedStores[source.Location]:
+			fmt.Fprintf(os.Stderr, "Warning: thread '%s' in %s comes from migrated store '%s'; run 'loom weave %s' to check it against the new location.\n", thread.Name, loomConfigPath, source.Location, thread.Name)
+		case source.Kind == project.SourceKindPath:
+			if project.PathContains(fromPath, filepath.FromSlash(source.Location)) {
+				fmt.Fprintf(os.Stderr, "Warning: thread '%s' in %s uses the path source '%s' under %s, which migrate does not rewrite.\n", thread.Name, loomConfigPath, source.Location, fromPath)
+			}
+		}
+	}
+}
+
+// storeExport is the portable document written by "loom config export" and read by "loom config import".
+type storeExport struct {
+	Version string          `yaml:"version" json:"version"`
+	Stores  []exportedStore `yaml:"stores" json:"stores"`
+}
+
+// exportedStore is a store in a storeExport.
+type exportedStore struct {
+	Name string `yaml:"name" json:"name"`
+	Type string `yaml:"type" json:"type"`
+	Path string `yaml:"path" json:"path"`
+	// Priority is the store's search priority; zero is omitted.
+	Priority int `yaml:"priority,omitempty" json:"priority,omitempty"`
+	// Ref is the branch a git store tracks. Its checkout is specific to this machine and not exported.
+	Ref string `yaml:"ref,omitempty" json:"ref,omitempty"`
+	// TokenEnv names the variable holding the store's access token; the token itself is never exported.
+	TokenEnv string `yaml:"token_env,omitempty" json:"token_env,omitempty"`
+	// ReadOnly marks a store that Loom must never write to.
+	ReadOnly bool `yaml:"read_only,omitempty" json:"read_only,omitempty"`
+}
+
+// exportStoresAction implements "loom config export". Paths are written as they appear in the global
+// configuration, so environment variable references such as $HOME stay portable.
+func exportStoresAction(c *cli.Context) error {
+	if c.NArg() != 0 {
+		return exitcode.Usagef("export takes no arguments; use --output to write to a file")
+	}
+	format := strings.ToLower(strings.TrimSpace(c.String("format")))
+	if format != "yaml" && format != "json" {
+		return exitcode.Usagef("invalid --format \"%s\": expected yaml or json", c.String("format"))
+	}
+
+	config, err := globalconfig.LoadGlobalConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load global Loom configuration: %w", err)
+	}
+	export := storeExport{Version: "1", Stores: []exportedStore{}}
+	for _, store := range config.Stores {
+		storePath := store.Path
+		if store.RawPath != "" {
+			storePath = store.RawPath
+		}
+		export.Stores = append(export.Stores, exportedStore{Name: store.Name, Type: store.Type, Path: storePath, Priority: store.Priority, Ref: store.Ref, TokenEnv: store.TokenEnv, ReadOnly: store.ReadOnly})
+	}
+
+	var data []byte
+	if format == "json" {
+		data, err = json.MarshalIndent(export, "", "  ")
+		data = append(data, '\n')
+	} else {
+		data, err = yaml.Marshal(&export)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to encode stores: %w", err)
+	}
+
+	outputPath := c.String("output")
+	if outputPath == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(outputPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", outputPath, err)
+	}
+	fmt.Printf("Exported %d store(s) to %s\n", len(export.Stores), outputPath)
+	return nil
+}
+
+// importStoresAction implements "loom config import <file>": stores whose path is already registered are
+// skipped, and stores whose name is taken are renamed with a numeric suffix. Absolute local paths are
+// flagged because they may not exist on this machine.
+func importStoresAction(c *cli.Context) error {
+	if c.NArg() != 1 {
+		return exitcode.Usagef("incorrect number of arguments. Expected <file>")
+	}
+	importPath := c.Args().First()
+	data, err := os.ReadFile(importPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return exitcode.Usagef("import file \"%s\" does not exist", importPath)
+		}
+		return fmt.Errorf("failed to read %s: %w", importPath, err)
+	}
+	// JSON is valid YAML, so one decoder reads both export formats.
+	var imported storeExport
+	if err := yaml.Unmarshal(data, &imported); err != nil {
+		return exitcode.Usagef("failed to parse %s: %v", importPath, err)
+	}
+
+	config, err := globalconfig.LoadGlobalConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load global Loom configuration: %w", err)
+	}
+
+	added, skipped := 0, 0
+	for _, store := range imported.Stores {
+		name, storeType, rawPath := strings.TrimSpace(store.Name), strings.TrimSpace(store.Type), strings.TrimSpace(store.Path)
+		if err := project.ValidateName("store", name); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: skipping store with invalid name: %v\n", err)
+			skipped++
+			continue
+		}
+		if err := globalconfig.ValidateStoreType(storeType); err != nil || rawPath == "" {
+			fmt.Fprintf(os.Stderr, "Warning: skipping store \"%s\": it needs a valid type and a path\n", name)
+			skipped++
+			continue
+		}
+		if store.TokenEnv != "" {
+			if err := globalconfig.ValidateTokenEnv(store.TokenEnv); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: skipping store \"%s\": %v\n", name, err)
+				skipped++
+				continue
+			}
+		}
+		expandedPath := os.ExpandEnv(rawPath)
+
+		duplicate := ""
+		for _, existing := range config.Stores {
+			if sameStorePath(existing.Path, expandedPath) {
+				duplicate = existing.Name
+				break
+			}
+		}
+		if duplicate != "" {
+			fmt.Printf("Skipping store \"%s\": its path/url \"%s\" is already registered as store \"%s\"\n", name, expandedPath, duplicate)
+			skipped++
+			continue
+		}
+
+		finalName := uniqueStoreName(config.Stores, name)
+		if finalName != name {
+			fmt.Printf("A store named \"%s\" already exists; importing it as \"%s\"\n", name, finalName)
+		}
+		if storeType == globalconfig.StoreTypeLocal && filepath.IsAbs(rawPath) {
+			note := ""
+			if _, err := os.Stat(expandedPath); err != nil {
+				note = " and does not exist here"
+			}
+			fmt.Fprintf(os.Stderr, "Warning: store \"%s\" uses the absolute path \"%s\", which may not be portable%s; fix it with 'loom config migrate' if needed\n", finalName, rawPath, note)
+		}
+
+		config.Stores = append(config.Stores, globalconfig.Store{Name: finalName, Type: storeType, Path: expandedPath, Priority: store.Priority, Ref: store.Ref, TokenEnv: store.TokenEnv, ReadOnly: store.ReadOnly, RawPath: rawPath})
+		fmt.Printf("Imported %s store \"%s\" with path/url \"%s\"\n", storeType, finalName, rawPath)
+		added++
+	}
+
+	if added > 0 {
+		if err := globalconfig.SaveGlobalConfig(config); err != nil {
+			return fmt.Errorf("failed to save global Loom configuration: %w", err)
+		}
+	}
+	fmt.Printf("Imported %d store(s), skipped %d.\n", added, skipped)
+	return nil
+}
+
+// uniqueStoreName returns name, or name with the smallest "-N" suffix (N >= 2) that no store in
+// stores uses, comparing case-insensitively like "loom config add".
+func uniqueStoreName(stores []globalconfig.Store, name string) string {
+	taken := func(candidate string) bool {
+		for _, store := range stores {
+			if strings.EqualFold(store.Name, candidate) {
+				return true
+			}
+		}
+		return false
+	}
+	candidate := name
+	for n := 2; taken(candidate); n++ {
+		candidate = fmt.Sprintf("%s-%d", name, n)
+	}
+	return candidate
+}
@@ -3,9 +3,16 @@ package init
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 
-	"github.com/urfave/cli/v2"
+	addCmd "loom/internal/cli/add"
+	"loom/internal/core/exitcode"
+	"loom/internal/core/globalconfig"
 	"loom/internal/core/project"
+
+	"github.com/urfave/cli/v2"
 )
 
 // Command returns the init command for the CLI
@@ -13,6 +20,16 @@ func Command() *cli.Command {
 	return &cli.Command{
 		Name:  "init",
 		Usage: "Initialize a new loom.yaml file in the current directory",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "template",
+				Usage: "Seed loom.yaml with the threads of a template defined in the global configuration",
+			},
+			&cli.StringSliceFlag{
+				Name:  "with-thread",
+				Usage: "Seed loom.yaml with a thread (<thread_name> or <store_name>/<thread_name>). Repeatable.",
+			},
+		},
 		Action: func(c *cli.Context) error {
 			return handleInit(c)
 		},
@@ -21,12 +38,81 @@ func Command() *cli.Command {
 
 // handleInit handles the init command
 func handleInit(c *cli.Context) error {
+	threadArgs, err := collectSeedThreads(c.String("template"), c.StringSlice("with-thread"))
+	if err != nil {
+		return fmt.Errorf("failed to initialize project: %w", err)
+	}
+
+	threads, err := resolveSeedThreads(threadArgs)
+	if err != nil {
+		return fmt.Errorf("failed to initialize project: %w", err)
+	}
+
 	// Initialize the project
-	err := project.InitProject()
+	err = project.InitProject(threads)
 	if err != nil {
 		return fmt.Errorf("failed to initialize project: %w", err)
 	}
 
-	fmt.Println("Initialized empty Loom project with loom.yaml")
+	if len(threads) == 0 {
+		fmt.Println("Initialized empty Loom project with loom.yaml")
+		return nil
+	}
+	fmt.Printf("Initialized Loom project with %d thread(s) in loom.yaml:\n", len(threads))
+	for _, thread := range threads {
+		fmt.Printf("  - %s (Source: %s)\n", thread.Name, thread.Source)
+	}
+	fmt.Println("Run 'loom weave' to install their files.")
 	return nil
 }
+
+// collectSeedThreads returns the thread arguments from the named template followed by the --with-thread values.
+func collectSeedThreads(templateName string, withThreads []string) ([]string, error) {
+	var threadArgs []string
+	if templateName != "" {
+		gConf, err := globalconfig.LoadGlobalConfig()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load global Loom configuration: %w", err)
+		}
+		template, found := gConf.FindTemplate(templateName)
+		if !found {
+			var names []string
+			for _, t := range gConf.Templates {
+				names = append(names, t.Name)
+			}
+			if len(names) == 0 {
+				return nil, exitcode.Usagef("template '%s' not found; no templates are defined in the global configuration", templateName)
+			}
+			return nil, exitcode.Usagef("template '%s' not found (available: %s)", templateName, strings.Join(names, ", "))
+		}
+		threadArgs = append(threadArgs, template.Threads...)
+	}
+	return append(threadArgs, withThreads...), nil
+}
+
+// resolveSeedThreads resolves each thread argument to a manifest entry, skipping duplicates by name.
+func resolveSeedThreads(threadArgs []string) ([]project.Thread, error) {
+	if len(threadArgs) == 0 {
+		return nil, nil
+	}
+	projectRoot, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current directory: %w", err)
+	}
+	loomConfigPath := filepath.Join(projectRoot, project.YamlFileName)
+
+	var threads []project.Thread
+	seen := make(map[string]bool)
+	for _, arg := range threadArgs {
+		name, source, err := addCmd.ResolveThread(loomConfigPath, arg)
+		if err != nil {
+			return nil, fmt.Errorf("cannot seed thread '%s': %w", arg, err)
+		}
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		threads = append(threads, project.Thread{Name: name, Source: source})
+	}
+	return threads, nil
+}
@@ -4,30 +4,94 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
 	"os"
+	"path"
 	"path/filepath" // Added for store path operations
-	"strings"       // Added for string operations
+	"slices"
+	"sort"
+	"strings" // Added for string operations
 
+	"loom/internal/core/exitcode"
+	"loom/internal/core/fileutil"
 	"loom/internal/core/globalconfig" // Added for global config access
 	"loom/internal/core/project"      // Import the project package
-
-	"gopkg.in/yaml.v3"
+	"loom/internal/core/threadstore"
 )
 
 // Remove local Thread and LoomConfig structs, use project package versions
 
+// projectStoreFilter is the --store value that selects only the project's .loom store.
+const projectStoreFilter = "project"
+
+// Options selects what `loom list` prints.
+type Options struct {
+	// Store restricts the store listing to a single named store, or "project" for the .loom store.
+	Store string
+	// Active lists only the threads recorded in loom.yaml.
+	Active bool
+	// Available lists only the threads offered by the stores.
+	Available bool
+	// Check hashes every file an active thread owns and compares it with the thread's source to flag
+	// modified files. Without it, only missing files are flagged.
+	Check bool
+	// ConfigPath is the loom.yaml whose threads are listed; its directory holds the project store.
+	// Empty means loom.yaml in the current directory.
+	ConfigPath string
+}
+
 // listThreads reads the loom.yaml file and lists active threads.
 // It also lists available threads from configured local stores.
-func listThreads() error {
-	if err := printActiveProjectThreads(); err != nil {
+// opts.Active and opts.Available restrict the output to one of the two sections; neither means both.
+// If opts.Store is non-empty, only the named store (or the project store for "project") is listed.
+func listThreads(opts Options) error {
+	if opts.Active && opts.Available {
+		return exitcode.Usagef("--active and --available cannot be used together")
+	}
+	if opts.Active && opts.Store != "" {
+		return exitcode.Usagef("--store only applies to store threads and cannot be used with --active")
+	}
+	projectRoot, loomConfigPath, err := project.LocateManifest(opts.ConfigPath)
+	if err != nil {
 		return err
 	}
+	if opts.Check && opts.Available {
+		return exitcode.Usagef("--check only applies to active threads and cannot be used with --available")
+	}
+	if opts.Active {
+		return printActiveProjectThreads(loomConfigPath, opts.Check)
+	}
+	storeFilter := opts.Store
 
-	fmt.Println("\nAvailable store threads:")
-	gConf, err := globalconfig.LoadGlobalConfig() // This loads the actual global config struct
+	gConf, err := project.LoadStoreConfig(loomConfigPath) // Global stores, plus any loom.yaml declares
 	if err != nil {
-		return fmt.Errorf("failed to load global Loom configuration: %w", err)
+		return err
+	}
+
+	// Validate the filter before printing anything so a typo fails fast.
+	if storeFilter != "" && storeFilter != projectStoreFilter {
+		filteredStores := filterStores(gConf.Stores, storeFilter)
+		if len(filteredStores) == 0 {
+			return exitcode.Usagef("store '%s' not found in global configuration", storeFilter)
+		}
+		gConf = &globalconfig.GlobalLoomConfig{Version: gConf.Version, Stores: filteredStores}
+	}
+
+	if !opts.Available {
+		if err := printActiveProjectThreads(loomConfigPath, opts.Check); err != nil {
+			return err
+		}
+		fmt.Println()
+	}
+
+	fmt.Println("Available store threads:")
+
+	if storeFilter == projectStoreFilter {
+		if found, _ := printProjectStoreThreads(projectRoot, loomConfigPath); !found {
+			fmt.Println("No threads found in the project store.")
+		}
+		return nil
 	}
 
 	foundAnyStoreThreads := false
@@ -42,7 +106,12 @@ func listThreads() error {
 		foundAnyStoreThreads = foundAnyStoreThreads || foundGlobalStoreThreads
 	}
 
-	foundProjectStoreThreads, errPrintingProjectStore := printProjectStoreThreads()
+	if storeFilter != "" {
+		// A single global store was requested; the project store is skipped.
+		return nil
+	}
+
+	foundProjectStoreThreads, errPrintingProjectStore := printProjectStoreThreads(projectRoot, loomConfigPath)
 	if errPrintingProjectStore != nil {
 		fmt.Fprintf(os.Stderr, "Error processing project store: %v\n", errPrintingProjectStore)
 	}
@@ -72,15 +141,27 @@ func listThreads() error {
 	return nil
 }
 
+// filterStores returns the stores whose name matches storeName (case-insensitive).
+func filterStores(stores []globalconfig.Store, storeName string) []globalconfig.Store {
+	var matched []globalconfig.Store
+	for _, store := range stores {
+		if strings.EqualFold(store.Name, storeName) {
+			matched = append(matched, store)
+		}
+	}
+	return matched
+}
+
 // printGlobalStoreThreads iterates over configured global stores and prints their threads.
 // It returns true if any threads were found in global stores, false otherwise.
 // The gConf parameter should be the struct type defined in the globalconfig package.
 func printGlobalStoreThreads(gConf *globalconfig.GlobalLoomConfig) (bool, error) { // Corrected type to globalconfig.GlobalLoomConfig
 	foundAny := false
+	storesByThread := make(map[string][]string) // Thread name -> stores listing it, to flag ambiguous names
 	for _, store := range gConf.Stores {
-		if store.Type == "local" { // For now, only supporting local stores
+		if resolver, resolveErr := threadstore.New(store); resolveErr == nil { // For now, only local stores resolve
 			fmt.Printf("\nStore: %s (Type: %s, Path: %s)\n", store.Name, store.Type, store.Path)
-			threads, err := listThreadsInStore(store.Path)
+			threads, err := resolver.ListThreads()
 			if err != nil {
 				fmt.Fprintf(os.Stderr, "  Error listing threads in store '%s': %v\n", store.Name, err)
 				continue // Continue to the next store
@@ -89,28 +170,62 @@ func printGlobalStoreThreads(gConf *globalconfig.GlobalLoomConfig) (bool, error)
 				fmt.Println("  No threads found in this store.")
 			} else {
 				foundAny = true
-				for _, threadName := range threads {
-					fmt.Printf("  - %s\n", threadName)
+				for _, thread := range threads {
+					fmt.Printf("  - %s\n", thread.Name)
+					storesByThread[thread.Name] = append(storesByThread[thread.Name], store.Name)
 				}
 			}
+		} else if err := globalconfig.ValidateStoreType(store.Type); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: store '%s' has an %v. Fix it with 'loom config set-type %s <type>'.\n", store.Name, err, store.Name)
+		} else if !errors.Is(resolveErr, threadstore.ErrUnsupportedType) {
+			fmt.Fprintf(os.Stderr, "\nError reading store '%s': %v\n", store.Name, resolveErr)
 		}
 	}
+	printAmbiguousThreads(gConf, storesByThread)
 	return foundAny, nil
 }
 
-// printProjectStoreThreads lists threads from the project-specific .loom store.
-// It returns true if any threads were found in the project store, false otherwise.
-func printProjectStoreThreads() (bool, error) {
-	projectRoot, err := os.Getwd()
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Warning: Could not determine current directory to check for project store: %v\n", err)
-		return false, nil // Not a fatal error for listing, just can't check project store
+// printAmbiguousThreads notes each thread name that storesByThread lists in more than one store,
+// naming the store 'loom add <name>' takes it from, which follows the store search order, and
+// suggesting the <store>/<thread> form to pick another.
+func printAmbiguousThreads(gConf *globalconfig.GlobalLoomConfig, storesByThread map[string][]string) {
+	var ambiguous []string
+	for name, stores := range storesByThread {
+		if len(stores) > 1 {
+			ambiguous = append(ambiguous, name)
+		}
+	}
+	if len(ambiguous) == 0 {
+		return
 	}
+	sort.Strings(ambiguous)
+	fmt.Println()
+	for _, name := range ambiguous {
+		stores := storesByThread[name]
+		chosen := stores[0]
+		for _, store := range gConf.StoresInSearchOrder() {
+			if slices.Contains(stores, store.Name) {
+				chosen = store.Name
+				break
+			}
+		}
+		quoted := make([]string, len(stores))
+		for i, store := range stores {
+			quoted[i] = "'" + store + "'"
+		}
+		fmt.Printf("Note: thread '%s' is in stores %s and %s; 'loom add %s' takes it from '%s'. Use 'loom add <store>/%s' to choose.\n",
+			name, strings.Join(quoted[:len(quoted)-1], ", "), quoted[len(quoted)-1], name, chosen, name)
+	}
+}
 
+// printProjectStoreThreads lists threads from the project-specific .loom store, marking each as
+// (active) if a thread in the loom.yaml at loomConfigPath is woven from it, or (available) otherwise.
+// It returns true if any threads were found in the project store, false otherwise.
+func printProjectStoreThreads(projectRoot, loomConfigPath string) (bool, error) {
 	projectStorePath := filepath.Join(projectRoot, ".loom")
 	if _, statErr := os.Stat(projectStorePath); statErr == nil {
 		fmt.Printf("\nProject Store (.loom):\n")
-		threads, listErr := listThreadsInStore(projectStorePath)
+		threads, listErr := ListThreadsInProjectStore(projectStorePath)
 		if listErr != nil {
 			fmt.Fprintf(os.Stderr, "  Error listing threads in project store: %v\n", listErr)
 			return false, nil // Error occurred, but treat as no threads found for the purpose of the caller
@@ -119,8 +234,16 @@ func printProjectStoreThreads() (bool, error) {
 			fmt.Println("  No threads found in this store.")
 			return false, nil
 		}
+		activeAs := projectSourcedThreads(loomConfigPath)
 		for _, threadName := range threads {
-			fmt.Printf("  - %s\n", threadName)
+			switch name, active := activeAs[threadName]; {
+			case !active:
+				fmt.Printf("  - %s (available)\n", threadName)
+			case name != threadName:
+				fmt.Printf("  - %s (active as '%s')\n", threadName, name)
+			default:
+				fmt.Printf("  - %s (active)\n", threadName)
+			}
 		}
 		return true, nil // Threads found
 	} else if !os.IsNotExist(statErr) {
@@ -130,9 +253,36 @@ func printProjectStoreThreads() (bool, error) {
 	return false, nil // Project store does not exist or error stating it
 }
 
-// printActiveProjectThreads handles reading loom.yaml and printing active project threads.
-func printActiveProjectThreads() error {
-	file, err := os.Open(project.YamlFileName) // Use project.YamlFileName
+// projectSourcedThreads maps the name of each .loom directory that a thread in the loom.yaml at
+// loomConfigPath is woven from (source "project:.loom/<name>") to that thread's name. A missing or
+// unreadable loom.yaml has no such threads.
+func projectSourcedThreads(loomConfigPath string) map[string]string {
+	activeAs := make(map[string]string)
+	data, err := os.ReadFile(loomConfigPath)
+	if err != nil {
+		return activeAs
+	}
+	projectConfig, err := project.ParseLoomConfig(data)
+	if err != nil {
+		return activeAs
+	}
+	for _, thread := range projectConfig.Threads {
+		source := project.ParseSource(thread.Source)
+		if source.Kind != project.SourceKindProject {
+			continue
+		}
+		if name, ok := strings.CutPrefix(path.Clean(filepath.ToSlash(source.Location)), project.ProjectStoreDirName+"/"); ok {
+			activeAs[name] = thread.Name
+		}
+	}
+	return activeAs
+}
+
+// printActiveProjectThreads handles reading the loom.yaml at loomConfigPath and printing active project threads,
+// with the number of files each owns. Threads with missing files, or with check set modified ones, are
+// marked with [!].
+func printActiveProjectThreads(loomConfigPath string, check bool) error {
+	data, err := os.ReadFile(loomConfigPath)
 	if err != nil {
 		// If loom.yaml doesn't exist, it's not an error for listing, just means no project threads
 		if !os.IsNotExist(err) {
@@ -141,19 +291,16 @@ func printActiveProjectThreads() error {
 		fmt.Println("No active project configuration (loom.yaml) found.")
 		return nil // Not an error in this context
 	}
-	defer func() {
-		if err := file.Close(); err != nil {
-			fmt.Fprintf(os.Stderr, "Error closing file %s: %v\n", project.YamlFileName, err)
-		}
-	}()
 
-	var projectConfig project.LoomConfig // Use project.LoomConfig
-	decoder := yaml.NewDecoder(file)
-	if err := decoder.Decode(&projectConfig); err != nil {
+	projectConfig, err := project.ParseLoomConfig(data)
+	if err != nil {
 		return fmt.Errorf("failed to parse %s: %w", project.YamlFileName, err)
 	}
 
 	gConfForActive, _ := globalconfig.LoadGlobalConfig() // Load global config to check store names
+	if gConfForActive != nil && len(projectConfig.Stores) > 0 {
+		gConfForActive = gConfForActive.WithProjectStores(projectConfig.ProjectStores(filepath.Dir(loomConfigPath)))
+	}
 
 	if len(projectConfig.Threads) == 0 {
 		fmt.Println("No threads are currently active in the project.")
@@ -173,43 +320,137 @@ func printActiveProjectThreads() error {
 					}
 				}
 			}
-			fmt.Printf("- %s (Source: %s)\n", thread.Name, displaySource) // Print thread name and source
+			status := threadFileStatus(&thread, filepath.Dir(loomConfigPath), gConfForActive, check)
+			marker := ""
+			if status.missing > 0 || status.modified > 0 || status.checkErr != nil {
+				marker = " [!]"
+			}
+			fmt.Printf("- %s (Source: %s)%s\n", thread.Name, displaySource, marker) // Print thread name and source
+			fmt.Printf("    Files:     %s\n", status)
+			if status.checkErr != nil {
+				fmt.Printf("    Warning:   cannot check for modified files: %v\n", status.checkErr)
+			}
+			if thread.InstalledAt != "" {
+				fmt.Printf("    Installed: %s\n", thread.InstalledAt)
+			}
+			if thread.UpdatedAt != "" {
+				fmt.Printf("    Updated:   %s\n", thread.UpdatedAt)
+			}
 		}
 	}
 	return nil
 }
 
-// listThreadsInStore lists subdirectories in a given store path that appear to be valid Loom threads.
-// A directory is considered a thread if it contains a 'config.yml' file or a '_thread/' subdirectory.
-func listThreadsInStore(storePath string) ([]string, error) {
-	entries, err := os.ReadDir(storePath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to read store directory '%s': %w", storePath, err)
-	}
+// fileStatus summarizes the state of the files an active thread owns.
+type fileStatus struct {
+	owned    int
+	missing  int
+	modified int   // Only counted with --check
+	checkErr error // Why modified files could not be counted, with --check
+}
 
-	var threadNames []string
-	for _, entry := range entries {
-		if entry.IsDir() {
-			threadName := entry.Name()
-			// Check for config.yml or _thread/ directory to qualify as a thread
-			configFilePath := filepath.Join(storePath, threadName, "config.yml")
-			threadDirPath := filepath.Join(storePath, threadName, "_thread")
+// String formats s as "3", "3 (1 missing)" or "3 (1 missing, 1 modified)".
+func (s fileStatus) String() string {
+	var problems []string
+	if s.missing > 0 {
+		problems = append(problems, fmt.Sprintf("%d missing", s.missing))
+	}
+	if s.modified > 0 {
+		problems = append(problems, fmt.Sprintf("%d modified", s.modified))
+	}
+	if len(problems) == 0 {
+		return fmt.Sprintf("%d", s.owned)
+	}
+	return fmt.Sprintf("%d (%s)", s.owned, strings.Join(problems, ", "))
+}
 
-			_, errConfig := os.Stat(configFilePath)
-			_, errDir := os.Stat(threadDirPath)
+// threadFileStatus counts the files thread owns and how many of them are missing from disk. With
+// check, it also hashes the files that exist and counts those that differ from the thread's source;
+// files no longer in the source are not compared.
+func threadFileStatus(thread *project.Thread, projectRoot string, gConf *globalconfig.GlobalLoomConfig, check bool) fileStatus {
+	var status fileStatus
+	var sourceHashes map[string]string
+	if check {
+		sourceHashes, status.checkErr = sourceFileHashes(thread, projectRoot, gConf)
+	}
+	for dir, files := range thread.Files {
+		for _, file := range files {
+			status.owned++
+			relPath := path.Join(dir, file)
+			filePath := filepath.Join(projectRoot, filepath.FromSlash(relPath))
+			if _, err := os.Stat(filePath); err != nil {
+				status.missing++
+				continue
+			}
+			sourceHash, ok := sourceHashes[relPath]
+			if !ok {
+				continue
+			}
+			if hash, err := fileutil.HashFile(filePath); err != nil || hash != sourceHash {
+				status.modified++
+			}
+		}
+	}
+	return status
+}
 
-			if errConfig == nil || errDir == nil { // If either exists, it's a thread
-				threadNames = append(threadNames, threadName)
+// sourceFileHashes resolves thread's source and returns the hash of every file it installs, keyed by
+// project-relative destination path.
+func sourceFileHashes(thread *project.Thread, projectRoot string, gConf *globalconfig.GlobalLoomConfig) (map[string]string, error) {
+	lookupStorePath := func(storeName string) (string, error) {
+		if gConf != nil {
+			if store, ok := gConf.FindStore(storeName); ok {
+				return store.Path, nil
 			}
 		}
+		return "", fmt.Errorf("store '%s' not found in global configuration", storeName)
+	}
+	sourceDir, err := project.ResolveSource(project.ParseSource(thread.Source), thread.Name, projectRoot, lookupStorePath)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := os.Stat(sourceDir); err != nil {
+		return nil, fmt.Errorf("thread source directory %s is missing", sourceDir)
+	}
+	locked, err := project.LockThread(thread, sourceDir, projectRoot)
+	if err != nil {
+		return nil, err
+	}
+	return locked.Files, nil
+}
+
+// ListThreadsInStore lists the threads in the local store at storePath, including threads nested in
+// category directories, which are named by their slash-separated path relative to the store
+// (frontend/button). See threadstore.LocalStore.ListThreads.
+func ListThreadsInStore(storePath string) ([]string, error) {
+	threads, err := (&threadstore.LocalStore{Path: storePath}).ListThreads()
+	if err != nil {
+		return nil, err
+	}
+	threadNames := make([]string, 0, len(threads))
+	for _, thread := range threads {
+		threadNames = append(threadNames, thread.Name)
 	}
 	return threadNames, nil
 }
 
-// ExecuteListCommand is the entry point for the `loom list` command.
-func ExecuteListCommand() {
-	if err := listThreads(); err != nil {
-		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
-		os.Exit(1)
+// ListThreadsInProjectStore lists the threads in the project store at projectStorePath. Project
+// threads are added by bare name, so only top-level threads are listed.
+func ListThreadsInProjectStore(projectStorePath string) ([]string, error) {
+	threads, err := ListThreadsInStore(projectStorePath)
+	if err != nil {
+		return nil, err
 	}
+	topLevel := threads[:0]
+	for _, threadName := range threads {
+		if !strings.Contains(threadName, "/") {
+			topLevel = append(topLevel, threadName)
+		}
+	}
+	return topLevel, nil
+}
+
+// ExecuteListCommand is the entry point for the `loom list` command.
+func ExecuteListCommand(opts Options) error {
+	return listThreads(opts)
 }
@@ -0,0 +1,94 @@
+// Package manifest implements the 'loom manifest' command.
+package manifest
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"sort"
+	"strings"
+
+	"loom/internal/core/exitcode"
+	"loom/internal/core/project"
+
+	"github.com/urfave/cli/v2"
+)
+
+// Command returns the cli.Command for the "manifest" command.
+func Command() *cli.Command {
+	return &cli.Command{
+		Name:      "manifest",
+		Usage:     "Print the files an installed thread owns, as JSON or with --paths one path per line",
+		ArgsUsage: "<thread_name>",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "paths",
+				Usage: "Print one project-relative file path per line instead of JSON",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			if c.NArg() != 1 {
+				return exitcode.Usagef("manifest takes exactly one thread name")
+			}
+			return Manifest(c.Args().First(), c.Bool("paths"), c.String("config"))
+		},
+	}
+}
+
+// Manifest prints the files map that the loom.yaml at configPath records for threadName: as a JSON
+// object mapping each directory ("./", "src/") to its file names, or, with paths, as one
+// project-relative path per line in sorted order.
+func Manifest(threadName string, paths bool, configPath string) error {
+	projectRoot, loomConfigPath, err := project.LocateManifest(configPath)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(loomConfigPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return exitcode.Usagef("%s not found in %s", project.YamlFileName, projectRoot)
+		}
+		return fmt.Errorf("failed to read %s: %w", project.YamlFileName, err)
+	}
+	loomConfig, err := project.ParseLoomConfig(data)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %w", project.YamlFileName, err)
+	}
+
+	for _, thread := range loomConfig.Threads {
+		if thread.Name != threadName {
+			continue
+		}
+		if paths {
+			for _, p := range manifestPaths(thread.Files) {
+				fmt.Println(p)
+			}
+			return nil
+		}
+		files := thread.Files
+		if files == nil {
+			files = map[string][]string{} // An empty object rather than null
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(files); err != nil {
+			return fmt.Errorf("failed to write JSON output: %v", err)
+		}
+		return nil
+	}
+	return exitcode.Usagef("thread '%s' not found in %s", threadName, project.YamlFileName)
+}
+
+// manifestPaths returns the project-relative paths of the files in a thread's files map, sorted.
+func manifestPaths(files map[string][]string) []string {
+	var paths []string
+	for dir, names := range files {
+		dir = strings.TrimPrefix(dir, "./")
+		for _, name := range names {
+			paths = append(paths, path.Join(dir, name))
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
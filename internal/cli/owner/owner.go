@@ -0,0 +1,73 @@
+// Package owner implements the 'loom owner' command.
+package owner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"loom/internal/core/exitcode"
+	"loom/internal/core/project"
+
+	"github.com/urfave/cli/v2"
+)
+
+// Command returns the cli.Command for the "owner" command.
+func Command() *cli.Command {
+	return &cli.Command{
+		Name:      "owner",
+		Usage:     "Show which thread owns each of the given files",
+		ArgsUsage: "<path>...",
+		Action: func(c *cli.Context) error {
+			if c.NArg() == 0 {
+				return exitcode.Usagef("owner takes at least one file path")
+			}
+			return Owner(c.Args().Slice(), c.String("config"))
+		},
+	}
+}
+
+// Owner prints the thread that owns each of paths according to the loom.yaml at configPath, along
+// with that thread's source. Relative paths are taken relative to the project root, like the paths
+// recorded in loom.yaml.
+func Owner(paths []string, configPath string) error {
+	projectRoot, loomConfigPath, err := project.LocateManifest(configPath)
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(loomConfigPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return exitcode.Usagef("%s not found in %s", project.YamlFileName, projectRoot)
+		}
+		return fmt.Errorf("failed to read %s: %w", project.YamlFileName, err)
+	}
+	loomConfig, err := project.ParseLoomConfig(data)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %w", project.YamlFileName, err)
+	}
+
+	for _, p := range paths {
+		filePath := filepath.Clean(p)
+		if !filepath.IsAbs(filePath) {
+			filePath = filepath.Join(projectRoot, filePath)
+		}
+		threadName, owned := loomConfig.IsFileOwned(filePath, projectRoot)
+		if !owned {
+			fmt.Printf("%s: not owned by any thread.\n", p)
+			continue
+		}
+		fmt.Printf("%s: %s (Source: %s)\n", p, threadName, threadSource(&loomConfig, threadName))
+	}
+	return nil
+}
+
+// threadSource returns the source recorded for the thread named threadName.
+func threadSource(loomConfig *project.LoomConfig, threadName string) string {
+	for _, thread := range loomConfig.Threads {
+		if thread.Name == threadName {
+			return thread.Source
+		}
+	}
+	return ""
+}
@@ -0,0 +1,202 @@
+// Package publish implements the 'loom publish' command.
+package publish
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"loom/internal/core/exitcode"
+	"loom/internal/core/fileutil"
+	"loom/internal/core/project"
+	"loom/internal/core/threadconfig"
+	"loom/internal/core/threadstore"
+
+	"github.com/urfave/cli/v2"
+)
+
+// Command returns the cli.Command for the "publish" command.
+func Command() *cli.Command {
+	return &cli.Command{
+		Name:      "publish",
+		Usage:     "Copy project files into a new thread in a writable local store",
+		ArgsUsage: "<thread_name>",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:     "store",
+				Usage:    "Name of the writable local store to publish the thread to",
+				Required: true,
+			},
+			&cli.StringSliceFlag{
+				Name:     "files",
+				Usage:    "Project files to publish, as a glob relative to the project root (repeatable); patterns without \"/\" match names anywhere, and directories include everything below them",
+				Required: true,
+			},
+			&cli.BoolFlag{
+				Name:  "force",
+				Usage: "Replace the thread if it already exists in the store",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			if c.NArg() != 1 {
+				return exitcode.Usagef("publish takes exactly one thread name")
+			}
+			return Publish(c.Args().First(), c.String("store"), c.StringSlice("files"), c.Bool("force"), c.String("config"))
+		},
+	}
+}
+
+// Publish copies the files of the project at configPath that match patterns into
+// <store>/<threadName>/_thread, keeping their project-relative layout, and writes a minimal
+// config.yml next to it. The store must be writable (see globalconfig.Store.Writable), and an
+// existing thread is only replaced with force.
+func Publish(threadName, storeName string, patterns []string, force bool, configPath string) error {
+	if err := project.ValidateThreadPath(threadName); err != nil {
+		return exitcode.UsageError(err)
+	}
+	cleanPatterns := make([]string, 0, len(patterns))
+	for _, pattern := range patterns {
+		cleanPattern := strings.Trim(strings.ReplaceAll(strings.TrimSpace(pattern), "\\", "/"), "/")
+		cleanPattern = strings.TrimPrefix(cleanPattern, "./")
+		if cleanPattern == "" || cleanPattern == "." {
+			return exitcode.Usagef("--files needs a non-empty pattern")
+		}
+		if _, err := path.Match(cleanPattern, ""); err != nil {
+			return exitcode.Usagef("invalid --files pattern '%s': %v", pattern, err)
+		}
+		cleanPatterns = append(cleanPatterns, cleanPattern)
+	}
+
+	projectRoot, loomConfigPath, err := project.LocateManifest(configPath)
+	if err != nil {
+		return err
+	}
+	gConf, err := project.LoadStoreConfig(loomConfigPath)
+	if err != nil {
+		return err
+	}
+	store, found := gConf.FindStore(storeName)
+	if !found {
+		return exitcode.Usagef("store '%s' not found; run 'loom config list' to see the configured stores", storeName)
+	}
+	if !store.Writable() {
+		if store.ReadOnly {
+			return exitcode.Usagef("store '%s' is read-only; threads can only be published to writable local stores", storeName)
+		}
+		return exitcode.Usagef("store '%s' is a remote %s store; threads can only be published to writable local stores", storeName, store.Type)
+	}
+	storePath, ok := threadstore.LocalPath(store)
+	if !ok {
+		return exitcode.Usagef("store '%s' has no directory on this machine to publish to", storeName)
+	}
+	if info, err := os.Stat(storePath); err != nil || !info.IsDir() {
+		return fmt.Errorf("store '%s' directory %s does not exist", storeName, storePath)
+	}
+
+	threadDir := filepath.Join(storePath, filepath.FromSlash(threadName))
+	if _, err := os.Stat(threadDir); err == nil {
+		_, hasConfig := threadconfig.ConfigFilePath(threadDir)
+		_, errSource := os.Stat(filepath.Join(threadDir, threadconfig.SourceDirName))
+		if !hasConfig && errSource != nil {
+			return exitcode.Usagef("%s already exists in store '%s' and is not a thread; choose another thread name", threadName, storeName)
+		}
+		if !force {
+			return exitcode.Usagef("thread '%s' already exists in store '%s'; pass --force to replace it", threadName, storeName)
+		}
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("failed to access %s: %w", threadDir, err)
+	}
+
+	files, err := collectFiles(projectRoot, storePath, cleanPatterns)
+	if err != nil {
+		return err
+	}
+
+	if err := os.RemoveAll(threadDir); err != nil {
+		return fmt.Errorf("failed to remove existing thread '%s' from store '%s': %w", threadName, storeName, err)
+	}
+	sourceDir := filepath.Join(threadDir, threadconfig.SourceDirName)
+	for _, rel := range files {
+		src := filepath.Join(projectRoot, filepath.FromSlash(rel))
+		info, err := os.Stat(src)
+		if err != nil {
+			return fmt.Errorf("failed to access %s: %w", rel, err)
+		}
+		dest := filepath.Join(sourceDir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(dest), os.ModePerm); err != nil {
+			return fmt.Errorf("failed to create directory %s: %w", filepath.Dir(dest), err)
+		}
+		if err := fileutil.CopyFile(src, dest, info.Mode().Perm()); err != nil {
+			return err
+		}
+		fmt.Printf("Published %s\n", rel)
+	}
+
+	// A %q string is also a valid YAML double-quoted scalar.
+	config := fmt.Sprintf("version: 1\nmetadata:\n  description: %q\n", "Published from "+filepath.Base(projectRoot))
+	if err := os.WriteFile(filepath.Join(threadDir, threadconfig.ConfigFileName), []byte(config), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", threadconfig.ConfigFileName, err)
+	}
+
+	fmt.Printf("Published %d file(s) as thread '%s' in store '%s' (%s).\n", len(files), threadName, storeName, threadDir)
+	fmt.Printf("Run 'loom add --manifest-only %s/%s' to have this project own the published files.\n", storeName, threadName)
+	return nil
+}
+
+// collectFiles returns the slash-separated, project-relative paths of the regular files under
+// projectRoot that match at least one pattern, in sorted order. The loom manifests, .git, the
+// project's .loom directory and the store at storePath, should the project hold it, are never
+// published. Every pattern must match a file, so a mistyped one is reported rather than silently
+// publishing less.
+func collectFiles(projectRoot, storePath string, patterns []string) ([]string, error) {
+	matched := make(map[string]bool, len(patterns))
+	var files []string
+	err := filepath.WalkDir(projectRoot, func(p string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(projectRoot, p)
+		if err != nil {
+			return fmt.Errorf("failed to get relative path for %s: %w", p, err)
+		}
+		rel = filepath.ToSlash(rel)
+		if entry.IsDir() {
+			if rel == ".git" || rel == project.ProjectStoreDirName || rel == project.BackupsDirName || p == storePath {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !entry.Type().IsRegular() {
+			return nil // Symlinks and special files are not published
+		}
+		switch rel {
+		case project.YamlFileName, project.LockFileName, project.ResolutionLockFileName:
+			return nil
+		}
+		include := false
+		for _, pattern := range patterns {
+			if threadconfig.MatchFilterPattern(pattern, rel) {
+				matched[pattern] = true
+				include = true
+			}
+		}
+		if include {
+			files = append(files, rel)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read project directory %s: %w", projectRoot, err)
+	}
+	for _, pattern := range patterns {
+		if !matched[pattern] {
+			return nil, exitcode.Usagef("no project files match --files '%s'", pattern)
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
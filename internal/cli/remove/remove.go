@@ -7,8 +7,13 @@ package remove
 import (
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
+	"sort"
+	"strings"
 
+	"loom/internal/core/exitcode"
+	"loom/internal/core/output"
 	"loom/internal/core/project" // Import the project package
 
 	"github.com/urfave/cli/v2"
@@ -17,103 +22,338 @@ import (
 
 // Remove local LoomConfig and Thread structs, use project package versions
 
+// removeOptions holds the flags of the remove command.
+type removeOptions struct {
+	// force suppresses warnings about missing files and deletes leftover non-empty directories
+	// that only the removed thread(s) installed files into, unless they hold files no removed
+	// thread recorded.
+	force bool
+	// yes skips the confirmation before removing every thread (*) and before force deletes a
+	// non-empty directory.
+	yes bool
+	// purgeSource also deletes the .loom/<name> directory of removed project-sourced threads.
+	purgeSource bool
+	// keepDirs leaves every directory in place, even ones the removal empties; it takes precedence
+	// over force deleting leftover directories.
+	keepDirs bool
+	// all removes every thread matching --source rather than failing when several do.
+	all bool
+}
+
 // Command returns the cli.Command for the "remove" command.
 func Command() *cli.Command {
 	return &cli.Command{
 		Name:      "remove",
 		Usage:     "Remove a thread from the project",
 		ArgsUsage: "<thread_name>",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "source",
+				Usage: "Remove the thread whose source in loom.yaml is `SOURCE` (e.g. mystore or mystore/base) instead of naming it",
+			},
+			&cli.BoolFlag{
+				Name:  "all",
+				Usage: "With --source, remove every thread with that source instead of failing when there are several",
+			},
+			&cli.BoolFlag{
+				Name:  "force",
+				Usage: "Do not warn about files that are already gone, and also delete non-empty directories only this thread created unless they hold files it did not install",
+			},
+			&cli.BoolFlag{
+				Name:    "yes",
+				Aliases: []string{"y"},
+				Usage:   "Do not ask for confirmation before removing all threads (*) or, with --force, deleting non-empty directories",
+			},
+			&cli.BoolFlag{
+				Name:  "purge-source",
+				Usage: "Also delete the .loom/<name> source directory of threads added from the project store",
+			},
+			&cli.BoolFlag{
+				Name:  "no-empty-dir-cleanup",
+				Usage: "Remove the thread's files but leave every directory in place, even ones left empty",
+			},
+		},
 		Action: func(c *cli.Context) error {
 			threadName := c.Args().First()
-			if threadName == "" {
-				return fmt.Errorf("thread name is required")
+			source := strings.TrimSpace(c.String("source"))
+			switch {
+			case c.IsSet("source") && threadName != "":
+				return exitcode.Usagef("--source cannot be combined with a thread name")
+			case c.IsSet("source") && source == "":
+				return exitcode.Usagef("--source needs a non-empty source")
+			case c.Bool("all") && !c.IsSet("source"):
+				return exitcode.Usagef("--all can only be used with --source")
+			case threadName == "" && !c.IsSet("source"):
+				return exitcode.Usagef("thread name is required")
+			}
+
+			opts := removeOptions{force: c.Bool("force"), yes: c.Bool("yes"), purgeSource: c.Bool("purge-source"), keepDirs: c.Bool("no-empty-dir-cleanup"), all: c.Bool("all")}
+			projectRoot, loomConfigPath, err := project.LocateManifest(c.String("config"))
+			if err != nil {
+				return err
+			}
+			lock, err := project.AcquireLock(loomConfigPath, c.Bool("force-unlock"))
+			if err != nil {
+				return err
+			}
+			defer func() { _ = lock.Release() }()
+
+			if source != "" {
+				return removeBySourceAction(projectRoot, loomConfigPath, source, opts)
 			}
 			if threadName == "*" {
-				return removeAllThreadsAction()
+				return removeAllThreadsAction(projectRoot, loomConfigPath, opts)
 			}
-			return removeThreadAction(threadName)
+			return removeThreadAction(projectRoot, loomConfigPath, threadName, opts)
 		},
 	}
 }
 
-// readLoomConfig reads and parses the loom.yaml file from the project root.
-func readLoomConfig(projectRoot string) (*project.LoomConfig, error) {
-	loomConfigPath := filepath.Join(projectRoot, project.YamlFileName)
+// readLoomConfig reads and parses the loom.yaml file at loomConfigPath.
+func readLoomConfig(loomConfigPath string) (*project.LoomConfig, error) {
 	data, err := os.ReadFile(loomConfigPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read %s: %w", project.YamlFileName, err)
 	}
 
-	var config project.LoomConfig
-	err = yaml.Unmarshal(data, &config)
+	config, err := project.ParseLoomConfig(data)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse %s: %w", project.YamlFileName, err)
 	}
 	return &config, nil
 }
 
-// findThreadInConfig searches for a thread by name in the LoomConfig.
-// It returns the thread and a new slice of threads with the found thread removed.
-// If the thread is not found, it returns an error.
-func findThreadInConfig(config *project.LoomConfig, threadName string) (project.Thread, []project.Thread, error) {
-	var threadToRemove project.Thread
+// findThreadInConfig splits the threads of the LoomConfig into those matches accepts, in manifest
+// order, and the remaining ones.
+func findThreadInConfig(config *project.LoomConfig, matches func(project.Thread) bool) ([]project.Thread, []project.Thread) {
+	var found []project.Thread
 	var updatedThreads []project.Thread
-	threadFound := false
-
 	for _, thread := range config.Threads {
-		if thread.Name == threadName {
-			threadFound = true
-			threadToRemove = thread
+		if matches(thread) {
+			found = append(found, thread)
 		} else {
 			updatedThreads = append(updatedThreads, thread)
 		}
 	}
+	return found, updatedThreads
+}
 
-	if !threadFound {
-		return project.Thread{}, nil, fmt.Errorf("thread '%s' not found in %s", threadName, project.YamlFileName)
-	}
-	return threadToRemove, updatedThreads, nil
+// sameSource reports whether two loom.yaml sources name the same thread source once decoded, so a
+// legacy "local:<store>" matches "<store>".
+func sameSource(a, b string) bool {
+	return project.EncodeSource(project.ParseSource(a)) == project.EncodeSource(project.ParseSource(b))
+}
+
+// RemoveThreadFiles deletes the files thread owns and the directories they leave empty, as
+// `loom remove --force` does for a single thread, and returns how many files were deleted. Files
+// that are already gone are skipped silently. reweave uses it to clear a thread before weaving it
+// again.
+func RemoveThreadFiles(thread project.Thread, projectRoot string) int {
+	removed, _ := removeThreadFiles(thread, projectRoot, thread.Name, removeOptions{force: true})
+	return removed
 }
 
-// removeThreadFiles removes files associated with a given thread and attempts to clean up empty directories.
-func removeThreadFiles(thread project.Thread, projectRoot string, threadName string) {
+// removeThreadFiles removes files associated with a given thread and attempts to clean up empty directories,
+// unless opts.keepDirs is set. It returns how many files it removed and the thread's directories that are
+// left over and not empty.
+func removeThreadFiles(thread project.Thread, projectRoot string, threadName string, opts removeOptions) (int, []string) {
 	if thread.Files == nil {
-		return
+		return 0, nil
 	}
+	removed := 0
+	dirs := make([]string, 0, len(thread.Files))
 	for dir, files := range thread.Files {
 		for _, file := range files {
 			filePath := filepath.Join(projectRoot, dir, file)
 			err := os.Remove(filePath)
 			if err != nil {
 				if os.IsNotExist(err) {
+					if opts.force {
+						continue
+					}
 					fmt.Printf("Warning: File %s listed in %s for thread '%s' not found, skipping.\n", filePath, project.YamlFileName, threadName)
 				} else {
 					fmt.Printf("Warning: Failed to remove file %s: %v\n", filePath, err)
 				}
 			} else {
-				fmt.Printf("Removed file: %s\n", filePath)
+				removed++
+				output.Printf(output.StyleDelete, "Removed file: %s\n", filePath)
 			}
 		}
-		// Attempt to remove the directory if it's empty
+		dirs = append(dirs, dir)
+	}
+	if opts.keepDirs {
+		return removed, nil
+	}
+
+	// Deepest directories first, so a parent emptied by removing its children is removed too.
+	sort.Slice(dirs, func(i, j int) bool { return len(dirs[i]) > len(dirs[j]) })
+	var leftoverDirs []string
+	for _, dir := range dirs {
 		dirPath := filepath.Join(projectRoot, dir)
-		if dirPath != projectRoot { // Don't try to remove the project root
-			entries, readDirErr := os.ReadDir(dirPath)
-			if readDirErr == nil && len(entries) == 0 {
-				err := os.Remove(dirPath)
-				if err != nil {
-					// Ignore error if directory is not empty or other issues
-					// fmt.Printf("Warning: Failed to remove directory %s: %v\n", dirPath, err)
-				} else {
-					fmt.Printf("Removed empty directory: %s\n", dirPath)
+		if dirPath == filepath.Clean(projectRoot) { // Don't try to remove the project root
+			continue
+		}
+		entries, readDirErr := os.ReadDir(dirPath)
+		if readDirErr == nil && len(entries) == 0 {
+			if os.Remove(dirPath) == nil {
+				fmt.Printf("Removed empty directory: %s\n", dirPath)
+			}
+		} else if readDirErr == nil {
+			leftoverDirs = append(leftoverDirs, dir)
+		}
+	}
+	return removed, leftoverDirs
+}
+
+// exclusiveDirs returns the manifest directories from dirs that no thread in others has files in,
+// either directly or in a subdirectory. The project root and the project store are never returned.
+func exclusiveDirs(dirs []string, others []project.Thread) []string {
+	var exclusive []string
+	for _, dir := range dirs {
+		normalized := path.Clean(filepath.ToSlash(dir))
+		if normalized == "." || normalized == ".." || strings.HasPrefix(normalized, "../") ||
+			normalized == project.ProjectStoreDirName || strings.HasPrefix(normalized, project.ProjectStoreDirName+"/") {
+			continue
+		}
+		shared := false
+		for _, other := range others {
+			for otherDir := range other.Files {
+				otherNormalized := path.Clean(filepath.ToSlash(otherDir))
+				if otherNormalized == normalized || strings.HasPrefix(otherNormalized, normalized+"/") {
+					shared = true
+					break
 				}
 			}
+			if shared {
+				break
+			}
+		}
+		if !shared {
+			exclusive = append(exclusive, normalized)
+		}
+	}
+	return exclusive
+}
+
+// removeLeftoverDirectories deletes non-empty manifest directories (see exclusiveDirs) with everything
+// still in them, after listing them and asking for confirmation unless opts.yes is set. A directory
+// holding a file that none of the removed threads recorded is kept, and those files are listed.
+func removeLeftoverDirectories(projectRoot string, dirs []string, removed []project.Thread, opts removeOptions) error {
+	sort.Strings(dirs)
+	// Deleting a directory deletes its subdirectories too, so only keep the outermost ones.
+	// Directories may have been emptied since they were collected; those need no confirmation.
+	var outermost []string
+	for _, dir := range dirs {
+		dirPath := filepath.Join(projectRoot, filepath.FromSlash(dir))
+		entries, err := os.ReadDir(dirPath)
+		if err != nil {
+			continue
+		}
+		if len(entries) == 0 {
+			if os.Remove(dirPath) == nil {
+				fmt.Printf("Removed empty directory: %s\n", dirPath)
+			}
+			continue
+		}
+		if len(outermost) > 0 {
+			last := outermost[len(outermost)-1]
+			if dir == last || strings.HasPrefix(dir, last+"/") {
+				continue
+			}
+		}
+		outermost = append(outermost, dir)
+	}
+
+	recorded := project.LoomConfig{Threads: removed}
+	var deletable []string
+	for _, dir := range outermost {
+		untracked, err := untrackedFiles(projectRoot, dir, &recorded)
+		if err != nil {
+			fmt.Printf("Warning: Keeping directory %s/: %v\n", dir, err)
+			continue
+		}
+		if len(untracked) > 0 {
+			fmt.Printf("Keeping directory %s/: it holds files that were not installed by the removed thread(s):\n", dir)
+			for _, file := range untracked {
+				fmt.Printf("  - %s\n", file)
+			}
+			continue
+		}
+		deletable = append(deletable, dir)
+	}
+	outermost = deletable
+	if len(outermost) == 0 {
+		return nil
+	}
+
+	fmt.Println("The following directories are not empty but contain only files of the removed thread(s):")
+	for _, dir := range outermost {
+		fmt.Printf("  - %s/\n", dir)
+	}
+	if !opts.yes {
+		confirmed, err := output.Confirm(os.Stdout, fmt.Sprintf("Delete these %d director(ies) and everything in them?", len(outermost)), false)
+		if err != nil {
+			return fmt.Errorf("failed to get confirmation for deleting directories: %w", err)
+		}
+		if !confirmed {
+			fmt.Println("Keeping the directories.")
+			return nil
+		}
+	}
+	for _, dir := range outermost {
+		dirPath := filepath.Join(projectRoot, filepath.FromSlash(dir))
+		if err := os.RemoveAll(dirPath); err != nil {
+			fmt.Printf("Warning: Failed to remove directory %s: %v\n", dirPath, err)
+			continue
+		}
+		output.Printf(output.StyleDelete, "Removed directory: %s\n", dirPath)
+	}
+	return nil
+}
+
+// untrackedFiles returns the files below the project directory dir, relative to projectRoot with
+// forward slashes, that no thread in recorded has in its manifest.
+func untrackedFiles(projectRoot, dir string, recorded *project.LoomConfig) ([]string, error) {
+	var untracked []string
+	err := filepath.WalkDir(filepath.Join(projectRoot, filepath.FromSlash(dir)), func(filePath string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		if _, owned := recorded.IsFileOwned(filePath, projectRoot); !owned {
+			relPath, relErr := filepath.Rel(projectRoot, filePath)
+			if relErr != nil {
+				return relErr
+			}
+			untracked = append(untracked, filepath.ToSlash(relPath))
+		}
+		return nil
+	})
+	return untracked, err
+}
+
+// printRemoveAllSummary lists each thread with the number of files it owns, followed by the totals,
+// so a bulk removal can be checked before it happens.
+func printRemoveAllSummary(threads []project.Thread) {
+	totalFiles := 0
+	fmt.Println("The following threads will be removed:")
+	for _, thread := range threads {
+		fileCount := 0
+		for _, files := range thread.Files {
+			fileCount += len(files)
 		}
+		totalFiles += fileCount
+		fmt.Printf("  - %s: %d file(s)\n", thread.Name, fileCount)
 	}
+	fmt.Printf("Total: %d thread(s), %d file(s)\n", len(threads), totalFiles)
 }
 
 // updateLoomConfig marshals the updated configuration and writes it back to loom.yaml.
-func updateLoomConfig(projectRoot string, config *project.LoomConfig) error {
-	loomConfigPath := filepath.Join(projectRoot, project.YamlFileName)
+func updateLoomConfig(loomConfigPath string, config *project.LoomConfig) error {
+	config.Normalize()
 	updatedData, err := yaml.Marshal(config)
 	if err != nil {
 		return fmt.Errorf("failed to marshal %s: %w", project.YamlFileName, err)
@@ -127,36 +367,120 @@ func updateLoomConfig(projectRoot string, config *project.LoomConfig) error {
 }
 
 // removeThreadAction handles the logic for removing a thread.
-func removeThreadAction(threadName string) error {
-	projectRoot, err := os.Getwd() // Assuming loom commands run from project root
+func removeThreadAction(projectRoot, loomConfigPath, threadName string, opts removeOptions) error {
+	config, err := readLoomConfig(loomConfigPath)
 	if err != nil {
-		return fmt.Errorf("failed to get current directory: %w", err)
+		return err // Error already contains context
 	}
 
-	config, err := readLoomConfig(projectRoot)
-	if err != nil {
-		return err // Error already contains context
+	found, updatedThreads := findThreadInConfig(config, func(thread project.Thread) bool { return thread.Name == threadName })
+	if len(found) == 0 {
+		return exitcode.Usagef("thread '%s' not found in %s", threadName, project.YamlFileName)
 	}
+	return removeThreads(projectRoot, loomConfigPath, config, found, updatedThreads, opts)
+}
 
-	threadToRemove, updatedThreads, err := findThreadInConfig(config, threadName)
+// removeBySourceAction removes the thread whose loom.yaml source is source, or with opts.all every
+// such thread. Several matching threads without opts.all are an error, as is none.
+func removeBySourceAction(projectRoot, loomConfigPath, source string, opts removeOptions) error {
+	config, err := readLoomConfig(loomConfigPath)
 	if err != nil {
-		return err // Error already contains context
+		return err
+	}
+
+	found, updatedThreads := findThreadInConfig(config, func(thread project.Thread) bool { return sameSource(thread.Source, source) })
+	if len(found) == 0 {
+		return exitcode.Usagef("no thread in %s has source '%s'", project.YamlFileName, source)
+	}
+	if len(found) > 1 && !opts.all {
+		names := make([]string, len(found))
+		for i, thread := range found {
+			names[i] = thread.Name
+		}
+		return exitcode.Usagef("%d threads have source '%s' (%s); pass --all to remove them all, or remove one by name", len(found), source, strings.Join(names, ", "))
 	}
+	return removeThreads(projectRoot, loomConfigPath, config, found, updatedThreads, opts)
+}
 
-	removeThreadFiles(threadToRemove, projectRoot, threadName)
+// removeThreads deletes the files of the threads in toRemove, records remaining as the threads of
+// config and saves it, then purges project sources if opts.purgeSource is set.
+func removeThreads(projectRoot, loomConfigPath string, config *project.LoomConfig, toRemove, remaining []project.Thread, opts removeOptions) error {
+	var leftoverDirs []string
+	for _, thread := range toRemove {
+		_, dirs := removeThreadFiles(thread, projectRoot, thread.Name, opts)
+		leftoverDirs = append(leftoverDirs, dirs...)
+	}
+	if opts.force && !opts.keepDirs {
+		if err := removeLeftoverDirectories(projectRoot, exclusiveDirs(leftoverDirs, remaining), toRemove, opts); err != nil {
+			return err
+		}
+	}
 
-	config.Threads = updatedThreads
-	if err := updateLoomConfig(projectRoot, config); err != nil {
+	config.Threads = remaining
+	if err := updateLoomConfig(loomConfigPath, config); err != nil {
 		return err // Error already contains context
 	}
+	if err := project.PruneLockFile(loomConfigPath, config); err != nil {
+		return err
+	}
 
-	fmt.Printf("Thread '%s' removed successfully.\n", threadName)
+	for _, thread := range toRemove {
+		fmt.Printf("Thread '%s' removed successfully.\n", thread.Name)
+	}
+	if opts.purgeSource {
+		for _, thread := range toRemove {
+			if project.ParseSource(thread.Source).Kind != project.SourceKindProject {
+				fmt.Printf("Thread '%s' is not from the project store (source '%s'); leaving its source alone.\n", thread.Name, thread.Source)
+				continue
+			}
+			purgeProjectSource(projectRoot, thread, remaining)
+		}
+	}
 	return nil
 }
 
+// purgeProjectSource deletes the .loom/<name> directory that the project-sourced thread removed was
+// added from, unless a thread in remaining still uses it. Sources of any other kind are never touched,
+// and the .loom directory itself is only removed once it is empty.
+func purgeProjectSource(projectRoot string, removed project.Thread, remaining []project.Thread) {
+	source := project.ParseSource(removed.Source)
+	if source.Kind != project.SourceKindProject {
+		return
+	}
+	location := path.Clean(filepath.ToSlash(source.Location))
+	name, ok := strings.CutPrefix(location, project.ProjectStoreDirName+"/")
+	if !ok || project.ValidateName("thread", name) != nil {
+		fmt.Printf("Warning: Not purging source '%s' of thread '%s': it is not a thread directory directly inside %s.\n", removed.Source, removed.Name, project.ProjectStoreDirName)
+		return
+	}
+	for _, other := range remaining {
+		otherSource := project.ParseSource(other.Source)
+		if otherSource.Kind == project.SourceKindProject && path.Clean(filepath.ToSlash(otherSource.Location)) == location {
+			fmt.Printf("Keeping source %s: thread '%s' still uses it.\n", location, other.Name)
+			return
+		}
+	}
+
+	storeDir := filepath.Join(projectRoot, project.ProjectStoreDirName)
+	sourceDir := filepath.Join(storeDir, name)
+	if _, err := os.Stat(sourceDir); err != nil {
+		return // Already gone, e.g. purged for another thread with the same source.
+	}
+	if err := os.RemoveAll(sourceDir); err != nil {
+		fmt.Printf("Warning: Failed to remove thread source %s: %v\n", sourceDir, err)
+		return
+	}
+	output.Printf(output.StyleDelete, "Removed thread source: %s\n", sourceDir)
+	if entries, err := os.ReadDir(storeDir); err == nil && len(entries) == 0 {
+		if os.Remove(storeDir) == nil {
+			fmt.Printf("Removed empty directory: %s\n", storeDir)
+		}
+	}
+}
+
 // removeThreadFilesAndCollectDirs processes a single thread's files for removal
 // and collects directories that might become empty.
-func removeThreadFilesAndCollectDirs(thread project.Thread, projectRoot string, directoriesToRemove map[string]bool) {
+func removeThreadFilesAndCollectDirs(thread project.Thread, projectRoot string, directoriesToRemove map[string]bool, opts removeOptions) {
 	fmt.Printf("Processing thread: %s\n", thread.Name)
 	if thread.Files != nil {
 		for dir, files := range thread.Files {
@@ -167,12 +491,15 @@ func removeThreadFilesAndCollectDirs(thread project.Thread, projectRoot string,
 				err := os.Remove(filePath)
 				if err != nil {
 					if os.IsNotExist(err) {
+						if opts.force {
+							continue
+						}
 						fmt.Printf("Warning: File %s listed for thread '%s' not found, skipping.\n", filePath, thread.Name)
 					} else {
 						fmt.Printf("Warning: Failed to remove file %s: %v\n", filePath, err)
 					}
 				} else {
-					fmt.Printf("Removed file: %s\n", filePath)
+					output.Printf(output.StyleDelete, "Removed file: %s\n", filePath)
 				}
 			}
 		}
@@ -180,7 +507,9 @@ func removeThreadFilesAndCollectDirs(thread project.Thread, projectRoot string,
 }
 
 // removeEmptyDirectories attempts to remove directories that are now empty.
-func removeEmptyDirectories(projectRoot string, directoriesToRemove map[string]bool) {
+// It returns the directories, relative to projectRoot, that are left over and not empty.
+func removeEmptyDirectories(projectRoot string, directoriesToRemove map[string]bool) []string {
+	var leftoverDirs []string
 	for dirPath := range directoriesToRemove {
 		if dirPath != projectRoot { // Don't try to remove the project root
 			entries, readDirErr := os.ReadDir(dirPath)
@@ -191,19 +520,18 @@ func removeEmptyDirectories(projectRoot string, directoriesToRemove map[string]b
 				} else {
 					fmt.Printf("Removed empty directory: %s\n", dirPath)
 				}
+			} else if readDirErr == nil {
+				if relDir, err := filepath.Rel(projectRoot, dirPath); err == nil {
+					leftoverDirs = append(leftoverDirs, relDir)
+				}
 			}
 		}
 	}
+	return leftoverDirs
 }
 
 // removeAllThreadsAction handles the logic for removing all threads.
-func removeAllThreadsAction() error {
-	projectRoot, err := os.Getwd()
-	if err != nil {
-		return fmt.Errorf("failed to get current directory: %w", err)
-	}
-	loomConfigPath := filepath.Join(projectRoot, project.YamlFileName)
-
+func removeAllThreadsAction(projectRoot, loomConfigPath string, opts removeOptions) error {
 	data, err := os.ReadFile(loomConfigPath)
 	if err != nil {
 		if os.IsNotExist(err) {
@@ -213,8 +541,7 @@ func removeAllThreadsAction() error {
 		return fmt.Errorf("failed to read %s: %w", project.YamlFileName, err)
 	}
 
-	var config project.LoomConfig
-	err = yaml.Unmarshal(data, &config)
+	config, err := project.ParseLoomConfig(data)
 	if err != nil {
 		return fmt.Errorf("failed to parse %s: %w", project.YamlFileName, err)
 	}
@@ -224,28 +551,57 @@ func removeAllThreadsAction() error {
 		return nil
 	}
 
+	printRemoveAllSummary(config.Threads)
+	if !opts.yes {
+		// A script piping into loom must not wipe the project by accident, so only a person at a
+		// terminal can confirm.
+		if !output.StdinIsTerminal() {
+			return exitcode.Usagef("refusing to remove all threads without confirmation; rerun with --yes")
+		}
+		confirmed, err := output.Confirm(os.Stdout, "Remove all of these threads and their files?", false)
+		if err != nil {
+			return fmt.Errorf("failed to get confirmation for removing all threads: %w", err)
+		}
+		if !confirmed {
+			fmt.Println("Aborted. Nothing was removed.")
+			return nil
+		}
+	}
+
 	fmt.Println("Removing all threads and their files...")
 
 	directoriesToRemove := make(map[string]bool)
 
 	for _, thread := range config.Threads {
-		removeThreadFilesAndCollectDirs(thread, projectRoot, directoriesToRemove)
+		removeThreadFilesAndCollectDirs(thread, projectRoot, directoriesToRemove, opts)
 	}
 
-	removeEmptyDirectories(projectRoot, directoriesToRemove)
+	var leftoverDirs []string
+	if !opts.keepDirs {
+		leftoverDirs = removeEmptyDirectories(projectRoot, directoriesToRemove)
+	}
+	if opts.force {
+		// Every thread is being removed, so no directory is shared with a remaining one.
+		if err := removeLeftoverDirectories(projectRoot, exclusiveDirs(leftoverDirs, nil), config.Threads, opts); err != nil {
+			return err
+		}
+	}
 
 	// Clear threads from config
+	removedThreads := config.Threads
 	config.Threads = []project.Thread{}
-	updatedData, err := yaml.Marshal(&config)
-	if err != nil {
-		return fmt.Errorf("failed to marshal %s: %w", project.YamlFileName, err)
+	if err := updateLoomConfig(loomConfigPath, &config); err != nil {
+		return err
 	}
-
-	err = os.WriteFile(loomConfigPath, updatedData, 0644)
-	if err != nil {
-		return fmt.Errorf("failed to write updated %s: %w", project.YamlFileName, err)
+	if err := project.PruneLockFile(loomConfigPath, &config); err != nil {
+		return err
 	}
 
 	fmt.Printf("All threads removed and %s cleared successfully.\n", project.YamlFileName)
+	if opts.purgeSource {
+		for _, thread := range removedThreads {
+			purgeProjectSource(projectRoot, thread, nil)
+		}
+	}
 	return nil
 }
@@ -0,0 +1,164 @@
+// Package search implements the 'loom search' command.
+package search
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	listCmd "loom/internal/cli/list"
+	"loom/internal/core/exitcode"
+	"loom/internal/core/project"
+	"loom/internal/core/threadconfig"
+	"loom/internal/core/threadstore"
+
+	"github.com/urfave/cli/v2"
+)
+
+// projectStoreName is the store name shown for threads found in the project's .loom store.
+const projectStoreName = "project"
+
+// Options controls what `loom search` matches.
+type Options struct {
+	// Term is matched case-insensitively against thread names, descriptions and tags.
+	Term string
+	// Tag, if set, only keeps threads carrying this exact tag (case-insensitive).
+	Tag string
+	// ConfigPath locates the project whose .loom store is searched alongside the configured stores.
+	ConfigPath string
+}
+
+// match is a thread that satisfied the search.
+type match struct {
+	store       string
+	thread      string
+	description string
+}
+
+// Command returns the cli.Command for the "search" command.
+func Command() *cli.Command {
+	return &cli.Command{
+		Name:      "search",
+		Usage:     "Find threads across all stores by name, description or tag",
+		ArgsUsage: "[term]",
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "tag",
+				Usage: "Only show threads tagged with this tag",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			if c.Args().Len() > 1 {
+				return exitcode.Usagef("search takes at most one term; quote it if it contains spaces")
+			}
+			return Search(Options{Term: c.Args().First(), Tag: c.String("tag"), ConfigPath: c.String("config")})
+		},
+	}
+}
+
+// Search prints every thread in the configured local stores and the project store that matches opts.
+func Search(opts Options) error {
+	term := strings.TrimSpace(opts.Term)
+	tag := strings.TrimSpace(opts.Tag)
+	if term == "" && tag == "" {
+		return exitcode.Usagef("a search term or --tag is required")
+	}
+
+	projectRoot, loomConfigPath, err := project.LocateManifest(opts.ConfigPath)
+	if err != nil {
+		return err
+	}
+	gConf, err := project.LoadStoreConfig(loomConfigPath)
+	if err != nil {
+		return err
+	}
+
+	var matches []match
+	for _, store := range gConf.Stores {
+		storePath, ok := threadstore.LocalPath(store)
+		if !ok { // Only stores on this machine can be enumerated for now, as in 'loom list'
+			continue
+		}
+		matches = append(matches, searchStore(store.Name, storePath, listCmd.ListThreadsInStore, term, tag)...)
+	}
+
+	projectStorePath := filepath.Join(projectRoot, ".loom")
+	if info, statErr := os.Stat(projectStorePath); statErr == nil && info.IsDir() {
+		matches = append(matches, searchStore(projectStoreName, projectStorePath, listCmd.ListThreadsInProjectStore, term, tag)...)
+	}
+
+	if len(matches) == 0 {
+		fmt.Println("No matching threads found.")
+		return nil
+	}
+	for _, m := range matches {
+		if m.description == "" {
+			fmt.Printf("%s/%s\n", m.store, m.thread)
+			continue
+		}
+		fmt.Printf("%s/%s - %s\n", m.store, m.thread, m.description)
+	}
+	return nil
+}
+
+// searchStore returns the threads in the store at storePath, as enumerated by listThreads, that match
+// term and tag. Unreadable stores and thread configs are reported as warnings and skipped.
+func searchStore(storeName, storePath string, listThreads func(string) ([]string, error), term, tag string) []match {
+	threads, err := listThreads(storePath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: skipping store '%s': %v\n", storeName, err)
+		return nil
+	}
+
+	var matches []match
+	for _, threadName := range threads {
+		config, err := threadconfig.LoadThreadConfig(filepath.Join(storePath, threadName))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: skipping thread '%s/%s': %v\n", storeName, threadName, err)
+			continue
+		}
+		if tag != "" && !hasTag(config.Metadata.Tags, tag) {
+			continue
+		}
+		if term != "" && !matchesTerm(threadName, config.Metadata, term) {
+			continue
+		}
+		matches = append(matches, match{store: storeName, thread: threadName, description: firstLine(config.Metadata.Description)})
+	}
+	return matches
+}
+
+// matchesTerm reports whether term appears (case-insensitively) in the thread name, description or a tag.
+func matchesTerm(threadName string, metadata threadconfig.Metadata, term string) bool {
+	needle := strings.ToLower(term)
+	if strings.Contains(strings.ToLower(threadName), needle) || strings.Contains(strings.ToLower(metadata.Description), needle) {
+		return true
+	}
+	for _, t := range metadata.Tags {
+		if strings.Contains(strings.ToLower(t), needle) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasTag reports whether tags contains tag, ignoring case.
+func hasTag(tags []string, tag string) bool {
+	for _, t := range tags {
+		if strings.EqualFold(strings.TrimSpace(t), tag) {
+			return true
+		}
+	}
+	return false
+}
+
+// firstLine returns the first non-empty line of s, trimmed, for one-line result listings.
+func firstLine(s string) string {
+	for _, line := range strings.Split(s, "\n") {
+		if trimmed := strings.TrimSpace(line); trimmed != "" {
+			return trimmed
+		}
+	}
+	return ""
+}
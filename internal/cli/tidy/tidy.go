@@ -0,0 +1,102 @@
+// Package tidy implements the 'loom tidy' command.
+package tidy
+
+import (
+	"fmt"
+	"os"
+
+	"loom/internal/core/exitcode"
+	"loom/internal/core/project"
+
+	"github.com/urfave/cli/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Command returns the cli.Command for the "tidy" command.
+func Command() *cli.Command {
+	return &cli.Command{
+		Name:  "tidy",
+		Usage: "Drop the files listed in loom.yaml that no longer exist on disk",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "Show the entries that would be dropped without saving loom.yaml",
+			},
+			&cli.BoolFlag{
+				Name:  "prune-threads",
+				Usage: "Also drop threads left without any file",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			if c.NArg() != 0 {
+				return exitcode.Usagef("tidy takes no arguments")
+			}
+			projectRoot, loomConfigPath, err := project.LocateManifest(c.String("config"))
+			if err != nil {
+				return err
+			}
+			if !c.Bool("dry-run") {
+				lock, err := project.AcquireLock(loomConfigPath, c.Bool("force-unlock"))
+				if err != nil {
+					return err
+				}
+				defer func() { _ = lock.Release() }()
+			}
+			return Tidy(projectRoot, loomConfigPath, c.Bool("dry-run"), c.Bool("prune-threads"))
+		},
+	}
+}
+
+// Tidy drops the files listed in the loom.yaml at loomConfigPath that no longer exist under
+// projectRoot, along with the directory keys left empty and, with pruneThreads, the threads left
+// without files, and saves the result unless dryRun is set. The loom.lock entries of dropped
+// threads are removed as well.
+func Tidy(projectRoot, loomConfigPath string, dryRun, pruneThreads bool) error {
+	data, err := os.ReadFile(loomConfigPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return exitcode.Usagef("%s not found in %s", project.YamlFileName, projectRoot)
+		}
+		return fmt.Errorf("failed to read %s: %w", project.YamlFileName, err)
+	}
+	loomConfig, err := project.ParseLoomConfig(data)
+	if err != nil {
+		return fmt.Errorf("failed to parse %s: %w", project.YamlFileName, err)
+	}
+
+	missing, droppedThreads := loomConfig.Tidy(projectRoot, pruneThreads)
+	if len(missing) == 0 {
+		fmt.Printf("Nothing to tidy: every file listed in %s exists.\n", project.YamlFileName)
+		return nil
+	}
+	verb := "Dropped"
+	if dryRun {
+		verb = "Would drop"
+	}
+	for _, file := range missing {
+		fmt.Printf("%s missing file %s (thread '%s')\n", verb, file.Path, file.Thread)
+	}
+	for _, name := range droppedThreads {
+		fmt.Printf("%s thread '%s', which has no files left\n", verb, name)
+	}
+	if dryRun {
+		fmt.Printf("Dry run: %d missing file(s) and %d thread(s) would be dropped; nothing was saved.\n", len(missing), len(droppedThreads))
+		return nil
+	}
+
+	loomConfig.Normalize()
+	updatedData, err := yaml.Marshal(&loomConfig)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", project.YamlFileName, err)
+	}
+	if err := os.WriteFile(loomConfigPath, updatedData, 0644); err != nil {
+		return fmt.Errorf("failed to write updated %s: %w", project.YamlFileName, err)
+	}
+	if len(droppedThreads) > 0 {
+		if err := project.PruneLockFile(loomConfigPath, &loomConfig); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("Tidied %s: dropped %d missing file(s) and %d thread(s).\n", project.YamlFileName, len(missing), len(droppedThreads))
+	return nil
+}
@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"loom/internal/core/fileutil"
+	"loom/internal/core/project"
+)
+
+// weaveBackup saves the files a weave overwrites under .loom-backups/<timestamp>/, mirroring their
+// paths in the project, so edits made to them by hand can be recovered.
+type weaveBackup struct {
+	mu    sync.Mutex // Threads woven in parallel back up concurrently
+	dir   string
+	count int
+}
+
+// newWeaveBackup returns a weaveBackup writing to a directory named after the current time. The
+// directory is only created once a file is backed up.
+func newWeaveBackup(projectRoot string) *weaveBackup {
+	base := filepath.Join(projectRoot, project.BackupsDirName, time.Now().Format("20060102-150405"))
+	dir := base
+	for i := 2; ; i++ {
+		if _, err := os.Stat(dir); os.IsNotExist(err) {
+			break
+		}
+		dir = base + "-" + strconv.Itoa(i)
+	}
+	return &weaveBackup{dir: dir}
+}
+
+// save copies the file at destPath, which relPath (project-relative, forward slashes) names, into the
+// backup tree before it is overwritten with the file at sourcePath. Files missing from the project
+// or identical to the source as it is installed with lineEndings have nothing to lose and are not
+// backed up.
+func (b *weaveBackup) save(out io.Writer, destPath, relPath, sourcePath, lineEndings string) error {
+	info, err := os.Stat(destPath)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("failed to stat %s for backup: %w", destPath, err)
+	}
+	destHash, err := fileutil.HashFile(destPath)
+	if err != nil {
+		return err
+	}
+	sourceHash, err := project.HashThreadFile(sourcePath, lineEndings)
+	if err != nil {
+		return err
+	}
+	if destHash == sourceHash {
+		return nil
+	}
+
+	backupPath := filepath.Join(b.dir, filepath.FromSlash(relPath))
+	if err := os.MkdirAll(filepath.Dir(backupPath), os.ModePerm); err != nil {
+		return fmt.Errorf("failed to create backup directory %s: %w", filepath.Dir(backupPath), err)
+	}
+	if err := ignoreBackups(filepath.Dir(b.dir)); err != nil {
+		return err
+	}
+	if err := fileutil.CopyFile(destPath, backupPath, info.Mode()); err != nil {
+		return fmt.Errorf("failed to back up %s: %w", destPath, err)
+	}
+	b.mu.Lock()
+	b.count++
+	b.mu.Unlock()
+	fmt.Fprintf(out, "Backed up %s to %s\n", relPath, backupPath)
+	return nil
+}
+
+// ignoreBackups writes a .gitignore ignoring everything in backupsDir, the directory holding every
+// weave's backups, so they never show up as changes to commit. An existing one is left alone.
+func ignoreBackups(backupsDir string) error {
+	ignorePath := filepath.Join(backupsDir, ".gitignore")
+	if _, err := os.Stat(ignorePath); err == nil {
+		return nil
+	}
+	if err := os.WriteFile(ignorePath, []byte("*\n"), 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", ignorePath, err)
+	}
+	return nil
+}
+
+// report tells the user where the backups of this weave are, if there are any.
+func (b *weaveBackup) report(out io.Writer) {
+	if b == nil || b.count == 0 {
+		return
+	}
+	fmt.Fprintf(out, "Backed up %d overwritten file(s) to %s; copy them back into the project to restore them.\n", b.count, b.dir)
+}
@@ -0,0 +1,95 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"loom/internal/core/project"
+)
+
+// verifyIdempotentWeave weaves like Weave, then weaves again and fails if the second weave changed
+// loom.yaml, printing the lines that differ.
+func verifyIdempotentWeave(projectRoot, loomConfigPath, threadNameToWeave string, opts Options) error {
+	opts.VerifyIdempotent = false
+	if err := Weave(projectRoot, loomConfigPath, threadNameToWeave, opts); err != nil {
+		return err
+	}
+	first, err := comparableManifestLines(loomConfigPath)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println("Weaving again to verify that the weave is idempotent...")
+	if err := Weave(projectRoot, loomConfigPath, threadNameToWeave, opts); err != nil {
+		return fmt.Errorf("second weave failed: %w", err)
+	}
+	second, err := comparableManifestLines(loomConfigPath)
+	if err != nil {
+		return err
+	}
+
+	differences := diffLines(first, second)
+	if len(differences) == 0 {
+		fmt.Printf("Weave is idempotent: the second weave left %s unchanged.\n", project.YamlFileName)
+		return nil
+	}
+	fmt.Printf("The second weave changed %s:\n", project.YamlFileName)
+	for _, line := range differences {
+		fmt.Printf("  %s\n", line)
+	}
+	return fmt.Errorf("weave is not idempotent: %d line(s) of %s changed on the second weave", len(differences), project.YamlFileName)
+}
+
+// comparableManifestLines returns the lines of the loom.yaml at loomConfigPath.
+func comparableManifestLines(loomConfigPath string) ([]string, error) {
+	data, err := os.ReadFile(loomConfigPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", project.YamlFileName, err)
+	}
+	return strings.Split(string(bytes.TrimRight(data, "\n")), "\n"), nil
+}
+
+// diffLines returns the lines removed from before ("- line") and added in after ("+ line"), in
+// order, based on their longest common subsequence. Only the part between the common prefix and
+// suffix is compared line by line, which keeps large manifests with few changes cheap.
+func diffLines(before, after []string) []string {
+	for len(before) > 0 && len(after) > 0 && before[0] == after[0] {
+		before, after = before[1:], after[1:]
+	}
+	for len(before) > 0 && len(after) > 0 && before[len(before)-1] == after[len(after)-1] {
+		before, after = before[:len(before)-1], after[:len(after)-1]
+	}
+
+	// common[i][j] is the length of the longest common subsequence of before[i:] and after[j:].
+	common := make([][]int, len(before)+1)
+	for i := range common {
+		common[i] = make([]int, len(after)+1)
+	}
+	for i := len(before) - 1; i >= 0; i-- {
+		for j := len(after) - 1; j >= 0; j-- {
+			if before[i] == after[j] {
+				common[i][j] = common[i+1][j+1] + 1
+			} else {
+				common[i][j] = max(common[i+1][j], common[i][j+1])
+			}
+		}
+	}
+
+	var differences []string
+	i, j := 0, 0
+	for i < len(before) || j < len(after) {
+		switch {
+		case i < len(before) && j < len(after) && before[i] == after[j]:
+			i, j = i+1, j+1
+		case i < len(before) && (j == len(after) || common[i+1][j] >= common[i][j+1]):
+			differences = append(differences, "- "+before[i])
+			i++
+		default:
+			differences = append(differences, "+ "+after[j])
+			j++
+		}
+	}
+	return differences
+}
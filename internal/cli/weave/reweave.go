@@ -0,0 +1,135 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	addCmd "loom/internal/cli/add"
+	removeCmd "loom/internal/cli/remove"
+	"loom/internal/core/exitcode"
+	"loom/internal/core/project"
+
+	"github.com/urfave/cli/v2"
+)
+
+// ReweaveOptions controls `loom reweave`.
+type ReweaveOptions struct {
+	// Yes takes ownership of files other threads own, or nobody owns, without prompting.
+	Yes bool
+	// FromStore resolves the thread again from the configured stores instead of reusing the source
+	// recorded in loom.yaml, and records the store it was found in.
+	FromStore bool
+}
+
+// ReweaveCommand returns the cli.Command for the "reweave" command.
+func ReweaveCommand() *cli.Command {
+	return &cli.Command{
+		Name:      "reweave",
+		Usage:     "Delete a thread's installed files and install it fresh from its source, keeping its place in loom.yaml",
+		ArgsUsage: "<thread_name>",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "from-store",
+				Usage: "Re-pull the thread from the configured stores instead of reusing its recorded source",
+			},
+			&cli.BoolFlag{
+				Name:    "yes",
+				Aliases: []string{"y"},
+				Usage:   "Take ownership of existing files without prompting",
+			},
+		},
+		Action: func(c *cli.Context) error {
+			if c.NArg() != 1 {
+				return exitcode.Usagef("reweave takes exactly one thread name")
+			}
+			opts := ReweaveOptions{Yes: c.Bool("yes"), FromStore: c.Bool("from-store")}
+			projectRoot, loomConfigPath, err := project.LocateManifest(c.String("config"))
+			if err != nil {
+				return err
+			}
+			lock, err := project.AcquireLock(loomConfigPath, c.Bool("force-unlock"))
+			if err != nil {
+				return err
+			}
+			defer func() { _ = lock.Release() }()
+
+			return Reweave(projectRoot, loomConfigPath, c.Args().First(), opts)
+		},
+	}
+}
+
+// Reweave removes the files a thread owns in the project at projectRoot and weaves it again from
+// its whole source, so files removed from the source disappear and drifted ones are replaced.
+// Unlike remove followed by add, the thread keeps its name, prefix and position in the loom.yaml at
+// loomConfigPath. Nothing is deleted unless the thread's source can be resolved.
+func Reweave(projectRoot, loomConfigPath, threadName string, opts ReweaveOptions) error {
+	loomConfig, err := loadProjectLoomConfig(loomConfigPath)
+	if err != nil {
+		return err
+	}
+	var thread *project.Thread
+	for i := range loomConfig.Threads {
+		if loomConfig.Threads[i].Name == threadName {
+			thread = &loomConfig.Threads[i]
+			break
+		}
+	}
+	if thread == nil {
+		return exitcode.Usagef("thread '%s' not found in %s", threadName, project.YamlFileName)
+	}
+
+	if opts.FromStore {
+		if err := resolveFromStore(thread, loomConfigPath); err != nil {
+			return err
+		}
+	}
+	threadSourcePath, err := determineThreadSourcePath(thread, loomConfig, projectRoot)
+	if err != nil {
+		return fmt.Errorf("cannot resolve source '%s' for thread '%s': %w", thread.Source, thread.Name, err)
+	}
+	if info, err := os.Stat(threadSourcePath); err != nil || !info.IsDir() {
+		return fmt.Errorf("thread source directory %s for thread '%s' is missing; nothing was removed", threadSourcePath, thread.Name)
+	}
+
+	removed := removeCmd.RemoveThreadFiles(*thread, projectRoot)
+	fmt.Printf("Removed %d file(s) of thread '%s'.\n", removed, thread.Name)
+	thread.Files = make(map[string][]string)
+
+	// Weaving with no specific thread walks the whole source rather than the (now empty) manifest.
+	weaveErr := processWeavingForThread(thread, loomConfig, projectRoot, "", Options{Yes: opts.Yes, out: os.Stdout}, nil, &sync.Mutex{})
+	if weaveErr != nil {
+		// Record what was written so far; the deleted files are gone either way.
+		_ = saveProjectLoomConfig(loomConfigPath, loomConfig)
+		return fmt.Errorf("error reweaving thread '%s': %w", thread.Name, weaveErr)
+	}
+	return finishWeave(os.Stdout, loomConfigPath, projectRoot, loomConfig, nil)
+}
+
+// resolveFromStore points thread at the store that provides it: its current store for store sources,
+// otherwise the first configured local store with a thread of the same name. The stores are those
+// of the loom.yaml at loomConfigPath and the global configuration.
+func resolveFromStore(thread *project.Thread, loomConfigPath string) error {
+	source := project.ParseSource(thread.Source)
+	storeName, storeThread := "", thread.Name
+	if source.Kind == project.SourceKindStore {
+		storeName = source.Location
+		if source.Thread != "" {
+			storeThread = source.Thread
+		}
+	}
+	_, newSource, err := addCmd.ResolveThreadInStores(loomConfigPath, storeName, storeThread)
+	if err != nil {
+		return err
+	}
+	if storeThread != thread.Name {
+		resolved := project.ParseSource(newSource)
+		resolved.Thread = storeThread
+		newSource = project.EncodeSource(resolved)
+	}
+	if newSource != thread.Source {
+		fmt.Printf("Thread '%s' now comes from '%s' instead of '%s'.\n", thread.Name, newSource, thread.Source)
+		thread.Source = newSource
+	}
+	return nil
+}
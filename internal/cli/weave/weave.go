@@ -1,17 +1,278 @@
 package cli
 
 import (
-	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"path"
 	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 
+	"loom/internal/core/exitcode"
+	"loom/internal/core/globalconfig"
+	"loom/internal/core/output"
 	"loom/internal/core/project" // Import the project package
+	"loom/internal/core/threadconfig"
+	"loom/internal/core/threadstore"
 
+	"github.com/urfave/cli/v2"
 	"gopkg.in/yaml.v3"
 )
 
+// Options controls optional weave behaviour.
+type Options struct {
+	// Prune deletes files a thread owns whose source file has been removed from the thread.
+	Prune bool
+	// Yes answers confirmation prompts (prune deletions and taking ownership of existing files) with yes.
+	Yes bool
+	// OverwritePolicy resolves every conflict with an existing file the same way, taking precedence
+	// over config.yml policies and Yes: threadconfig.PolicyOverwrite writes the thread's version and
+	// takes ownership, and threadconfig.PolicySkip keeps what is on disk, including edits to files the
+	// thread already owns. Empty resolves conflicts as usual.
+	OverwritePolicy string
+	// Check reports the changes a weave would make without writing anything or prompting,
+	// and makes Weave return an error if there are any.
+	Check bool
+	// DryRun reports the changes like Check, but without returning an error if there are any.
+	DryRun bool
+	// JSON prints the plan of a Check or DryRun weave as a JSON array of planEntry on stdout instead
+	// of the list of changes; every other message goes to stderr.
+	JSON bool
+	// Strict turns discrepancies between a thread's manifest entries and its source (such as a listed
+	// file that no longer exists in the source, checked for every thread before anything is woven when
+	// weaving all of them), files provided by the sources of two threads when weaving all of them, and
+	// project directories where a thread provides a file (or files where it needs a directory), into
+	// errors that abort the weave instead of warnings.
+	Strict bool
+	// Parallel is the number of threads woven concurrently when weaving all threads; 0 weaves them
+	// one at a time without the restrictions below. Parallel weaving never prompts, so any non-zero
+	// value requires Yes (or Check).
+	Parallel int
+	// Locked refuses to weave unless every thread still resolves to the source and file hashes
+	// recorded in loom.lock.
+	Locked bool
+	// SourceOverride is a _thread directory to weave the single named thread from instead of its
+	// recorded source, for this run only; loom.yaml keeps the recorded source.
+	SourceOverride string
+	// Backup copies every existing file a weave overwrites with different contents to
+	// .loom-backups/<timestamp>/ first.
+	Backup bool
+	// VerifyIdempotent weaves a second time after the weave and fails, listing the differences, if
+	// the second weave changed loom.yaml.
+	VerifyIdempotent bool
+	// ChangedOnly skips threads whose source still matches loom.lock: the same source and the same
+	// file hashes as when the thread was last added or woven, with all of its files still present.
+	// Threads missing from loom.lock are always woven.
+	ChangedOnly bool
+	// Only restricts the weave to files whose project-relative destination matches this pattern,
+	// matched like config.yml include patterns (see threadconfig.MatchFilterPattern). Files a
+	// thread owns that do not match are neither written nor dropped from loom.yaml. Empty weaves
+	// every file.
+	Only string
+	// Progress shows a progress line, "[120/3400] weaving src/...", instead of a line per file for
+	// every thread; threads with at least output.ProgressThreshold files show it regardless.
+	Progress bool
+
+	backup *weaveBackup      // Set by Weave when Backup is set
+	lock   *project.LockFile // loom.lock as it was before the weave; set by Weave when ChangedOnly is set
+	out    io.Writer         // Receives progress messages and prompts; set by Weave to stdout, or stderr with JSON
+}
+
+// Actions of a planEntry besides the create, overwrite, chmod, prune and blocked changes.
+const (
+	// planTakeOwnership overwrites a file owned by another thread, or an unmanaged one, and takes it over.
+	planTakeOwnership = "take-ownership"
+	// planPrompt is a file that the weave would ask about before taking it over.
+	planPrompt = "prompt"
+	// planSkip is a file that differs from the thread's but would be left as it is.
+	planSkip = "skip"
+)
+
+// planEntry is what a weave would do to one file, as printed by --json.
+type planEntry struct {
+	Thread string `json:"thread"`
+	// Path is the project-relative path of the file, with forward slashes.
+	Path   string `json:"path"`
+	Action string `json:"action"`
+	// PreviousOwner is the thread that owns the file before the weave, when it is another thread.
+	PreviousOwner string `json:"previousOwner,omitempty"`
+}
+
+// String formats the entry as a line of the --check report.
+func (e planEntry) String() string {
+	if e.PreviousOwner != "" {
+		return fmt.Sprintf("%s %s (thread '%s', owned by '%s')", e.Action, e.Path, e.Thread, e.PreviousOwner)
+	}
+	return fmt.Sprintf("%s %s (thread '%s')", e.Action, e.Path, e.Thread)
+}
+
+// weaveCheck collects the plan of a weave running with Options.Check or Options.DryRun.
+type weaveCheck struct {
+	mu     sync.Mutex // Threads woven in parallel record concurrently
+	plan   []planEntry
+	dryRun bool     // Report the changes without failing
+	json   *os.File // Stdout when the plan is printed as JSON, or nil
+}
+
+// record notes that the weave would apply action to the file at relPath (project-relative).
+// previousOwner is the other thread owning the file, if any.
+func (w *weaveCheck) record(action, relPath, threadName, previousOwner string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.plan = append(w.plan, planEntry{Thread: threadName, Path: relPath, Action: action, PreviousOwner: previousOwner})
+}
+
+// changes returns the entries of the plan that would change the project: all but skipped files.
+func (w *weaveCheck) changes() []planEntry {
+	var changes []planEntry
+	for _, entry := range w.plan {
+		if entry.Action != planSkip {
+			changes = append(changes, entry)
+		}
+	}
+	return changes
+}
+
+// parallelFlag is the value of --parallel[=N]. It acts as a boolean flag so a bare --parallel
+// (one worker per CPU) does not swallow the thread name that may follow it.
+type parallelFlag struct {
+	workers int
+}
+
+// IsBoolFlag lets the flag parser accept --parallel without a value.
+func (p *parallelFlag) IsBoolFlag() bool { return true }
+
+// String returns the configured number of workers, or "" when parallel weaving is off.
+func (p *parallelFlag) String() string {
+	if p == nil || p.workers == 0 {
+		return ""
+	}
+	return strconv.Itoa(p.workers)
+}
+
+// Set parses "true" (a bare --parallel), "false", or a positive number of workers.
+func (p *parallelFlag) Set(value string) error {
+	switch value {
+	case "true":
+		p.workers = runtime.NumCPU()
+	case "false":
+		p.workers = 0
+	default:
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			return fmt.Errorf("expected a positive number of workers, got '%s'", value)
+		}
+		p.workers = n
+	}
+	return nil
+}
+
+// Command returns the cli.Command for the "weave" command.
+func Command() *cli.Command {
+	parallel := &parallelFlag{}
+	return &cli.Command{
+		Name:      "weave",
+		Aliases:   []string{"install"},
+		Usage:     "Install or re-apply threads to the project. Optionally specify a thread name to weave only that thread.",
+		ArgsUsage: "[thread_name]",
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "prune",
+				Usage: "Delete owned files that no longer exist in their thread's source",
+			},
+			&cli.BoolFlag{
+				Name:    "yes",
+				Aliases: []string{"y"},
+				Usage:   "Answer yes to every prompt: pruning files and taking ownership of existing files",
+			},
+			&cli.StringFlag{
+				Name:  "overwrite-policy",
+				Value: "prompt",
+				Usage: "Resolve every conflict with an existing file the same way: `theirs` overwrites it and takes ownership, ours keeps it, prompt asks",
+			},
+			&cli.BoolFlag{
+				Name:  "check",
+				Usage: "Do not write anything; exit non-zero if weaving would create, overwrite or prune any file",
+			},
+			&cli.BoolFlag{
+				Name:  "dry-run",
+				Usage: "Show the changes weaving would make without writing anything or prompting",
+			},
+			&cli.BoolFlag{
+				Name:  "json",
+				Usage: "With --dry-run or --check, print the plan as a JSON array of {thread, path, action, previousOwner} on stdout",
+			},
+			&cli.BoolFlag{
+				Name:  "strict",
+				Usage: "Fail if loom.yaml lists a file that is missing from its thread's source, and when weaving all threads, if two threads provide the same file",
+			},
+			&cli.BoolFlag{
+				Name:  "locked",
+				Usage: "Fail unless every thread resolves to the same source and file hashes as recorded in loom.lock",
+			},
+			&cli.StringFlag{
+				Name:    "source",
+				Aliases: []string{"thread-source-override"},
+				Usage:   "Weave the named thread from this `DIR` (a thread or its _thread directory) instead of its recorded source, without changing loom.yaml",
+			},
+			&cli.BoolFlag{
+				Name:  "backup",
+				Usage: "Copy files that would be overwritten to .loom-backups/<timestamp>/ before replacing them",
+			},
+			&cli.BoolFlag{
+				Name:  "verify-idempotent",
+				Usage: "Weave a second time and fail if that changes loom.yaml, showing the differences",
+			},
+			&cli.BoolFlag{
+				Name:  "changed-only",
+				Usage: "Skip threads whose source files are unchanged since they were last woven, according to loom.lock",
+			},
+			&cli.StringFlag{
+				Name:  "only",
+				Usage: "Weave only the files whose project path matches `GLOB` (e.g. 'config/**' or '*.yml'), leaving other owned files as they are",
+			},
+			&cli.BoolFlag{
+				Name:  "progress",
+				Usage: fmt.Sprintf("Show a progress line instead of a line per file (automatic for threads with %d or more files)", output.ProgressThreshold),
+			},
+			&cli.GenericFlag{
+				Name:  "parallel",
+				Usage: "Weave up to `N` threads concurrently (default: one per CPU); requires --yes or --check",
+				Value: parallel,
+			},
+		},
+		Action: func(c *cli.Context) error {
+			threadName := "" // Default to empty, meaning all threads
+			if c.Args().Len() > 0 {
+				threadName = c.Args().First()
+			}
+			overwritePolicy, err := threadconfig.ParseOverwritePolicy(c.String("overwrite-policy"))
+			if err != nil {
+				return exitcode.UsageError(err)
+			}
+			opts := Options{Prune: c.Bool("prune"), Yes: c.Bool("yes"), OverwritePolicy: overwritePolicy, Check: c.Bool("check"), DryRun: c.Bool("dry-run"), JSON: c.Bool("json"), Strict: c.Bool("strict"), Locked: c.Bool("locked"), Parallel: parallel.workers, SourceOverride: c.String("source"), Backup: c.Bool("backup"), ChangedOnly: c.Bool("changed-only"), VerifyIdempotent: c.Bool("verify-idempotent"), Only: c.String("only"), Progress: c.Bool("progress")}
+			projectRoot, loomConfigPath, err := project.LocateManifest(c.String("config"))
+			if err != nil {
+				return err
+			}
+			lock, err := project.AcquireLock(loomConfigPath, c.Bool("force-unlock"))
+			if err != nil {
+				return err
+			}
+			defer func() { _ = lock.Release() }()
+
+			return Weave(projectRoot, loomConfigPath, threadName, opts)
+		},
+	}
+}
+
 // normalizeDir ensures directory paths are consistent for loom.yaml keys.
 // Returns "./" for empty or "." paths, otherwise ensures forward slashes and a trailing slash.
 func normalizeDir(dirPath string) string {
@@ -25,46 +286,123 @@ func normalizeDir(dirPath string) string {
 	return slashed
 }
 
-// promptUserForOverwriteInWeave prompts the user with a message and expects a yes/no/skip response.
-// Duplicated from add.go for now, consider refactoring to a shared utility if more widely needed.
-func promptUserForOverwriteInWeave(message string) (string, error) {
-	reader := bufio.NewReader(os.Stdin)
-	for {
-		fmt.Printf("%s [Y]es/[N]o/[S]kip [Yes]: ", message)
-		input, err := reader.ReadString('\n')
+// Weave re-applies threads to the project at projectRoot, whose manifest is at loomConfigPath.
+// If threadNameToWeave is empty, all threads are woven.
+// Otherwise, only the specified thread is woven.
+func Weave(projectRoot, loomConfigPath, threadNameToWeave string, opts Options) error {
+	if opts.JSON && !opts.Check && !opts.DryRun {
+		return exitcode.Usagef("--json prints the plan of --dry-run or --check; add one of them")
+	}
+	if opts.DryRun {
+		if opts.Backup || opts.VerifyIdempotent {
+			return exitcode.Usagef("--dry-run cannot be combined with --backup or --verify-idempotent, which need a weave that writes")
+		}
+		// A dry run is a check that does not fail on changes; --check with it still does.
+		opts.DryRun, opts.Check = !opts.Check, true
+	}
+	if opts.Parallel > 0 && !opts.Yes && !opts.Check {
+		return exitcode.Usagef("--parallel cannot prompt for confirmation; add --yes (or use --check)")
+	}
+	if opts.SourceOverride != "" {
+		if threadNameToWeave == "" {
+			return exitcode.Usagef("--source needs the name of the thread to weave from it")
+		}
+		if opts.Locked {
+			return exitcode.Usagef("--source cannot be combined with --locked")
+		}
+		sourceDir, err := resolveSourceOverride(opts.SourceOverride)
 		if err != nil {
-			return "", err
+			return err
 		}
-		input = strings.ToLower(strings.TrimSpace(input))
-		// Corrected condition to handle Enter key (empty input) as "yes"
-		switch input {
-		case "", "yes", "y":
-			return "yes", nil
-		case "no", "n":
-			return "no", nil
-		case "skip", "s":
-			return "skip", nil
+		opts.SourceOverride = sourceDir
+	}
+	if opts.Backup && opts.Check {
+		return exitcode.Usagef("--backup cannot be used with --check, which overwrites nothing")
+	}
+	if opts.VerifyIdempotent {
+		if opts.Check {
+			return exitcode.Usagef("--verify-idempotent cannot be used with --check, which writes nothing")
 		}
-		// Corrected error message
-		fmt.Println("Invalid input. Please enter 'yes', 'no', 'skip', or press Enter for 'yes'.")
+		return verifyIdempotentWeave(projectRoot, loomConfigPath, threadNameToWeave, opts)
+	}
+	if opts.Only != "" {
+		pattern, err := normalizeOnlyPattern(opts.Only)
+		if err != nil {
+			return err
+		}
+		if opts.Prune {
+			return exitcode.Usagef("--only cannot be combined with --prune")
+		}
+		opts.Only = pattern
 	}
-}
 
-// Weave re-applies threads to the project.
-// If threadNameToWeave is empty, all threads are woven.
-// Otherwise, only the specified thread is woven.
-func Weave(threadNameToWeave string) error {
-	projectRoot, err := os.Getwd()
-	if err != nil {
-		return fmt.Errorf("failed to get current directory: %w", err)
+	opts.out = os.Stdout
+	if opts.Check && opts.JSON {
+		// Per-thread messages go to stderr so stdout carries nothing but the JSON plan.
+		opts.out = os.Stderr
 	}
 
-	loomConfig, loomConfigPath, err := loadProjectLoomConfig(projectRoot)
+	loomConfig, err := loadProjectLoomConfig(loomConfigPath)
 	if err != nil {
 		return err // Error already contains context
 	}
 
+	if opts.Locked {
+		if err := verifyLocked(opts.out, loomConfigPath, projectRoot, loomConfig); err != nil {
+			return err
+		}
+	}
+
+	var check *weaveCheck
+	if opts.Check {
+		check = &weaveCheck{dryRun: opts.DryRun}
+		if opts.JSON {
+			check.json = os.Stdout
+		}
+	}
+	if opts.ChangedOnly {
+		lock, err := project.LoadLockFile(project.LockFilePath(loomConfigPath))
+		if os.IsNotExist(err) {
+			lock = &project.LockFile{}
+		} else if err != nil {
+			return err
+		}
+		opts.lock = lock
+	}
+	if opts.Backup {
+		opts.backup = newWeaveBackup(projectRoot)
+		defer opts.backup.report(opts.out) // Backups stay useful even if the weave fails part way.
+	}
+
+	if threadNameToWeave == "" {
+		if err := reportSourceCollisions(opts.out, loomConfig, projectRoot, opts.Strict); err != nil {
+			return err
+		}
+		if opts.Strict {
+			if err := checkAllManifestSources(loomConfig, projectRoot); err != nil {
+				return err
+			}
+		}
+	}
+
+	configMu := &sync.Mutex{}
+	if threadNameToWeave == "" && opts.Parallel > 1 && len(loomConfig.Threads) > 1 {
+		if overlap := findOwnershipOverlap(loomConfig, projectRoot); overlap != "" {
+			fmt.Fprintf(opts.out, "Threads overlap on '%s'; weaving them one at a time instead of in parallel.\n", overlap)
+		} else {
+			skipped, err := weaveThreadsInParallel(loomConfig, projectRoot, opts, check, configMu)
+			if err != nil {
+				return err
+			}
+			if err := finishWeave(opts.out, loomConfigPath, projectRoot, loomConfig, check); err != nil {
+				return err
+			}
+			return skippedThreadsError(skipped)
+		}
+	}
+
 	foundSpecificThread := false
+	var skipped []string
 	for i := range loomConfig.Threads {
 		currentThread := &loomConfig.Threads[i] // Use pointer to allow modification by helpers
 
@@ -75,8 +413,10 @@ func Weave(threadNameToWeave string) error {
 			foundSpecificThread = true
 		}
 
-		err := processWeavingForThread(currentThread, loomConfig, projectRoot, threadNameToWeave)
-		if err != nil {
+		err := processWeavingForThread(currentThread, loomConfig, projectRoot, threadNameToWeave, opts, check, configMu)
+		if errors.Is(err, errThreadSkipped) {
+			skipped = append(skipped, currentThread.Name)
+		} else if err != nil {
 			// An error from processWeavingForThread is considered significant enough to stop.
 			// It would typically be a file system error or critical prompt failure.
 			// Minor issues like a single file not found in source are handled within processWeavingForThread by logging.
@@ -90,27 +430,214 @@ func Weave(threadNameToWeave string) error {
 	}
 
 	if threadNameToWeave != "" && !foundSpecificThread {
-		return fmt.Errorf("thread '%s' not found in %s", threadNameToWeave, project.YamlFileName)
+		return exitcode.Usagef("thread '%s' not found in %s", threadNameToWeave, project.YamlFileName)
+	}
+	if err := finishWeave(opts.out, loomConfigPath, projectRoot, loomConfig, check); err != nil {
+		return err
+	}
+	return skippedThreadsError(skipped)
+}
+
+// errThreadSkipped is returned by processWeavingForThread for a thread it could not weave, such as
+// one whose source is missing, after saying why. The weave goes on with the other threads, saves
+// what it did, and then fails (see skippedThreadsError).
+var errThreadSkipped = errors.New("thread was skipped")
+
+// skippedThreadsError reports the threads a weave skipped, or returns nil if it skipped none. It is
+// not a usage error: the command line was fine, but the threads' sources were not.
+func skippedThreadsError(skipped []string) error {
+	if len(skipped) == 0 {
+		return nil
+	}
+	return fmt.Errorf("skipped %d thread(s) that could not be woven: %s", len(skipped), strings.Join(skipped, ", "))
+}
+
+// resolveSourceOverride returns the absolute _thread directory named by a --source value, which may
+// be a thread directory (holding _thread) or the _thread directory itself.
+func resolveSourceOverride(dir string) (string, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to get absolute path for '%s': %w", dir, err)
+	}
+	if info, err := os.Stat(filepath.Join(absDir, "_thread")); err == nil && info.IsDir() {
+		return filepath.Join(absDir, "_thread"), nil
+	}
+	info, err := os.Stat(absDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", exitcode.Usagef("source directory '%s' does not exist", absDir)
+		}
+		return "", fmt.Errorf("failed to access source directory '%s': %w", absDir, err)
+	}
+	if !info.IsDir() {
+		return "", exitcode.Usagef("source '%s' is not a directory", absDir)
+	}
+	return absDir, nil
+}
+
+// normalizeOnlyPattern converts a --only pattern to forward slashes without leading or trailing ones,
+// like config.yml include patterns, and rejects malformed ones.
+func normalizeOnlyPattern(pattern string) (string, error) {
+	cleanPattern := strings.Trim(strings.ReplaceAll(strings.TrimSpace(pattern), "\\", "/"), "/")
+	if cleanPattern == "" {
+		return "", exitcode.Usagef("--only needs a non-empty pattern")
+	}
+	if _, err := path.Match(cleanPattern, ""); err != nil {
+		return "", exitcode.Usagef("invalid --only pattern '%s': %v", pattern, err)
+	}
+	return cleanPattern, nil
+}
+
+// filterFilesForOnly returns the entries of filesToProcess (source-relative directories to file
+// names) whose installed, project-relative path matches the --only pattern.
+func filterFilesForOnly(filesToProcess map[string][]string, thread *project.Thread, threadConfig *threadconfig.ThreadConfig, pattern string) map[string][]string {
+	filtered := make(map[string][]string)
+	for dir, files := range filesToProcess {
+		for _, file := range files {
+			destRel := path.Join(thread.Prefix, threadConfig.DestinationFor(path.Join(dir, file)))
+			if threadconfig.MatchFilterPattern(pattern, destRel) {
+				filtered[dir] = append(filtered[dir], file)
+			}
+		}
+	}
+	return filtered
+}
+
+// filesOutsideOnly returns the manifest entries of thread whose path does not match the --only
+// pattern; a weave restricted to the pattern leaves them as they are.
+func filesOutsideOnly(thread *project.Thread, pattern string) map[string][]string {
+	kept := make(map[string][]string)
+	for dir, files := range thread.Files {
+		for _, file := range files {
+			if !threadconfig.MatchFilterPattern(pattern, path.Join(strings.TrimPrefix(normalizeDir(dir), "./"), file)) {
+				kept[normalizeDir(dir)] = append(kept[normalizeDir(dir)], file)
+			}
+		}
+	}
+	return kept
+}
+
+// finishWeave reports the result of a --check run, or saves the updated manifest and loom.lock after a weave.
+func finishWeave(out io.Writer, loomConfigPath string, projectRoot string, loomConfig *project.LoomConfig, check *weaveCheck) error {
+	if check != nil {
+		return check.report(out)
 	}
 
 	if err := saveProjectLoomConfig(loomConfigPath, loomConfig); err != nil {
 		return err // Error already contains context
 	}
+	if err := saveLockFile(loomConfigPath, projectRoot, loomConfig); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(out, "Weave operation completed.")
+	return nil
+}
 
-	fmt.Println("Weave operation completed.")
+// report prints the plan, as JSON or as the list of changes, and fails if there are changes and
+// this is not a dry run.
+func (w *weaveCheck) report(out io.Writer) error {
+	changes := w.changes()
+	if w.json != nil {
+		plan := w.plan
+		if plan == nil {
+			plan = []planEntry{} // An empty array rather than null
+		}
+		encoder := json.NewEncoder(w.json)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(plan); err != nil {
+			return fmt.Errorf("failed to write JSON output: %v", err)
+		}
+	} else if len(changes) > 0 {
+		fmt.Fprintln(out, "Weaving would make the following changes:")
+		for _, change := range changes {
+			fmt.Fprintf(out, "  - %s\n", change)
+		}
+	}
+	switch {
+	case len(changes) == 0 && !w.dryRun:
+		fmt.Fprintln(out, "Weave check passed: the project matches its threads.")
+	case len(changes) == 0:
+		fmt.Fprintln(out, "Dry run: weaving would change nothing.")
+	case w.dryRun:
+		fmt.Fprintf(out, "Dry run: %d file(s) would change; nothing was written.\n", len(changes))
+	default:
+		return fmt.Errorf("weave check failed: %d file(s) would change", len(changes))
+	}
 	return nil
 }
 
-// loadProjectLoomConfig reads and parses the loom.yaml file from the project root.
-func loadProjectLoomConfig(projectRoot string) (*project.LoomConfig, string, error) {
-	loomConfigPath := filepath.Join(projectRoot, project.YamlFileName)
+// lockThread resolves thread's source and records it, with the hashes of its files, for loom.lock.
+func lockThread(thread *project.Thread, loomConfig *project.LoomConfig, projectRoot string) (project.LockedThread, error) {
+	threadSourcePath, err := determineThreadSourcePath(thread, loomConfig, projectRoot)
+	if err != nil {
+		return project.LockedThread{}, err
+	}
+	return project.LockThread(thread, threadSourcePath, projectRoot)
+}
+
+// saveLockFile rewrites loom.lock from the current resolution of every thread. A thread whose source
+// cannot be resolved keeps its previous entry, if it has one.
+func saveLockFile(loomConfigPath string, projectRoot string, loomConfig *project.LoomConfig) error {
+	lockPath := project.LockFilePath(loomConfigPath)
+	previous, err := project.LoadLockFile(lockPath)
+	if os.IsNotExist(err) {
+		previous = &project.LockFile{}
+	} else if err != nil {
+		return err
+	}
+	lock := &project.LockFile{}
+	for i := range loomConfig.Threads {
+		locked, err := lockThread(&loomConfig.Threads[i], loomConfig, projectRoot)
+		if err != nil {
+			if old, ok := previous.Find(loomConfig.Threads[i].Name); ok {
+				lock.Set(old)
+			}
+			continue
+		}
+		lock.Set(locked)
+	}
+	return project.SaveLockFile(lockPath, lock)
+}
+
+// verifyLocked fails unless every thread in loomConfig still resolves to what loom.lock records,
+// listing the differences.
+func verifyLocked(out io.Writer, loomConfigPath string, projectRoot string, loomConfig *project.LoomConfig) error {
+	lock, err := project.LoadLockFile(project.LockFilePath(loomConfigPath))
+	if os.IsNotExist(err) {
+		return exitcode.Usagef("--locked requires %s; run 'loom weave' without --locked to create it", project.ResolutionLockFileName)
+	} else if err != nil {
+		return err
+	}
+	current := &project.LockFile{}
+	for i := range loomConfig.Threads {
+		locked, err := lockThread(&loomConfig.Threads[i], loomConfig, projectRoot)
+		if err != nil {
+			return fmt.Errorf("failed to resolve thread '%s': %w", loomConfig.Threads[i].Name, err)
+		}
+		current.Set(locked)
+	}
+
+	mismatches := lock.Mismatches(current)
+	if len(mismatches) == 0 {
+		return nil
+	}
+	fmt.Fprintf(out, "The project's threads no longer match %s:\n", project.ResolutionLockFileName)
+	for _, mismatch := range mismatches {
+		fmt.Fprintf(out, "  - %s\n", mismatch)
+	}
+	return fmt.Errorf("refusing to weave with --locked: %d difference(s) from %s", len(mismatches), project.ResolutionLockFileName)
+}
+
+// loadProjectLoomConfig reads and parses the loom.yaml file at loomConfigPath.
+func loadProjectLoomConfig(loomConfigPath string) (*project.LoomConfig, error) {
 	configData, err := os.ReadFile(loomConfigPath)
 	if err != nil {
-		return nil, "", fmt.Errorf("failed to read %s: %w", loomConfigPath, err)
+		return nil, fmt.Errorf("failed to read %s: %w", loomConfigPath, err)
 	}
 
-	var loomConfig project.LoomConfig
-	if err := yaml.Unmarshal(configData, &loomConfig); err != nil {
+	loomConfig, err := project.ParseLoomConfig(configData)
+	if err != nil {
 		// Ensure Files map is initialized for all threads if it's nil
 		// This is good practice though yaml unmarshal of an empty map should be fine.
 		for i := range loomConfig.Threads {
@@ -118,7 +645,7 @@ func loadProjectLoomConfig(projectRoot string) (*project.LoomConfig, string, err
 				loomConfig.Threads[i].Files = make(map[string][]string)
 			}
 		}
-		return nil, "", fmt.Errorf("failed to parse %s: %w", loomConfigPath, err)
+		return nil, fmt.Errorf("failed to parse %s: %w", loomConfigPath, err)
 	}
 	// Ensure Files map is initialized post-unmarshal
 	for i := range loomConfig.Threads {
@@ -126,7 +653,7 @@ func loadProjectLoomConfig(projectRoot string) (*project.LoomConfig, string, err
 			loomConfig.Threads[i].Files = make(map[string][]string)
 		}
 	}
-	return &loomConfig, loomConfigPath, nil
+	return &loomConfig, nil
 }
 
 // saveProjectLoomConfig marshals and writes the loomConfig back to the loom.yaml file.
@@ -137,6 +664,7 @@ func saveProjectLoomConfig(loomConfigPath string, loomConfig *project.LoomConfig
 			loomConfig.Threads[i].Files = make(map[string][]string)
 		}
 	}
+	loomConfig.Normalize()
 	updatedData, err := yaml.Marshal(loomConfig)
 	if err != nil {
 		return fmt.Errorf("failed to marshal updated %s: %w", project.YamlFileName, err)
@@ -186,14 +714,31 @@ type processFileWeavingParams struct {
 	projectRoot       string
 	threadSourcePath  string // Full path to the _thread directory
 	relPathFromSource string // Relative path of the file from _thread dir (e.g., "src/button.js" or "main.go")
+	relPathInDest     string // Relative path of the file under destPrefix, after config.yml renames
+	destPrefix        string // Thread prefix (forward slashes) prepended to relPathInDest in the project, or ""
 	currentThreadName string
 	threadNameToWeave string              // Specific thread to weave, or "" for all
 	loomConfig        *project.LoomConfig // Pointer to the main config for modifications
+	check             *weaveCheck         // Non-nil in --check mode: record changes instead of writing
+	yes               bool                // Take ownership of existing files without prompting
+	strict            bool                // Fail instead of skipping a file that a directory replaced, or vice versa
+	backup            *weaveBackup        // Non-nil with --backup: save files before overwriting them
+	policy            string              // The file's conflict policy: --overwrite-policy's, else config.yml's, or ""
+	policyReason      string              // Where policy comes from, shown when it answers a prompt
+	keepExisting      bool                // --overwrite-policy ours: leave every existing file as it is, even owned ones
+	lineEndings       string              // config.yml's line_endings, applied to text files
+	mode              os.FileMode         // Permission bits recorded for the file in loom.yaml, if modeRecorded
+	modeRecorded      bool                // Whether loom.yaml records a mode for the file
+	configMu          *sync.Mutex         // Guards loomConfig while threads are woven in parallel
+	progress          *output.Progress    // Non-nil when progress is shown instead of a line per file
+	out               io.Writer           // Receives the messages about the file and any prompt
+	changed           bool                // Set when the file's contents or mode were changed on disk
 }
 
 // fileWeavingAction holds the results of the decision logic for a file operation.
 type fileWeavingAction struct {
 	shouldWrite bool
+	keep        bool // The file stays as it is on disk but remains the current thread's
 }
 
 // handleFileConflictOwnedByOther handles logic when a file exists and is owned by another thread.
@@ -202,50 +747,78 @@ type fileWeavingAction struct {
 func handleFileConflictOwnedByOther(params *processFileWeavingParams, ownerThreadName string, relDestPathForDisplay string) (bool, error) {
 	switch params.threadNameToWeave {
 	case "": // Weaving all threads, standard conflict prompt
-		fmt.Printf("File '%s' is currently owned by thread '%s'.\n", relDestPathForDisplay, ownerThreadName)
-		choice, promptErr := promptUserForOverwriteInWeave(fmt.Sprintf("Thread '%s' wants to overwrite it. Take ownership? ", params.currentThreadName))
+		output.Fprintf(params.out, output.StyleTransfer, "File '%s' is currently owned by thread '%s'.\n", relDestPathForDisplay, ownerThreadName)
+		choice, promptErr := confirmTakeOwnership(params, true, output.StyleTransfer)
 		if promptErr != nil {
 			return false, fmt.Errorf("failed to get user input for '%s': %w", relDestPathForDisplay, promptErr)
 		}
 		if choice == "yes" {
-			fmt.Printf("Thread '%s' is taking ownership of '%s'.\n", params.currentThreadName, relDestPathForDisplay)
+			output.Fprintf(params.out, output.StyleTransfer, "Thread '%s' is taking ownership of '%s'.\n", params.currentThreadName, relDestPathForDisplay)
 			removeFileFromThreadManifest(params.loomConfig, ownerThreadName, relDestPathForDisplay)
 			return true, nil
 		}
-		fmt.Printf("Skipping file '%s'. Thread '%s' retains ownership.\n", relDestPathForDisplay, ownerThreadName)
+		fmt.Fprintf(params.out, "Skipping file '%s'. Thread '%s' retains ownership.\n", relDestPathForDisplay, ownerThreadName)
 		return false, nil
 	case params.currentThreadName: // Weaving specific thread, and it's this one, taking from another.
-		fmt.Printf("File '%s' is currently owned by thread '%s'.\n", relDestPathForDisplay, ownerThreadName)
-		fmt.Printf("Thread '%s' (being specifically woven) is taking ownership of '%s'.\n", params.currentThreadName, relDestPathForDisplay)
+		output.Fprintf(params.out, output.StyleTransfer, "File '%s' is currently owned by thread '%s'.\n", relDestPathForDisplay, ownerThreadName)
+		if params.policy == threadconfig.PolicySkip {
+			fmt.Fprintf(params.out, "Skipping file '%s' (%s). Thread '%s' retains ownership.\n", relDestPathForDisplay, params.policyReason, ownerThreadName)
+			return false, nil
+		}
+		output.Fprintf(params.out, output.StyleTransfer, "Thread '%s' (being specifically woven) is taking ownership of '%s'.\n", params.currentThreadName, relDestPathForDisplay)
 		removeFileFromThreadManifest(params.loomConfig, ownerThreadName, relDestPathForDisplay)
 		return true, nil
 	default: // Weaving specific thread, but this file is owned by another (and not the one being woven). Skip.
-		fmt.Printf("Skipping file '%s'. It is owned by '%s', and we are weaving '%s' (not '%s').\n", relDestPathForDisplay, ownerThreadName, params.threadNameToWeave, params.currentThreadName)
+		fmt.Fprintf(params.out, "Skipping file '%s'. It is owned by '%s', and we are weaving '%s' (not '%s').\n", relDestPathForDisplay, ownerThreadName, params.threadNameToWeave, params.currentThreadName)
 		return false, nil
 	}
 }
 
+// confirmTakeOwnership answers whether the current thread should take ownership of an existing file,
+// which another thread owns if owned is set: from the file's policy (--overwrite-policy or config.yml)
+// if it has one, with yes under --yes, and by asking otherwise, with the global configuration's
+// preferred answer as the default.
+func confirmTakeOwnership(params *processFileWeavingParams, owned bool, style output.Style) (string, error) {
+	message := fmt.Sprintf("Thread '%s' wants to overwrite it. Take ownership? ", params.currentThreadName)
+	switch params.policy {
+	case threadconfig.PolicyOverwrite:
+		fmt.Fprintf(params.out, "%s yes (%s)\n", output.Paint(style, message), params.policyReason)
+		return "yes", nil
+	case threadconfig.PolicySkip:
+		fmt.Fprintf(params.out, "%s no (%s)\n", output.Paint(style, message), params.policyReason)
+		return "no", nil
+	}
+	if params.yes {
+		return "yes", nil
+	}
+	return output.AskChoice(params.out, style, message, []string{"yes", "no", "skip"}, globalconfig.ConflictDefault(owned))
+}
+
 // handleFileConflictUnowned handles logic when a file exists but is not owned by any Loom thread.
 // Returns true if the file should be written by the current thread.
 func handleFileConflictUnowned(params *processFileWeavingParams, relDestPathForDisplay string) (bool, error) {
 	switch params.threadNameToWeave {
 	case "": // Weaving all, prompt
-		fmt.Printf("File '%s' exists but is not currently owned by any Loom thread.\n", relDestPathForDisplay)
-		choice, promptErr := promptUserForOverwriteInWeave(fmt.Sprintf("Thread '%s' wants to overwrite it. Take ownership? ", params.currentThreadName))
+		output.Fprintf(params.out, output.StyleOverwrite, "File '%s' exists but is not currently owned by any Loom thread.\n", relDestPathForDisplay)
+		choice, promptErr := confirmTakeOwnership(params, false, output.StyleOverwrite)
 		if promptErr != nil {
 			return false, fmt.Errorf("failed to get user input for '%s': %w", relDestPathForDisplay, promptErr)
 		}
 		if choice == "yes" {
-			fmt.Printf("Thread '%s' is taking ownership of '%s'.\n", params.currentThreadName, relDestPathForDisplay)
+			output.Fprintf(params.out, output.StyleOverwrite, "Thread '%s' is taking ownership of '%s'.\n", params.currentThreadName, relDestPathForDisplay)
 			return true, nil
 		}
-		fmt.Printf("Skipping file '%s'. It remains an unmanaged file.\n", relDestPathForDisplay)
+		fmt.Fprintf(params.out, "Skipping file '%s'. It remains an unmanaged file.\n", relDestPathForDisplay)
 		return false, nil
 	case params.currentThreadName: // Weaving specific thread (this one), file is unowned. Take ownership.
-		fmt.Printf("File '%s' exists but is not owned. Thread '%s' (being specifically woven) is taking ownership.\n", relDestPathForDisplay, params.currentThreadName)
+		if params.policy == threadconfig.PolicySkip {
+			fmt.Fprintf(params.out, "Skipping file '%s' (%s). It remains an unmanaged file.\n", relDestPathForDisplay, params.policyReason)
+			return false, nil
+		}
+		output.Fprintf(params.out, output.StyleOverwrite, "File '%s' exists but is not owned. Thread '%s' (being specifically woven) is taking ownership.\n", relDestPathForDisplay, params.currentThreadName)
 		return true, nil
 	default: // Weaving specific thread (not this one), file is unowned. Skip.
-		fmt.Printf("Skipping unowned file '%s'. We are weaving '%s', not '%s'.\n", relDestPathForDisplay, params.threadNameToWeave, params.currentThreadName)
+		fmt.Fprintf(params.out, "Skipping unowned file '%s'. We are weaving '%s', not '%s'.\n", relDestPathForDisplay, params.threadNameToWeave, params.currentThreadName)
 		return false, nil
 	}
 }
@@ -278,90 +851,353 @@ func decideFileWeavingAction(params *processFileWeavingParams, destPathInProject
 				return fileWeavingAction{}, err
 			}
 		} else if isOwned && ownerThreadName == params.currentThreadName {
-			// File is owned by the current thread. Re-apply.
-			fmt.Printf("Re-applying file '%s' from thread '%s'.\n", relDestPathForDisplay, params.currentThreadName)
+			// File is owned by the current thread. Re-apply, unless existing files are kept.
+			if params.keepExisting {
+				fmt.Fprintf(params.out, "Keeping file '%s' as it is (--overwrite-policy ours).\n", relDestPathForDisplay)
+				return fileWeavingAction{keep: true}, nil
+			}
+			if params.progress == nil {
+				output.Fprintf(params.out, output.StyleOverwrite, "Re-applying file '%s' from thread '%s'.\n", relDestPathForDisplay, params.currentThreadName)
+			}
 			action.shouldWrite = true
 		}
 	} else { // File does not exist at destination.
 		if err := os.MkdirAll(filepath.Dir(destPathInProject), os.ModePerm); err != nil {
 			return fileWeavingAction{}, fmt.Errorf("failed to create directory for %s: %w", destPathInProject, err)
 		}
-		fmt.Printf("Creating new file '%s' from thread '%s'.\n", relDestPathForDisplay, params.currentThreadName)
+		if params.progress == nil {
+			output.Fprintf(params.out, output.StyleCreate, "Creating new file '%s' from thread '%s'.\n", relDestPathForDisplay, params.currentThreadName)
+		}
 		action.shouldWrite = true
 	}
 	return action, nil
 }
 
 // handleFileWeavingOperation processes a single file for the weave operation.
-// Returns true if the file was written, false otherwise, and an error if one occurred.
+// Returns true if the file was written, or already matched the source and is kept for the thread,
+// false otherwise, and an error if one occurred.
 func handleFileWeavingOperation(params *processFileWeavingParams) (bool, error) {
 	pathInThreadSource := filepath.Join(params.threadSourcePath, params.relPathFromSource)
-	destPathInProject := filepath.Join(params.projectRoot, params.relPathFromSource)
+	destPathInProject := filepath.Join(params.projectRoot, filepath.FromSlash(params.destPrefix), filepath.FromSlash(params.relPathInDest))
 
 	sourceInfo, statSourceErr := os.Stat(pathInThreadSource)
+	if statSourceErr != nil || sourceInfo.IsDir() {
+		output.ClearProgress() // The warnings below start on a line of their own
+	}
 	if os.IsNotExist(statSourceErr) {
-		fmt.Printf("Warning: Source file %s for thread '%s' not found. Skipping this file.\n", pathInThreadSource, params.currentThreadName)
+		fmt.Fprintf(params.out, "Warning: Source file %s for thread '%s' not found. Skipping this file.\n", pathInThreadSource, params.currentThreadName)
 		return false, nil
 	} else if statSourceErr != nil {
-		fmt.Printf("Error stating source file %s for thread '%s': %v. Skipping this file.\n", pathInThreadSource, params.currentThreadName, statSourceErr)
+		fmt.Fprintf(params.out, "Error stating source file %s for thread '%s': %v. Skipping this file.\n", pathInThreadSource, params.currentThreadName, statSourceErr)
 		return false, nil // Logged, not a fatal error for the whole weave
 	}
 
 	if sourceInfo.IsDir() {
-		fmt.Printf("Warning: Source path %s is a directory, expected a file. Skipping.\n", pathInThreadSource)
+		fmt.Fprintf(params.out, "Warning: Source path %s is a directory, expected a file. Skipping.\n", pathInThreadSource)
 		return false, nil
 	}
 
 	relDestPathForDisplay, _ := filepath.Rel(params.projectRoot, destPathInProject)
 	relDestPathForDisplay = filepath.ToSlash(relDestPathForDisplay) // For consistent display and map keys
 
+	if problem := destinationTypeMismatch(params.projectRoot, destPathInProject); problem != "" {
+		return skipMismatchedDestination(params, destPathInProject, relDestPathForDisplay, problem)
+	}
+
+	if params.check != nil {
+		return false, checkFileWeaving(params, pathInThreadSource, destPathInProject, relDestPathForDisplay)
+	}
+
+	// A file that already holds what the thread would write needs neither a prompt nor a copy.
+	identical, err := project.InstalledMatches(pathInThreadSource, destPathInProject, params.lineEndings)
+	if err != nil {
+		return false, err
+	}
+	if identical {
+		return keepIdenticalFile(params, destPathInProject)
+	}
+
+	// Deciding reads and may change ownership in loomConfig; the copy itself runs unlocked.
+	params.configMu.Lock()
 	action, err := decideFileWeavingAction(params, destPathInProject, relDestPathForDisplay)
+	params.configMu.Unlock()
 	if err != nil {
 		return false, err // Propagate errors from decision logic (e.g., prompt failure)
 	}
 
 	if action.shouldWrite {
-		data, readErr := os.ReadFile(pathInThreadSource)
-		if readErr != nil {
-			return false, fmt.Errorf("failed to read source file %s: %w", pathInThreadSource, readErr)
+		if params.backup != nil {
+			if err := params.backup.save(params.out, destPathInProject, relDestPathForDisplay, pathInThreadSource, params.lineEndings); err != nil {
+				return false, err
+			}
+		}
+		mode := sourceInfo.Mode()
+		if params.modeRecorded {
+			mode = params.mode
 		}
-		if writeErr := os.WriteFile(destPathInProject, data, sourceInfo.Mode()); writeErr != nil {
-			return false, fmt.Errorf("failed to write file %s: %w", destPathInProject, writeErr)
+		if err := project.CopyThreadFile(pathInThreadSource, destPathInProject, mode, params.lineEndings); err != nil {
+			return false, err
 		}
+		params.changed = true
 		return true, nil
 	}
-	return false, nil
+	return action.keep, nil
+}
+
+// checkFileWeaving records in params.check what weaving would do to the file at destPathInProject,
+// without prompting. Files whose contents already match the source are not part of the plan.
+func checkFileWeaving(params *processFileWeavingParams, pathInThreadSource, destPathInProject, relDestPathForDisplay string) error {
+	destData, err := os.ReadFile(destPathInProject)
+	if os.IsNotExist(err) {
+		params.check.record("create", relDestPathForDisplay, params.currentThreadName, "")
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("error reading destination file %s: %w", destPathInProject, err)
+	}
+	sourceData, err := project.InstalledContent(pathInThreadSource, params.lineEndings)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(sourceData, destData) {
+		params.configMu.Lock()
+		ownerThreadName, isOwned := params.loomConfig.IsFileOwned(destPathInProject, params.projectRoot)
+		params.configMu.Unlock()
+		previousOwner := ""
+		if isOwned && ownerThreadName != params.currentThreadName {
+			previousOwner = ownerThreadName
+		}
+		params.check.record(plannedConflictAction(params, isOwned && previousOwner == ""), relDestPathForDisplay, params.currentThreadName, previousOwner)
+	} else if params.modeRecorded {
+		if info, err := os.Stat(destPathInProject); err == nil && info.Mode().Perm() != params.mode {
+			params.check.record("chmod", relDestPathForDisplay, params.currentThreadName, "")
+		}
+	}
+	return nil
+}
+
+// plannedConflictAction returns what weaving would do to an existing file that differs from the
+// thread's, following decideFileWeavingAction: ownedByCurrent files are overwritten, and files owned
+// by another thread or by none are taken over, left alone, or asked about.
+func plannedConflictAction(params *processFileWeavingParams, ownedByCurrent bool) string {
+	switch {
+	case params.keepExisting:
+		return planSkip
+	case ownedByCurrent:
+		return "overwrite"
+	}
+	switch params.threadNameToWeave {
+	case "":
+		switch {
+		case params.policy == threadconfig.PolicyOverwrite:
+			return planTakeOwnership
+		case params.policy == threadconfig.PolicySkip:
+			return planSkip
+		case params.yes:
+			return planTakeOwnership
+		}
+		return planPrompt
+	case params.currentThreadName:
+		if params.policy == threadconfig.PolicySkip {
+			return planSkip
+		}
+		return planTakeOwnership
+	default:
+		return planSkip
+	}
+}
+
+// destinationTypeMismatch describes why the file at destPath cannot be written because the project
+// has the wrong kind of entry there: a directory where the thread provides a file, or a file where
+// the thread needs a directory above it. It returns "" if there is no such mismatch.
+func destinationTypeMismatch(projectRoot, destPath string) string {
+	if info, err := os.Stat(destPath); err == nil {
+		if info.IsDir() {
+			return "it is a directory in the project, but the thread provides a file"
+		}
+		return ""
+	}
+	for dir := filepath.Dir(destPath); project.PathContains(projectRoot, dir) && dir != projectRoot; dir = filepath.Dir(dir) {
+		info, err := os.Stat(dir)
+		if err != nil {
+			continue // Missing directories are created when the file is written
+		}
+		if !info.IsDir() {
+			rel, _ := filepath.Rel(projectRoot, dir)
+			return fmt.Sprintf("'%s' is a file in the project, but the thread needs a directory there", filepath.ToSlash(rel))
+		}
+		break
+	}
+	return ""
+}
+
+// skipMismatchedDestination handles a file whose destination has the wrong kind of entry (see
+// destinationTypeMismatch). With --strict the weave fails; --check reports the file as blocked;
+// otherwise it is skipped with a warning. A file the thread owned stays in its manifest, so the
+// warning repeats until the project entry is moved aside.
+func skipMismatchedDestination(params *processFileWeavingParams, destPathInProject, relDestPathForDisplay, problem string) (bool, error) {
+	if params.check != nil {
+		params.check.record("blocked", relDestPathForDisplay, params.currentThreadName, "")
+		return false, nil
+	}
+	if params.strict {
+		return false, fmt.Errorf("cannot write '%s': %s; move it aside and weave again", relDestPathForDisplay, problem)
+	}
+	output.ClearProgress()
+	fmt.Fprintf(params.out, "Warning: skipping '%s' of thread '%s': %s. Move it aside and weave again to restore the file.\n", relDestPathForDisplay, params.currentThreadName, problem)
+	params.configMu.Lock()
+	ownerThreadName, isOwned := params.loomConfig.IsFileOwned(destPathInProject, params.projectRoot)
+	params.configMu.Unlock()
+	return isOwned && ownerThreadName == params.currentThreadName, nil
+}
+
+// keepIdenticalFile handles a project file that is identical to the thread's version: it is left
+// untouched and kept for the current thread, unless another thread owns it or, when a single thread
+// is woven, it is an unowned file of another thread; those stay as they are. A recorded mode that
+// differs from the file's is still applied.
+func keepIdenticalFile(params *processFileWeavingParams, destPathInProject string) (bool, error) {
+	params.configMu.Lock()
+	ownerThreadName, isOwned := params.loomConfig.IsFileOwned(destPathInProject, params.projectRoot)
+	params.configMu.Unlock()
+	if isOwned && ownerThreadName != params.currentThreadName {
+		return false, nil
+	}
+	if !isOwned && params.threadNameToWeave != "" && params.threadNameToWeave != params.currentThreadName {
+		return false, nil
+	}
+	if params.modeRecorded {
+		if info, err := os.Stat(destPathInProject); err == nil && info.Mode().Perm() != params.mode {
+			if err := os.Chmod(destPathInProject, params.mode); err != nil {
+				return false, fmt.Errorf("failed to set mode of %s: %w", destPathInProject, err)
+			}
+			params.changed = true
+		}
+	}
+	return true, nil
 }
 
 // determineThreadSourcePath calculates the absolute path to the thread's source directory (_thread).
-func determineThreadSourcePath(thread *project.Thread, projectRoot string) string {
-	if strings.HasPrefix(thread.Source, "project:") {
-		relativePath := strings.TrimPrefix(thread.Source, "project:")
-		return filepath.Join(projectRoot, relativePath, "_thread")
+// Store sources are looked up in the global stores and those loomConfig declares.
+func determineThreadSourcePath(thread *project.Thread, loomConfig *project.LoomConfig, projectRoot string) (string, error) {
+	lookupStorePath := func(storeName string) (string, error) {
+		return lookupStorePath(loomConfig, projectRoot, storeName)
+	}
+	return project.ResolveSource(project.ParseSource(thread.Source), thread.Name, projectRoot, lookupStorePath)
+}
+
+// lookupStorePath returns the directory that a store declared by loomConfig, the project's loom.yaml,
+// or the global configuration is read from: the path of a local store, the checkout of a git store,
+// or the extraction of an archive store.
+func lookupStorePath(loomConfig *project.LoomConfig, projectRoot, storeName string) (string, error) {
+	gConf, err := project.StoreConfig(projectRoot, loomConfig)
+	if err != nil {
+		return "", err
+	}
+	for _, store := range gConf.Stores {
+		if store.Name != storeName {
+			continue
+		}
+		resolver, err := threadstore.New(store)
+		if errors.Is(err, threadstore.ErrUnsupportedType) {
+			return "", fmt.Errorf("store '%s' has type '%s', which cannot be woven from yet", storeName, store.Type)
+		} else if err != nil {
+			return "", err
+		}
+		local, ok := resolver.(*threadstore.LocalStore)
+		if !ok {
+			return "", fmt.Errorf("store '%s' has type '%s', which cannot be woven from yet", storeName, store.Type)
+		}
+		return local.Path, nil
+	}
+	return "", exitcode.Usagef("store '%s' not found in %s or the global configuration", storeName, project.YamlFileName)
+}
+
+// threadUnchanged reports whether weaving thread from threadSourcePath would change nothing that
+// loom.lock does not already record: the thread resolves to the locked source with the locked file
+// hashes, and every file it owns is still in the project. Threads without a lock entry have changed.
+func threadUnchanged(thread *project.Thread, threadSourcePath string, projectRoot string, lock *project.LockFile) bool {
+	locked, ok := lock.Find(thread.Name)
+	if !ok {
+		return false
+	}
+	current, err := project.LockThread(thread, threadSourcePath, projectRoot)
+	if err != nil {
+		return false
+	}
+	before := &project.LockFile{Threads: []project.LockedThread{locked}}
+	if len(before.Mismatches(&project.LockFile{Threads: []project.LockedThread{current}})) > 0 {
+		return false
+	}
+	for dir, files := range thread.Files {
+		for _, file := range files {
+			if _, err := os.Stat(filepath.Join(projectRoot, filepath.FromSlash(dir), file)); err != nil {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// stripThreadPrefix converts a normalized project-relative manifest directory into a normalized
+// directory relative to the thread source. It returns false if dir does not lie under prefix.
+func stripThreadPrefix(dir string, prefix string) (string, bool) {
+	if prefix == "" {
+		return dir, true
+	}
+	normalizedPrefix := normalizeDir(prefix)
+	if !strings.HasPrefix(dir, normalizedPrefix) {
+		return "", false
 	}
-	return filepath.Join(projectRoot, ".loom", thread.Name, "_thread")
+	return normalizeDir(strings.TrimPrefix(dir, normalizedPrefix)), true
 }
 
 // collectFilesToProcessForWeaving determines the set of files to process for a given thread.
-// Returns a map of [normalized directory relative to project] -> [list of filenames].
+// Returns a map of [normalized directory relative to the thread source] -> [list of filenames].
+// With strict set, a manifest entry that has no file in the thread source is an error rather than a warning.
 func collectFilesToProcessForWeaving(
+	out io.Writer,
 	thread *project.Thread,
 	threadSourcePath string,
-	projectRoot string, // Not directly used here, but kept for potential future use or consistency
+	projectRoot string,
 	threadNameToWeave string,
+	threadConfig *threadconfig.ThreadConfig,
+	strict bool,
 ) (map[string][]string, error) {
 	filesToProcess := make(map[string][]string)
 
 	// If weaving a specific thread, and it's this thread, use its manifest.
 	if threadNameToWeave != "" && threadNameToWeave == thread.Name {
-		fmt.Printf("Weaving specific thread '%s'. Will only process files it owns as per %s.\n", thread.Name, project.YamlFileName)
+		fmt.Fprintf(out, "Weaving specific thread '%s'. Will only process files it owns as per %s.\n", thread.Name, project.YamlFileName)
 		if len(thread.Files) == 0 {
-			fmt.Printf("Thread '%s' does not own any files according to %s. Nothing to weave for this thread.\n", thread.Name, project.YamlFileName)
+			fmt.Fprintf(out, "Thread '%s' does not own any files according to %s. Nothing to weave for this thread.\n", thread.Name, project.YamlFileName)
 			return filesToProcess, nil // Empty map, no error
 		}
+		if strict {
+			if err := checkManifestSources(thread, threadSourcePath, threadConfig); err != nil {
+				return nil, err
+			}
+		}
 		for dir, filesInDir := range thread.Files {
-			normalizedDir := normalizeDir(dir) // Should be normalized already, but ensure.
-			filesToProcess[normalizedDir] = append(filesToProcess[normalizedDir], filesInDir...)
+			// Manifest directories are project-relative; strip the thread prefix to get source-relative ones.
+			destDir, ok := stripThreadPrefix(normalizeDir(dir), thread.Prefix)
+			if !ok {
+				fmt.Fprintf(out, "Warning: Directory '%s' of thread '%s' is outside its prefix '%s'. Skipping.\n", dir, thread.Name, thread.Prefix)
+				continue
+			}
+			for _, fileName := range filesInDir {
+				// Undo config.yml renames to find the file in the thread source.
+				sourceRel, ok := threadConfig.SourceFor(path.Join(destDir, fileName))
+				if !ok {
+					fmt.Fprintf(out, "Warning: No source file in thread '%s' maps to '%s%s'. Skipping.\n", thread.Name, destDir, fileName)
+					continue
+				}
+				if !threadConfig.Includes(sourceRel) {
+					fmt.Fprintf(out, "Skipping '%s%s' of thread '%s': excluded by %s.\n", destDir, fileName, thread.Name, threadconfig.ConfigFileName)
+					continue
+				}
+				sourceDir, sourceFile := path.Split(sourceRel)
+				sourceDir = normalizeDir(sourceDir)
+				filesToProcess[sourceDir] = append(filesToProcess[sourceDir], sourceFile)
+			}
 		}
 	} else if threadNameToWeave == "" { // Weaving all threads - walk the source directory.
 		walkErr := filepath.Walk(threadSourcePath, func(path string, info os.FileInfo, walkErrInner error) error {
@@ -369,13 +1205,23 @@ func collectFilesToProcessForWeaving(
 				return walkErrInner // Propagate errors from previous WalkFunc calls
 			}
 			if info.IsDir() {
-				return nil // Skip directories
+				if path == threadSourcePath {
+					return nil
+				}
+				if project.SkipSourceDir(path, projectRoot) {
+					fmt.Fprintf(out, "Skipping directory '%s' in thread '%s'.\n", path, thread.Name)
+					return filepath.SkipDir
+				}
+				return nil
 			}
 			relPathFromSourceDir, err := filepath.Rel(threadSourcePath, path)
 			if err != nil {
 				// This error is critical for this file, wrap it with more context.
 				return fmt.Errorf("failed to get relative path for %s (base: %s): %w", path, threadSourcePath, err)
 			}
+			if !threadConfig.Includes(filepath.ToSlash(relPathFromSourceDir)) {
+				return nil
+			}
 			destDirRelToProject, fileName := filepath.Split(relPathFromSourceDir)
 			destDirNorm := normalizeDir(destDirRelToProject)
 			filesToProcess[destDirNorm] = append(filesToProcess[destDirNorm], fileName)
@@ -390,32 +1236,121 @@ func collectFilesToProcessForWeaving(
 	return filesToProcess, nil
 }
 
+// checkManifestSources implements --strict: it returns an error for the first file (in sorted order)
+// that loom.yaml lists for thread but that has no file in its source at threadSourcePath, because
+// its directory is outside the thread's prefix, no source file maps to it, or its source file is missing.
+func checkManifestSources(thread *project.Thread, threadSourcePath string, threadConfig *threadconfig.ThreadConfig) error {
+	dirs := make([]string, 0, len(thread.Files))
+	for dir := range thread.Files {
+		dirs = append(dirs, dir)
+	}
+	sort.Strings(dirs)
+	for _, dir := range dirs {
+		destDir, ok := stripThreadPrefix(normalizeDir(dir), thread.Prefix)
+		if !ok {
+			return fmt.Errorf("thread '%s': directory '%s' in %s is outside the thread's prefix '%s'", thread.Name, dir, project.YamlFileName, thread.Prefix)
+		}
+		for _, fileName := range thread.Files[dir] {
+			destPath := path.Join(destDir, fileName)
+			sourceRel, ok := threadConfig.SourceFor(destPath)
+			if !ok {
+				return fmt.Errorf("thread '%s': no source file maps to '%s' listed in %s", thread.Name, destPath, project.YamlFileName)
+			}
+			sourcePath := filepath.Join(threadSourcePath, filepath.FromSlash(sourceRel))
+			if info, err := os.Stat(sourcePath); err != nil || info.IsDir() {
+				return fmt.Errorf("thread '%s': '%s' is listed in %s but its source file %s is missing", thread.Name, destPath, project.YamlFileName, sourcePath)
+			}
+		}
+	}
+	return nil
+}
+
+// checkAllManifestSources implements --strict when weaving all threads: before anything is woven, it
+// fails if a thread's source cannot be found or if checkManifestSources fails for it.
+func checkAllManifestSources(loomConfig *project.LoomConfig, projectRoot string) error {
+	for i := range loomConfig.Threads {
+		thread := &loomConfig.Threads[i]
+		threadSourcePath, err := determineThreadSourcePath(thread, loomConfig, projectRoot)
+		if err != nil {
+			return fmt.Errorf("thread '%s': cannot resolve source '%s': %w", thread.Name, thread.Source, err)
+		}
+		if _, err := os.Stat(threadSourcePath); os.IsNotExist(err) {
+			return fmt.Errorf("thread '%s': thread source directory %s is missing", thread.Name, threadSourcePath)
+		}
+		threadConfig, err := thread.LoadConfig(threadSourcePath)
+		if err != nil {
+			return fmt.Errorf("thread '%s': %w", thread.Name, err)
+		}
+		if err := checkManifestSources(thread, threadSourcePath, threadConfig); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // processWeavingForThread handles the weaving logic for a single thread.
 func processWeavingForThread(
 	thread *project.Thread, // Pointer to the thread in loomConfig
 	loomConfig *project.LoomConfig,
 	projectRoot string,
 	threadNameToWeave string,
+	opts Options,
+	check *weaveCheck,
+	configMu *sync.Mutex,
 ) error {
 	// If weaving a specific thread, only proceed if this IS the thread.
 	if threadNameToWeave != "" && thread.Name != threadNameToWeave {
 		return nil // Not the target thread for a specific weave.
 	}
 
-	threadSourcePath := determineThreadSourcePath(thread, projectRoot)
+	threadSourcePath := opts.SourceOverride // Only set when weaving this one thread
+	if threadSourcePath != "" {
+		fmt.Fprintf(opts.out, "Using %s instead of source '%s' for thread '%s'; %s is not changed.\n", threadSourcePath, thread.Source, thread.Name, project.YamlFileName)
+	} else {
+		var err error
+		threadSourcePath, err = determineThreadSourcePath(thread, loomConfig, projectRoot)
+		if err != nil {
+			fmt.Fprintf(opts.out, "Cannot resolve source '%s' for thread '%s': %v. Skipping this thread.\n", thread.Source, thread.Name, err)
+			return errThreadSkipped
+		}
+	}
 	if _, statErr := os.Stat(threadSourcePath); os.IsNotExist(statErr) {
-		fmt.Printf("Thread source directory not found for thread '%s': %s. Skipping this thread.\n", thread.Name, threadSourcePath)
-		return nil // Skip this thread, not a fatal error for the whole weave operation.
+		if opts.Strict {
+			return fmt.Errorf("thread source directory %s is missing", threadSourcePath)
+		}
+		fmt.Fprintf(opts.out, "Thread source directory not found for thread '%s': %s. Skipping this thread.\n", thread.Name, threadSourcePath)
+		return errThreadSkipped
+	}
+
+	if opts.lock != nil && opts.SourceOverride == "" && threadUnchanged(thread, threadSourcePath, projectRoot, opts.lock) {
+		fmt.Fprintf(opts.out, "Skipping thread '%s': unchanged since it was last woven.\n", thread.Name)
+		return nil
 	}
 
 	// If we are here, either weaving all, or (weaving specific AND this is the target thread).
-	fmt.Printf("Weaving thread '%s' from %s...\n", thread.Name, threadSourcePath)
+	fmt.Fprintf(opts.out, "Weaving thread '%s' from %s...\n", thread.Name, threadSourcePath)
 
-	filesToProcess, err := collectFilesToProcessForWeaving(thread, threadSourcePath, projectRoot, threadNameToWeave)
+	threadConfig, err := thread.LoadConfig(threadSourcePath)
 	if err != nil {
+		fmt.Fprintf(opts.out, "Failed to load config for thread '%s': %v. Skipping this thread.\n", thread.Name, err)
+		return errThreadSkipped
+	}
+
+	filesToProcess, err := collectFilesToProcessForWeaving(opts.out, thread, threadSourcePath, projectRoot, threadNameToWeave, threadConfig, opts.Strict)
+	if err != nil {
+		if opts.Strict {
+			return err
+		}
 		// Error already has context from collectFilesToProcessForWeaving.
-		fmt.Printf("Failed to collect files for thread '%s': %v. Skipping this thread.\n", thread.Name, err)
-		return nil // Skip this thread.
+		fmt.Fprintf(opts.out, "Failed to collect files for thread '%s': %v. Skipping this thread.\n", thread.Name, err)
+		return errThreadSkipped
+	}
+
+	if opts.Only != "" {
+		filesToProcess = filterFilesForOnly(filesToProcess, thread, threadConfig, opts.Only)
+		if len(filesToProcess) == 0 {
+			fmt.Fprintf(opts.out, "No files of thread '%s' match --only '%s'.\n", thread.Name, opts.Only)
+		}
 	}
 
 	// collectFilesToProcessForWeaving already prints a message if thread.Files is empty for a specific weave.
@@ -426,32 +1361,104 @@ func processWeavingForThread(
 	// 	// No explicit message needed here if collectFilesToProcess already informed.
 	// }
 
+	fileCount := 0
+	for _, files := range filesToProcess {
+		fileCount += len(files)
+	}
+	progress := output.NewProgressTo(opts.out, fileCount, opts.Progress)
+	defer progress.Finish()
+	progressVerb := "weaving"
+	if check != nil {
+		progressVerb = "checking"
+	}
+
 	filesActuallyWrittenByThisThread := make(map[string][]string)
+	changedOnDisk := false
+	if opts.Only != "" {
+		filesActuallyWrittenByThisThread = filesOutsideOnly(thread, opts.Only)
+	}
 
 	for dirToProcess, filesInDirToProcess := range filesToProcess { // dirToProcess is normalized
 		for _, fileToProcess := range filesInDirToProcess { // fileToProcess is just filename
 			relPathFromFileSource := filepath.Join(dirToProcess, fileToProcess) // Reconstruct relative path
+			relPathInDest := threadConfig.DestinationFor(path.Join(dirToProcess, fileToProcess))
+			mode, modeRecorded, err := thread.RecordedMode(path.Join(thread.Prefix, relPathInDest))
+			if err != nil {
+				return err
+			}
 
+			policy, policyReason := opts.OverwritePolicy, "--overwrite-policy"
+			if policy == "" {
+				policy, policyReason = threadConfig.PolicyFor(path.Join(dirToProcess, fileToProcess)), "config.yml policy"
+			}
 			params := processFileWeavingParams{
 				projectRoot:       projectRoot,
 				threadSourcePath:  threadSourcePath,
 				relPathFromSource: relPathFromFileSource,
+				relPathInDest:     relPathInDest,
+				destPrefix:        thread.Prefix,
 				currentThreadName: thread.Name,
 				threadNameToWeave: threadNameToWeave,
 				loomConfig:        loomConfig,
+				check:             check,
+				yes:               opts.Yes,
+				strict:            opts.Strict,
+				backup:            opts.backup,
+				policy:            policy,
+				policyReason:      policyReason,
+				keepExisting:      opts.OverwritePolicy == threadconfig.PolicySkip,
+				lineEndings:       threadConfig.LineEndings,
+				mode:              mode,
+				modeRecorded:      modeRecorded,
+				configMu:          configMu,
+				progress:          progress,
+				out:               opts.out,
 			}
 
+			progress.Step(progressVerb, path.Join(thread.Prefix, relPathInDest))
 			fileWasWritten, opErr := handleFileWeavingOperation(&params)
 			if opErr != nil {
 				// Propagate error if file operation failed critically
 				return fmt.Errorf("processing file '%s' for thread '%s': %w", relPathFromFileSource, thread.Name, opErr)
 			}
+			changedOnDisk = changedOnDisk || params.changed
 
 			if fileWasWritten {
-				// dirToProcess is already normalized (e.g., "./" or "src/components/")
-				filesActuallyWrittenByThisThread[dirToProcess] = append(filesActuallyWrittenByThisThread[dirToProcess], fileToProcess)
+				// The manifest records the installed location: prefix plus the renamed path.
+				destDir, destFile := path.Split(relPathInDest)
+				manifestDir := normalizeDir(path.Join(thread.Prefix, destDir))
+				filesActuallyWrittenByThisThread[manifestDir] = append(filesActuallyWrittenByThisThread[manifestDir], destFile)
+			}
+		}
+	}
+
+	configMu.Lock()
+	defer configMu.Unlock()
+
+	if check != nil {
+		if opts.Prune {
+			candidates, _ := findPruneCandidates(thread, loomConfig, projectRoot, threadSourcePath, threadConfig)
+			for _, candidate := range candidates {
+				check.record("prune", candidate, thread.Name, "")
 			}
 		}
+		return nil // The manifest is left untouched in --check mode.
+	}
+
+	if opts.Prune {
+		kept, err := pruneRemovedFiles(opts.out, thread, loomConfig, projectRoot, threadSourcePath, threadConfig, opts.Yes)
+		if err != nil {
+			return err
+		}
+		for dir, files := range kept {
+			filesActuallyWrittenByThisThread[dir] = append(filesActuallyWrittenByThisThread[dir], files...)
+		}
+	}
+
+	// A weave that wrote, adopted or pruned nothing leaves updated_at alone, so loom.yaml only
+	// changes when the project did.
+	if changedOnDisk || !sameManifestFiles(thread.Files, filesActuallyWrittenByThisThread) {
+		thread.UpdatedAt = project.Timestamp()
 	}
 
 	// Update the thread's manifest in loomConfig with files it actually wrote/owns.
@@ -463,3 +1470,291 @@ func processWeavingForThread(
 
 	return nil
 }
+
+// sameManifestFiles reports whether two file manifests list the same project paths, regardless of
+// their order or how their directories are spelled.
+func sameManifestFiles(a, b map[string][]string) bool {
+	paths := func(files map[string][]string) map[string]bool {
+		set := make(map[string]bool)
+		for dir, names := range files {
+			for _, name := range names {
+				set[path.Join(normalizeDir(dir), name)] = true
+			}
+		}
+		return set
+	}
+	setA, setB := paths(a), paths(b)
+	if len(setA) != len(setB) {
+		return false
+	}
+	for p := range setA {
+		if !setB[p] {
+			return false
+		}
+	}
+	return true
+}
+
+// weaveThreadsInParallel weaves every thread in loomConfig using up to opts.Parallel workers.
+// Threads must not overlap (see findOwnershipOverlap); configMu guards the shared loomConfig.
+// The first error, in manifest order, is returned after all workers finish; otherwise the names of
+// the threads that were skipped are.
+func weaveThreadsInParallel(loomConfig *project.LoomConfig, projectRoot string, opts Options, check *weaveCheck, configMu *sync.Mutex) ([]string, error) {
+	workers := opts.Parallel
+	if workers > len(loomConfig.Threads) {
+		workers = len(loomConfig.Threads)
+	}
+	fmt.Fprintf(opts.out, "Weaving %d threads with %d workers.\n", len(loomConfig.Threads), workers)
+
+	// Each thread writes to its own buffer, flushed in manifest order once the thread is done and
+	// every thread before it has been flushed, so the output reads as if they were woven in turn.
+	jobs := make(chan int)
+	errs := make([]error, len(loomConfig.Threads))
+	outputs := make([]bytes.Buffer, len(loomConfig.Threads))
+	done := make([]chan struct{}, len(loomConfig.Threads))
+	for i := range done {
+		done[i] = make(chan struct{})
+	}
+	for w := 0; w < workers; w++ {
+		go func() {
+			for i := range jobs {
+				threadOpts := opts
+				threadOpts.out = &outputs[i]
+				errs[i] = processWeavingForThread(&loomConfig.Threads[i], loomConfig, projectRoot, "", threadOpts, check, configMu)
+				close(done[i])
+			}
+		}()
+	}
+	go func() {
+		for i := range loomConfig.Threads {
+			jobs <- i
+		}
+		close(jobs)
+	}()
+	for i := range loomConfig.Threads {
+		<-done[i]
+		_, _ = opts.out.Write(outputs[i].Bytes())
+	}
+
+	var skipped []string
+	for i, err := range errs {
+		if errors.Is(err, errThreadSkipped) {
+			skipped = append(skipped, loomConfig.Threads[i].Name)
+		} else if err != nil {
+			return nil, fmt.Errorf("error weaving thread '%s': %w", loomConfig.Threads[i].Name, err)
+		}
+	}
+	return skipped, nil
+}
+
+// findOwnershipOverlap returns a project-relative path that more than one thread would write or
+// already owns, or "" if the threads are disjoint and can safely be woven in parallel.
+func findOwnershipOverlap(loomConfig *project.LoomConfig, projectRoot string) string {
+	claimedBy := make(map[string]string)
+	for i := range loomConfig.Threads {
+		thread := &loomConfig.Threads[i]
+		for _, p := range threadClaimedPaths(thread, loomConfig, projectRoot) {
+			if owner, claimed := claimedBy[p]; claimed && owner != thread.Name {
+				return p
+			}
+			claimedBy[p] = thread.Name
+		}
+	}
+	return ""
+}
+
+// threadClaimedPaths returns the project-relative paths (forward slashes, sorted) the thread owns
+// in loom.yaml plus those a weave of its source would write.
+func threadClaimedPaths(thread *project.Thread, loomConfig *project.LoomConfig, projectRoot string) []string {
+	paths := make(map[string]bool)
+	for dir, files := range thread.Files {
+		for _, file := range files {
+			paths[path.Join(normalizeDir(dir), file)] = true
+		}
+	}
+	for _, p := range threadSourcePaths(thread, loomConfig, projectRoot) {
+		paths[p] = true
+	}
+
+	sorted := make([]string, 0, len(paths))
+	for p := range paths {
+		sorted = append(sorted, p)
+	}
+	sort.Strings(sorted)
+	return sorted
+}
+
+// threadSourcePaths returns the project-relative paths (forward slashes, sorted) a weave of the
+// thread's source would write, after its prefix, config.yml renames and files filter.
+func threadSourcePaths(thread *project.Thread, loomConfig *project.LoomConfig, projectRoot string) []string {
+	var paths []string
+	// Unresolvable sources and configs are skipped here; processWeavingForThread reports them.
+	if threadSourcePath, err := determineThreadSourcePath(thread, loomConfig, projectRoot); err == nil {
+		if threadConfig, err := thread.LoadConfig(threadSourcePath); err == nil {
+			_ = filepath.Walk(threadSourcePath, func(p string, info os.FileInfo, err error) error {
+				if err != nil {
+					return nil
+				}
+				if info.IsDir() {
+					if p != threadSourcePath && project.SkipSourceDir(p, projectRoot) {
+						return filepath.SkipDir
+					}
+					return nil
+				}
+				rel, err := filepath.Rel(threadSourcePath, p)
+				if err != nil || !threadConfig.Includes(filepath.ToSlash(rel)) {
+					return nil
+				}
+				paths = append(paths, path.Join(thread.Prefix, threadConfig.DestinationFor(filepath.ToSlash(rel))))
+				return nil
+			})
+		}
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// sourceCollision is a project path that the sources of two threads both provide.
+type sourceCollision struct {
+	path          string
+	first, second string // Thread names, in loom.yaml order
+}
+
+// findSourceCollisions returns every project path (sorted) that the sources of more than one thread
+// provide. Weaving them all lets the later thread take the file over, which hides an authoring
+// conflict between the threads. A path provided by three threads is reported once per later thread.
+func findSourceCollisions(loomConfig *project.LoomConfig, projectRoot string) []sourceCollision {
+	providedBy := make(map[string]string)
+	var collisions []sourceCollision
+	for i := range loomConfig.Threads {
+		thread := &loomConfig.Threads[i]
+		for _, p := range threadSourcePaths(thread, loomConfig, projectRoot) {
+			if first, provided := providedBy[p]; provided && first != thread.Name {
+				collisions = append(collisions, sourceCollision{path: p, first: first, second: thread.Name})
+				continue
+			}
+			providedBy[p] = thread.Name
+		}
+	}
+	sort.SliceStable(collisions, func(i, j int) bool { return collisions[i].path < collisions[j].path })
+	return collisions
+}
+
+// reportSourceCollisions warns about every path two threads both provide, or with strict fails
+// before anything is woven.
+func reportSourceCollisions(out io.Writer, loomConfig *project.LoomConfig, projectRoot string, strict bool) error {
+	collisions := findSourceCollisions(loomConfig, projectRoot)
+	if len(collisions) == 0 {
+		return nil
+	}
+	if strict {
+		var described []string
+		for _, c := range collisions {
+			described = append(described, fmt.Sprintf("'%s' (threads '%s' and '%s')", c.path, c.first, c.second))
+		}
+		return fmt.Errorf("threads provide the same files: %s", strings.Join(described, ", "))
+	}
+	for _, c := range collisions {
+		fmt.Fprintf(out, "Warning: Threads '%s' and '%s' both provide '%s'; weaving '%s' after '%s' takes it over.\n", c.first, c.second, c.path, c.second, c.first)
+	}
+	return nil
+}
+
+// findPruneCandidates returns the files in the thread's current manifest (project-relative, forward slashes,
+// sorted) whose source file no longer exists in the thread and that no other thread owns. Files recorded
+// outside the thread's prefix, e.g. after the prefix was edited, can't be mapped back to a source file;
+// they are returned separately as outside and are never candidates.
+func findPruneCandidates(
+	thread *project.Thread,
+	loomConfig *project.LoomConfig,
+	projectRoot string,
+	threadSourcePath string,
+	threadConfig *threadconfig.ThreadConfig,
+) (candidates, outside []string) {
+	otherThreads := project.LoomConfig{}
+	for _, t := range loomConfig.Threads {
+		if t.Name != thread.Name {
+			otherThreads.Threads = append(otherThreads.Threads, t)
+		}
+	}
+
+	for dir, files := range thread.Files {
+		destDir, ok := stripThreadPrefix(normalizeDir(dir), thread.Prefix)
+		for _, file := range files {
+			relToProject := path.Join(normalizeDir(dir), file)
+			if !ok {
+				outside = append(outside, relToProject)
+				continue
+			}
+			if sourceRel, found := threadConfig.SourceFor(path.Join(destDir, file)); found && threadConfig.Includes(sourceRel) {
+				if _, err := os.Stat(filepath.Join(threadSourcePath, filepath.FromSlash(sourceRel))); err == nil || !os.IsNotExist(err) {
+					continue // Still in the source (or not determinable); not a prune candidate.
+				}
+			}
+			if _, ownedElsewhere := otherThreads.IsFileOwned(filepath.Join(projectRoot, filepath.FromSlash(relToProject)), projectRoot); ownedElsewhere {
+				continue
+			}
+			candidates = append(candidates, relToProject)
+		}
+	}
+	sort.Strings(candidates)
+	sort.Strings(outside)
+	return candidates, outside
+}
+
+// pruneRemovedFiles deletes the thread's prune candidates (see findPruneCandidates). It asks for
+// confirmation unless assumeYes is set. It returns the manifest entries that were not deleted
+// (outside the prefix, declined or failed) so they stay tracked.
+func pruneRemovedFiles(
+	out io.Writer,
+	thread *project.Thread,
+	loomConfig *project.LoomConfig,
+	projectRoot string,
+	threadSourcePath string,
+	threadConfig *threadconfig.ThreadConfig,
+	assumeYes bool,
+) (map[string][]string, error) {
+	candidates, outside := findPruneCandidates(thread, loomConfig, projectRoot, threadSourcePath, threadConfig)
+	kept := make(map[string][]string)
+	for _, file := range outside {
+		fmt.Fprintf(out, "Warning: Not pruning '%s': it lies outside thread '%s''s prefix '%s', so its source can't be checked.\n", file, thread.Name, thread.Prefix)
+		dir, name := path.Split(file)
+		kept[normalizeDir(dir)] = append(kept[normalizeDir(dir)], name)
+	}
+	if len(candidates) == 0 {
+		return kept, nil
+	}
+
+	fmt.Fprintf(out, "The following files owned by thread '%s' were removed from its source:\n", thread.Name)
+	for _, candidate := range candidates {
+		fmt.Fprintf(out, "  - %s\n", candidate)
+	}
+	confirmed := assumeYes
+	if !confirmed {
+		var err error
+		confirmed, err = output.Confirm(out, fmt.Sprintf("Delete these %d file(s)?", len(candidates)), false)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get confirmation for pruning thread '%s': %w", thread.Name, err)
+		}
+	}
+
+	for _, candidate := range candidates {
+		dir, file := path.Split(candidate)
+		dir = normalizeDir(dir)
+		if !confirmed {
+			kept[dir] = append(kept[dir], file)
+			continue
+		}
+		fullPath := filepath.Join(projectRoot, filepath.FromSlash(candidate))
+		if err := os.Remove(fullPath); err != nil && !os.IsNotExist(err) {
+			fmt.Fprintf(out, "Warning: Failed to prune %s: %v\n", fullPath, err)
+			kept[dir] = append(kept[dir], file)
+			continue
+		}
+		output.Fprintf(out, output.StyleDelete, "Pruned file '%s'.\n", candidate)
+	}
+	if !confirmed {
+		fmt.Fprintf(out, "Keeping %d file(s) for thread '%s'.\n", len(candidates), thread.Name)
+	}
+	return kept, nil
+}
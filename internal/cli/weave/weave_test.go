@@ -0,0 +1,64 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"loom/internal/core/project"
+)
+
+// TestWeaveThreadsInParallel weaves disjoint threads concurrently; run it with -race. Each thread's
+// output must come out whole and in manifest order.
+func TestWeaveThreadsInParallel(t *testing.T) {
+	t.Setenv("LOOM_GLOBAL_DIR", t.TempDir())
+	projectRoot := t.TempDir()
+	loomConfig := &project.LoomConfig{Version: "1"}
+	for i := 0; i < 8; i++ {
+		name := fmt.Sprintf("t%d", i)
+		for j := 0; j < 20; j++ {
+			file := filepath.Join(projectRoot, project.ProjectStoreDirName, name, "_thread", name, fmt.Sprintf("f%d.txt", j))
+			if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(file, []byte(name), 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		loomConfig.Threads = append(loomConfig.Threads, project.Thread{Name: name, Source: project.EncodeSource(project.ProjectSource(name)), Files: map[string][]string{}})
+	}
+
+	var out bytes.Buffer
+	opts := Options{Yes: true, Parallel: 4, out: &out}
+	if skipped, err := weaveThreadsInParallel(loomConfig, projectRoot, opts, nil, &sync.Mutex{}); err != nil || len(skipped) > 0 {
+		t.Fatalf("weaveThreadsInParallel() = (%v, %v), want no skipped threads", skipped, err)
+	}
+
+	for _, thread := range loomConfig.Threads {
+		if got := len(thread.Files[thread.Name+"/"]); got != 20 {
+			t.Errorf("thread '%s' manifest lists %d files, want 20", thread.Name, got)
+		}
+	}
+	mention := regexp.MustCompile(`thread 't(\d+)'`)
+	last := 0
+	for _, line := range strings.Split(out.String(), "\n") {
+		match := mention.FindStringSubmatch(line)
+		if match == nil {
+			continue
+		}
+		index, _ := strconv.Atoi(match[1])
+		if index < last {
+			t.Fatalf("output of thread 't%d' follows thread 't%d':\n%s", index, last, out.String())
+		}
+		last = index
+	}
+	if last != 7 {
+		t.Errorf("output never mentions the last thread:\n%s", out.String())
+	}
+}
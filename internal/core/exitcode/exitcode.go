@@ -0,0 +1,49 @@
+// Package exitcode classifies command errors into the process exit codes loom reports.
+package exitcode
+
+import (
+	"errors"
+	"fmt"
+)
+
+const (
+	// OK is returned when the command succeeded.
+	OK = 0
+	// Failure is returned for errors the user cannot fix by changing the command line, such as I/O errors.
+	Failure = 1
+	// Usage is returned for user errors: bad arguments or flags, and names that do not exist.
+	Usage = 2
+)
+
+// usageError marks an error as a user error.
+type usageError struct {
+	err error
+}
+
+func (e *usageError) Error() string { return e.err.Error() }
+func (e *usageError) Unwrap() error { return e.err }
+
+// UsageError marks err as a user error. It returns nil if err is nil.
+func UsageError(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &usageError{err: err}
+}
+
+// Usagef formats a user error like fmt.Errorf.
+func Usagef(format string, args ...any) error {
+	return &usageError{err: fmt.Errorf(format, args...)}
+}
+
+// For returns the exit code for err. User errors stay user errors when wrapped with %w.
+func For(err error) int {
+	if err == nil {
+		return OK
+	}
+	var usage *usageError
+	if errors.As(err, &usage) {
+		return Usage
+	}
+	return Failure
+}
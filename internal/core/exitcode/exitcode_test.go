@@ -0,0 +1,34 @@
+package exitcode
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestFor(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "success", err: nil, want: OK},
+		{name: "plain error", err: errors.New("disk full"), want: Failure},
+		{name: "user error", err: Usagef("thread '%s' not found", "x"), want: Usage},
+		{name: "wrapped user error", err: fmt.Errorf("context: %w", UsageError(errors.New("bad"))), want: Usage},
+		{name: "user error formatted with %v loses its class", err: fmt.Errorf("context: %v", Usagef("bad")), want: Failure},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := For(tt.err); got != tt.want {
+				t.Errorf("For(%v) = %d, want %d", tt.err, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUsageErrorNil(t *testing.T) {
+	if err := UsageError(nil); err != nil {
+		t.Errorf("UsageError(nil) = %v, want nil", err)
+	}
+}
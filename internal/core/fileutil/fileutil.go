@@ -0,0 +1,80 @@
+// Package fileutil holds file-system helpers shared by the loom commands.
+package fileutil
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// CopyFile streams the contents of src into dest, creating dest if it does not exist and truncating
+// it otherwise, and then sets its permissions to mode, so an existing dest does not keep its old
+// permissions. Files are copied through a bounded buffer so large thread assets are never held in memory.
+func CopyFile(src, dest string, mode os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to read source file %s: %w", src, err)
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return fmt.Errorf("failed to write destination file %s: %w", dest, err)
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+		return fmt.Errorf("failed to copy %s to %s: %w", src, dest, err)
+	}
+	if err := out.Chmod(mode); err != nil {
+		_ = out.Close()
+		return fmt.Errorf("failed to set the permissions of %s: %w", dest, err)
+	}
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("failed to write destination file %s: %w", dest, err)
+	}
+	return nil
+}
+
+// CopyTree recursively copies the directory src to dest, creating dest and any subdirectories.
+// Regular files keep their permission bits; symlinks and other special files are skipped.
+func CopyTree(src, dest string) error {
+	return filepath.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return fmt.Errorf("failed to get relative path for %s: %w", path, err)
+		}
+		target := filepath.Join(dest, rel)
+		switch {
+		case info.IsDir():
+			if err := os.MkdirAll(target, os.ModePerm); err != nil {
+				return fmt.Errorf("failed to create directory %s: %w", target, err)
+			}
+			return nil
+		case info.Mode().IsRegular():
+			return CopyFile(path, target, info.Mode().Perm())
+		default:
+			return nil
+		}
+	})
+}
+
+// HashFile returns the SHA-256 of the file at path as "sha256:<hex>", streaming it like CopyFile.
+func HashFile(path string) (string, error) {
+	in, err := os.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	defer in.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, in); err != nil {
+		return "", fmt.Errorf("failed to hash %s: %w", path, err)
+	}
+	return "sha256:" + hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,148 @@
+package fileutil
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestCopyFileLargeFile(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "dataset.bin")
+	dest := filepath.Join(dir, "copy.bin")
+
+	// 8 MiB of non-repeating-per-block data, so a truncated or misaligned copy is detected.
+	data := make([]byte, 8<<20)
+	for i := range data {
+		data[i] = byte(i*31 + i>>12)
+	}
+	if err := os.WriteFile(src, data, 0o640); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := CopyFile(src, dest, 0o640); err != nil {
+		t.Fatalf("CopyFile() error = %v", err)
+	}
+	got, err := os.ReadFile(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Fatalf("copied content differs from source (got %d bytes, want %d)", len(got), len(data))
+	}
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(dest)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm()&^0o640 != 0 {
+			t.Errorf("dest mode = %v, want at most %v", info.Mode().Perm(), os.FileMode(0o640))
+		}
+	}
+}
+
+func TestCopyFileTruncatesExistingDest(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src.txt")
+	dest := filepath.Join(dir, "dest.txt")
+	if err := os.WriteFile(src, []byte("new"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(dest, []byte("much longer old content"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := CopyFile(src, dest, 0o644); err != nil {
+		t.Fatalf("CopyFile() error = %v", err)
+	}
+	got, err := os.ReadFile(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "new" {
+		t.Errorf("dest content = %q, want %q", got, "new")
+	}
+}
+
+func TestCopyFileMissingSource(t *testing.T) {
+	dir := t.TempDir()
+	dest := filepath.Join(dir, "dest.txt")
+	if err := CopyFile(filepath.Join(dir, "missing.txt"), dest, 0o644); err == nil {
+		t.Fatal("CopyFile() error = nil, want error for missing source")
+	}
+	if _, err := os.Stat(dest); !os.IsNotExist(err) {
+		t.Errorf("dest should not be created when the source is missing, stat err = %v", err)
+	}
+}
+
+func TestCopyTree(t *testing.T) {
+	src := filepath.Join(t.TempDir(), "thread")
+	if err := os.MkdirAll(filepath.Join(src, "_thread", "nested"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(src, "config.yml"), []byte("version: 1"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(src, "_thread", "nested", "file.txt"), []byte("nested"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	dest := filepath.Join(t.TempDir(), "copy")
+	if err := CopyTree(src, dest); err != nil {
+		t.Fatalf("CopyTree() error = %v", err)
+	}
+	for rel, want := range map[string]string{"config.yml": "version: 1", filepath.Join("_thread", "nested", "file.txt"): "nested"} {
+		got, err := os.ReadFile(filepath.Join(dest, rel))
+		if err != nil {
+			t.Fatalf("reading copied %s: %v", rel, err)
+		}
+		if string(got) != want {
+			t.Errorf("%s = %q, want %q", rel, got, want)
+		}
+	}
+}
+
+func TestHashFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "file.txt")
+	if err := os.WriteFile(path, []byte("hello\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	got, err := HashFile(path)
+	if err != nil {
+		t.Fatalf("HashFile() error = %v", err)
+	}
+	if want := "sha256:5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03"; got != want {
+		t.Errorf("HashFile() = %q, want %q", got, want)
+	}
+	if _, err := HashFile(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("HashFile() of a missing file succeeded, want an error")
+	}
+}
+
+func TestCopyFileSetsModeOfExistingDest(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("permission bits are not enforced on Windows")
+	}
+	dir := t.TempDir()
+	src := filepath.Join(dir, "deploy.sh")
+	dest := filepath.Join(dir, "copy.sh")
+	if err := os.WriteFile(src, []byte("#!/bin/sh\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(dest, []byte("old"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := CopyFile(src, dest, 0o755); err != nil {
+		t.Fatalf("CopyFile() error = %v", err)
+	}
+	info, err := os.Stat(dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o755 {
+		t.Errorf("dest mode = %v, want %v", info.Mode().Perm(), os.FileMode(0o755))
+	}
+}
@@ -3,10 +3,18 @@
 package globalconfig
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io/fs"
 	"os"
 	"path/filepath"
+	"regexp"
 	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -16,19 +24,276 @@ const (
 	ConfigDirName = "loom"
 	// ConfigFileName is the name of the global Loom configuration file.
 	ConfigFileName = "loom.yaml"
+	// LoomHomeEnv names the environment variable holding the base directory that relative local
+	// store paths are resolved against.
+	LoomHomeEnv = "LOOM_HOME"
 )
 
+// Known store types.
+const (
+	StoreTypeLocal  = "local"
+	StoreTypeGitHub = "github"
+	StoreTypeGit    = "git"
+	// StoreTypeArchive stores are a .zip, .tar.gz or .tgz file, extracted into a cache on use.
+	StoreTypeArchive = "archive"
+)
+
+// ValidStoreTypes lists every store type Loom accepts in the global configuration.
+var ValidStoreTypes = []string{StoreTypeLocal, StoreTypeGitHub, StoreTypeGit, StoreTypeArchive}
+
+// ValidateStoreType returns an error if storeType is not one of ValidStoreTypes.
+func ValidateStoreType(storeType string) error {
+	for _, valid := range ValidStoreTypes {
+		if storeType == valid {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown store type \"%s\" (valid types: %s)", storeType, strings.Join(ValidStoreTypes, ", "))
+}
+
+// ValidateTokenEnv returns an error if name cannot be the name of an environment variable, which
+// usually means a token was passed where the name of the variable holding it was expected.
+func ValidateTokenEnv(name string) error {
+	if !tokenEnvPattern.MatchString(name) {
+		return fmt.Errorf("invalid token environment variable name \"%s\": expected letters, digits and underscores, such as LOOM_GH_TOKEN", name)
+	}
+	return nil
+}
+
+var tokenEnvPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Token returns the access token of the store, read from the environment variable named by
+// TokenEnv. It returns "" for a store without TokenEnv, and an error if the variable is unset or empty.
+func (s Store) Token() (string, error) {
+	if s.TokenEnv == "" {
+		return "", nil
+	}
+	token := strings.TrimSpace(os.Getenv(s.TokenEnv))
+	if token == "" {
+		return "", fmt.Errorf("the access token is read from the environment variable %s, which is not set; export %s with a token that can read %s", s.TokenEnv, s.TokenEnv, s.Path)
+	}
+	return token, nil
+}
+
+// Writable reports whether threads may be written back to the store: it must not be ReadOnly, and
+// it must be a directory on this machine, which is a local store or a git store read from a checkout.
+func (s Store) Writable() bool {
+	if s.ReadOnly {
+		return false
+	}
+	return s.Type == StoreTypeLocal || (s.Type == StoreTypeGit && s.Checkout != "")
+}
+
 // Store represents a configured thread store.
 type Store struct {
 	Name string `yaml:"name"`
 	Type string `yaml:"type"` // e.g., "local", "github"
-	Path string `yaml:"path"` // For local type, this is the filesystem path. For github, a base URL.
+	Path string `yaml:"path"` // For local type, this is the filesystem path. For github, a base URL. For archive, the archive file.
+	// Priority orders stores when a thread is looked up without a store name: higher priorities are
+	// searched first, and stores with equal priorities keep their configuration order.
+	Priority int `yaml:"priority,omitempty"`
+	// Ref and Checkout are set on git stores registered from a working tree: Ref is the branch the
+	// tree had checked out, and Checkout the tree itself, which threads are read from.
+	Ref      string `yaml:"ref,omitempty"`
+	Checkout string `yaml:"checkout,omitempty"`
+	// TokenEnv names the environment variable holding the access token of a private git or github
+	// store. Only the name is saved; the token is read from the environment when the store is fetched.
+	TokenEnv string `yaml:"token_env,omitempty"`
+	// ReadOnly marks a store that Loom must never write to, such as a curated store that threads are
+	// only fetched from. See Writable.
+	ReadOnly bool `yaml:"read_only,omitempty"`
+
+	// RawPath is Path as written in the config file, before environment variables were expanded and
+	// a relative local path was resolved against RelativeStoreBase. SaveGlobalConfig writes it back
+	// instead of Path as long as Path still matches its resolution.
+	RawPath string `yaml:"-"`
+}
+
+// RelativeStoreBase returns the directory that relative local store paths are resolved against:
+// $LOOM_HOME when it is set, otherwise the directory holding the global configuration file.
+func RelativeStoreBase() (string, error) {
+	if home := os.Getenv(LoomHomeEnv); home != "" {
+		return filepath.Abs(home)
+	}
+	configPath, err := GetGlobalConfigPath()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Dir(configPath), nil
+}
+
+// resolveStorePath expands environment variables in the configured path of a store of storeType
+// and resolves a relative local path against RelativeStoreBase. It returns the resolved path, the
+// names of undefined variables, and whether the path was relative.
+func resolveStorePath(storeType, rawPath string) (string, []string, bool, error) {
+	expanded, missing := expandStorePath(rawPath)
+	if storeType != StoreTypeLocal || expanded == "" || filepath.IsAbs(expanded) {
+		return expanded, missing, false, nil
+	}
+	base, err := RelativeStoreBase()
+	if err != nil {
+		return "", nil, true, err
+	}
+	return filepath.Join(base, expanded), missing, true, nil
+}
+
+// expandStorePath expands $VAR and ${VAR} references in a store path.
+// It returns the expanded path and the names of any variables that are not set.
+func expandStorePath(rawPath string) (string, []string) {
+	var missing []string
+	expanded := os.Expand(rawPath, func(name string) string {
+		value, ok := os.LookupEnv(name)
+		if !ok {
+			missing = append(missing, name)
+		}
+		return value
+	})
+	return expanded, missing
+}
+
+// Template is a named list of threads used by `loom init --template` to seed a new loom.yaml.
+type Template struct {
+	Name    string   `yaml:"name"`
+	Threads []string `yaml:"threads"` // <thread_name> or <store_name>/<thread_name>
+}
+
+// Answers accepted in the defaults section of the global configuration.
+const (
+	AnswerYes  = "yes"
+	AnswerNo   = "no"
+	AnswerSkip = "skip"
+	// AnswerPrompt states no preference: pressing Enter keeps Loom's own default, yes.
+	AnswerPrompt = "prompt"
+)
+
+// Defaults holds the answers that pressing Enter gives at Loom's prompts about overwriting an
+// existing file. Flags such as --yes and --overwrite-policy, and config.yml policies, still answer
+// without asking.
+type Defaults struct {
+	// OnUnownedConflict answers for a file that exists but no thread owns.
+	OnUnownedConflict string `yaml:"on_unowned_conflict,omitempty"`
+	// OnOwnedConflict answers for a file that another thread owns.
+	OnOwnedConflict string `yaml:"on_owned_conflict,omitempty"`
+}
+
+// ConflictAnswer returns the answer ("yes", "no" or "skip") for a conflict over a file that another
+// thread owns, if owned is set, or that no thread owns otherwise.
+func (d Defaults) ConflictAnswer(owned bool) string {
+	answer := d.OnUnownedConflict
+	if owned {
+		answer = d.OnOwnedConflict
+	}
+	if answer == "" || answer == AnswerPrompt {
+		return AnswerYes
+	}
+	return answer
+}
+
+// validate returns an error naming the first setting that is not a known answer.
+func (d Defaults) validate() error {
+	settings := []struct{ key, answer string }{
+		{"on_unowned_conflict", d.OnUnownedConflict},
+		{"on_owned_conflict", d.OnOwnedConflict},
+	}
+	for _, setting := range settings {
+		switch setting.answer {
+		case "", AnswerYes, AnswerNo, AnswerSkip, AnswerPrompt:
+		default:
+			return fmt.Errorf("defaults.%s must be %s, %s, %s or %s, not '%s'", setting.key, AnswerYes, AnswerNo, AnswerSkip, AnswerPrompt, setting.answer)
+		}
+	}
+	return nil
+}
+
+// ConflictDefault returns Defaults.ConflictAnswer for the global configuration, or "yes" if it
+// cannot be loaded.
+func ConflictDefault(owned bool) string {
+	config, err := LoadGlobalConfig()
+	if err != nil {
+		return AnswerYes
+	}
+	return config.Defaults.ConflictAnswer(owned)
 }
 
 // GlobalLoomConfig represents the structure of the global Loom configuration file.
 type GlobalLoomConfig struct {
 	Version string  `yaml:"version"`
 	Stores  []Store `yaml:"stores,omitempty"`
+	// DefaultStore names the store searched first, after the project store, when a thread is added
+	// without a store name.
+	DefaultStore string     `yaml:"default_store,omitempty"`
+	Templates    []Template `yaml:"templates,omitempty"`
+	Defaults     Defaults   `yaml:"defaults,omitempty"`
+
+	// projectStores is the number of stores at the start of Stores that a project's loom.yaml
+	// declares; see WithProjectStores.
+	projectStores int
+}
+
+// WithProjectStores returns a copy of c that also holds stores, the stores declared by a project's
+// loom.yaml. They are searched before the configured stores and replace configured stores of the
+// same name. The copy is meant for resolving threads; saving it would write the project's stores
+// into the global configuration.
+func (c *GlobalLoomConfig) WithProjectStores(stores []Store) *GlobalLoomConfig {
+	merged := *c
+	merged.Stores = append([]Store(nil), stores...)
+	for _, store := range c.Stores {
+		shadowed := false
+		for _, projectStore := range stores {
+			if strings.EqualFold(store.Name, projectStore.Name) {
+				shadowed = true
+				break
+			}
+		}
+		if !shadowed {
+			merged.Stores = append(merged.Stores, store)
+		}
+	}
+	merged.projectStores = len(stores)
+	return &merged
+}
+
+// FindStore returns the store with the given name (case-insensitive).
+func (c *GlobalLoomConfig) FindStore(name string) (Store, bool) {
+	for _, store := range c.Stores {
+		if strings.EqualFold(store.Name, name) {
+			return store, true
+		}
+	}
+	return Store{}, false
+}
+
+// StoresInSearchOrder returns the stores in the order threads are looked up in them: a project's
+// stores first (see WithProjectStores), then the default store, if one is set, then the others.
+// Project stores and the others are each ordered by descending priority, in configuration order
+// among equal priorities.
+func (c *GlobalLoomConfig) StoresInSearchOrder() []Store {
+	projectStores := append([]Store(nil), c.Stores[:c.projectStores]...)
+	sort.SliceStable(projectStores, func(i, j int) bool { return projectStores[i].Priority > projectStores[j].Priority })
+	ordered := make([]Store, 0, len(c.Stores)-c.projectStores)
+	for _, store := range c.Stores[c.projectStores:] {
+		if c.DefaultStore != "" && strings.EqualFold(store.Name, c.DefaultStore) {
+			ordered = append([]Store{store}, ordered...)
+			continue
+		}
+		ordered = append(ordered, store)
+	}
+	others := ordered
+	if len(ordered) > 0 && c.DefaultStore != "" && strings.EqualFold(ordered[0].Name, c.DefaultStore) {
+		others = ordered[1:]
+	}
+	sort.SliceStable(others, func(i, j int) bool { return others[i].Priority > others[j].Priority })
+	return append(projectStores, ordered...)
+}
+
+// FindTemplate returns the template with the given name (case-insensitive).
+func (c *GlobalLoomConfig) FindTemplate(name string) (Template, bool) {
+	for _, template := range c.Templates {
+		if strings.EqualFold(template.Name, name) {
+			return template, true
+		}
+	}
+	return Template{}, false
 }
 
 // GetGlobalConfigPath returns the absolute path to the global Loom configuration file.
@@ -38,7 +303,8 @@ func GetGlobalConfigPath() (string, error) {
 	var configPath string
 
 	// Check if LOOM_GLOBAL_DIR environment variable is set
-	if envDir := os.Getenv("LOOM_GLOBAL_DIR"); envDir != "" {
+	envDir := os.Getenv("LOOM_GLOBAL_DIR")
+	if envDir != "" {
 		configPath = envDir
 	} else {
 		switch runtime.GOOS {
@@ -57,20 +323,83 @@ func GetGlobalConfigPath() (string, error) {
 		}
 	}
 
+	if info, err := os.Stat(configPath); err == nil && !info.IsDir() {
+		if envDir != "" {
+			return "", fmt.Errorf("LOOM_GLOBAL_DIR points to a file (%s), expected a directory", configPath)
+		}
+		return "", fmt.Errorf("loom config directory %s is a file, expected a directory", configPath)
+	}
 	if err := os.MkdirAll(configPath, os.ModePerm); err != nil {
+		if errors.Is(err, fs.ErrPermission) {
+			return "", fmt.Errorf("permission denied creating loom config directory %s; make sure you can write to its parent directory or set LOOM_GLOBAL_DIR to a writable directory", configPath)
+		}
 		return "", fmt.Errorf("failed to create loom config directory at %s: %w", configPath, err)
 	}
 	return filepath.Join(configPath, ConfigFileName), nil
 }
 
 // LoadGlobalConfig loads the global Loom configuration from the default path.
-// If the file doesn't exist, it returns an empty GlobalLoomConfig with version 1.
+// If the file doesn't exist or is empty, it returns an empty GlobalLoomConfig with version 1.
+// The file is read and parsed at most once per command: later calls return a copy of the cached
+// configuration until the file changes or SaveGlobalConfig writes it, so callers may modify the
+// result freely.
 func LoadGlobalConfig() (*GlobalLoomConfig, error) {
 	configPath, err := GetGlobalConfigPath()
 	if err != nil {
 		return nil, err
 	}
+	info, statErr := os.Stat(configPath)
+
+	loaded.Lock()
+	defer loaded.Unlock()
+	if statErr == nil && loaded.config != nil && loaded.path == configPath && loaded.size == info.Size() && loaded.modTime.Equal(info.ModTime()) {
+		return loaded.config.clone(), nil
+	}
+	config, err := readGlobalConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+	loaded.config = nil
+	if statErr == nil {
+		loaded.path, loaded.size, loaded.modTime, loaded.config = configPath, info.Size(), info.ModTime(), config
+	}
+	return config.clone(), nil
+}
 
+// loaded caches the configuration LoadGlobalConfig last read, keyed by the file's path, size and
+// modification time so that a file replaced by another process is read again.
+var loaded struct {
+	sync.Mutex
+	path    string
+	size    int64
+	modTime time.Time
+	config  *GlobalLoomConfig
+}
+
+// invalidateLoaded drops the cached configuration, so the next LoadGlobalConfig reads the file.
+func invalidateLoaded() {
+	loaded.Lock()
+	defer loaded.Unlock()
+	loaded.config = nil
+}
+
+// clone returns a copy of c that shares no slices with it.
+func (c *GlobalLoomConfig) clone() *GlobalLoomConfig {
+	clone := *c
+	clone.Stores = append([]Store{}, c.Stores...)
+	if c.Templates != nil {
+		clone.Templates = make([]Template, len(c.Templates))
+		for i, template := range c.Templates {
+			template.Threads = append([]string(nil), template.Threads...)
+			clone.Templates[i] = template
+		}
+	}
+	return &clone
+}
+
+// readGlobalConfig reads and parses the global configuration file at configPath, resolving the
+// paths of its stores. A missing or empty file holds no stores.
+func readGlobalConfig(configPath string) (*GlobalLoomConfig, error) {
 	var config GlobalLoomConfig
 	configData, err := os.ReadFile(configPath)
 	if err != nil {
@@ -81,27 +410,89 @@ func LoadGlobalConfig() (*GlobalLoomConfig, error) {
 		return nil, fmt.Errorf("failed to read global config file %s: %w", configPath, err)
 	}
 
+	if len(bytes.TrimSpace(configData)) == 0 {
+		// An empty file, left by an interrupted write or a truncating editor, holds no stores.
+		return &GlobalLoomConfig{Version: "1", Stores: []Store{}}, nil
+	}
 	err = yaml.Unmarshal(configData, &config)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse global config file %s: %w", configPath, err)
+		return nil, fmt.Errorf("global config file %s is corrupt: %w; fix it by hand, or run 'loom config reset' to start over without stores", configPath, err)
+	}
+	if config.Version == "" { // A file holding only comments
+		config.Version = "1"
 	}
 	if config.Stores == nil { // Ensure Stores is initialized if it was null in the YAML
 		config.Stores = []Store{}
 	}
+	if err := config.Defaults.validate(); err != nil {
+		return nil, fmt.Errorf("global config file %s is invalid: %w", configPath, err)
+	}
+	for i := range config.Stores {
+		store := &config.Stores[i]
+		resolved, missing, relative, err := resolveStorePath(store.Type, store.Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve the relative path of store '%s': %w", store.Name, err)
+		}
+		if len(missing) > 0 {
+			fmt.Fprintf(os.Stderr, "Warning: path of store '%s' (%s) references undefined environment variable(s): %s\n", store.Name, store.Path, strings.Join(missing, ", "))
+		}
+		if _, statErr := os.Stat(resolved); relative && statErr != nil {
+			fmt.Fprintf(os.Stderr, "Warning: relative path of store '%s' (%s) resolves to %s, which is not accessible here; set %s to the directory it is relative to\n", store.Name, store.Path, resolved, LoomHomeEnv)
+		}
+		store.RawPath = store.Path
+		store.Path = resolved
+	}
 	return &config, nil
 }
 
+// ResetGlobalConfig replaces the global configuration file with one that has no stores. An existing
+// file, which may not even parse, is kept next to it with a ".bak" suffix; its path is returned, or ""
+// if there was no file.
+func ResetGlobalConfig() (string, error) {
+	configPath, err := GetGlobalConfigPath()
+	if err != nil {
+		return "", err
+	}
+	backupPath := ""
+	if _, err := os.Stat(configPath); err == nil {
+		backupPath = configPath + ".bak"
+		if err := os.Rename(configPath, backupPath); err != nil {
+			return "", fmt.Errorf("failed to back up global config file %s: %w", configPath, err)
+		}
+	} else if !os.IsNotExist(err) {
+		return "", fmt.Errorf("failed to check global config file %s: %w", configPath, err)
+	}
+	if err := SaveGlobalConfig(&GlobalLoomConfig{Version: "1", Stores: []Store{}}); err != nil {
+		return backupPath, err
+	}
+	return backupPath, nil
+}
+
 // SaveGlobalConfig saves the global Loom configuration to the default path.
+// Stores keep their environment variable references and relative paths; see Store.RawPath.
 func SaveGlobalConfig(config *GlobalLoomConfig) error {
 	configPath, err := GetGlobalConfigPath()
 	if err != nil {
 		return err
 	}
 
-	updatedData, err := yaml.Marshal(config)
+	// Write unexpanded paths back so environment variable references stay portable.
+	toSave := *config
+	toSave.Stores = make([]Store, len(config.Stores))
+	for i, store := range config.Stores {
+		if store.RawPath != "" {
+			if resolved, _, _, err := resolveStorePath(store.Type, store.RawPath); err == nil && resolved == store.Path {
+				store.Path = store.RawPath
+			}
+		}
+		toSave.Stores[i] = store
+	}
+
+	updatedData, err := yaml.Marshal(&toSave)
 	if err != nil {
 		return fmt.Errorf("failed to marshal global config: %w", err)
 	}
 
+	defer invalidateLoaded()
 	return os.WriteFile(configPath, updatedData, 0600) // 0600 for user read/write only
 }
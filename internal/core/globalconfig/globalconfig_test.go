@@ -0,0 +1,290 @@
+package globalconfig
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func TestLoadGlobalConfigExpandsAndPreservesEnvPaths(t *testing.T) {
+	globalDir := t.TempDir()
+	t.Setenv("LOOM_GLOBAL_DIR", globalDir)
+	t.Setenv("LOOM_TEST_STORES", "/srv/threads")
+
+	content := "version: \"1\"\nstores:\n  - name: company\n    type: local\n    path: ${LOOM_TEST_STORES}/company\n"
+	configPath := filepath.Join(globalDir, ConfigFileName)
+	if err := os.WriteFile(configPath, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := LoadGlobalConfig()
+	if err != nil {
+		t.Fatalf("LoadGlobalConfig() error = %v", err)
+	}
+	if got := config.Stores[0].Path; got != "/srv/threads/company" {
+		t.Errorf("expanded Path = %q, want %q", got, "/srv/threads/company")
+	}
+
+	if err := SaveGlobalConfig(config); err != nil {
+		t.Fatalf("SaveGlobalConfig() error = %v", err)
+	}
+	saved, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(saved), "${LOOM_TEST_STORES}/company") {
+		t.Errorf("saved config lost the unexpanded path:\n%s", saved)
+	}
+}
+
+func TestRelativeStorePathsResolveAgainstLoomHome(t *testing.T) {
+	globalDir := t.TempDir()
+	loomHome := t.TempDir()
+	t.Setenv("LOOM_GLOBAL_DIR", globalDir)
+	t.Setenv(LoomHomeEnv, loomHome)
+
+	content := "version: \"1\"\nstores:\n  - name: company\n    type: local\n    path: stores/company\n  - name: remote\n    type: git\n    path: example.com/threads.git\n"
+	configPath := filepath.Join(globalDir, ConfigFileName)
+	if err := os.WriteFile(configPath, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := LoadGlobalConfig()
+	if err != nil {
+		t.Fatalf("LoadGlobalConfig() error = %v", err)
+	}
+	if got, want := config.Stores[0].Path, filepath.Join(loomHome, "stores", "company"); got != want {
+		t.Errorf("resolved Path = %q, want %q", got, want)
+	}
+	if got := config.Stores[1].Path; got != "example.com/threads.git" {
+		t.Errorf("remote Path = %q, want it left alone", got)
+	}
+
+	if err := SaveGlobalConfig(config); err != nil {
+		t.Fatalf("SaveGlobalConfig() error = %v", err)
+	}
+	saved, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(saved), "path: stores/company") {
+		t.Errorf("saved config lost the relative path:\n%s", saved)
+	}
+}
+
+func TestExpandStorePathReportsMissingVariables(t *testing.T) {
+	t.Setenv("LOOM_TEST_SET", "x")
+	expanded, missing := expandStorePath("$LOOM_TEST_SET/${LOOM_TEST_UNSET_VAR}/threads")
+	if expanded != "x//threads" {
+		t.Errorf("expanded = %q, want %q", expanded, "x//threads")
+	}
+	if len(missing) != 1 || missing[0] != "LOOM_TEST_UNSET_VAR" {
+		t.Errorf("missing = %v, want [LOOM_TEST_UNSET_VAR]", missing)
+	}
+}
+
+func TestStoresInSearchOrder(t *testing.T) {
+	config := &GlobalLoomConfig{Stores: []Store{{Name: "a"}, {Name: "b"}, {Name: "c"}}}
+	names := func() string {
+		var names []string
+		for _, store := range config.StoresInSearchOrder() {
+			names = append(names, store.Name)
+		}
+		return strings.Join(names, ",")
+	}
+	if got := names(); got != "a,b,c" {
+		t.Errorf("StoresInSearchOrder() without a default = %s, want a,b,c", got)
+	}
+	config.DefaultStore = "C"
+	if got := names(); got != "c,a,b" {
+		t.Errorf("StoresInSearchOrder() with default c = %s, want c,a,b", got)
+	}
+	config.Stores[1].Priority = 10
+	config.Stores[2].Priority = 20
+	if got := names(); got != "c,b,a" {
+		t.Errorf("StoresInSearchOrder() with default c and priorities = %s, want c,b,a", got)
+	}
+	config.DefaultStore = ""
+	config.Stores = append(config.Stores, Store{Name: "d", Priority: 10})
+	if got := names(); got != "c,b,d,a" {
+		t.Errorf("StoresInSearchOrder() with priorities = %s, want c,b,d,a", got)
+	}
+}
+
+func TestWithProjectStores(t *testing.T) {
+	config := &GlobalLoomConfig{Stores: []Store{{Name: "a", Priority: 50}, {Name: "shared", Path: "/global"}}, DefaultStore: "a"}
+	merged := config.WithProjectStores([]Store{{Name: "p"}, {Name: "Shared", Path: "/project"}})
+
+	var names []string
+	for _, store := range merged.StoresInSearchOrder() {
+		names = append(names, store.Name)
+	}
+	if got := strings.Join(names, ","); got != "p,Shared,a" {
+		t.Errorf("StoresInSearchOrder() = %s, want p,Shared,a", got)
+	}
+	if store, ok := merged.FindStore("shared"); !ok || store.Path != "/project" {
+		t.Errorf("FindStore(shared) = %+v, %v, want the project's store", store, ok)
+	}
+	if len(config.Stores) != 2 {
+		t.Errorf("WithProjectStores changed the original stores: %+v", config.Stores)
+	}
+}
+
+func TestStoreToken(t *testing.T) {
+	store := Store{Name: "private", Path: "https://example.com/threads.git", TokenEnv: "LOOM_TEST_TOKEN"}
+	t.Setenv("LOOM_TEST_TOKEN", "")
+	if _, err := store.Token(); err == nil || !strings.Contains(err.Error(), "LOOM_TEST_TOKEN, which is not set") {
+		t.Errorf("Token() error = %v, want it to name the unset variable", err)
+	}
+	t.Setenv("LOOM_TEST_TOKEN", "secret")
+	if token, err := store.Token(); err != nil || token != "secret" {
+		t.Errorf("Token() = %q, %v, want secret", token, err)
+	}
+	if token, err := (Store{Name: "public"}).Token(); err != nil || token != "" {
+		t.Errorf("Token() without TokenEnv = %q, %v, want no token", token, err)
+	}
+	for name, valid := range map[string]bool{"LOOM_GH_TOKEN": true, "_token1": true, "1TOKEN": false, "ghp-abc": false, "": false} {
+		if err := ValidateTokenEnv(name); (err == nil) != valid {
+			t.Errorf("ValidateTokenEnv(%q) error = %v, want valid = %v", name, err, valid)
+		}
+	}
+}
+
+func TestStoreWritable(t *testing.T) {
+	tests := []struct {
+		store Store
+		want  bool
+	}{
+		{Store{Type: StoreTypeLocal}, true},
+		{Store{Type: StoreTypeLocal, ReadOnly: true}, false},
+		{Store{Type: StoreTypeGit, Checkout: "/src/threads"}, true},
+		{Store{Type: StoreTypeGit}, false},
+		{Store{Type: StoreTypeGitHub}, false},
+		{Store{Type: StoreTypeArchive}, false},
+	}
+	for _, tt := range tests {
+		if got := tt.store.Writable(); got != tt.want {
+			t.Errorf("%+v.Writable() = %v, want %v", tt.store, got, tt.want)
+		}
+	}
+}
+
+func TestGetGlobalConfigPathRejectsFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "not-a-dir")
+	if err := os.WriteFile(file, nil, 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("LOOM_GLOBAL_DIR", file)
+	if _, err := GetGlobalConfigPath(); err == nil || !strings.Contains(err.Error(), "LOOM_GLOBAL_DIR points to a file") {
+		t.Errorf("GetGlobalConfigPath() error = %v, want it to say LOOM_GLOBAL_DIR points to a file", err)
+	}
+}
+
+func TestGetGlobalConfigPathReportsPermissionDenied(t *testing.T) {
+	if runtime.GOOS == "windows" || os.Geteuid() == 0 {
+		t.Skip("directory permissions do not stop this user from writing")
+	}
+	parent := t.TempDir()
+	if err := os.Chmod(parent, 0500); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.Chmod(parent, 0700) })
+	t.Setenv("LOOM_GLOBAL_DIR", filepath.Join(parent, "loom"))
+	if _, err := GetGlobalConfigPath(); err == nil || !strings.Contains(err.Error(), "permission denied") {
+		t.Errorf("GetGlobalConfigPath() error = %v, want a permission denied error", err)
+	}
+}
+
+func TestLoadGlobalConfigToleratesEmptyAndReportsCorruptFiles(t *testing.T) {
+	globalDir := t.TempDir()
+	t.Setenv("LOOM_GLOBAL_DIR", globalDir)
+	configPath := filepath.Join(globalDir, ConfigFileName)
+
+	for _, content := range []string{"", "\n  \n", "# no stores yet\n"} {
+		if err := os.WriteFile(configPath, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		config, err := LoadGlobalConfig()
+		if err != nil {
+			t.Fatalf("LoadGlobalConfig(%q) error = %v", content, err)
+		}
+		if config.Version != "1" || len(config.Stores) != 0 {
+			t.Errorf("LoadGlobalConfig(%q) = %+v, want a fresh config", content, config)
+		}
+	}
+
+	if err := os.WriteFile(configPath, []byte("stores: [\n  - name: broken\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	_, err := LoadGlobalConfig()
+	if err == nil || !strings.Contains(err.Error(), configPath) || !strings.Contains(err.Error(), "loom config reset") {
+		t.Fatalf("LoadGlobalConfig(corrupt) error = %v, want one naming the file and 'loom config reset'", err)
+	}
+
+	backupPath, err := ResetGlobalConfig()
+	if err != nil {
+		t.Fatalf("ResetGlobalConfig() error = %v", err)
+	}
+	if backupPath != configPath+".bak" {
+		t.Errorf("ResetGlobalConfig() backup = %q, want %q", backupPath, configPath+".bak")
+	}
+	if backup, err := os.ReadFile(backupPath); err != nil || !strings.Contains(string(backup), "broken") {
+		t.Errorf("backup = %q, %v, want the corrupt file", backup, err)
+	}
+	if config, err := LoadGlobalConfig(); err != nil || len(config.Stores) != 0 {
+		t.Errorf("LoadGlobalConfig() after reset = %+v, %v, want a fresh config", config, err)
+	}
+}
+
+func TestLoadGlobalConfigReturnsCopiesUntilSaved(t *testing.T) {
+	globalDir := t.TempDir()
+	t.Setenv("LOOM_GLOBAL_DIR", globalDir)
+	content := "version: \"1\"\nstores:\n  - name: company\n    type: local\n    path: /srv/company\n"
+	if err := os.WriteFile(filepath.Join(globalDir, ConfigFileName), []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := LoadGlobalConfig()
+	if err != nil {
+		t.Fatalf("LoadGlobalConfig() error = %v", err)
+	}
+	config.Stores[0].Name = "renamed"
+	config.Stores = append(config.Stores, Store{Name: "extra", Type: StoreTypeLocal, Path: "/srv/extra"})
+
+	again, err := LoadGlobalConfig()
+	if err != nil {
+		t.Fatalf("LoadGlobalConfig() error = %v", err)
+	}
+	if len(again.Stores) != 1 || again.Stores[0].Name != "company" {
+		t.Fatalf("LoadGlobalConfig() after modifying an earlier result = %+v, want the stores on disk", again.Stores)
+	}
+
+	if err := SaveGlobalConfig(config); err != nil {
+		t.Fatalf("SaveGlobalConfig() error = %v", err)
+	}
+	saved, err := LoadGlobalConfig()
+	if err != nil {
+		t.Fatalf("LoadGlobalConfig() error = %v", err)
+	}
+	if len(saved.Stores) != 2 || saved.Stores[0].Name != "renamed" {
+		t.Errorf("LoadGlobalConfig() after SaveGlobalConfig = %+v, want the saved stores", saved.Stores)
+	}
+}
+
+func TestDefaultsConflictAnswer(t *testing.T) {
+	defaults := Defaults{OnUnownedConflict: AnswerSkip, OnOwnedConflict: AnswerPrompt}
+	if got := defaults.ConflictAnswer(false); got != AnswerSkip {
+		t.Errorf("ConflictAnswer(unowned) = %q, want %q", got, AnswerSkip)
+	}
+	if got := defaults.ConflictAnswer(true); got != AnswerYes {
+		t.Errorf("ConflictAnswer(owned) = %q, want %q for prompt", got, AnswerYes)
+	}
+	if got := (Defaults{}).ConflictAnswer(true); got != AnswerYes {
+		t.Errorf("ConflictAnswer() without defaults = %q, want %q", got, AnswerYes)
+	}
+	if err := (Defaults{OnOwnedConflict: "maybe"}).validate(); err == nil || !strings.Contains(err.Error(), "defaults.on_owned_conflict") {
+		t.Errorf("validate() error = %v, want one naming defaults.on_owned_conflict", err)
+	}
+}
@@ -0,0 +1,73 @@
+// Package output formats the messages loom commands print about file changes, with optional ANSI color.
+package output
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Style is the ANSI color used for one kind of file change.
+type Style string
+
+const (
+	// StyleCreate marks a file being created.
+	StyleCreate Style = "\x1b[32m" // green
+	// StyleOverwrite marks an existing file being overwritten.
+	StyleOverwrite Style = "\x1b[33m" // yellow
+	// StyleTransfer marks a file moving from one owner to another.
+	StyleTransfer Style = "\x1b[35m" // magenta
+	// StyleDelete marks a file being deleted.
+	StyleDelete Style = "\x1b[31m" // red
+
+	reset = "\x1b[0m"
+)
+
+// colorEnabled is decided once at startup and can be turned off with SetColorEnabled.
+var colorEnabled = defaultColorEnabled(os.Getenv("NO_COLOR"), os.Stdout)
+
+// defaultColorEnabled reports whether color should be used: never when NO_COLOR is set to a
+// non-empty value (https://no-color.org), and only when stdout is a terminal.
+func defaultColorEnabled(noColor string, stdout *os.File) bool {
+	if noColor != "" {
+		return false
+	}
+	return isTerminal(stdout)
+}
+
+// isTerminal reports whether f is a terminal.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// SetColorEnabled turns color on or off, e.g. for the --no-color flag.
+func SetColorEnabled(enabled bool) {
+	colorEnabled = enabled
+}
+
+// Paint wraps text in style's color when color is enabled. A trailing newline is kept outside
+// the color codes so the terminal is reset before the line ends.
+func Paint(style Style, text string) string {
+	if !colorEnabled || text == "" {
+		return text
+	}
+	body := strings.TrimSuffix(text, "\n")
+	return string(style) + body + reset + text[len(body):]
+}
+
+// Printf formats according to format and prints the result to stdout in style's color, after
+// clearing any in-place progress line.
+func Printf(style Style, format string, args ...any) {
+	Fprintf(os.Stdout, style, format, args...)
+}
+
+// Fprintf is Printf for messages written to w, such as stderr when stdout is reserved for JSON.
+func Fprintf(w io.Writer, style Style, format string, args ...any) {
+	ClearProgress()
+	fmt.Fprint(w, Paint(style, fmt.Sprintf(format, args...)))
+}
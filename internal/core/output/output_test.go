@@ -0,0 +1,80 @@
+package output
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestPaint(t *testing.T) {
+	defer SetColorEnabled(colorEnabled)
+
+	SetColorEnabled(false)
+	if got := Paint(StyleCreate, "created\n"); got != "created\n" {
+		t.Errorf("Paint() with color disabled = %q, want plain text", got)
+	}
+
+	SetColorEnabled(true)
+	if got, want := Paint(StyleOverwrite, "overwritten\n"), "\x1b[33moverwritten\x1b[0m\n"; got != want {
+		t.Errorf("Paint() = %q, want %q", got, want)
+	}
+	if got, want := Paint(StyleTransfer, "taken"), "\x1b[35mtaken\x1b[0m"; got != want {
+		t.Errorf("Paint() without newline = %q, want %q", got, want)
+	}
+	if got := Paint(StyleCreate, ""); got != "" {
+		t.Errorf("Paint() of empty text = %q, want empty", got)
+	}
+}
+
+func TestDefaultColorEnabled(t *testing.T) {
+	notTerminal, err := os.Create(filepath.Join(t.TempDir(), "out.txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer notTerminal.Close()
+
+	if defaultColorEnabled("", notTerminal) {
+		t.Error("color should be disabled when stdout is not a terminal")
+	}
+	if defaultColorEnabled("1", notTerminal) {
+		t.Error("color should be disabled when NO_COLOR is set")
+	}
+}
+
+func TestProgress(t *testing.T) {
+	if p := NewProgress(ProgressThreshold-1, false); p != nil {
+		t.Errorf("NewProgress() below the threshold = %v, want nil", p)
+	}
+	if p := NewProgress(0, true); p != nil {
+		t.Errorf("NewProgress() without files = %v, want nil", p)
+	}
+	if p := NewProgressTo(&strings.Builder{}, 3, true); p == nil || p.inPlace {
+		t.Errorf("NewProgressTo() a buffer = %+v, want periodic progress", p)
+	}
+	var nilProgress *Progress
+	nilProgress.Step("writing", "a.txt") // Must not panic
+	nilProgress.Finish()
+
+	var periodic strings.Builder
+	p := &Progress{total: 250, out: &periodic}
+	for i := 0; i < 250; i++ {
+		p.Step("writing", fmt.Sprintf("f%d.txt", i+1))
+	}
+	p.Finish()
+	if want := "[100/250] writing f100.txt\n[200/250] writing f200.txt\n[250/250] writing f250.txt\n"; periodic.String() != want {
+		t.Errorf("periodic progress = %q, want %q", periodic.String(), want)
+	}
+
+	var inPlace strings.Builder
+	p = &Progress{total: 2, inPlace: true, out: &inPlace}
+	p.Step("writing", "a.txt")
+	ClearProgress()
+	p.Step("writing", strings.Repeat("x", 100))
+	p.Finish()
+	want := "\r\x1b[K[1/2] writing a.txt\r\x1b[K\r\x1b[K[2/2] writing " + strings.Repeat("x", progressWidth-3-len("[2/2] writing ")) + "...\r\x1b[K"
+	if inPlace.String() != want {
+		t.Errorf("in-place progress = %q, want %q", inPlace.String(), want)
+	}
+}
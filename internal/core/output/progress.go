@@ -0,0 +1,107 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+)
+
+// ProgressThreshold is the number of files from which add and weave report progress without --progress.
+const ProgressThreshold = 500
+
+// progressLineInterval is how many files pass between the progress lines printed when stdout is
+// not a terminal, where the line cannot be updated in place.
+const progressLineInterval = 100
+
+// progressWidth bounds the length of an in-place progress line, so it never wraps and leaves the
+// carriage return unable to reach its start.
+const progressWidth = 79
+
+// Progress reports how far an operation over a known number of files has come, as
+// "[120/3400] writing src/...". A nil *Progress reports nothing, so callers need not check whether
+// progress is shown.
+type Progress struct {
+	total   int
+	done    int
+	inPlace bool // Update a single line on a terminal instead of printing periodic lines
+	shown   bool // The in-place line is currently on screen
+	out     io.Writer
+}
+
+var (
+	progressMu sync.Mutex
+	// activeProgress is the progress whose in-place line is on screen, if any. Printf clears it
+	// first, so messages about single files start on a line of their own.
+	activeProgress *Progress
+)
+
+// NewProgress returns a Progress over total files, or nil if progress should not be shown: when
+// there are fewer than ProgressThreshold files and force (the --progress flag) is not set.
+func NewProgress(total int, force bool) *Progress {
+	return NewProgressTo(os.Stdout, total, force)
+}
+
+// NewProgressTo is NewProgress for progress written to out instead of stdout. The line is only
+// updated in place when out is a terminal.
+func NewProgressTo(out io.Writer, total int, force bool) *Progress {
+	if total == 0 || (!force && total < ProgressThreshold) {
+		return nil
+	}
+	file, isFile := out.(*os.File)
+	return &Progress{total: total, inPlace: isFile && isTerminal(file), out: out}
+}
+
+// Step records that one more file is being processed: verb (such as "writing") and name describe it.
+func (p *Progress) Step(verb, name string) {
+	if p == nil {
+		return
+	}
+	progressMu.Lock()
+	defer progressMu.Unlock()
+	p.done++
+	line := fmt.Sprintf("[%d/%d] %s %s", p.done, p.total, verb, name)
+	if !p.inPlace {
+		if p.done%progressLineInterval == 0 || p.done == p.total {
+			fmt.Fprintln(p.out, line)
+		}
+		return
+	}
+	if len(line) > progressWidth {
+		line = line[:progressWidth-3] + "..."
+	}
+	fmt.Fprintf(p.out, "\r\x1b[K%s", line)
+	p.shown = true
+	activeProgress = p
+}
+
+// Finish removes the in-place progress line once the operation is over.
+func (p *Progress) Finish() {
+	if p == nil {
+		return
+	}
+	progressMu.Lock()
+	defer progressMu.Unlock()
+	p.clear()
+	if activeProgress == p {
+		activeProgress = nil
+	}
+}
+
+// ClearProgress removes the in-place progress line, if one is on screen, so the next message
+// starts on a line of its own. Printf does this itself.
+func ClearProgress() {
+	progressMu.Lock()
+	defer progressMu.Unlock()
+	if activeProgress != nil {
+		activeProgress.clear()
+	}
+}
+
+// clear erases p's in-place line. progressMu must be held.
+func (p *Progress) clear() {
+	if p.shown {
+		fmt.Fprint(p.out, "\r\x1b[K")
+		p.shown = false
+	}
+}
@@ -0,0 +1,103 @@
+package output
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ErrNoAnswer is returned by a prompt when stdin ends before it is answered, e.g. when loom runs
+// in a script that did not pass --yes.
+var ErrNoAnswer = errors.New("no answer given before the end of input; pass --yes to answer prompts in scripts")
+
+// stdin is shared by every prompt, so answers piped in for several prompts are not lost to the
+// buffering of a reader that only served the first one.
+var stdin = bufio.NewReader(os.Stdin)
+
+// StdinIsTerminal reports whether stdin is an interactive terminal. /dev/null is a character device
+// as well, so it is ruled out explicitly.
+func StdinIsTerminal() bool {
+	info, err := os.Stdin.Stat()
+	if err != nil || info.Mode()&os.ModeCharDevice == 0 {
+		return false
+	}
+	if devNull, err := os.Stat(os.DevNull); err == nil && os.SameFile(info, devNull) {
+		return false
+	}
+	return true
+}
+
+// Ask writes question to out and reads lines from stdin until accept takes one, writing invalid
+// after every line it rejects. Lines are trimmed; an empty one, for Enter, is passed to accept too.
+// It fails with ErrNoAnswer if stdin ends first.
+func Ask(out io.Writer, question, invalid string, accept func(input string) (string, bool)) (string, error) {
+	for {
+		ClearProgress()
+		fmt.Fprint(out, question)
+		input, err := stdin.ReadString('\n')
+		if err != nil && (err != io.EOF || input == "") {
+			fmt.Fprintln(out)
+			if err == io.EOF {
+				return "", ErrNoAnswer
+			}
+			return "", err
+		}
+		if answer, ok := accept(strings.TrimSpace(input)); ok {
+			return answer, nil
+		}
+		fmt.Fprintln(out, invalid)
+	}
+}
+
+// AskChoice asks question, colored with style, and returns one of answers, each of which can be
+// typed in full or by its first letter, case-insensitively. Enter picks defaultAnswer.
+func AskChoice(out io.Writer, style Style, question string, answers []string, defaultAnswer string) (string, error) {
+	labels := make([]string, len(answers))
+	quoted := make([]string, len(answers))
+	for i, answer := range answers {
+		labels[i] = "[" + strings.ToUpper(answer[:1]) + "]" + answer[1:]
+		quoted[i] = "'" + answer + "'"
+	}
+	prompt := fmt.Sprintf("%s %s [%s]: ", Paint(style, question), strings.Join(labels, "/"), strings.ToUpper(defaultAnswer[:1])+defaultAnswer[1:])
+	invalid := fmt.Sprintf("Invalid input. Please enter %s, or press Enter for '%s'.", strings.Join(quoted, ", "), defaultAnswer)
+	return Ask(out, prompt, invalid, func(input string) (string, bool) {
+		input = strings.ToLower(input)
+		if input == "" {
+			return defaultAnswer, true
+		}
+		for _, answer := range answers {
+			if input == answer || input == answer[:1] {
+				return answer, true
+			}
+		}
+		return "", false
+	})
+}
+
+// Confirm asks a yes/no question. Enter answers defaultYes. A question that defaults to no is
+// declined when stdin ends, since declining never changes anything; one that defaults to yes fails
+// with ErrNoAnswer instead.
+func Confirm(out io.Writer, question string, defaultYes bool) (bool, error) {
+	choices, defaultAnswer := "[y/N]", "no"
+	if defaultYes {
+		choices, defaultAnswer = "[Y/n]", "yes"
+	}
+	answer, err := Ask(out, question+" "+choices+": ", fmt.Sprintf("Invalid input. Please enter 'yes' or 'no', or press Enter for '%s'.", defaultAnswer), func(input string) (string, bool) {
+		switch strings.ToLower(input) {
+		case "":
+			return defaultAnswer, true
+		case "yes", "y":
+			return "yes", true
+		case "no", "n":
+			return "no", true
+		}
+		return "", false
+	})
+	if errors.Is(err, ErrNoAnswer) && !defaultYes {
+		return false, nil
+	}
+	return answer == "yes", err
+}
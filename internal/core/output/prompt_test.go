@@ -0,0 +1,48 @@
+package output
+
+import (
+	"bufio"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// withStdin makes the prompts read input until the test ends.
+func withStdin(t *testing.T, input string) {
+	t.Helper()
+	saved := stdin
+	stdin = bufio.NewReader(strings.NewReader(input))
+	t.Cleanup(func() { stdin = saved })
+}
+
+func TestAskChoice(t *testing.T) {
+	withStdin(t, "maybe\nS\n\n")
+	var out strings.Builder
+	answer, err := AskChoice(&out, "", "Overwrite it?", []string{"yes", "no", "skip"}, "no")
+	if err != nil || answer != "skip" {
+		t.Fatalf("AskChoice() = (%q, %v), want skip", answer, err)
+	}
+	if want := "Overwrite it? [Y]es/[N]o/[S]kip [No]: Invalid input. Please enter 'yes', 'no', 'skip', or press Enter for 'no'.\n"; !strings.HasPrefix(out.String(), want) {
+		t.Errorf("AskChoice() printed %q, want it to start with %q", out.String(), want)
+	}
+	// The second prompt reads the line left over by the first.
+	if answer, err := AskChoice(&out, "", "Overwrite it?", []string{"yes", "no", "skip"}, "no"); err != nil || answer != "no" {
+		t.Errorf("AskChoice() on Enter = (%q, %v), want the default", answer, err)
+	}
+}
+
+func TestConfirmAtEndOfInput(t *testing.T) {
+	var out strings.Builder
+	withStdin(t, "")
+	if confirmed, err := Confirm(&out, "Delete it?", false); err != nil || confirmed {
+		t.Errorf("Confirm(default no) at end of input = (%v, %v), want declined", confirmed, err)
+	}
+	withStdin(t, "")
+	if _, err := Confirm(&out, "Add it?", true); !errors.Is(err, ErrNoAnswer) {
+		t.Errorf("Confirm(default yes) at end of input error = %v, want ErrNoAnswer", err)
+	}
+	withStdin(t, "y")
+	if confirmed, err := Confirm(&out, "Delete it?", false); err != nil || !confirmed {
+		t.Errorf("Confirm() of an unterminated 'y' = (%v, %v), want confirmed", confirmed, err)
+	}
+}
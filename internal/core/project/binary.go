@@ -0,0 +1,50 @@
+package project
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"unicode/utf8"
+)
+
+// binarySniffLen is how much of a file IsBinary inspects, the same amount git uses.
+const binarySniffLen = 8000
+
+// IsBinary reports whether the file at path looks binary, judging by its first few kilobytes: it is
+// binary if they contain a NUL byte or are not valid UTF-8. Files starting with a UTF-16 byte order
+// mark are text even though they contain NUL bytes. Empty files are text.
+//
+// Code that renders or rewrites file contents (diffs, templates) should pass binary files through
+// untouched.
+func IsBinary(path string) (bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return false, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer file.Close()
+
+	sample := make([]byte, binarySniffLen)
+	n, err := io.ReadFull(file, sample)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return false, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return isBinaryContent(sample[:n], n == binarySniffLen), nil
+}
+
+// isBinaryContent applies IsBinary's rules to sample, the start of a file. truncated means the file
+// continues past the sample, which may then end partway through a multi-byte character.
+func isBinaryContent(sample []byte, truncated bool) bool {
+	if bytes.HasPrefix(sample, []byte{0xFF, 0xFE}) || bytes.HasPrefix(sample, []byte{0xFE, 0xFF}) {
+		return false
+	}
+	if bytes.IndexByte(sample, 0) >= 0 {
+		return true
+	}
+	if truncated {
+		for i := 1; i < utf8.UTFMax && len(sample) > 0 && !utf8.Valid(sample); i++ {
+			sample = sample[:len(sample)-1]
+		}
+	}
+	return !utf8.Valid(sample)
+}
@@ -0,0 +1,50 @@
+package project
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestIsBinary(t *testing.T) {
+	pngHeader := []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1a, '\n', 0x00, 0x00, 0x00, 0x0d, 'I', 'H', 'D', 'R'}
+	// A multi-byte character straddling the end of the sample must not make the file binary.
+	longText := append(bytes.Repeat([]byte("a"), binarySniffLen-1), []byte("é and more")...)
+
+	tests := []struct {
+		name    string
+		content []byte
+		want    bool
+	}{
+		{name: "png", content: pngHeader, want: true},
+		{name: "utf-8 text", content: []byte("héllo, wörld ✓\n"), want: false},
+		{name: "utf-8 with bom", content: []byte("\xef\xbb\xbfname: loom\n"), want: false},
+		{name: "utf-16le with bom", content: []byte("\xff\xfeh\x00i\x00\n\x00"), want: false},
+		{name: "utf-16be with bom", content: []byte("\xfe\xff\x00h\x00i\x00\n"), want: false},
+		{name: "invalid utf-8", content: []byte("caf\xe9\n"), want: true},
+		{name: "empty", content: nil, want: false},
+		{name: "long text split mid-character", content: longText, want: false},
+		{name: "nul after the first line", content: []byte(strings.Repeat("text\n", 10) + "\x00"), want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "file")
+			if err := os.WriteFile(path, tt.content, 0o644); err != nil {
+				t.Fatal(err)
+			}
+			got, err := IsBinary(path)
+			if err != nil {
+				t.Fatalf("IsBinary() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("IsBinary() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := IsBinary(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("IsBinary() of a missing file succeeded, want an error")
+	}
+}
@@ -4,8 +4,18 @@ package project
 import (
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
 	"strings" // Added missing import
+	"time"
+
+	"loom/internal/core/globalconfig"
+	"loom/internal/core/threadconfig"
+
+	"gopkg.in/yaml.v3"
 )
 
 // YamlFileName is the name of the loom configuration file
@@ -14,15 +24,167 @@ const YamlFileName = "loom.yaml"
 // LoomConfig represents the structure of loom.yaml
 // Note: Renamed from Config to LoomConfig and Version type changed to string
 type LoomConfig struct {
-	Version string   `yaml:"version"`
-	Threads []Thread `yaml:"threads"`
+	Version string `yaml:"version"`
+	// Stores are searched for threads before the stores in the global configuration, so everyone
+	// working on the project resolves threads the same way. See ProjectStores.
+	Stores []globalconfig.Store `yaml:"stores,omitempty"`
+	// Vars are the project's template variables, shared through version control. loom.vars and
+	// --var flags override them; see ResolveVars.
+	Vars    map[string]string `yaml:"vars,omitempty"`
+	Threads []Thread          `yaml:"threads"`
 }
 
 // Thread represents a thread entry in loom.yaml
 type Thread struct {
-	Name   string              `yaml:"name"`
-	Source string              `yaml:"source"`
-	Files  map[string][]string `yaml:"files,omitempty"`
+	Name        string              `yaml:"name"`
+	Source      string              `yaml:"source"`
+	Prefix      string              `yaml:"prefix,omitempty"`       // Project-relative directory the thread's files are installed under
+	InstalledAt string              `yaml:"installed_at,omitempty"` // RFC3339, set when the thread is first added
+	UpdatedAt   string              `yaml:"updated_at,omitempty"`   // RFC3339, refreshed on add and on a weave that changes its files
+	DependsOn   []string            `yaml:"depends_on,omitempty"`   // Names of the threads its config.yml declares as dependencies
+	Files       map[string][]string `yaml:"files,omitempty"`
+	// Modes maps project-relative file paths to the permission bits, in octal, that weave applies
+	// when rewriting them. Recorded by `loom add --record-modes`.
+	Modes map[string]string `yaml:"modes,omitempty"`
+	// Dotfiles overrides the dotfiles setting of the thread's config.yml, recorded by `loom add
+	// --include-dotfiles` or `--exclude-dotfiles` so weave installs the same files. See LoadConfig.
+	Dotfiles string `yaml:"dotfiles,omitempty"`
+}
+
+// LoadConfig reads the config.yml of the thread whose _thread directory is sourceDir and applies the
+// thread's Dotfiles override, if any.
+func (t *Thread) LoadConfig(sourceDir string) (*threadconfig.ThreadConfig, error) {
+	config, err := threadconfig.LoadThreadConfigForSource(sourceDir)
+	if err != nil {
+		return nil, err
+	}
+	if t.Dotfiles != "" {
+		config.Dotfiles = t.Dotfiles
+	}
+	return config, nil
+}
+
+// Timestamp returns the current time formatted for Thread.InstalledAt and Thread.UpdatedAt.
+func Timestamp() string {
+	return time.Now().UTC().Format(time.RFC3339)
+}
+
+// Normalize puts the manifest into a stable order before it is saved, so rewriting loom.yaml
+// does not produce noisy diffs: each directory's file list is sorted. Directory keys need no
+// sorting because yaml.v3 always marshals map keys in sorted order. Threads keep their order.
+// Recorded modes of files a thread no longer owns are dropped.
+func (lc *LoomConfig) Normalize() {
+	for i := range lc.Threads {
+		thread := &lc.Threads[i]
+		for _, files := range thread.Files {
+			sort.Strings(files)
+		}
+		for file := range thread.Modes {
+			if !thread.ownsFile(file) {
+				delete(thread.Modes, file)
+			}
+		}
+		if len(thread.Modes) == 0 {
+			thread.Modes = nil
+		}
+	}
+}
+
+// Clone returns a deep copy of lc, so changes to either leave the other untouched.
+func (lc *LoomConfig) Clone() LoomConfig {
+	clone := LoomConfig{Version: lc.Version}
+	if lc.Stores != nil {
+		clone.Stores = append([]globalconfig.Store(nil), lc.Stores...)
+	}
+	if lc.Vars != nil {
+		clone.Vars = make(map[string]string, len(lc.Vars))
+		for key, value := range lc.Vars {
+			clone.Vars[key] = value
+		}
+	}
+	if lc.Threads != nil {
+		clone.Threads = make([]Thread, len(lc.Threads))
+	}
+	for i, thread := range lc.Threads {
+		if thread.DependsOn != nil {
+			thread.DependsOn = append([]string(nil), thread.DependsOn...)
+		}
+		if thread.Files != nil {
+			files := make(map[string][]string, len(thread.Files))
+			for dir, names := range thread.Files {
+				files[dir] = append([]string(nil), names...)
+			}
+			thread.Files = files
+		}
+		if thread.Modes != nil {
+			modes := make(map[string]string, len(thread.Modes))
+			for file, mode := range thread.Modes {
+				modes[file] = mode
+			}
+			thread.Modes = modes
+		}
+		clone.Threads[i] = thread
+	}
+	return clone
+}
+
+// ownsFile reports whether the project-relative relPath is listed in t.Files. Paths are normalized
+// and compared like IsFileOwned does, so "./" and "." both mean the project root and case is folded
+// where the platform requires it.
+func (t *Thread) ownsFile(relPath string) bool {
+	relPath = normalizeManifestPath(relPath)
+	for dir, files := range t.Files {
+		normalizedDir := normalizeManifestPath(dir) // "./", "" and "." all mean the project root
+		for _, ownedFile := range files {
+			fullOwnedPath := normalizeManifestPath(path.Join(normalizedDir, normalizeManifestPath(ownedFile)))
+			if manifestPathsEqual(fullOwnedPath, relPath) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// FormatMode formats the permission bits of mode for Thread.Modes, e.g. "0755".
+func FormatMode(mode os.FileMode) string {
+	return fmt.Sprintf("%04o", mode.Perm())
+}
+
+// RecordedMode returns the permission bits recorded in t.Modes for the project-relative file relPath,
+// and false if none are recorded.
+func (t *Thread) RecordedMode(relPath string) (os.FileMode, bool, error) {
+	recorded, ok := t.Modes[relPath]
+	if !ok {
+		return 0, false, nil
+	}
+	mode, err := strconv.ParseUint(recorded, 8, 32)
+	if err != nil || mode > 0o777 {
+		return 0, false, fmt.Errorf("invalid mode '%s' recorded for '%s' in thread '%s'", recorded, relPath, t.Name)
+	}
+	return os.FileMode(mode), true, nil
+}
+
+// caseInsensitivePaths reports whether manifest paths are compared case-insensitively.
+// It is a variable so tests can exercise both behaviours on any platform.
+var caseInsensitivePaths = runtime.GOOS == "windows"
+
+// normalizeManifestPath converts a project-relative path to the canonical manifest form:
+// forward slashes, cleaned, and "." for the project root. Backslashes are always treated as
+// separators because loom.yaml paths may have been written on Windows.
+func normalizeManifestPath(p string) string {
+	p = strings.ReplaceAll(p, "\\", "/")
+	if p == "" {
+		return "."
+	}
+	return path.Clean(p)
+}
+
+// manifestPathsEqual compares two normalized manifest paths, case-folding where the platform requires it.
+func manifestPathsEqual(a, b string) bool {
+	if caseInsensitivePaths {
+		return strings.EqualFold(a, b)
+	}
+	return a == b
 }
 
 // IsFileOwned checks if a given file path is owned by any thread in the config.
@@ -35,36 +197,17 @@ func (lc *LoomConfig) IsFileOwned(filePath string, projectRoot string) (string,
 	}
 	relPath = filepath.ToSlash(relPath) // Ensure consistent path separators
 
-	for _, thread := range lc.Threads {
-		if thread.Files == nil {
-			continue
-		}
-		for dir, files := range thread.Files {
-			// Normalize dir to ensure it ends with a slash if it's not "./"
-			normalizedDir := dir
-			if normalizedDir != "./" && !strings.HasSuffix(normalizedDir, "/") {
-				normalizedDir += "/"
-			}
-
-			for _, ownedFile := range files {
-				var fullOwnedPath string
-				if normalizedDir == "./" {
-					fullOwnedPath = ownedFile
-				} else {
-					fullOwnedPath = filepath.ToSlash(filepath.Join(normalizedDir, ownedFile))
-				}
-
-				if fullOwnedPath == relPath {
-					return thread.Name, true
-				}
-			}
+	for i := range lc.Threads {
+		if lc.Threads[i].ownsFile(relPath) {
+			return lc.Threads[i].Name, true
 		}
 	}
 	return "", false
 }
 
-// InitProject initializes a new loom.yaml file in the current directory
-func InitProject() error {
+// InitProject initializes a new loom.yaml file in the current directory.
+// threads seeds the threads list; their files are installed by a later `loom weave`.
+func InitProject(threads []Thread) error {
 	// Check if loom.yaml already exists
 	if _, err := os.Stat(YamlFileName); err == nil { // Changed fileInfo to _
 		// File exists, check if it's empty or only comments/whitespace
@@ -101,6 +244,13 @@ func InitProject() error {
 version: "1"
 threads: []
 ` // Renamed content to contentString to avoid conflict
+	if len(threads) > 0 {
+		seeded, err := yaml.Marshal(&LoomConfig{Version: "1", Threads: threads})
+		if err != nil {
+			return fmt.Errorf("failed to marshal %s: %w", YamlFileName, err)
+		}
+		contentString = "# loom.yaml - Loom project configuration file\n" + string(seeded)
+	}
 
 	// Write the content to loom.yaml
 	errWrite := os.WriteFile(YamlFileName, []byte(contentString), 0644) // Used contentString and new err var
@@ -129,3 +279,26 @@ func GetProjectRoot() (string, error) {
 	// In the future, we might want to search up the directory tree for loom.yaml
 	return dir, nil
 }
+
+// LocateManifest returns the project root and the path of the loom.yaml a command operates on.
+// An empty configPath means loom.yaml in the current directory. Otherwise configPath names the
+// manifest (or a directory containing one) and the project root is the directory it lives in,
+// so the file paths recorded in it resolve the same way regardless of where loom is run from.
+func LocateManifest(configPath string) (string, string, error) {
+	if configPath == "" {
+		dir, err := os.Getwd()
+		if err != nil {
+			return "", "", fmt.Errorf("failed to get current directory: %w", err)
+		}
+		return dir, filepath.Join(dir, YamlFileName), nil
+	}
+
+	manifestPath, err := filepath.Abs(configPath)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to resolve config path '%s': %w", configPath, err)
+	}
+	if info, err := os.Stat(manifestPath); err == nil && info.IsDir() {
+		manifestPath = filepath.Join(manifestPath, YamlFileName)
+	}
+	return filepath.Dir(manifestPath), manifestPath, nil
+}
@@ -0,0 +1,229 @@
+package project
+
+import (
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func TestIsFileOwned(t *testing.T) {
+	projectRoot := filepath.Join(string(filepath.Separator), "work", "proj")
+	if runtime.GOOS == "windows" {
+		projectRoot = `C:\work\proj`
+	}
+
+	tests := []struct {
+		name            string
+		files           map[string][]string
+		filePath        string
+		projectRoot     string
+		caseInsensitive bool
+		windowsOnly     bool
+		wantOwner       string
+		wantOwned       bool
+	}{
+		{
+			name:      "root file with ./ key",
+			files:     map[string][]string{"./": {"README.md"}},
+			filePath:  filepath.Join(projectRoot, "README.md"),
+			wantOwner: "t",
+			wantOwned: true,
+		},
+		{
+			name:      "root file with empty key",
+			files:     map[string][]string{"": {"README.md"}},
+			filePath:  filepath.Join(projectRoot, "README.md"),
+			wantOwner: "t",
+			wantOwned: true,
+		},
+		{
+			name:      "root file with dot key",
+			files:     map[string][]string{".": {"README.md"}},
+			filePath:  filepath.Join(projectRoot, "README.md"),
+			wantOwner: "t",
+			wantOwned: true,
+		},
+		{
+			name:      "nested dir without trailing slash",
+			files:     map[string][]string{"src/app": {"main.go"}},
+			filePath:  filepath.Join(projectRoot, "src", "app", "main.go"),
+			wantOwner: "t",
+			wantOwned: true,
+		},
+		{
+			name:      "manifest key with backslashes",
+			files:     map[string][]string{`src\app\`: {"main.go"}},
+			filePath:  filepath.Join(projectRoot, "src", "app", "main.go"),
+			wantOwner: "t",
+			wantOwned: true,
+		},
+		{
+			name:      "manifest key with leading ./",
+			files:     map[string][]string{"./src/": {"main.go"}},
+			filePath:  filepath.Join(projectRoot, "src", "main.go"),
+			wantOwner: "t",
+			wantOwned: true,
+		},
+		{
+			name:      "different case is not owned on case-sensitive platforms",
+			files:     map[string][]string{"src/": {"Main.go"}},
+			filePath:  filepath.Join(projectRoot, "src", "main.go"),
+			wantOwned: false,
+		},
+		{
+			name:            "different case is owned when case-insensitive",
+			files:           map[string][]string{"SRC/": {"Main.go"}},
+			filePath:        filepath.Join(projectRoot, "src", "main.go"),
+			caseInsensitive: true,
+			wantOwner:       "t",
+			wantOwned:       true,
+		},
+		{
+			name:            "uppercase drive letter in project root",
+			files:           map[string][]string{"src/": {"main.go"}},
+			filePath:        `c:\work\proj\src\main.go`,
+			projectRoot:     `C:\work\proj`,
+			caseInsensitive: true,
+			windowsOnly:     true,
+			wantOwner:       "t",
+			wantOwned:       true,
+		},
+		{
+			name:      "file in another directory",
+			files:     map[string][]string{"src/": {"main.go"}},
+			filePath:  filepath.Join(projectRoot, "main.go"),
+			wantOwned: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.windowsOnly && runtime.GOOS != "windows" {
+				t.Skip("windows-only path semantics")
+			}
+			original := caseInsensitivePaths
+			caseInsensitivePaths = tt.caseInsensitive
+			defer func() { caseInsensitivePaths = original }()
+
+			root := projectRoot
+			if tt.projectRoot != "" {
+				root = tt.projectRoot
+			}
+			lc := &LoomConfig{Threads: []Thread{{Name: "t", Files: tt.files}}}
+			owner, owned := lc.IsFileOwned(tt.filePath, root)
+			if owned != tt.wantOwned || owner != tt.wantOwner {
+				t.Errorf("IsFileOwned(%q) = (%q, %v), want (%q, %v)", tt.filePath, owner, owned, tt.wantOwner, tt.wantOwned)
+			}
+		})
+	}
+}
+
+func TestNormalizeSortsFiles(t *testing.T) {
+	config := LoomConfig{Threads: []Thread{
+		{Name: "b", Files: map[string][]string{"./": {"z.txt", "a.txt", "m.txt"}, "src/": {"y.go", "x.go"}}},
+		{Name: "a", Files: map[string][]string{"./": {"c", "b"}}},
+	}}
+	config.Normalize()
+
+	if got := config.Threads[0].Files["./"]; !reflect.DeepEqual(got, []string{"a.txt", "m.txt", "z.txt"}) {
+		t.Errorf("root files = %v, want sorted", got)
+	}
+	if got := config.Threads[0].Files["src/"]; !reflect.DeepEqual(got, []string{"x.go", "y.go"}) {
+		t.Errorf("src files = %v, want sorted", got)
+	}
+	if config.Threads[0].Name != "b" || config.Threads[1].Name != "a" {
+		t.Errorf("threads were reordered: %s, %s", config.Threads[0].Name, config.Threads[1].Name)
+	}
+}
+
+func TestNormalizeDropsModesOfUnownedFiles(t *testing.T) {
+	config := LoomConfig{Threads: []Thread{{
+		Name:  "a",
+		Files: map[string][]string{"./": {"run.sh"}, "bin/": {"deploy.sh"}},
+		Modes: map[string]string{"run.sh": "0755", "bin/deploy.sh": "0750", "gone.sh": "0755"},
+	}}}
+	config.Normalize()
+
+	want := map[string]string{"run.sh": "0755", "bin/deploy.sh": "0750"}
+	if got := config.Threads[0].Modes; !reflect.DeepEqual(got, want) {
+		t.Errorf("Modes = %v, want %v", got, want)
+	}
+}
+
+func TestNormalizeKeepsModesOfFilesOwnedInAnotherCase(t *testing.T) {
+	original := caseInsensitivePaths
+	caseInsensitivePaths = true
+	defer func() { caseInsensitivePaths = original }()
+
+	config := LoomConfig{Threads: []Thread{{
+		Name:  "a",
+		Files: map[string][]string{"Bin/": {"Deploy.sh"}},
+		Modes: map[string]string{"bin/deploy.sh": "0750"},
+	}}}
+	config.Normalize()
+
+	want := map[string]string{"bin/deploy.sh": "0750"}
+	if got := config.Threads[0].Modes; !reflect.DeepEqual(got, want) {
+		t.Errorf("Modes = %v, want %v", got, want)
+	}
+}
+
+func TestCloneIsIndependent(t *testing.T) {
+	original := LoomConfig{Version: "1", Vars: map[string]string{"APP_NAME": "shop"}, Threads: []Thread{{
+		Name:      "a",
+		DependsOn: []string{"b"},
+		Files:     map[string][]string{"./": {"x.txt"}},
+		Modes:     map[string]string{"x.txt": "0755"},
+	}}}
+	clone := original.Clone()
+	if !reflect.DeepEqual(clone, original) {
+		t.Fatalf("Clone() = %+v, want %+v", clone, original)
+	}
+	clone.Threads[0].Files["./"][0] = "y.txt"
+	clone.Threads[0].Files["src/"] = []string{"z.txt"}
+	clone.Threads[0].Modes["x.txt"] = "0644"
+	clone.Threads[0].DependsOn[0] = "c"
+	clone.Threads[0].Name = "renamed"
+	clone.Vars["APP_NAME"] = "store"
+	if original.Vars["APP_NAME"] != "shop" {
+		t.Errorf("changing the clone's vars changed the original: %v", original.Vars)
+	}
+	want := Thread{Name: "a", DependsOn: []string{"b"}, Files: map[string][]string{"./": {"x.txt"}}, Modes: map[string]string{"x.txt": "0755"}}
+	if !reflect.DeepEqual(original.Threads[0], want) {
+		t.Errorf("changing the clone changed the original: %+v", original.Threads[0])
+	}
+}
+
+func TestRecordedMode(t *testing.T) {
+	thread := Thread{Name: "a", Modes: map[string]string{"run.sh": FormatMode(0o755), "bad.sh": "0999"}}
+	if mode, ok, err := thread.RecordedMode("run.sh"); err != nil || !ok || mode != 0o755 {
+		t.Errorf("RecordedMode(run.sh) = (%o, %v, %v), want (755, true, nil)", mode, ok, err)
+	}
+	if _, ok, err := thread.RecordedMode("other.sh"); err != nil || ok {
+		t.Errorf("RecordedMode(other.sh) = (%v, %v), want no mode", ok, err)
+	}
+	if _, _, err := thread.RecordedMode("bad.sh"); err == nil {
+		t.Error("RecordedMode(bad.sh) succeeded, want an error")
+	}
+}
+
+func TestLocateManifest(t *testing.T) {
+	dir := t.TempDir()
+
+	root, manifest, err := LocateManifest(filepath.Join(dir, "custom.yaml"))
+	if err != nil {
+		t.Fatalf("LocateManifest(file) error: %v", err)
+	}
+	if root != dir || manifest != filepath.Join(dir, "custom.yaml") {
+		t.Errorf("LocateManifest(file) = %q, %q", root, manifest)
+	}
+
+	root, manifest, err = LocateManifest(dir)
+	if err != nil {
+		t.Fatalf("LocateManifest(dir) error: %v", err)
+	}
+	if root != dir || manifest != filepath.Join(dir, YamlFileName) {
+		t.Errorf("LocateManifest(dir) = %q, %q", root, manifest)
+	}
+}
@@ -0,0 +1,123 @@
+package project
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+
+	"loom/internal/core/fileutil"
+	"loom/internal/core/threadconfig"
+)
+
+// convertsLineEndings reports whether lineEndings, a config.yml line_endings value, rewrites files.
+// The empty value of a thread without a config.yml preserves them.
+func convertsLineEndings(lineEndings string) bool {
+	return lineEndings == threadconfig.LineEndingsLF || lineEndings == threadconfig.LineEndingsCRLF
+}
+
+// ConvertLineEndings returns data with every line ending, "\r\n" or "\n", replaced by the one
+// lineEndings asks for. Lone "\r" characters are left alone. data is returned unchanged unless
+// lineEndings is threadconfig.LineEndingsLF or threadconfig.LineEndingsCRLF.
+func ConvertLineEndings(data []byte, lineEndings string) []byte {
+	if !convertsLineEndings(lineEndings) {
+		return data
+	}
+	lf := bytes.ReplaceAll(data, []byte("\r\n"), []byte("\n"))
+	if lineEndings == threadconfig.LineEndingsLF {
+		return lf
+	}
+	return bytes.ReplaceAll(lf, []byte("\n"), []byte("\r\n"))
+}
+
+// InstalledContent returns the contents of the thread source file at path as they are installed
+// with the config.yml line_endings value lineEndings: text files get their line endings converted,
+// while binary files, as judged by IsBinary, are returned as they are.
+func InstalledContent(path, lineEndings string) ([]byte, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	sample := data
+	if len(sample) > binarySniffLen {
+		sample = sample[:binarySniffLen]
+	}
+	if isBinaryContent(sample, len(data) > binarySniffLen) {
+		return data, nil
+	}
+	return ConvertLineEndings(data, lineEndings), nil
+}
+
+// CopyThreadFile copies the thread source file src to dest like fileutil.CopyFile, converting the
+// line endings of text files as InstalledContent does. Without a conversion the file is streamed.
+func CopyThreadFile(src, dest string, mode os.FileMode, lineEndings string) error {
+	if !convertsLineEndings(lineEndings) {
+		return fileutil.CopyFile(src, dest, mode)
+	}
+	data, err := InstalledContent(src, lineEndings)
+	if err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dest, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, mode)
+	if err != nil {
+		return fmt.Errorf("failed to write destination file %s: %w", dest, err)
+	}
+	if _, err := out.Write(data); err != nil {
+		_ = out.Close()
+		return fmt.Errorf("failed to copy %s to %s: %w", src, dest, err)
+	}
+	if err := out.Chmod(mode); err != nil {
+		_ = out.Close()
+		return fmt.Errorf("failed to set the permissions of %s: %w", dest, err)
+	}
+	if err := out.Close(); err != nil {
+		return fmt.Errorf("failed to write destination file %s: %w", dest, err)
+	}
+	return nil
+}
+
+// HashThreadFile returns the hash of the thread source file at path as it is installed, in the
+// format of fileutil.HashFile, so it can be compared with the hash of the installed file.
+func HashThreadFile(path, lineEndings string) (string, error) {
+	if !convertsLineEndings(lineEndings) {
+		return fileutil.HashFile(path)
+	}
+	data, err := InstalledContent(path, lineEndings)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:]), nil
+}
+
+// InstalledMatches reports whether the file at dest already holds what installing the thread source
+// file src with lineEndings would write, comparing bytes so binary files are handled too. A missing
+// dest does not match.
+func InstalledMatches(src, dest, lineEndings string) (bool, error) {
+	destInfo, err := os.Stat(dest)
+	if os.IsNotExist(err) {
+		return false, nil
+	} else if err != nil {
+		return false, fmt.Errorf("failed to stat %s: %w", dest, err)
+	}
+	if !convertsLineEndings(lineEndings) {
+		// Without a conversion the sizes must agree, which spares reading files that differ.
+		srcInfo, err := os.Stat(src)
+		if err != nil {
+			return false, fmt.Errorf("failed to stat %s: %w", src, err)
+		}
+		if srcInfo.Size() != destInfo.Size() {
+			return false, nil
+		}
+	}
+	want, err := InstalledContent(src, lineEndings)
+	if err != nil {
+		return false, err
+	}
+	have, err := os.ReadFile(dest)
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s: %w", dest, err)
+	}
+	return bytes.Equal(want, have), nil
+}
@@ -0,0 +1,113 @@
+package project
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"loom/internal/core/fileutil"
+	"loom/internal/core/threadconfig"
+)
+
+func TestConvertLineEndings(t *testing.T) {
+	mixed := []byte("#!/bin/sh\r\necho hi\nlone\rcr\r\n")
+	tests := []struct {
+		lineEndings string
+		want        string
+	}{
+		{lineEndings: threadconfig.LineEndingsLF, want: "#!/bin/sh\necho hi\nlone\rcr\n"},
+		{lineEndings: threadconfig.LineEndingsCRLF, want: "#!/bin/sh\r\necho hi\r\nlone\rcr\r\n"},
+		{lineEndings: threadconfig.LineEndingsPreserve, want: string(mixed)},
+		{lineEndings: "", want: string(mixed)},
+	}
+	for _, tt := range tests {
+		if got := ConvertLineEndings(mixed, tt.lineEndings); string(got) != tt.want {
+			t.Errorf("ConvertLineEndings(%q) = %q, want %q", tt.lineEndings, got, tt.want)
+		}
+	}
+}
+
+func TestCopyThreadFileConvertsTextOnly(t *testing.T) {
+	dir := t.TempDir()
+	text := filepath.Join(dir, "run.sh")
+	binary := filepath.Join(dir, "logo.png")
+	binaryContent := []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1a, '\n', 0x00, '\r', '\n'}
+	if err := os.WriteFile(text, []byte("#!/bin/sh\r\necho hi\r\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(binary, binaryContent, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	textDest := filepath.Join(dir, "out.sh")
+	if err := CopyThreadFile(text, textDest, 0o644, threadconfig.LineEndingsLF); err != nil {
+		t.Fatalf("CopyThreadFile(text) error = %v", err)
+	}
+	if got, _ := os.ReadFile(textDest); string(got) != "#!/bin/sh\necho hi\n" {
+		t.Errorf("copied text = %q, want LF line endings", got)
+	}
+	if err := os.Chmod(textDest, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := CopyThreadFile(text, textDest, 0o644, threadconfig.LineEndingsLF); err != nil {
+		t.Fatalf("CopyThreadFile(text) over an existing file error = %v", err)
+	}
+	if info, err := os.Stat(textDest); err != nil {
+		t.Fatal(err)
+	} else if runtime.GOOS != "windows" && info.Mode().Perm() != 0o644 {
+		t.Errorf("mode of the rewritten file = %v, want %v", info.Mode().Perm(), os.FileMode(0o644))
+	}
+	sourceHash, err := HashThreadFile(text, threadconfig.LineEndingsLF)
+	if err != nil {
+		t.Fatalf("HashThreadFile() error = %v", err)
+	}
+	if destHash, _ := fileutil.HashFile(textDest); destHash != sourceHash {
+		t.Errorf("HashThreadFile() = %s, want the hash of the installed file %s", sourceHash, destHash)
+	}
+
+	binaryDest := filepath.Join(dir, "out.png")
+	if err := CopyThreadFile(binary, binaryDest, 0o644, threadconfig.LineEndingsLF); err != nil {
+		t.Fatalf("CopyThreadFile(binary) error = %v", err)
+	}
+	if got, _ := os.ReadFile(binaryDest); !bytes.Equal(got, binaryContent) {
+		t.Errorf("copied binary = %q, want it unchanged", got)
+	}
+}
+
+func TestInstalledMatches(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name string, content []byte) string {
+		p := filepath.Join(dir, name)
+		if err := os.WriteFile(p, content, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	src := write("src.txt", []byte("a\r\nb\r\n"))
+	binary := write("logo.png", []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x00})
+	tests := []struct {
+		name        string
+		src         string
+		dest        string
+		lineEndings string
+		want        bool
+	}{
+		{name: "identical", src: src, dest: write("same.txt", []byte("a\r\nb\r\n")), want: true},
+		{name: "different", src: src, dest: write("other.txt", []byte("a\r\nc\r\n")), want: false},
+		{name: "converted", src: src, dest: write("lf.txt", []byte("a\nb\n")), lineEndings: threadconfig.LineEndingsLF, want: true},
+		{name: "unconverted", src: src, dest: filepath.Join(dir, "lf.txt"), want: false},
+		{name: "binary kept as is", src: binary, dest: write("copy.png", []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x00}), lineEndings: threadconfig.LineEndingsLF, want: true},
+		{name: "missing", src: src, dest: filepath.Join(dir, "missing.txt"), want: false},
+	}
+	for _, tt := range tests {
+		got, err := InstalledMatches(tt.src, tt.dest, tt.lineEndings)
+		if err != nil {
+			t.Fatalf("%s: InstalledMatches() error = %v", tt.name, err)
+		}
+		if got != tt.want {
+			t.Errorf("%s: InstalledMatches() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
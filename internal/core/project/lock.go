@@ -0,0 +1,115 @@
+package project
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// LockFileName is the name of the lock file guarding loom.yaml against concurrent loom processes.
+	// A manifest chosen with --config is guarded by its own path plus ".lock" instead.
+	LockFileName = YamlFileName + ".lock"
+	// LockTimeout is how long AcquireLock waits for another loom process to finish.
+	LockTimeout = 10 * time.Second
+	// LockStaleAfter is the age after which a lock held by a live process may be broken with forceStale.
+	LockStaleAfter = 10 * time.Minute
+	// lockPollInterval is how often AcquireLock retries while waiting.
+	lockPollInterval = 100 * time.Millisecond
+)
+
+// Lock is a held loom.yaml lock. Release it when the operation finishes.
+type Lock struct {
+	path string
+}
+
+// lockInfo is the content of a lock file: the holder's PID and when it was acquired.
+type lockInfo struct {
+	pid        int
+	acquiredAt time.Time
+}
+
+// AcquireLock creates the lock file of the manifest at loomConfigPath, loom.yaml.lock for a
+// loom.yaml, waiting up to LockTimeout for another loom process to release it. Locks whose process
+// is no longer running are removed automatically; locks older than LockStaleAfter are removed only
+// when forceStale is true.
+func AcquireLock(loomConfigPath string, forceStale bool) (*Lock, error) {
+	lockPath := loomConfigPath + ".lock"
+	deadline := time.Now().Add(LockTimeout)
+
+	for {
+		file, err := os.OpenFile(lockPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+		if err == nil {
+			_, writeErr := fmt.Fprintf(file, "%d\n%s\n", os.Getpid(), time.Now().UTC().Format(time.RFC3339))
+			closeErr := file.Close()
+			if writeErr != nil || closeErr != nil {
+				_ = os.Remove(lockPath)
+				return nil, fmt.Errorf("failed to write lock file %s: %w", lockPath, errors.Join(writeErr, closeErr))
+			}
+			return &Lock{path: lockPath}, nil
+		}
+		if !os.IsExist(err) {
+			return nil, fmt.Errorf("failed to create lock file %s: %w", lockPath, err)
+		}
+
+		info, readErr := readLockInfo(lockPath)
+		if readErr == nil && isStaleLock(info, forceStale) {
+			fmt.Fprintf(os.Stderr, "Warning: Removing stale lock %s held by process %d since %s.\n", lockPath, info.pid, info.acquiredAt.Format(time.RFC3339))
+			if removeErr := os.Remove(lockPath); removeErr != nil && !os.IsNotExist(removeErr) {
+				return nil, fmt.Errorf("failed to remove stale lock file %s: %w", lockPath, removeErr)
+			}
+			continue
+		}
+
+		if time.Now().After(deadline) {
+			if readErr != nil {
+				return nil, fmt.Errorf("another loom operation is in progress (lock file %s exists); remove it if no other loom process is running", lockPath)
+			}
+			return nil, fmt.Errorf("another loom operation is in progress (process %d has held %s since %s); retry later, or use --force-unlock if the lock is older than %s", info.pid, lockPath, info.acquiredAt.Format(time.RFC3339), LockStaleAfter)
+		}
+		time.Sleep(lockPollInterval)
+	}
+}
+
+// Release removes the lock file. It is safe to call on a nil Lock.
+func (l *Lock) Release() error {
+	if l == nil {
+		return nil
+	}
+	if err := os.Remove(l.path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove lock file %s: %w", l.path, err)
+	}
+	return nil
+}
+
+// readLockInfo parses the PID and acquisition time written by AcquireLock.
+func readLockInfo(lockPath string) (lockInfo, error) {
+	data, err := os.ReadFile(lockPath)
+	if err != nil {
+		return lockInfo{}, err
+	}
+	lines := strings.Fields(string(data))
+	if len(lines) < 2 {
+		return lockInfo{}, fmt.Errorf("malformed lock file %s", lockPath)
+	}
+	pid, err := strconv.Atoi(lines[0])
+	if err != nil {
+		return lockInfo{}, fmt.Errorf("malformed pid in lock file %s: %w", lockPath, err)
+	}
+	acquiredAt, err := time.Parse(time.RFC3339, lines[1])
+	if err != nil {
+		return lockInfo{}, fmt.Errorf("malformed timestamp in lock file %s: %w", lockPath, err)
+	}
+	return lockInfo{pid: pid, acquiredAt: acquiredAt}, nil
+}
+
+// isStaleLock reports whether a lock may be broken: its process is gone, or it is old and forceStale is set.
+func isStaleLock(info lockInfo, forceStale bool) bool {
+	if !processAlive(info.pid) {
+		return true
+	}
+	return forceStale && time.Since(info.acquiredAt) > LockStaleAfter
+}
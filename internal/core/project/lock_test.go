@@ -0,0 +1,53 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestAcquireLockReleasesAndReplacesDeadHolder(t *testing.T) {
+	projectRoot := t.TempDir()
+	lockPath := filepath.Join(projectRoot, LockFileName)
+
+	// A lock left by a process that no longer exists must not block.
+	if err := os.WriteFile(lockPath, []byte("999999999\n2020-01-01T00:00:00Z\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	lock, err := AcquireLock(filepath.Join(projectRoot, YamlFileName), false)
+	if err != nil {
+		t.Fatalf("AcquireLock() error = %v", err)
+	}
+	info, err := readLockInfo(lockPath)
+	if err != nil {
+		t.Fatalf("readLockInfo() error = %v", err)
+	}
+	if info.pid != os.Getpid() {
+		t.Errorf("lock pid = %d, want %d", info.pid, os.Getpid())
+	}
+
+	if err := lock.Release(); err != nil {
+		t.Fatalf("Release() error = %v", err)
+	}
+	if _, err := os.Stat(lockPath); !os.IsNotExist(err) {
+		t.Errorf("lock file still exists after Release, stat err = %v", err)
+	}
+}
+
+func TestAcquireLockNextToCustomManifest(t *testing.T) {
+	projectRoot := t.TempDir()
+	// A held loom.yaml.lock belongs to another manifest and must not block this one.
+	if err := os.WriteFile(filepath.Join(projectRoot, LockFileName), []byte("1\n2020-01-01T00:00:00Z\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	lock, err := AcquireLock(filepath.Join(projectRoot, "other.yaml"), false)
+	if err != nil {
+		t.Fatalf("AcquireLock() error = %v", err)
+	}
+	defer func() { _ = lock.Release() }()
+	if _, err := os.Stat(filepath.Join(projectRoot, "other.yaml.lock")); err != nil {
+		t.Errorf("other.yaml.lock not created: %v", err)
+	}
+}
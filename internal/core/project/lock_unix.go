@@ -0,0 +1,22 @@
+//go:build !windows
+
+package project
+
+import (
+	"os"
+	"syscall"
+)
+
+// processAlive reports whether a process with the given PID is running.
+// Signal 0 performs the existence and permission checks without delivering a signal.
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	err = process.Signal(syscall.Signal(0))
+	return err == nil || err == syscall.EPERM
+}
@@ -0,0 +1,19 @@
+//go:build windows
+
+package project
+
+import "os"
+
+// processAlive reports whether a process with the given PID is running.
+// On Windows, FindProcess opens a handle and fails if the process does not exist.
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	_ = process.Release()
+	return true
+}
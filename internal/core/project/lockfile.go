@@ -0,0 +1,217 @@
+package project
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LockFileVersion is the schema version written to loom.lock.
+const LockFileVersion = "1"
+
+// ResolutionLockFileName is the name of the file, next to loom.yaml, that records what each thread
+// resolved to so `loom weave --locked` can insist on identical files. Not to be confused with
+// LockFileName, which guards loom.yaml against concurrent loom processes.
+const ResolutionLockFileName = "loom.lock"
+
+// LockFile represents the structure of loom.lock.
+type LockFile struct {
+	Version string         `yaml:"version"`
+	Threads []LockedThread `yaml:"threads"`
+}
+
+// LockedThread records how a thread in loom.yaml was resolved when it was last added or woven.
+type LockedThread struct {
+	Name   string `yaml:"name"`
+	Source string `yaml:"source"`          // The thread's source as recorded in loom.yaml
+	Store  string `yaml:"store,omitempty"` // Store the thread resolved from, for store sources
+	// Files maps each project-relative destination path the thread installs to the hash of its
+	// source file, as returned by fileutil.HashFile.
+	Files map[string]string `yaml:"files,omitempty"`
+}
+
+// LockFilePath returns the path of the loom.lock belonging to the loom.yaml at loomConfigPath.
+func LockFilePath(loomConfigPath string) string {
+	return filepath.Join(filepath.Dir(loomConfigPath), ResolutionLockFileName)
+}
+
+// LoadLockFile reads and parses the loom.lock at lockPath. A missing file is reported with an
+// error satisfying os.IsNotExist.
+func LoadLockFile(lockPath string) (*LockFile, error) {
+	data, err := os.ReadFile(lockPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", ResolutionLockFileName, err)
+	}
+	var lock LockFile
+	if err := yaml.Unmarshal(data, &lock); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", ResolutionLockFileName, err)
+	}
+	return &lock, nil
+}
+
+// SaveLockFile writes lock to lockPath with its threads sorted by name, so rewriting it does not
+// produce noisy diffs.
+func SaveLockFile(lockPath string, lock *LockFile) error {
+	lock.Version = LockFileVersion
+	sort.Slice(lock.Threads, func(i, j int) bool { return lock.Threads[i].Name < lock.Threads[j].Name })
+	data, err := yaml.Marshal(lock)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s: %w", ResolutionLockFileName, err)
+	}
+	if err := os.WriteFile(lockPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", ResolutionLockFileName, err)
+	}
+	return nil
+}
+
+// Set adds locked to the lock file, replacing any entry with the same name.
+func (l *LockFile) Set(locked LockedThread) {
+	for i := range l.Threads {
+		if l.Threads[i].Name == locked.Name {
+			l.Threads[i] = locked
+			return
+		}
+	}
+	l.Threads = append(l.Threads, locked)
+}
+
+// Find returns the entry for the thread named name.
+func (l *LockFile) Find(name string) (LockedThread, bool) {
+	for _, locked := range l.Threads {
+		if locked.Name == name {
+			return locked, true
+		}
+	}
+	return LockedThread{}, false
+}
+
+// Retain drops the entries of threads that are no longer in config.
+func (l *LockFile) Retain(config *LoomConfig) {
+	names := make(map[string]bool, len(config.Threads))
+	for _, thread := range config.Threads {
+		names[thread.Name] = true
+	}
+	kept := l.Threads[:0]
+	for _, locked := range l.Threads {
+		if names[locked.Name] {
+			kept = append(kept, locked)
+		}
+	}
+	l.Threads = kept
+}
+
+// PruneLockFile drops the entries of the loom.lock next to the loom.yaml at loomConfigPath that
+// belong to threads no longer in config. Projects without a loom.lock are left alone.
+func PruneLockFile(loomConfigPath string, config *LoomConfig) error {
+	lockPath := LockFilePath(loomConfigPath)
+	lock, err := LoadLockFile(lockPath)
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	lock.Retain(config)
+	return SaveLockFile(lockPath, lock)
+}
+
+// Mismatches lists how current, a freshly computed resolution, differs from l: in sources, stores
+// and file hashes.
+func (l *LockFile) Mismatches(current *LockFile) []string {
+	var mismatches []string
+	for _, now := range current.Threads {
+		locked, ok := l.Find(now.Name)
+		if !ok {
+			mismatches = append(mismatches, fmt.Sprintf("thread '%s' is not in %s", now.Name, ResolutionLockFileName))
+			continue
+		}
+		if locked.Source != now.Source {
+			mismatches = append(mismatches, fmt.Sprintf("thread '%s': source is '%s', locked '%s'", now.Name, now.Source, locked.Source))
+		}
+		if locked.Store != now.Store {
+			mismatches = append(mismatches, fmt.Sprintf("thread '%s': resolves from store '%s', locked '%s'", now.Name, now.Store, locked.Store))
+		}
+		for _, file := range sortedKeys(now.Files) {
+			lockedHash, ok := locked.Files[file]
+			switch {
+			case !ok:
+				mismatches = append(mismatches, fmt.Sprintf("thread '%s': %s is not locked", now.Name, file))
+			case lockedHash != now.Files[file]:
+				mismatches = append(mismatches, fmt.Sprintf("thread '%s': %s has changed", now.Name, file))
+			}
+		}
+		for _, file := range sortedKeys(locked.Files) {
+			if _, ok := now.Files[file]; !ok {
+				mismatches = append(mismatches, fmt.Sprintf("thread '%s': locked file %s is missing from the source", now.Name, file))
+			}
+		}
+	}
+	for _, locked := range l.Threads {
+		if _, ok := current.Find(locked.Name); !ok {
+			mismatches = append(mismatches, fmt.Sprintf("thread '%s' is locked but not in %s", locked.Name, YamlFileName))
+		}
+	}
+	return mismatches
+}
+
+// LockThread records the resolution of thread, whose _thread directory is sourceDir: where it came
+// from and the hash of every file it installs, keyed by project-relative destination path. Like
+// weave, it skips nested project stores and thread sources and the project itself.
+func LockThread(thread *Thread, sourceDir string, projectRoot string) (LockedThread, error) {
+	threadConfig, err := thread.LoadConfig(sourceDir)
+	if err != nil {
+		return LockedThread{}, err
+	}
+	locked := LockedThread{Name: thread.Name, Source: thread.Source, Files: map[string]string{}}
+	if source := ParseSource(thread.Source); source.Kind == SourceKindStore {
+		locked.Store = source.Location
+	}
+
+	err = filepath.Walk(sourceDir, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if filePath != sourceDir && SkipSourceDir(filePath, projectRoot) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		rel, err := filepath.Rel(sourceDir, filePath)
+		if err != nil {
+			return fmt.Errorf("failed to get relative path for %s: %w", filePath, err)
+		}
+		if !threadConfig.Includes(filepath.ToSlash(rel)) {
+			return nil
+		}
+		hash, err := HashThreadFile(filePath, threadConfig.LineEndings)
+		if err != nil {
+			return err
+		}
+		locked.Files[path.Join(thread.Prefix, threadConfig.DestinationFor(filepath.ToSlash(rel)))] = hash
+		return nil
+	})
+	if err != nil {
+		return LockedThread{}, fmt.Errorf("failed to lock thread '%s' (%s): %w", thread.Name, sourceDir, err)
+	}
+	return locked, nil
+}
+
+// sortedKeys returns the keys of m in sorted order.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,79 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLockThread(t *testing.T) {
+	threadDir := filepath.Join(t.TempDir(), "myThread")
+	sourceDir := filepath.Join(threadDir, "_thread")
+	if err := os.MkdirAll(filepath.Join(sourceDir, ".loom", "nested"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(sourceDir, "gitignore"), []byte("build/\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(sourceDir, ".loom", "nested", "skipped.txt"), []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(threadDir, "config.yml"), []byte("map:\n  gitignore: .gitignore\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	thread := &Thread{Name: "myThread", Source: "company", Prefix: "web"}
+	locked, err := LockThread(thread, sourceDir, t.TempDir())
+	if err != nil {
+		t.Fatalf("LockThread() error = %v", err)
+	}
+	if locked.Store != "company" {
+		t.Errorf("LockThread() = %+v, want store 'company'", locked)
+	}
+	if len(locked.Files) != 1 || !strings.HasPrefix(locked.Files["web/.gitignore"], "sha256:") {
+		t.Errorf("Files = %v, want only a hash for web/.gitignore", locked.Files)
+	}
+}
+
+func TestLockFileMismatches(t *testing.T) {
+	locked := &LockFile{Threads: []LockedThread{
+		{Name: "a", Source: "company", Store: "company", Files: map[string]string{"a.txt": "sha256:1", "gone.txt": "sha256:2"}},
+		{Name: "removed", Source: "company"},
+	}}
+	current := &LockFile{Threads: []LockedThread{
+		{Name: "a", Source: "company", Store: "company", Files: map[string]string{"a.txt": "sha256:9", "new.txt": "sha256:3"}},
+		{Name: "added", Source: "other"},
+	}}
+
+	got := strings.Join(locked.Mismatches(current), "\n")
+	for _, want := range []string{"a.txt has changed", "new.txt is not locked", "gone.txt is missing", "'added' is not in loom.lock", "'removed' is locked but not"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("Mismatches() = %q, missing %q", got, want)
+		}
+	}
+	if mismatches := locked.Mismatches(locked); len(mismatches) != 0 {
+		t.Errorf("Mismatches() of a lock with itself = %q, want none", mismatches)
+	}
+}
+
+func TestSaveAndLoadLockFile(t *testing.T) {
+	lockPath := LockFilePath(filepath.Join(t.TempDir(), YamlFileName))
+	if _, err := LoadLockFile(lockPath); !os.IsNotExist(err) {
+		t.Fatalf("LoadLockFile() of a missing file error = %v, want a not-exist error", err)
+	}
+
+	lock := &LockFile{Threads: []LockedThread{{Name: "b", Source: "s"}, {Name: "a", Source: "s"}}}
+	lock.Set(LockedThread{Name: "b", Source: "t"})
+	lock.Retain(&LoomConfig{Threads: []Thread{{Name: "b"}}})
+	if err := SaveLockFile(lockPath, lock); err != nil {
+		t.Fatalf("SaveLockFile() error = %v", err)
+	}
+	loaded, err := LoadLockFile(lockPath)
+	if err != nil {
+		t.Fatalf("LoadLockFile() error = %v", err)
+	}
+	if loaded.Version != LockFileVersion || len(loaded.Threads) != 1 || loaded.Threads[0].Source != "t" {
+		t.Errorf("LoadLockFile() = %+v, want only thread 'b' from source 't'", loaded)
+	}
+}
@@ -0,0 +1,326 @@
+package project
+
+import (
+	"fmt"
+	"os"
+	"sync"
+
+	"loom/internal/core/globalconfig"
+	"loom/internal/core/threadconfig"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ManifestError describes a loom.yaml that parses as YAML but does not have the expected structure.
+// Line and Column locate the offending node.
+type ManifestError struct {
+	Line    int
+	Column  int
+	Message string
+}
+
+func (e *ManifestError) Error() string {
+	return fmt.Sprintf("line %d, column %d: %s", e.Line, e.Column, e.Message)
+}
+
+// ParseLoomConfig parses the contents of a loom.yaml. Beyond YAML syntax, it checks the structure of
+// the manifest, so a hand-edited file with, say, a list where a map belongs is reported with the
+// thread, key and line at fault instead of a bare type error. Unknown keys only produce a warning
+// (see warnUnknownKey), so a manifest written by a newer loom can still be read.
+func ParseLoomConfig(data []byte) (LoomConfig, error) {
+	var config LoomConfig
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return config, err
+	}
+	if len(doc.Content) == 0 {
+		return config, nil // Empty file
+	}
+	if err := validateManifest(doc.Content[0]); err != nil {
+		return config, err
+	}
+	if err := doc.Decode(&config); err != nil {
+		return config, err
+	}
+	return config, nil
+}
+
+// validateManifest checks the root node of a loom.yaml document.
+func validateManifest(root *yaml.Node) error {
+	root = resolveAlias(root)
+	if root.Kind == yaml.ScalarNode && root.Tag == "!!null" {
+		return nil
+	}
+	if root.Kind != yaml.MappingNode {
+		return manifestErrorf(root, "expected a map with 'version' and 'threads' keys, found %s", describeNode(root))
+	}
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		key, value := root.Content[i], resolveAlias(root.Content[i+1])
+		switch key.Value {
+		case "version":
+			if err := expectScalar(value, "'version'"); err != nil {
+				return err
+			}
+		case "threads":
+			if isNull(value) {
+				continue
+			}
+			if value.Kind != yaml.SequenceNode {
+				return manifestErrorf(value, "'threads' must be a list of threads, found %s", describeNode(value))
+			}
+			seen := make(map[string]int) // Line of each thread name's first entry
+			for index, thread := range value.Content {
+				thread = resolveAlias(thread)
+				if err := validateManifestThread(thread, index); err != nil {
+					return err
+				}
+				// Threads are looked up by name, so a second entry would be silently shadowed.
+				name := mappingValue(thread, "name")
+				if firstLine, ok := seen[name.Value]; ok {
+					return manifestErrorf(name, "thread '%s' is listed more than once (first at line %d); rename or merge the entries", name.Value, firstLine)
+				}
+				seen[name.Value] = name.Line
+			}
+		case "stores":
+			if isNull(value) {
+				continue
+			}
+			if value.Kind != yaml.SequenceNode {
+				return manifestErrorf(value, "'stores' must be a list of stores, found %s", describeNode(value))
+			}
+			for index, store := range value.Content {
+				if err := validateManifestStore(resolveAlias(store), index); err != nil {
+					return err
+				}
+			}
+		case "vars":
+			if err := expectMap(value, "'vars'", "a map of variable names to values"); err != nil {
+				return err
+			}
+			for j := 1; j < len(value.Content); j += 2 {
+				name := value.Content[j-1]
+				if !varNamePattern.MatchString(name.Value) {
+					return manifestErrorf(name, "invalid variable name '%s' in 'vars': use letters, digits and underscores, not starting with a digit", name.Value)
+				}
+				if err := expectScalar(resolveAlias(value.Content[j]), fmt.Sprintf("variable '%s' in 'vars'", name.Value)); err != nil {
+					return err
+				}
+			}
+		default:
+			warnUnknownKey(key, "", "expected 'version', 'stores', 'vars' or 'threads'")
+		}
+	}
+	return nil
+}
+
+// validateManifestThread checks the entry at index in the threads list.
+func validateManifestThread(thread *yaml.Node, index int) error {
+	label := fmt.Sprintf("thread #%d", index+1)
+	if thread.Kind != yaml.MappingNode {
+		return manifestErrorf(thread, "%s must be a map with 'name' and 'source' keys, found %s", label, describeNode(thread))
+	}
+	for i := 0; i+1 < len(thread.Content); i += 2 {
+		if key, value := thread.Content[i], resolveAlias(thread.Content[i+1]); key.Value == "name" && value.Kind == yaml.ScalarNode && value.Value != "" {
+			label = fmt.Sprintf("thread '%s'", value.Value)
+		}
+	}
+
+	hasName := false
+	for i := 0; i+1 < len(thread.Content); i += 2 {
+		key, value := thread.Content[i], resolveAlias(thread.Content[i+1])
+		field := fmt.Sprintf("%s: '%s'", label, key.Value)
+		switch key.Value {
+		case "name":
+			if err := expectScalar(value, field); err != nil {
+				return err
+			}
+			hasName = value.Value != ""
+		case "source", "prefix", "installed_at", "updated_at":
+			if err := expectScalar(value, field); err != nil {
+				return err
+			}
+		case "depends_on":
+			if err := expectList(value, field, "a list of thread names"); err != nil {
+				return err
+			}
+		case "dotfiles":
+			if err := expectScalar(value, field); err != nil {
+				return err
+			}
+			if value.Value != threadconfig.DotfilesInclude && value.Value != threadconfig.DotfilesExclude {
+				return manifestErrorf(value, "%s must be '%s' or '%s', found '%s'", field, threadconfig.DotfilesInclude, threadconfig.DotfilesExclude, value.Value)
+			}
+		case "files":
+			if err := expectMap(value, field, "a map of directories to lists of file names"); err != nil {
+				return err
+			}
+			for j := 1; j < len(value.Content); j += 2 {
+				dir := value.Content[j-1].Value
+				if err := expectList(resolveAlias(value.Content[j]), fmt.Sprintf("%s: directory '%s' in 'files'", label, dir), "a list of file names"); err != nil {
+					return err
+				}
+			}
+		case "modes":
+			if err := expectMap(value, field, "a map of file paths to octal modes"); err != nil {
+				return err
+			}
+			for j := 1; j < len(value.Content); j += 2 {
+				if err := expectScalar(resolveAlias(value.Content[j]), fmt.Sprintf("%s: mode of '%s'", label, value.Content[j-1].Value)); err != nil {
+					return err
+				}
+			}
+		default:
+			warnUnknownKey(key, label, "")
+		}
+	}
+	if !hasName {
+		return manifestErrorf(thread, "%s has no 'name'", label)
+	}
+	return nil
+}
+
+// validateManifestStore checks the entry at index in the stores list, which takes the keys of a
+// store in the global configuration.
+func validateManifestStore(store *yaml.Node, index int) error {
+	label := fmt.Sprintf("store #%d", index+1)
+	if store.Kind != yaml.MappingNode {
+		return manifestErrorf(store, "%s must be a map with 'name', 'type' and 'path' keys, found %s", label, describeNode(store))
+	}
+	if name := mappingValue(store, "name"); name != nil && name.Kind == yaml.ScalarNode && name.Value != "" {
+		label = fmt.Sprintf("store '%s'", name.Value)
+	}
+	for i := 0; i+1 < len(store.Content); i += 2 {
+		key, value := store.Content[i], resolveAlias(store.Content[i+1])
+		field := fmt.Sprintf("%s: '%s'", label, key.Value)
+		switch key.Value {
+		case "name", "path", "priority", "ref", "checkout", "read_only":
+			if err := expectScalar(value, field); err != nil {
+				return err
+			}
+		case "token_env":
+			if err := expectScalar(value, field); err != nil {
+				return err
+			}
+			if err := globalconfig.ValidateTokenEnv(value.Value); err != nil {
+				return manifestErrorf(value, "%s: %v", label, err)
+			}
+		case "type":
+			if err := expectScalar(value, field); err != nil {
+				return err
+			}
+			if err := globalconfig.ValidateStoreType(value.Value); err != nil {
+				return manifestErrorf(value, "%s: %v", label, err)
+			}
+		default:
+			warnUnknownKey(key, label, "")
+		}
+	}
+	for _, required := range []string{"name", "type", "path"} {
+		if value := mappingValue(store, required); value == nil || value.Value == "" {
+			return manifestErrorf(store, "%s has no '%s'", label, required)
+		}
+	}
+	return nil
+}
+
+// warnedUnknownKeys holds the unknown-key warnings already printed, since a command may read
+// loom.yaml more than once.
+var warnedUnknownKeys sync.Map
+
+// warnUnknownKey prints a warning, once per command, that the loom.yaml key node is not known and is
+// ignored. It is most likely a typo, or was written by a newer loom; either way it is dropped when
+// loom.yaml is rewritten. label names the thread or store holding the key, if any, and hint, if not
+// empty, the keys expected instead.
+func warnUnknownKey(key *yaml.Node, label, hint string) {
+	if label != "" {
+		label += ": "
+	}
+	if hint != "" {
+		hint = " (" + hint + ")"
+	}
+	message := fmt.Sprintf("Warning: %s line %d, column %d: %sunknown key '%s'%s is ignored and will be dropped when %s is rewritten\n", YamlFileName, key.Line, key.Column, label, key.Value, hint, YamlFileName)
+	if _, printed := warnedUnknownKeys.LoadOrStore(message, true); !printed {
+		fmt.Fprint(os.Stderr, message)
+	}
+}
+
+// mappingValue returns the value of key in the map node, or nil if it has no such key.
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return resolveAlias(node.Content[i+1])
+		}
+	}
+	return nil
+}
+
+// expectScalar reports an error naming what if node is a map or list.
+func expectScalar(node *yaml.Node, what string) error {
+	if node.Kind != yaml.ScalarNode {
+		return manifestErrorf(node, "%s must be a single value, found %s", what, describeNode(node))
+	}
+	return nil
+}
+
+// expectList reports an error naming what unless node is null or a list of single values.
+func expectList(node *yaml.Node, what, expected string) error {
+	if isNull(node) {
+		return nil
+	}
+	if node.Kind != yaml.SequenceNode {
+		return manifestErrorf(node, "%s must be %s, found %s", what, expected, describeNode(node))
+	}
+	for _, item := range node.Content {
+		if item = resolveAlias(item); item.Kind != yaml.ScalarNode {
+			return manifestErrorf(item, "%s must be %s, found %s in the list", what, expected, describeNode(item))
+		}
+	}
+	return nil
+}
+
+// expectMap reports an error naming what unless node is null or a map with single-value keys.
+func expectMap(node *yaml.Node, what, expected string) error {
+	if isNull(node) {
+		return nil
+	}
+	if node.Kind != yaml.MappingNode {
+		return manifestErrorf(node, "%s must be %s, found %s", what, expected, describeNode(node))
+	}
+	for i := 0; i < len(node.Content); i += 2 {
+		if key := node.Content[i]; key.Kind != yaml.ScalarNode {
+			return manifestErrorf(key, "%s must be %s, found %s as a key", what, expected, describeNode(key))
+		}
+	}
+	return nil
+}
+
+// isNull reports whether node is an explicit or empty null value.
+func isNull(node *yaml.Node) bool {
+	return node.Kind == yaml.ScalarNode && node.Tag == "!!null"
+}
+
+// resolveAlias returns the node an alias refers to, or node itself.
+func resolveAlias(node *yaml.Node) *yaml.Node {
+	for node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+	return node
+}
+
+// describeNode names the kind of node for error messages.
+func describeNode(node *yaml.Node) string {
+	switch node.Kind {
+	case yaml.MappingNode:
+		return "a map"
+	case yaml.SequenceNode:
+		return "a list"
+	case yaml.ScalarNode:
+		return fmt.Sprintf("the value '%s'", node.Value)
+	}
+	return "an unexpected value"
+}
+
+// manifestErrorf returns a ManifestError located at node.
+func manifestErrorf(node *yaml.Node, format string, args ...any) error {
+	return &ManifestError{Line: node.Line, Column: node.Column, Message: fmt.Sprintf(format, args...)}
+}
@@ -0,0 +1,162 @@
+package project
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestParseLoomConfig(t *testing.T) {
+	valid := "version: \"1\"\nthreads:\n  - name: go-ci\n    source: store/shared\n    files:\n      .github/:\n        - ci.yml\n    modes:\n      run.sh: \"0755\"\n"
+	config, err := ParseLoomConfig([]byte(valid))
+	if err != nil {
+		t.Fatalf("ParseLoomConfig() error = %v", err)
+	}
+	if len(config.Threads) != 1 || config.Threads[0].Files[".github/"][0] != "ci.yml" || config.Threads[0].Modes["run.sh"] != "0755" {
+		t.Errorf("ParseLoomConfig() = %+v, want the thread with its files and modes", config)
+	}
+	if _, err := ParseLoomConfig(nil); err != nil {
+		t.Errorf("ParseLoomConfig(empty) error = %v, want none", err)
+	}
+
+	tests := []struct {
+		name    string
+		content string
+		line    int
+		message string
+	}{
+		{
+			name:    "files as a list",
+			content: "version: \"1\"\nthreads:\n  - name: go-ci\n    source: project\n    files:\n      - ci.yml\n",
+			line:    6,
+			message: "thread 'go-ci': 'files' must be a map of directories to lists of file names, found a list",
+		},
+		{
+			name:    "file list as a single value",
+			content: "threads:\n  - name: go-ci\n    files:\n      ./: ci.yml\n",
+			line:    4,
+			message: "thread 'go-ci': directory './' in 'files' must be a list of file names, found the value 'ci.yml'",
+		},
+		{
+			name:    "unknown dotfiles setting",
+			content: "threads:\n  - name: go-ci\n    dotfiles: hidden\n",
+			line:    3,
+			message: "thread 'go-ci': 'dotfiles' must be 'include' or 'exclude', found 'hidden'",
+		},
+		{
+			name:    "thread without a name",
+			content: "threads:\n  - name: a\n  - source: project\n",
+			line:    3,
+			message: "thread #2 has no 'name'",
+		},
+		{
+			name:    "duplicate thread name",
+			content: "threads:\n  - name: go-ci\n    source: project\n  - name: go-ci\n    source: store/shared\n",
+			line:    4,
+			message: "thread 'go-ci' is listed more than once (first at line 2)",
+		},
+		{
+			name:    "threads as a map",
+			content: "threads:\n  go-ci: project\n",
+			line:    2,
+			message: "'threads' must be a list of threads, found a map",
+		},
+		{
+			name:    "store with an unknown type",
+			content: "stores:\n  - name: team\n    type: ftp\n    path: stores/team\n",
+			line:    3,
+			message: "store 'team': unknown store type \"ftp\"",
+		},
+		{
+			name:    "store without a path",
+			content: "stores:\n  - name: team\n    type: local\n",
+			line:    2,
+			message: "store 'team' has no 'path'",
+		},
+		{
+			name:    "store with a token instead of a variable name",
+			content: "stores:\n  - name: team\n    type: github\n    path: acme/threads\n    token_env: ghp-0123\n",
+			line:    5,
+			message: "store 'team': invalid token environment variable name \"ghp-0123\"",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseLoomConfig([]byte(tt.content))
+			var manifestErr *ManifestError
+			if !errors.As(err, &manifestErr) {
+				t.Fatalf("ParseLoomConfig() error = %v, want a *ManifestError", err)
+			}
+			if manifestErr.Line != tt.line || !strings.Contains(manifestErr.Message, tt.message) {
+				t.Errorf("ParseLoomConfig() error = %v, want line %d and %q", err, tt.line, tt.message)
+			}
+		})
+	}
+}
+
+func TestParseLoomConfigWithoutTimestamps(t *testing.T) {
+	// A loom.yaml written before threads recorded installed_at and updated_at.
+	legacy := "version: \"1\"\nthreads:\n  - name: go-ci\n    source: store/shared\n    files:\n      .github/:\n        - ci.yml\n"
+	config, err := ParseLoomConfig([]byte(legacy))
+	if err != nil {
+		t.Fatalf("ParseLoomConfig() error = %v", err)
+	}
+	if config.Threads[0].InstalledAt != "" || config.Threads[0].UpdatedAt != "" {
+		t.Errorf("ParseLoomConfig() thread = %+v, want no timestamps", config.Threads[0])
+	}
+	config.Normalize()
+	written, err := yaml.Marshal(&config)
+	if err != nil {
+		t.Fatalf("yaml.Marshal() error = %v", err)
+	}
+	if strings.Contains(string(written), "installed_at") || strings.Contains(string(written), "updated_at") {
+		t.Errorf("rewritten loom.yaml =\n%s\nwant no timestamps added", written)
+	}
+	reparsed, err := ParseLoomConfig(written)
+	if err != nil {
+		t.Fatalf("ParseLoomConfig(rewritten) error = %v", err)
+	}
+	rewritten, err := yaml.Marshal(&reparsed)
+	if err != nil {
+		t.Fatalf("yaml.Marshal() error = %v", err)
+	}
+	if string(rewritten) != string(written) {
+		t.Errorf("loom.yaml changed on the second round trip:\n%s\nwant\n%s", rewritten, written)
+	}
+}
+
+func TestParseLoomConfigIgnoresUnknownKeys(t *testing.T) {
+	content := "version: \"1\"\nthread: []\nthreads:\n  - name: go-ci\n    sorce: project\n    source: store/shared\nstores:\n  - name: shared\n    type: local\n    path: /srv/threads\n    mirror: true\n"
+	config, err := ParseLoomConfig([]byte(content))
+	if err != nil {
+		t.Fatalf("ParseLoomConfig() error = %v, want unknown keys to be ignored", err)
+	}
+	if len(config.Threads) != 1 || config.Threads[0].Source != "store/shared" || len(config.Stores) != 1 || config.Stores[0].Path != "/srv/threads" {
+		t.Errorf("ParseLoomConfig() = %+v, want the known keys decoded", config)
+	}
+	for _, message := range []string{
+		"line 2, column 1: unknown key 'thread'",
+		"line 5, column 5: thread 'go-ci': unknown key 'sorce'",
+		"line 11, column 5: store 'shared': unknown key 'mirror'",
+	} {
+		if _, warned := warnedUnknownKeys.Load(warningFor(t, message)); !warned {
+			t.Errorf("no warning containing %q was printed", message)
+		}
+	}
+}
+
+// warningFor returns the printed unknown-key warning containing part.
+func warningFor(t *testing.T, part string) string {
+	t.Helper()
+	found := ""
+	warnedUnknownKeys.Range(func(key, _ any) bool {
+		if strings.Contains(key.(string), part) {
+			found = key.(string)
+			return false
+		}
+		return true
+	})
+	return found
+}
@@ -0,0 +1,196 @@
+package project
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// ProjectStoreDirName is the name of the project-local thread store directory.
+const ProjectStoreDirName = ".loom"
+
+// BackupsDirName is the directory in the project root that holds one backup tree per weave. It is
+// kept out of the project store so it can never be mistaken for a thread.
+const BackupsDirName = ".loom-backups"
+
+// threadSourceDirName is the directory inside a thread that holds the files it installs.
+const threadSourceDirName = "_thread"
+
+// Source kinds recorded in a thread's Source field.
+const (
+	// SourceKindProject is a thread in the project's .loom store, encoded as "project:.loom/<name>".
+	SourceKindProject = "project"
+	// SourceKindPath is a thread added from an arbitrary directory, encoded as "path:<absolute dir>".
+	SourceKindPath = "path"
+	// SourceKindGit is a thread fetched from a git repository, encoded as "git:<url>". Not resolvable yet.
+	SourceKindGit = "git"
+	// SourceKindStore is a thread from a configured global store, encoded as the bare store name, or as
+	// "<store>/<thread>" when the thread is recorded under a different name (loom add --rename) or is
+	// nested in category directories ("<store>/frontend/button").
+	SourceKindStore = "store"
+)
+
+// ThreadSource is the decoded form of Thread.Source.
+type ThreadSource struct {
+	Kind string
+	// Location is the project-relative thread directory (project), the absolute thread directory (path),
+	// the repository URL (git), or the store name (store).
+	Location string
+	// Thread is the thread's slash-separated path in its store when it differs from the name recorded
+	// in loom.yaml. Only store sources use it; the other kinds point at the thread directory directly.
+	Thread string
+}
+
+// ProjectSource returns the source of a thread stored in the project's .loom directory.
+func ProjectSource(threadName string) ThreadSource {
+	return ThreadSource{Kind: SourceKindProject, Location: path.Join(ProjectStoreDirName, threadName)}
+}
+
+// EncodeSource returns the string stored in loom.yaml for source.
+func EncodeSource(source ThreadSource) string {
+	if source.Kind == SourceKindStore {
+		// Store sources predate source prefixes and are kept as bare names for compatibility.
+		if source.Thread != "" {
+			return source.Location + "/" + source.Thread
+		}
+		return source.Location
+	}
+	return source.Kind + ":" + source.Location
+}
+
+// ParseSource decodes a Thread.Source string. Unprefixed values, and the legacy "local:" prefix, are store names.
+func ParseSource(encoded string) ThreadSource {
+	for _, kind := range []string{SourceKindProject, SourceKindPath, SourceKindGit} {
+		if location, ok := strings.CutPrefix(encoded, kind+":"); ok {
+			return ThreadSource{Kind: kind, Location: location}
+		}
+	}
+	location := encoded
+	if legacy, ok := strings.CutPrefix(encoded, "local:"); ok {
+		location = legacy
+	}
+	// Store names cannot contain "/", so anything after the first one is the thread's name in the store.
+	storeName, threadName, _ := strings.Cut(location, "/")
+	return ThreadSource{Kind: SourceKindStore, Location: storeName, Thread: threadName}
+}
+
+// warnedLegacySources holds the legacy sources already warned about, so each is reported once.
+var warnedLegacySources sync.Map
+
+// ResolveSource returns the absolute path of the _thread directory for a thread named threadName in
+// loom.yaml. lookupStorePath maps a store name to its filesystem path; it is only called for store sources.
+// Manifests written before sources were encoded may name something that is not a store; when the
+// store cannot be found but .loom/<threadName>/_thread exists, that directory is used with a warning.
+func ResolveSource(source ThreadSource, threadName string, projectRoot string, lookupStorePath func(storeName string) (string, error)) (string, error) {
+	switch source.Kind {
+	case SourceKindProject:
+		return filepath.Join(projectRoot, filepath.FromSlash(source.Location), threadSourceDirName), nil
+	case SourceKindPath:
+		return filepath.Join(filepath.FromSlash(source.Location), threadSourceDirName), nil
+	case SourceKindStore:
+		storePath, err := lookupStorePath(source.Location)
+		if err != nil {
+			if ValidateName("thread", threadName) != nil {
+				return "", err
+			}
+			legacyPath := filepath.Join(projectRoot, ProjectStoreDirName, threadName, threadSourceDirName)
+			if info, statErr := os.Stat(legacyPath); statErr != nil || !info.IsDir() {
+				return "", err
+			}
+			projectSource := ThreadSource{Kind: SourceKindProject, Location: path.Join(ProjectStoreDirName, threadName)}
+			if _, warned := warnedLegacySources.LoadOrStore(threadName, true); !warned {
+				fmt.Fprintf(os.Stderr, "Warning: thread '%s': %v; using %s instead. Set its source to '%s' in %s to silence this warning.\n",
+					threadName, err, projectSource.Location, EncodeSource(projectSource), YamlFileName)
+			}
+			return legacyPath, nil
+		}
+		if source.Thread != "" {
+			// Hand-edited loom.yaml files must not point outside the store.
+			if err := ValidateThreadPath(source.Thread); err != nil {
+				return "", err
+			}
+			threadName = source.Thread
+		}
+		return filepath.Join(storePath, filepath.FromSlash(threadName), threadSourceDirName), nil
+	default:
+		return "", fmt.Errorf("%s sources are not supported yet", source.Kind)
+	}
+}
+
+// SkipSourceDir reports whether walking a thread source must not descend into the directory at
+// dirPath: a project store, a nested thread source, or the project itself (when the project lies
+// inside the thread source), so a thread can't copy its own manifest or source tree. Weaving,
+// locking and every other walk over a thread's files use it, so they agree on what a thread installs.
+func SkipSourceDir(dirPath string, projectRoot string) bool {
+	name := filepath.Base(dirPath)
+	return name == ProjectStoreDirName || name == threadSourceDirName ||
+		(PathContains(dirPath, projectRoot) && PathContains(projectRoot, dirPath))
+}
+
+// PathContains reports whether child is parent itself or lies inside it. Both paths are made
+// absolute and, where they exist, have symlinks resolved so different spellings of the same
+// directory compare equal.
+func PathContains(parent, child string) bool {
+	parent, child = canonicalPath(parent), canonicalPath(child)
+	if caseInsensitivePaths {
+		parent, child = strings.ToLower(parent), strings.ToLower(child)
+	}
+	rel, err := filepath.Rel(parent, child)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// canonicalPath returns p as an absolute path with symlinks resolved, falling back to the
+// lexically cleaned absolute path when p does not exist.
+func canonicalPath(p string) string {
+	absPath, err := filepath.Abs(p)
+	if err != nil {
+		absPath = filepath.Clean(p)
+	}
+	if resolved, err := filepath.EvalSymlinks(absPath); err == nil {
+		return resolved
+	}
+	return absPath
+}
+
+// ValidateName checks that a thread or store name is safe to use as a single path element, so joining
+// it onto a store or project path can never escape that directory. kind ("thread" or "store") is
+// used in the error message.
+func ValidateName(kind, name string) error {
+	if name == "" {
+		return fmt.Errorf("%s name must not be empty", kind)
+	}
+	if strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("invalid %s name '%s': must not contain '/' or '\\'", kind, name)
+	}
+	if strings.Contains(name, "..") || name == "." {
+		return fmt.Errorf("invalid %s name '%s': must not be '.' or contain '..'", kind, name)
+	}
+	for _, r := range name {
+		if unicode.IsControl(r) {
+			return fmt.Errorf("invalid %s name %q: must not contain control characters", kind, name)
+		}
+	}
+	return nil
+}
+
+// ValidateThreadPath checks a thread's path within a store, which may nest the thread in category
+// directories ("frontend/button"). Every slash-separated segment must be a valid thread name, so
+// joining the path onto the store can never escape it.
+func ValidateThreadPath(threadPath string) error {
+	if threadPath == "" {
+		return fmt.Errorf("thread name must not be empty")
+	}
+	for _, segment := range strings.Split(threadPath, "/") {
+		if err := ValidateName("thread", segment); err != nil {
+			return fmt.Errorf("invalid thread path '%s': %w", threadPath, err)
+		}
+	}
+	return nil
+}
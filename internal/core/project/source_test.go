@@ -0,0 +1,161 @@
+package project
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSourceRoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  ThreadSource
+		encoded string
+	}{
+		{name: "project", source: ProjectSource("api"), encoded: "project:.loom/api"},
+		{name: "path", source: ThreadSource{Kind: SourceKindPath, Location: "/home/me/threads/api"}, encoded: "path:/home/me/threads/api"},
+		{name: "store", source: ThreadSource{Kind: SourceKindStore, Location: "myStore"}, encoded: "myStore"},
+		{name: "renamed store thread", source: ThreadSource{Kind: SourceKindStore, Location: "myStore", Thread: "base"}, encoded: "myStore/base"},
+		{name: "git", source: ThreadSource{Kind: SourceKindGit, Location: "https://github.com/org/threads.git"}, encoded: "git:https://github.com/org/threads.git"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := EncodeSource(tt.source); got != tt.encoded {
+				t.Errorf("EncodeSource() = %q, want %q", got, tt.encoded)
+			}
+			if got := ParseSource(tt.encoded); got != tt.source {
+				t.Errorf("ParseSource(%q) = %+v, want %+v", tt.encoded, got, tt.source)
+			}
+		})
+	}
+}
+
+func TestParseSourceLegacyLocalPrefix(t *testing.T) {
+	want := ThreadSource{Kind: SourceKindStore, Location: "myStore"}
+	if got := ParseSource("local:myStore"); got != want {
+		t.Errorf("ParseSource(\"local:myStore\") = %+v, want %+v", got, want)
+	}
+}
+
+func TestResolveSource(t *testing.T) {
+	projectRoot := filepath.Join(string(filepath.Separator), "work", "proj")
+	storeRoot := filepath.Join(string(filepath.Separator), "stores", "company")
+	lookup := func(storeName string) (string, error) {
+		if storeName == "company" {
+			return storeRoot, nil
+		}
+		return "", fmt.Errorf("store '%s' not found", storeName)
+	}
+
+	tests := []struct {
+		name    string
+		source  string
+		want    string
+		wantErr bool
+	}{
+		{name: "project", source: "project:.loom/api", want: filepath.Join(projectRoot, ".loom", "api", "_thread")},
+		{name: "store", source: "company", want: filepath.Join(storeRoot, "api", "_thread")},
+		{name: "renamed store thread", source: "company/base", want: filepath.Join(storeRoot, "base", "_thread")},
+		{name: "nested store thread", source: "company/frontend/button", want: filepath.Join(storeRoot, "frontend", "button", "_thread")},
+		{name: "store thread outside the store", source: "company/frontend/../../etc", wantErr: true},
+		{name: "unknown store", source: "missing", wantErr: true},
+		{name: "git", source: "git:https://example.com/threads.git", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ResolveSource(ParseSource(tt.source), "api", projectRoot, lookup)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ResolveSource() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("ResolveSource() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveSourceFallsBackToProjectStore(t *testing.T) {
+	projectRoot := t.TempDir()
+	legacyDir := filepath.Join(projectRoot, ".loom", "away", "_thread")
+	if err := os.MkdirAll(legacyDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	lookup := func(storeName string) (string, error) {
+		return "", fmt.Errorf("store '%s' not found", storeName)
+	}
+
+	got, err := ResolveSource(ParseSource("loom"), "away", projectRoot, lookup)
+	if err != nil || got != legacyDir {
+		t.Errorf("ResolveSource(legacy) = (%q, %v), want (%q, nil)", got, err, legacyDir)
+	}
+	if _, err := ResolveSource(ParseSource("loom"), "other", projectRoot, lookup); err == nil {
+		t.Error("ResolveSource() without a .loom directory succeeded, want the store error")
+	}
+}
+
+func TestPathContains(t *testing.T) {
+	root := t.TempDir()
+	project := filepath.Join(root, "proj")
+	tests := []struct {
+		name          string
+		parent, child string
+		want          bool
+	}{
+		{name: "same directory", parent: project, child: project, want: true},
+		{name: "parent of the project", parent: root, child: project, want: true},
+		{name: "nested child", parent: project, child: filepath.Join(project, "a", "b"), want: true},
+		{name: "child of the project", parent: filepath.Join(project, "a"), child: project, want: false},
+		{name: "sibling sharing a name prefix", parent: project, child: project + "-other", want: false},
+		{name: "unclean spelling", parent: filepath.Join(project, "a", ".."), child: project, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := PathContains(tt.parent, tt.child); got != tt.want {
+				t.Errorf("PathContains(%q, %q) = %v, want %v", tt.parent, tt.child, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSkipSourceDir(t *testing.T) {
+	source := filepath.Join(t.TempDir(), "_thread")
+	projectRoot := filepath.Join(source, "app")
+	for dir, want := range map[string]bool{
+		filepath.Join(source, ".loom"):             true,
+		filepath.Join(source, "nested", "_thread"): true,
+		projectRoot:                       true,
+		filepath.Join(source, "config"):   false,
+		filepath.Join(projectRoot, "src"): false,
+	} {
+		if got := SkipSourceDir(dir, projectRoot); got != want {
+			t.Errorf("SkipSourceDir(%q) = %v, want %v", dir, got, want)
+		}
+	}
+}
+
+func TestValidateName(t *testing.T) {
+	for _, name := range []string{"go-ci", "my_thread", "v1.2", ".hidden"} {
+		if err := ValidateName("thread", name); err != nil {
+			t.Errorf("ValidateName(%q) = %v, want nil", name, err)
+		}
+	}
+	for _, name := range []string{"", ".", "..", "../escape", "a/b", `a\b`, "a..b", "tab\there", "nul\x00"} {
+		if err := ValidateName("thread", name); err == nil {
+			t.Errorf("ValidateName(%q) = nil, want error", name)
+		}
+	}
+}
+
+func TestValidateThreadPath(t *testing.T) {
+	for _, threadPath := range []string{"go-ci", "frontend/button", "a/b/c"} {
+		if err := ValidateThreadPath(threadPath); err != nil {
+			t.Errorf("ValidateThreadPath(%q) = %v, want nil", threadPath, err)
+		}
+	}
+	for _, threadPath := range []string{"", "/abs", "trailing/", "a//b", "a/../b", "..", "a\b"} {
+		if err := ValidateThreadPath(threadPath); err == nil {
+			t.Errorf("ValidateThreadPath(%q) = nil, want error", threadPath)
+		}
+	}
+}
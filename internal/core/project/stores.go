@@ -0,0 +1,55 @@
+package project
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"loom/internal/core/globalconfig"
+)
+
+// ProjectStores returns the stores declared in lc for the project at projectRoot, with relative
+// local store paths resolved against projectRoot rather than $LOOM_HOME, so a store checked into
+// the repository works wherever it is cloned.
+func (lc *LoomConfig) ProjectStores(projectRoot string) []globalconfig.Store {
+	stores := make([]globalconfig.Store, 0, len(lc.Stores))
+	for _, store := range lc.Stores {
+		store.RawPath = store.Path
+		if store.Type == globalconfig.StoreTypeLocal && !filepath.IsAbs(store.Path) {
+			store.Path = filepath.Join(projectRoot, filepath.FromSlash(store.Path))
+		}
+		stores = append(stores, store)
+	}
+	return stores
+}
+
+// LoadStoreConfig loads the global configuration and adds the stores declared by the loom.yaml at
+// loomConfigPath, which take precedence (see StoreConfig). Without that file, the global
+// configuration is returned as it is.
+func LoadStoreConfig(loomConfigPath string) (*globalconfig.GlobalLoomConfig, error) {
+	data, err := os.ReadFile(loomConfigPath)
+	if os.IsNotExist(err) {
+		return StoreConfig(filepath.Dir(loomConfigPath), nil)
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", YamlFileName, err)
+	}
+	loomConfig, err := ParseLoomConfig(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", YamlFileName, err)
+	}
+	return StoreConfig(filepath.Dir(loomConfigPath), &loomConfig)
+}
+
+// StoreConfig loads the global configuration and adds the stores declared by loomConfig, the
+// already parsed loom.yaml of the project at projectRoot, which take precedence (see
+// globalconfig.GlobalLoomConfig.WithProjectStores). A nil loomConfig adds none.
+func StoreConfig(projectRoot string, loomConfig *LoomConfig) (*globalconfig.GlobalLoomConfig, error) {
+	gConf, err := globalconfig.LoadGlobalConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load global loom configuration: %w", err)
+	}
+	if loomConfig == nil || len(loomConfig.Stores) == 0 {
+		return gConf, nil
+	}
+	return gConf.WithProjectStores(loomConfig.ProjectStores(projectRoot)), nil
+}
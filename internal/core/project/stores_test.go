@@ -0,0 +1,52 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"loom/internal/core/globalconfig"
+)
+
+func TestProjectStores(t *testing.T) {
+	root := t.TempDir()
+	absolute := filepath.Join(t.TempDir(), "shared")
+	config := &LoomConfig{Stores: []globalconfig.Store{
+		{Name: "team", Type: globalconfig.StoreTypeLocal, Path: "stores/team"},
+		{Name: "shared", Type: globalconfig.StoreTypeLocal, Path: absolute},
+		{Name: "remote", Type: globalconfig.StoreTypeGit, Path: "https://example.com/threads"},
+	}}
+
+	stores := config.ProjectStores(root)
+	want := []string{filepath.Join(root, "stores", "team"), absolute, "https://example.com/threads"}
+	for i, store := range stores {
+		if store.Path != want[i] {
+			t.Errorf("ProjectStores()[%d].Path = %s, want %s", i, store.Path, want[i])
+		}
+	}
+	if stores[0].RawPath != "stores/team" || config.Stores[0].Path != "stores/team" {
+		t.Errorf("ProjectStores() should keep the path as written in RawPath and leave loom.yaml's stores alone")
+	}
+}
+
+func TestLoadStoreConfigReadsGivenManifest(t *testing.T) {
+	t.Setenv("LOOM_GLOBAL_DIR", t.TempDir())
+	root := t.TempDir()
+	// A loom.yaml beside the chosen manifest must not contribute its stores.
+	if err := os.WriteFile(filepath.Join(root, YamlFileName), []byte("version: \"1\"\nstores:\n  - name: team\n    type: local\n    path: decoy\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	manifest := filepath.Join(root, "custom.yaml")
+	if err := os.WriteFile(manifest, []byte("version: \"1\"\nstores:\n  - name: team\n    type: local\n    path: stores/team\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	gConf, err := LoadStoreConfig(manifest)
+	if err != nil {
+		t.Fatalf("LoadStoreConfig() error = %v", err)
+	}
+	store, ok := gConf.FindStore("team")
+	if want := filepath.Join(root, "stores", "team"); !ok || store.Path != want {
+		t.Errorf("LoadStoreConfig() store team = %+v, want path %s", store, want)
+	}
+}
@@ -0,0 +1,61 @@
+package project
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+)
+
+// MissingFile is a file that a thread in loom.yaml lists but that is no longer on disk.
+type MissingFile struct {
+	Thread string
+	// Path is the project-relative path of the file, with forward slashes.
+	Path string
+}
+
+// Tidy drops the files listed in lc whose destination under projectRoot no longer exists, then
+// the directory keys left without files. With pruneThreads, threads left without any file are
+// dropped as well; threads that listed no files to begin with are kept. Tidy returns the dropped
+// files, sorted by thread and path, and the names of the dropped threads. Files that cannot be
+// checked for a reason other than not existing are kept.
+func (lc *LoomConfig) Tidy(projectRoot string, pruneThreads bool) ([]MissingFile, []string) {
+	var missing []MissingFile
+	var droppedThreads []string
+	kept := lc.Threads[:0]
+	for _, thread := range lc.Threads {
+		hadFiles := len(thread.Files) > 0
+		for dir, names := range thread.Files {
+			present := names[:0]
+			for _, name := range names {
+				_, err := os.Lstat(filepath.Join(projectRoot, filepath.FromSlash(dir), name))
+				if os.IsNotExist(err) {
+					missing = append(missing, MissingFile{Thread: thread.Name, Path: path.Join(dir, name)})
+					continue
+				}
+				present = append(present, name)
+			}
+			if len(present) == 0 {
+				delete(thread.Files, dir)
+			} else {
+				thread.Files[dir] = present
+			}
+		}
+		if len(thread.Files) == 0 {
+			thread.Files = nil
+			if hadFiles && pruneThreads {
+				droppedThreads = append(droppedThreads, thread.Name)
+				continue
+			}
+		}
+		kept = append(kept, thread)
+	}
+	lc.Threads = kept
+	sort.Slice(missing, func(i, j int) bool {
+		if missing[i].Thread != missing[j].Thread {
+			return missing[i].Thread < missing[j].Thread
+		}
+		return missing[i].Path < missing[j].Path
+	})
+	return missing, droppedThreads
+}
@@ -0,0 +1,46 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestTidy(t *testing.T) {
+	root := t.TempDir()
+	for _, file := range []string{"Makefile", "src/kept.go"} {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(root, file)), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(root, file), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	newConfig := func() LoomConfig {
+		return LoomConfig{Threads: []Thread{
+			{Name: "build", Files: map[string][]string{"./": {"Makefile", "gone.mk"}, "src/": {"kept.go"}, "docs/": {"gone.md"}}},
+			{Name: "stale", Files: map[string][]string{"ci/": {"gone.yml"}}},
+			{Name: "empty"},
+		}}
+	}
+
+	config := newConfig()
+	missing, dropped := config.Tidy(root, false)
+	want := []MissingFile{{Thread: "build", Path: "docs/gone.md"}, {Thread: "build", Path: "gone.mk"}, {Thread: "stale", Path: "ci/gone.yml"}}
+	if !reflect.DeepEqual(missing, want) || dropped != nil {
+		t.Errorf("Tidy() = %v, %v, want %v and no dropped threads", missing, dropped, want)
+	}
+	if files := config.Threads[0].Files; !reflect.DeepEqual(files, map[string][]string{"./": {"Makefile"}, "src/": {"kept.go"}}) {
+		t.Errorf("build files = %v, want the existing ones only", files)
+	}
+	if len(config.Threads) != 3 || config.Threads[1].Files != nil {
+		t.Errorf("threads = %+v, want stale kept without files", config.Threads)
+	}
+
+	config = newConfig()
+	_, dropped = config.Tidy(root, true)
+	if !reflect.DeepEqual(dropped, []string{"stale"}) || len(config.Threads) != 2 || config.Threads[1].Name != "empty" {
+		t.Errorf("Tidy(pruneThreads) dropped %v, kept %+v, want only stale dropped", dropped, config.Threads)
+	}
+}
@@ -0,0 +1,121 @@
+package project
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// VarsFileName is the optional file in the project root that holds template variables, one
+// KEY=value per line. It complements the vars section of loom.yaml for values that are personal or
+// change often; see ResolveVars.
+const VarsFileName = "loom.vars"
+
+// varNamePattern matches the names of template variables: a letter or underscore followed by
+// letters, digits and underscores, like shell and .env variables.
+var varNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ParseVars parses the contents of a loom.vars file. Every line is KEY=value; blank lines and lines
+// starting with # are ignored, and so is a # comment after an unquoted value. Values may be wrapped
+// in double quotes, which understand \", \\, \n and \t, or in single quotes, which are taken
+// literally; either keeps the # and surrounding spaces inside them. A later line for the same key
+// wins. Malformed lines are skipped with a warning naming their line number.
+func ParseVars(data []byte) (map[string]string, []string) {
+	vars := make(map[string]string)
+	var warnings []string
+	for i, line := range strings.Split(strings.ReplaceAll(string(data), "\r\n", "\n"), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		warn := func(format string, args ...any) {
+			warnings = append(warnings, fmt.Sprintf("%s:%d: %s", VarsFileName, i+1, fmt.Sprintf(format, args...)))
+		}
+		key, rawValue, found := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !found {
+			warn("expected KEY=value, got '%s'", line)
+			continue
+		}
+		if !varNamePattern.MatchString(key) {
+			warn("invalid variable name '%s': use letters, digits and underscores, not starting with a digit", key)
+			continue
+		}
+		value, err := parseVarValue(strings.TrimSpace(rawValue))
+		if err != nil {
+			warn("variable '%s': %v", key, err)
+			continue
+		}
+		vars[key] = value
+	}
+	return vars, warnings
+}
+
+// parseVarValue returns the value of a loom.vars line, with its quotes or trailing comment removed.
+func parseVarValue(raw string) (string, error) {
+	if raw == "" || (raw[0] != '"' && raw[0] != '\'') {
+		for i := 1; i < len(raw); i++ {
+			if raw[i] == '#' && (raw[i-1] == ' ' || raw[i-1] == '\t') {
+				return strings.TrimSpace(raw[:i]), nil
+			}
+		}
+		return raw, nil
+	}
+
+	quote := raw[0]
+	var value strings.Builder
+	for i := 1; i < len(raw); i++ {
+		c := raw[i]
+		if c == quote {
+			if rest := strings.TrimSpace(raw[i+1:]); rest != "" && !strings.HasPrefix(rest, "#") {
+				return "", fmt.Errorf("unexpected '%s' after the closing quote", rest)
+			}
+			return value.String(), nil
+		}
+		if c == '\\' && quote == '"' && i+1 < len(raw) {
+			i++
+			switch raw[i] {
+			case 'n':
+				value.WriteByte('\n')
+			case 't':
+				value.WriteByte('\t')
+			case '"', '\\':
+				value.WriteByte(raw[i])
+			default:
+				value.WriteByte('\\')
+				value.WriteByte(raw[i])
+			}
+			continue
+		}
+		value.WriteByte(c)
+	}
+	return "", fmt.Errorf("missing closing %c", quote)
+}
+
+// ResolveVars returns the template variables of the project at projectRoot: manifestVars, the vars
+// section of its loom.yaml, overridden by its loom.vars file. A missing loom.vars is fine; malformed
+// lines in it are reported as warnings on stderr.
+func ResolveVars(projectRoot string, manifestVars map[string]string) (map[string]string, error) {
+	vars := make(map[string]string, len(manifestVars))
+	for key, value := range manifestVars {
+		vars[key] = value
+	}
+
+	varsPath := filepath.Join(projectRoot, VarsFileName)
+	data, err := os.ReadFile(varsPath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read %s: %w", varsPath, err)
+	}
+	if err == nil {
+		fileVars, warnings := ParseVars(data)
+		for _, warning := range warnings {
+			fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+		}
+		for key, value := range fileVars {
+			vars[key] = value
+		}
+	}
+	return vars, nil
+}
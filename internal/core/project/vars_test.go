@@ -0,0 +1,86 @@
+package project
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseVars(t *testing.T) {
+	data := strings.Join([]string{
+		"# Values for this checkout",
+		"",
+		"APP_NAME=shop",
+		"  PORT = 8080  # the dev port",
+		`GREETING="Hello, # world\n"`,
+		`RAW='keep \n as is'`,
+		"URL=http://example.com/#top",
+		"EMPTY=",
+		"no equals sign",
+		"1BAD=x",
+		`OPEN="unterminated`,
+		`TRAILING="a" b`,
+		"APP_NAME=store",
+	}, "\r\n")
+
+	vars, warnings := ParseVars([]byte(data))
+	want := map[string]string{
+		"APP_NAME": "store",
+		"PORT":     "8080",
+		"GREETING": "Hello, # world\n",
+		"RAW":      `keep \n as is`,
+		"URL":      "http://example.com/#top",
+		"EMPTY":    "",
+	}
+	if !reflect.DeepEqual(vars, want) {
+		t.Errorf("ParseVars() = %q, want %q", vars, want)
+	}
+	wantWarnings := []string{
+		"loom.vars:9: expected KEY=value",
+		"loom.vars:10: invalid variable name '1BAD'",
+		"loom.vars:11: variable 'OPEN': missing closing \"",
+		"loom.vars:12: variable 'TRAILING': unexpected 'b'",
+	}
+	if len(warnings) != len(wantWarnings) {
+		t.Fatalf("ParseVars() warnings = %q, want %d of them", warnings, len(wantWarnings))
+	}
+	for i, prefix := range wantWarnings {
+		if !strings.HasPrefix(warnings[i], prefix) {
+			t.Errorf("warning %d = %q, want it to start with %q", i, warnings[i], prefix)
+		}
+	}
+}
+
+func TestResolveVars(t *testing.T) {
+	projectRoot := t.TempDir()
+	manifestVars := map[string]string{"NAME": "manifest", "REGION": "eu", "PORT": "80"}
+
+	vars, err := ResolveVars(projectRoot, manifestVars)
+	if err != nil || !reflect.DeepEqual(vars, manifestVars) {
+		t.Errorf("ResolveVars() without loom.vars = (%q, %v)", vars, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(projectRoot, VarsFileName), []byte("NAME=file\nPORT=8080\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	vars, err = ResolveVars(projectRoot, manifestVars)
+	want := map[string]string{"NAME": "file", "REGION": "eu", "PORT": "8080"}
+	if err != nil || !reflect.DeepEqual(vars, want) {
+		t.Errorf("ResolveVars() = (%q, %v), want %q", vars, err, want)
+	}
+}
+
+func TestParseLoomConfigVars(t *testing.T) {
+	config, err := ParseLoomConfig([]byte("version: \"1\"\nvars:\n  APP_NAME: shop\nthreads: []\n"))
+	if err != nil || config.Vars["APP_NAME"] != "shop" {
+		t.Errorf("ParseLoomConfig() vars = (%q, %v), want APP_NAME=shop", config.Vars, err)
+	}
+	if _, err := ParseLoomConfig([]byte("version: \"1\"\nvars:\n  app-name: shop\n")); err == nil || !strings.Contains(err.Error(), "invalid variable name 'app-name'") {
+		t.Errorf("ParseLoomConfig() with a bad variable name error = %v", err)
+	}
+	if _, err := ParseLoomConfig([]byte("version: \"1\"\nvars:\n  PORTS: [80, 443]\n")); err == nil {
+		t.Error("ParseLoomConfig() accepted a list as a variable value")
+	}
+}
@@ -0,0 +1,481 @@
+// Package threadconfig reads the per-thread config.yml (or config.yaml) that sits next to a thread's
+// _thread directory.
+package threadconfig
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// ConfigFileName is the name of the thread configuration file.
+	ConfigFileName = "config.yml"
+	// AltConfigFileName is the other name accepted for the thread configuration file. ConfigFileName
+	// takes precedence when a thread has both.
+	AltConfigFileName = "config.yaml"
+	// SourceDirName is the name of the directory holding the files a thread installs.
+	SourceDirName = "_thread"
+)
+
+// Conflict policies a config.yml can assign to files through its policy map.
+const (
+	// PolicyOverwrite overwrites an existing file without asking.
+	PolicyOverwrite = "overwrite"
+	// PolicySkip never overwrites an existing file.
+	PolicySkip = "skip"
+	// PolicyPrompt asks the user, which is also the behaviour for files without a policy.
+	PolicyPrompt = "prompt"
+)
+
+// Line ending conversions a config.yml can request through line_endings.
+const (
+	// LineEndingsPreserve installs files exactly as they are in _thread, which is the default.
+	LineEndingsPreserve = "preserve"
+	// LineEndingsLF installs text files with "\n" line endings.
+	LineEndingsLF = "lf"
+	// LineEndingsCRLF installs text files with "\r\n" line endings.
+	LineEndingsCRLF = "crlf"
+)
+
+// Dotfile handling a config.yml can request through dotfiles, and 'loom add' through
+// --include-dotfiles and --exclude-dotfiles.
+const (
+	// DotfilesInclude installs files and directories whose names start with "." like any other,
+	// which is the default.
+	DotfilesInclude = "include"
+	// DotfilesExclude never installs them, nor anything inside a directory whose name starts with ".".
+	DotfilesExclude = "exclude"
+)
+
+// Metadata holds the optional descriptive fields of a thread.
+type Metadata struct {
+	Description string `yaml:"description,omitempty"`
+	Author      string `yaml:"author,omitempty"`
+	License     string `yaml:"license,omitempty"`
+	// Tags are free-form keywords used by 'loom search'.
+	Tags []string `yaml:"tags,omitempty"`
+}
+
+// ThreadConfig represents the structure of a thread's config.yml.
+type ThreadConfig struct {
+	Version string `yaml:"version"`
+	// Name, if set, is the name 'loom add' records the thread under in loom.yaml instead of its
+	// directory's, so the thread keeps its identity however the directory is named.
+	Name          string   `yaml:"name,omitempty"`
+	ThreadVersion string   `yaml:"thread_version,omitempty"`
+	Metadata      Metadata `yaml:"metadata,omitempty"`
+	// Map rewrites source paths (relative to _thread) to destination paths (relative to the install root),
+	// e.g. "gitignore" -> ".gitignore".
+	Map map[string]string `yaml:"map,omitempty"`
+	// Policy maps glob patterns to the conflict policy used when a matching file already exists in the
+	// project, e.g. "*.sql" -> "overwrite". Patterns without a "/" match file names in any directory;
+	// others match whole paths. Either the source path (relative to _thread) or the destination path
+	// may match.
+	Policy map[string]string `yaml:"policy,omitempty"`
+	// Dependencies are threads that must be installed before this one, each given as
+	// <thread_name> or <store_name>/<thread_name> like a 'loom add' argument.
+	Dependencies []string `yaml:"dependencies,omitempty"`
+	// Files limits which files under _thread are installed; see Includes.
+	Files FileFilter `yaml:"files,omitempty"`
+	// LineEndings converts the line endings of installed text files: LineEndingsLF, LineEndingsCRLF
+	// or LineEndingsPreserve. Binary files are always installed as they are.
+	LineEndings string `yaml:"line_endings,omitempty"`
+	// Dotfiles is DotfilesInclude or DotfilesExclude; see Includes.
+	Dotfiles string `yaml:"dotfiles,omitempty"`
+}
+
+// FileFilter lists glob patterns selecting the files of _thread a thread installs, so authors can
+// keep READMEs, tests or CI config next to the installable files. Patterns without a "/" match a file
+// name or the name of any directory above it; others match the whole path relative to _thread or
+// one of its leading directories.
+type FileFilter struct {
+	// Include, if non-empty, installs only files matching at least one pattern.
+	Include []string `yaml:"include,omitempty"`
+	// Exclude never installs files matching any pattern, even ones Include matches.
+	Exclude []string `yaml:"exclude,omitempty"`
+}
+
+// ConfigFilePath returns the path of the configuration file of the thread in threadDir: config.yml,
+// or config.yaml if only that exists. It reports false, with the path config.yml would have, if the
+// thread has neither. When both exist config.yml is used, and a warning that config.yaml is ignored
+// is printed once per thread.
+func ConfigFilePath(threadDir string) (string, bool) {
+	configPath := filepath.Join(threadDir, ConfigFileName)
+	altPath := filepath.Join(threadDir, AltConfigFileName)
+	_, err := os.Stat(configPath)
+	_, altErr := os.Stat(altPath)
+	switch {
+	case err == nil && altErr == nil:
+		if _, warned := warnedBothConfigs.LoadOrStore(threadDir, true); !warned {
+			fmt.Fprintf(os.Stderr, "Warning: %s has both %s and %s; using %s and ignoring %s.\n", threadDir, ConfigFileName, AltConfigFileName, ConfigFileName, AltConfigFileName)
+		}
+		return configPath, true
+	case err == nil:
+		return configPath, true
+	case altErr == nil:
+		return altPath, true
+	default:
+		return configPath, false
+	}
+}
+
+// warnedBothConfigs holds the thread directories ConfigFilePath has already warned about.
+var warnedBothConfigs sync.Map
+
+// LoadThreadConfig reads config.yml, or config.yaml (see ConfigFilePath), from threadDir (the
+// directory containing _thread). A missing configuration file is not an error; an empty
+// ThreadConfig is returned instead.
+func LoadThreadConfig(threadDir string) (*ThreadConfig, error) {
+	configPath, _ := ConfigFilePath(threadDir)
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return &ThreadConfig{}, nil
+		}
+		return nil, fmt.Errorf("failed to read %s: %w", configPath, err)
+	}
+
+	var config ThreadConfig
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", configPath, err)
+	}
+	if err := config.normalizeMap(); err != nil {
+		return nil, fmt.Errorf("invalid map in %s: %w", configPath, err)
+	}
+	if err := config.normalizePolicy(); err != nil {
+		return nil, fmt.Errorf("invalid policy in %s: %w", configPath, err)
+	}
+	if err := config.normalizeDependencies(); err != nil {
+		return nil, fmt.Errorf("invalid dependencies in %s: %w", configPath, err)
+	}
+	if err := config.normalizeFiles(); err != nil {
+		return nil, fmt.Errorf("invalid files in %s: %w", configPath, err)
+	}
+	if err := config.normalizeLineEndings(); err != nil {
+		return nil, fmt.Errorf("invalid line_endings in %s: %w", configPath, err)
+	}
+	if err := config.normalizeDotfiles(); err != nil {
+		return nil, fmt.Errorf("invalid dotfiles in %s: %w", configPath, err)
+	}
+	return &config, nil
+}
+
+// LoadThreadConfigForSource reads config.yml for the thread whose _thread directory is sourceDir.
+func LoadThreadConfigForSource(sourceDir string) (*ThreadConfig, error) {
+	return LoadThreadConfig(filepath.Dir(sourceDir))
+}
+
+// normalizeMap cleans map entries to forward-slash relative paths and rejects ones escaping their root.
+func (c *ThreadConfig) normalizeMap() error {
+	if len(c.Map) == 0 {
+		return nil
+	}
+	normalized := make(map[string]string, len(c.Map))
+	destinations := make(map[string]string, len(c.Map))
+	for src, dest := range c.Map {
+		cleanSrc, err := cleanRelativePath(src)
+		if err != nil {
+			return fmt.Errorf("source '%s': %w", src, err)
+		}
+		cleanDest, err := cleanRelativePath(dest)
+		if err != nil {
+			return fmt.Errorf("destination '%s': %w", dest, err)
+		}
+		if other, exists := destinations[cleanDest]; exists {
+			return fmt.Errorf("sources '%s' and '%s' both map to '%s'", other, cleanSrc, cleanDest)
+		}
+		destinations[cleanDest] = cleanSrc
+		normalized[cleanSrc] = cleanDest
+	}
+	c.Map = normalized
+	return nil
+}
+
+// normalizePolicy converts policy patterns to forward slashes, lower-cases the policies and rejects
+// malformed patterns and unknown policies.
+func (c *ThreadConfig) normalizePolicy() error {
+	if len(c.Policy) == 0 {
+		return nil
+	}
+	normalized := make(map[string]string, len(c.Policy))
+	for pattern, policy := range c.Policy {
+		cleanPattern := strings.ReplaceAll(strings.TrimSpace(pattern), "\\", "/")
+		if cleanPattern == "" {
+			return fmt.Errorf("empty pattern")
+		}
+		if _, err := path.Match(cleanPattern, ""); err != nil {
+			return fmt.Errorf("pattern '%s': %w", pattern, err)
+		}
+		cleanPolicy := strings.ToLower(strings.TrimSpace(policy))
+		switch cleanPolicy {
+		case PolicyOverwrite, PolicySkip, PolicyPrompt:
+		default:
+			return fmt.Errorf("pattern '%s': unknown policy '%s' (expected %s, %s or %s)", pattern, policy, PolicyOverwrite, PolicySkip, PolicyPrompt)
+		}
+		normalized[cleanPattern] = cleanPolicy
+	}
+	c.Policy = normalized
+	return nil
+}
+
+// normalizeDependencies trims dependency entries, drops duplicates and rejects empty ones.
+func (c *ThreadConfig) normalizeDependencies() error {
+	if len(c.Dependencies) == 0 {
+		return nil
+	}
+	seen := make(map[string]bool, len(c.Dependencies))
+	normalized := make([]string, 0, len(c.Dependencies))
+	for _, dep := range c.Dependencies {
+		cleanDep := strings.TrimSpace(dep)
+		if cleanDep == "" {
+			return fmt.Errorf("empty dependency")
+		}
+		if seen[cleanDep] {
+			continue
+		}
+		seen[cleanDep] = true
+		normalized = append(normalized, cleanDep)
+	}
+	c.Dependencies = normalized
+	return nil
+}
+
+// normalizeLineEndings lowercases line_endings, defaulting it to LineEndingsPreserve, and rejects
+// unknown values.
+func (c *ThreadConfig) normalizeLineEndings() error {
+	c.LineEndings = strings.ToLower(strings.TrimSpace(c.LineEndings))
+	switch c.LineEndings {
+	case "":
+		c.LineEndings = LineEndingsPreserve
+	case LineEndingsPreserve, LineEndingsLF, LineEndingsCRLF:
+	default:
+		return fmt.Errorf("'%s' is not one of %s, %s or %s", c.LineEndings, LineEndingsLF, LineEndingsCRLF, LineEndingsPreserve)
+	}
+	return nil
+}
+
+// normalizeDotfiles lowercases dotfiles, defaulting it to DotfilesInclude, and rejects unknown values.
+func (c *ThreadConfig) normalizeDotfiles() error {
+	c.Dotfiles = strings.ToLower(strings.TrimSpace(c.Dotfiles))
+	switch c.Dotfiles {
+	case "":
+		c.Dotfiles = DotfilesInclude
+	case DotfilesInclude, DotfilesExclude:
+	default:
+		return fmt.Errorf("'%s' is not one of %s or %s", c.Dotfiles, DotfilesInclude, DotfilesExclude)
+	}
+	return nil
+}
+
+// normalizeFiles converts include and exclude patterns to forward slashes without leading or
+// trailing ones, and rejects malformed patterns.
+func (c *ThreadConfig) normalizeFiles() error {
+	for _, patterns := range [][]string{c.Files.Include, c.Files.Exclude} {
+		for i, pattern := range patterns {
+			cleanPattern := strings.Trim(strings.ReplaceAll(strings.TrimSpace(pattern), "\\", "/"), "/")
+			if cleanPattern == "" {
+				return fmt.Errorf("empty pattern")
+			}
+			if _, err := path.Match(cleanPattern, ""); err != nil {
+				return fmt.Errorf("pattern '%s': %w", pattern, err)
+			}
+			patterns[i] = cleanPattern
+		}
+	}
+	return nil
+}
+
+// Includes reports whether the file at sourceRel (relative to _thread, forward slashes) is installed:
+// it matches no exclude pattern and, if there are include patterns, at least one of them. With
+// Dotfiles set to DotfilesExclude, files whose path has an element starting with "." never are.
+func (c *ThreadConfig) Includes(sourceRel string) bool {
+	if c.Dotfiles == DotfilesExclude {
+		for _, element := range strings.Split(sourceRel, "/") {
+			if strings.HasPrefix(element, ".") {
+				return false
+			}
+		}
+	}
+	for _, pattern := range c.Files.Exclude {
+		if MatchFilterPattern(pattern, sourceRel) {
+			return false
+		}
+	}
+	if len(c.Files.Include) == 0 {
+		return true
+	}
+	for _, pattern := range c.Files.Include {
+		if MatchFilterPattern(pattern, sourceRel) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasFileFilter reports whether config.yml restricts the installed files at all.
+func (c *ThreadConfig) HasFileFilter() bool {
+	return len(c.Files.Include) > 0 || len(c.Files.Exclude) > 0 || c.Dotfiles == DotfilesExclude
+}
+
+// MatchFilterPattern matches a pattern without "/" against every element of relPath, and others
+// against relPath and each of its leading directories, so "tests" and "docs/internal" cover
+// everything below those directories. Both use forward slashes.
+func MatchFilterPattern(pattern, relPath string) bool {
+	elements := strings.Split(relPath, "/")
+	if !strings.Contains(pattern, "/") {
+		for _, element := range elements {
+			if matched, _ := path.Match(pattern, element); matched {
+				return true
+			}
+		}
+		return false
+	}
+	for i := range elements {
+		if matched, _ := path.Match(pattern, strings.Join(elements[:i+1], "/")); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// PolicyFor returns the conflict policy for the file at sourceRel (relative to _thread, forward
+// slashes), or "" if no pattern matches. When several patterns match, the longest wins, with ties
+// broken alphabetically, so "config/*.env" overrides "*.env".
+func (c *ThreadConfig) PolicyFor(sourceRel string) string {
+	if len(c.Policy) == 0 {
+		return ""
+	}
+	patterns := make([]string, 0, len(c.Policy))
+	for pattern := range c.Policy {
+		patterns = append(patterns, pattern)
+	}
+	sort.Slice(patterns, func(i, j int) bool {
+		if len(patterns[i]) != len(patterns[j]) {
+			return len(patterns[i]) > len(patterns[j])
+		}
+		return patterns[i] < patterns[j]
+	})
+	destRel := c.DestinationFor(sourceRel)
+	for _, pattern := range patterns {
+		if matchPolicyPattern(pattern, sourceRel) || matchPolicyPattern(pattern, destRel) {
+			return c.Policy[pattern]
+		}
+	}
+	return ""
+}
+
+// ParseOverwritePolicy converts a --overwrite-policy value into the conflict policy applied to
+// every existing file: "theirs" overwrites it (PolicyOverwrite), "ours" keeps it (PolicySkip), and
+// "prompt" returns "" so config.yml policies and prompts decide as usual.
+func ParseOverwritePolicy(value string) (string, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "theirs":
+		return PolicyOverwrite, nil
+	case "ours":
+		return PolicySkip, nil
+	case "prompt", "":
+		return "", nil
+	}
+	return "", fmt.Errorf("unknown overwrite policy '%s' (expected ours, theirs or prompt)", value)
+}
+
+// matchPolicyPattern matches a pattern without "/" against the file name, and others against relPath.
+func matchPolicyPattern(pattern, relPath string) bool {
+	if !strings.Contains(pattern, "/") {
+		relPath = path.Base(relPath)
+	}
+	matched, _ := path.Match(pattern, relPath)
+	return matched
+}
+
+// cleanRelativePath converts p to a cleaned forward-slash path and rejects absolute or escaping paths.
+func cleanRelativePath(p string) (string, error) {
+	slashed := strings.ReplaceAll(p, "\\", "/")
+	if slashed == "" || strings.HasPrefix(slashed, "/") || filepath.IsAbs(p) {
+		return "", fmt.Errorf("must be a non-empty relative path")
+	}
+	cleaned := path.Clean(slashed)
+	if cleaned == "." || cleaned == ".." || strings.HasPrefix(cleaned, "../") {
+		return "", fmt.Errorf("must stay within the thread")
+	}
+	return cleaned, nil
+}
+
+// DestinationFor returns the destination path (relative to the install root) for a source path
+// relative to _thread. Both use forward slashes. Unmapped paths are returned unchanged.
+func (c *ThreadConfig) DestinationFor(sourceRel string) string {
+	if dest, ok := c.Map[sourceRel]; ok {
+		return dest
+	}
+	return sourceRel
+}
+
+// SourceFor is the inverse of DestinationFor. It returns false if destRel is the target of no source,
+// which happens when an unmapped source path is shadowed by a mapping onto the same destination.
+func (c *ThreadConfig) SourceFor(destRel string) (string, bool) {
+	for src, dest := range c.Map {
+		if dest == destRel {
+			return src, true
+		}
+	}
+	if _, mapped := c.Map[destRel]; mapped {
+		return "", false
+	}
+	return destRel, true
+}
+
+// RewriteDependencies replaces entries of the dependencies list in threadDir's config.yml (or
+// config.yaml) according to rewrites, keyed by the trimmed entry. Only those entries change; the rest
+// of the file, including its comments, is written back as parsed. It reports whether the file
+// changed; a missing configuration file, or one without matching entries, is left alone.
+func RewriteDependencies(threadDir string, rewrites map[string]string) (bool, error) {
+	configPath, _ := ConfigFilePath(threadDir)
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to read %s: %w", configPath, err)
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return false, fmt.Errorf("failed to parse %s: %w", configPath, err)
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return false, nil
+	}
+	root := doc.Content[0]
+	changed := false
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value != "dependencies" || root.Content[i+1].Kind != yaml.SequenceNode {
+			continue
+		}
+		for _, entry := range root.Content[i+1].Content {
+			if entry.Kind != yaml.ScalarNode {
+				continue
+			}
+			if replacement, ok := rewrites[strings.TrimSpace(entry.Value)]; ok && replacement != entry.Value {
+				entry.Value = replacement
+				changed = true
+			}
+		}
+	}
+	if !changed {
+		return false, nil
+	}
+	updated, err := yaml.Marshal(&doc)
+	if err != nil {
+		return false, fmt.Errorf("failed to encode %s: %w", configPath, err)
+	}
+	if err := os.WriteFile(configPath, updated, 0644); err != nil {
+		return false, fmt.Errorf("failed to write %s: %w", configPath, err)
+	}
+	return true, nil
+}
@@ -0,0 +1,348 @@
+package threadconfig
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLoadThreadConfigMap(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantMap map[string]string
+		wantErr bool
+	}{
+		{
+			name:    "missing map",
+			content: "version: 1\n",
+			wantMap: nil,
+		},
+		{
+			name:    "normalizes separators and dot segments",
+			content: "version: 1\nmap:\n  ./gitignore: .gitignore\n  'tmpl\\x.tmpl': conf/./x.txt\n",
+			wantMap: map[string]string{"gitignore": ".gitignore", "tmpl/x.tmpl": "conf/x.txt"},
+		},
+		{
+			name:    "rejects destinations escaping the install root",
+			content: "map:\n  a: ../a\n",
+			wantErr: true,
+		},
+		{
+			name:    "rejects absolute sources",
+			content: "map:\n  /etc/passwd: a\n",
+			wantErr: true,
+		},
+		{
+			name:    "rejects two sources with one destination",
+			content: "map:\n  a: c\n  b: c\n",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			if err := os.WriteFile(filepath.Join(dir, ConfigFileName), []byte(tt.content), 0644); err != nil {
+				t.Fatal(err)
+			}
+			config, err := LoadThreadConfig(dir)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("expected an error, got map %v", config.Map)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(config.Map) != len(tt.wantMap) {
+				t.Fatalf("Map = %v, want %v", config.Map, tt.wantMap)
+			}
+			for src, dest := range tt.wantMap {
+				if config.Map[src] != dest {
+					t.Errorf("Map[%q] = %q, want %q", src, config.Map[src], dest)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadThreadConfigMissingFile(t *testing.T) {
+	config, err := LoadThreadConfig(t.TempDir())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if config == nil || len(config.Map) != 0 {
+		t.Fatalf("expected an empty config, got %+v", config)
+	}
+}
+
+func TestLoadThreadConfigYamlExtension(t *testing.T) {
+	dir := t.TempDir()
+	if _, ok := ConfigFilePath(dir); ok {
+		t.Fatalf("ConfigFilePath() found a configuration file in an empty directory")
+	}
+	if err := os.WriteFile(filepath.Join(dir, AltConfigFileName), []byte("policy:\n  '*.sql': skip\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config, err := LoadThreadConfig(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if config.Policy["*.sql"] != PolicySkip {
+		t.Errorf("Policy = %v, want the policy from %s", config.Policy, AltConfigFileName)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, ConfigFileName), []byte("policy:\n  '*.sql': overwrite\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if configPath, ok := ConfigFilePath(dir); !ok || filepath.Base(configPath) != ConfigFileName {
+		t.Errorf("ConfigFilePath() = %s, %v, want %s to take precedence", configPath, ok, ConfigFileName)
+	}
+	if config, err := LoadThreadConfig(dir); err != nil || config.Policy["*.sql"] != PolicyOverwrite {
+		t.Errorf("LoadThreadConfig() = %+v, %v, want the policy from %s", config, err, ConfigFileName)
+	}
+}
+
+func TestSourceAndDestinationFor(t *testing.T) {
+	config := &ThreadConfig{Map: map[string]string{"gitignore": ".gitignore", "a.txt": "b.txt"}}
+
+	tests := []struct {
+		destRel    string
+		wantSource string
+		wantOK     bool
+	}{
+		{destRel: ".gitignore", wantSource: "gitignore", wantOK: true},
+		{destRel: "README.md", wantSource: "README.md", wantOK: true},
+		{destRel: "b.txt", wantSource: "a.txt", wantOK: true},
+		// a.txt is renamed away, so nothing in the thread installs to a.txt.
+		{destRel: "a.txt", wantOK: false},
+	}
+	for _, tt := range tests {
+		source, ok := config.SourceFor(tt.destRel)
+		if ok != tt.wantOK || source != tt.wantSource {
+			t.Errorf("SourceFor(%q) = (%q, %v), want (%q, %v)", tt.destRel, source, ok, tt.wantSource, tt.wantOK)
+		}
+		if ok && config.DestinationFor(source) != tt.destRel {
+			t.Errorf("DestinationFor(%q) = %q, want %q", source, config.DestinationFor(source), tt.destRel)
+		}
+	}
+}
+
+func TestLoadThreadConfigPolicy(t *testing.T) {
+	dir := t.TempDir()
+	content := "policy:\n  '*.sql': Overwrite\n  .env.example: skip\n"
+	if err := os.WriteFile(filepath.Join(dir, ConfigFileName), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config, err := LoadThreadConfig(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if config.Policy["*.sql"] != PolicyOverwrite || config.Policy[".env.example"] != PolicySkip {
+		t.Errorf("Policy = %v, want normalized policies", config.Policy)
+	}
+
+	for _, bad := range []string{"policy:\n  '*.sql': always\n", "policy:\n  '[': skip\n"} {
+		if err := os.WriteFile(filepath.Join(dir, ConfigFileName), []byte(bad), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadThreadConfig(dir); err == nil {
+			t.Errorf("LoadThreadConfig(%q) succeeded, want an error", bad)
+		}
+	}
+}
+
+func TestLoadThreadConfigDependencies(t *testing.T) {
+	dir := t.TempDir()
+	content := "dependencies:\n  - base-js\n  - ' company/prettier '\n  - base-js\n"
+	if err := os.WriteFile(filepath.Join(dir, ConfigFileName), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config, err := LoadThreadConfig(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{"base-js", "company/prettier"}
+	if len(config.Dependencies) != len(want) || config.Dependencies[0] != want[0] || config.Dependencies[1] != want[1] {
+		t.Errorf("Dependencies = %q, want %q", config.Dependencies, want)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, ConfigFileName), []byte("dependencies:\n  - ''\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadThreadConfig(dir); err == nil {
+		t.Error("LoadThreadConfig with an empty dependency succeeded, want an error")
+	}
+}
+
+func TestPolicyFor(t *testing.T) {
+	config := &ThreadConfig{
+		Map:    map[string]string{"env.example": ".env"},
+		Policy: map[string]string{"*.sql": PolicyOverwrite, "db/seed.sql": PolicyPrompt, ".env": PolicySkip},
+	}
+
+	tests := map[string]string{
+		"schema.sql":    PolicyOverwrite, // name pattern in the root
+		"db/schema.sql": PolicyOverwrite, // name pattern in a subdirectory
+		"db/seed.sql":   PolicyPrompt,    // the longer path pattern wins
+		"env.example":   PolicySkip,      // matched through its destination
+		"README.md":     "",
+	}
+	for sourceRel, want := range tests {
+		if got := config.PolicyFor(sourceRel); got != want {
+			t.Errorf("PolicyFor(%q) = %q, want %q", sourceRel, got, want)
+		}
+	}
+}
+
+func TestParseOverwritePolicy(t *testing.T) {
+	tests := map[string]string{
+		"theirs": PolicyOverwrite,
+		"Ours":   PolicySkip,
+		"prompt": "",
+	}
+	for value, want := range tests {
+		got, err := ParseOverwritePolicy(value)
+		if err != nil || got != want {
+			t.Errorf("ParseOverwritePolicy(%q) = %q, %v, want %q", value, got, err, want)
+		}
+	}
+	if _, err := ParseOverwritePolicy("mine"); err == nil {
+		t.Error("ParseOverwritePolicy(\"mine\") succeeded, want an error")
+	}
+}
+
+func TestLoadThreadConfigFiles(t *testing.T) {
+	dir := t.TempDir()
+	content := "files:\n  include: ['src/', '*.go']\n  exclude: ['docs\\internal']\n"
+	if err := os.WriteFile(filepath.Join(dir, ConfigFileName), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config, err := LoadThreadConfig(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(config.Files.Include) != 2 || config.Files.Include[0] != "src" || config.Files.Exclude[0] != "docs/internal" {
+		t.Errorf("Files = %+v, want normalized patterns", config.Files)
+	}
+
+	for _, bad := range []string{"files:\n  include: ['']\n", "files:\n  exclude: ['[']\n"} {
+		if err := os.WriteFile(filepath.Join(dir, ConfigFileName), []byte(bad), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadThreadConfig(dir); err == nil {
+			t.Errorf("LoadThreadConfig(%q) succeeded, want an error", bad)
+		}
+	}
+}
+
+func TestIncludes(t *testing.T) {
+	config := &ThreadConfig{Files: FileFilter{
+		Include: []string{"src", "*.md"},
+		Exclude: []string{"testdata", "src/internal", "CHANGELOG.md"},
+	}}
+
+	tests := map[string]bool{
+		"src/main.go":              true,  // below an included directory
+		"README.md":                true,  // name pattern in the root
+		"docs/guide.md":            true,  // name pattern in a subdirectory
+		"CHANGELOG.md":             false, // exclude wins over include
+		"src/internal/x.go":        false, // excluded directory path
+		"src/testdata/fixture.txt": false, // excluded directory name
+		"main.go":                  false, // not included
+	}
+	for sourceRel, want := range tests {
+		if got := config.Includes(sourceRel); got != want {
+			t.Errorf("Includes(%q) = %v, want %v", sourceRel, got, want)
+		}
+	}
+
+	if !(&ThreadConfig{}).Includes("anything/at/all") {
+		t.Error("Includes() without a files filter = false, want true")
+	}
+}
+
+func TestLoadThreadConfigLineEndings(t *testing.T) {
+	dir := t.TempDir()
+	for content, want := range map[string]string{"line_endings: LF\n": LineEndingsLF, "version: \"1\"\n": LineEndingsPreserve} {
+		if err := os.WriteFile(filepath.Join(dir, ConfigFileName), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		config, err := LoadThreadConfig(dir)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if config.LineEndings != want {
+			t.Errorf("LineEndings for %q = %q, want %q", content, config.LineEndings, want)
+		}
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, ConfigFileName), []byte("line_endings: unix\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadThreadConfig(dir); err == nil {
+		t.Error("LoadThreadConfig with an unknown line_endings succeeded, want an error")
+	}
+}
+
+func TestIncludesDotfiles(t *testing.T) {
+	config := &ThreadConfig{Dotfiles: DotfilesExclude, Files: FileFilter{Exclude: []string{"tmp"}}}
+	tests := map[string]bool{
+		"main.go":              true,
+		".env.example":         false, // dotfile in the root
+		".github/workflow.yml": false, // inside a dot directory
+		"config/.keep":         false, // dotfile in a subdirectory
+		"tmp/x":                false, // the files filter still applies
+	}
+	for sourceRel, want := range tests {
+		if got := config.Includes(sourceRel); got != want {
+			t.Errorf("Includes(%q) = %v, want %v", sourceRel, got, want)
+		}
+	}
+	if !(&ThreadConfig{Dotfiles: DotfilesInclude}).Includes(".github/workflow.yml") {
+		t.Error("Includes() with dotfiles: include = false, want true")
+	}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, ConfigFileName), []byte("dotfiles: hidden\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadThreadConfig(dir); err == nil {
+		t.Error("LoadThreadConfig with an unknown dotfiles succeeded, want an error")
+	}
+}
+
+func TestRewriteDependencies(t *testing.T) {
+	dir := t.TempDir()
+	content := "# Shared lint setup\nversion: \"1\"\ndependencies:\n  - shared/editorconfig # first\n  - go-ci\n"
+	if err := os.WriteFile(filepath.Join(dir, ConfigFileName), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	changed, err := RewriteDependencies(dir, map[string]string{"shared/editorconfig": "editorconfig"})
+	if err != nil || !changed {
+		t.Fatalf("RewriteDependencies() = %v, %v, want true, nil", changed, err)
+	}
+	config, err := LoadThreadConfig(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := []string{"editorconfig", "go-ci"}; !reflect.DeepEqual(config.Dependencies, want) {
+		t.Errorf("Dependencies = %v, want %v", config.Dependencies, want)
+	}
+	data, _ := os.ReadFile(filepath.Join(dir, ConfigFileName))
+	if !strings.Contains(string(data), "# Shared lint setup") || !strings.Contains(string(data), "# first") {
+		t.Errorf("comments were dropped:\n%s", data)
+	}
+
+	if changed, err := RewriteDependencies(dir, map[string]string{"other/thread": "thread"}); err != nil || changed {
+		t.Errorf("RewriteDependencies() without matches = %v, %v, want false, nil", changed, err)
+	}
+	if changed, err := RewriteDependencies(t.TempDir(), map[string]string{"a/b": "b"}); err != nil || changed {
+		t.Errorf("RewriteDependencies() without config.yml = %v, %v, want false, nil", changed, err)
+	}
+}
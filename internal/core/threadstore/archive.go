@@ -0,0 +1,259 @@
+package threadstore
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"loom/internal/core/project"
+	"loom/internal/core/threadconfig"
+)
+
+// archiveExtensions are the file name suffixes of the archives an archive store can point at.
+var archiveExtensions = []string{".tar.gz", ".tgz", ".zip"}
+
+// extractedStoreDirName is the directory of a cached extraction that holds the contents of an archive
+// of several threads. An archive holding a single thread is extracted into a directory named after
+// the archive instead, so it is listed as that thread.
+const extractedStoreDirName = "contents"
+
+// IsArchivePath reports whether p names a .zip, .tar.gz or .tgz file, judging by its extension.
+func IsArchivePath(p string) bool {
+	return ArchiveBaseName(p) != ""
+}
+
+// ArchiveBaseName returns the file name of p without its archive extension, or "" if p does not
+// have one. It is the default name of an archive store, and of the thread in a single-thread archive.
+func ArchiveBaseName(p string) string {
+	name := filepath.Base(p)
+	for _, ext := range archiveExtensions {
+		if len(name) > len(ext) && strings.EqualFold(name[len(name)-len(ext):], ext) {
+			return name[:len(name)-len(ext)]
+		}
+	}
+	return ""
+}
+
+// ArchiveCacheDir returns the directory that archive stores are extracted into: loom/archives in the
+// user's cache directory, or in the temporary directory if there is none.
+func ArchiveCacheDir() string {
+	base, err := os.UserCacheDir()
+	if err != nil {
+		base = os.TempDir()
+	}
+	return filepath.Join(base, "loom", "archives")
+}
+
+// extractArchive returns the store directory holding the threads of the archive at archivePath. The
+// archive is extracted into ArchiveCacheDir under a name derived from its contents, so later calls
+// reuse the extraction until the archive changes. The archive either holds threads, possibly in
+// category directories, or is a single thread with _thread at its root, which is then named after
+// the archive.
+func extractArchive(archivePath string) (string, error) {
+	baseName := ArchiveBaseName(archivePath)
+	if baseName == "" {
+		return "", fmt.Errorf("'%s' is not a .zip, .tar.gz or .tgz archive", archivePath)
+	}
+	hash, err := hashArchive(archivePath)
+	if err != nil {
+		return "", err
+	}
+	cacheDir := ArchiveCacheDir()
+	extractionDir := filepath.Join(cacheDir, baseName+"-"+hash)
+	if storeDir, ok := cachedStoreDir(extractionDir); ok {
+		return storeDir, nil
+	}
+
+	if err := os.MkdirAll(cacheDir, os.ModePerm); err != nil {
+		return "", fmt.Errorf("failed to create archive cache %s: %w", cacheDir, err)
+	}
+	// Extract next to the final location and rename it into place, so an interrupted extraction is
+	// never mistaken for a complete one.
+	tempDir, err := os.MkdirTemp(cacheDir, baseName+"-"+hash+".tmp-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create a directory to extract %s into: %w", archivePath, err)
+	}
+	defer os.RemoveAll(tempDir)
+	contentsDir := filepath.Join(tempDir, extractedStoreDirName)
+	if err := extractArchiveTo(archivePath, contentsDir); err != nil {
+		return "", err
+	}
+	if isThreadDir(contentsDir) {
+		if err := os.Rename(contentsDir, filepath.Join(tempDir, baseName)); err != nil {
+			return "", fmt.Errorf("failed to arrange the extraction of %s: %w", archivePath, err)
+		}
+	}
+	if err := os.Rename(tempDir, extractionDir); err != nil {
+		// Another loom process may have extracted the same archive in the meantime.
+		if storeDir, ok := cachedStoreDir(extractionDir); ok {
+			return storeDir, nil
+		}
+		return "", fmt.Errorf("failed to move the extraction of %s into the cache: %w", archivePath, err)
+	}
+	storeDir, _ := cachedStoreDir(extractionDir)
+	return storeDir, nil
+}
+
+// cachedStoreDir returns the store directory of a complete extraction at extractionDir, if there is one.
+func cachedStoreDir(extractionDir string) (string, bool) {
+	if _, err := os.Stat(extractionDir); err != nil {
+		return "", false
+	}
+	if info, err := os.Stat(filepath.Join(extractionDir, extractedStoreDirName)); err == nil && info.IsDir() {
+		return filepath.Join(extractionDir, extractedStoreDirName), true
+	}
+	return extractionDir, true // A single-thread archive, extracted into a directory of its own name
+}
+
+// isThreadDir reports whether dir is a thread itself rather than a store of threads.
+func isThreadDir(dir string) bool {
+	_, errDir := os.Stat(filepath.Join(dir, threadconfig.SourceDirName))
+	_, hasConfig := threadconfig.ConfigFilePath(dir)
+	return errDir == nil || hasConfig
+}
+
+// hashArchive returns a short hex digest of the contents of the archive at archivePath.
+func hashArchive(archivePath string) (string, error) {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return "", fmt.Errorf("failed to open archive %s: %w", archivePath, err)
+	}
+	defer file.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", fmt.Errorf("failed to read archive %s: %w", archivePath, err)
+	}
+	return hex.EncodeToString(hash.Sum(nil))[:16], nil
+}
+
+// extractArchiveTo extracts the archive at archivePath into dest, choosing the format by extension.
+func extractArchiveTo(archivePath, dest string) error {
+	if err := os.MkdirAll(dest, os.ModePerm); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dest, err)
+	}
+	var err error
+	if strings.EqualFold(filepath.Ext(archivePath), ".zip") {
+		err = extractZip(archivePath, dest)
+	} else {
+		err = extractTarGz(archivePath, dest)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to extract archive %s: %w", archivePath, err)
+	}
+	return nil
+}
+
+// extractZip extracts the directories and regular files of a zip archive into dest.
+func extractZip(archivePath, dest string) error {
+	reader, err := zip.OpenReader(archivePath)
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	for _, entry := range reader.File {
+		mode := entry.Mode()
+		if !mode.IsDir() && !mode.IsRegular() {
+			continue // Symlinks could point anywhere; they are not extracted
+		}
+		target, err := archiveEntryTarget(dest, entry.Name)
+		if err != nil {
+			return err
+		}
+		if mode.IsDir() {
+			if err := os.MkdirAll(target, os.ModePerm); err != nil {
+				return err
+			}
+			continue
+		}
+		in, err := entry.Open()
+		if err != nil {
+			return fmt.Errorf("failed to read entry '%s': %w", entry.Name, err)
+		}
+		err = writeArchiveFile(target, in, mode.Perm())
+		in.Close()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// extractTarGz extracts the directories and regular files of a gzip-compressed tar archive into dest.
+func extractTarGz(archivePath, dest string) error {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+	reader := tar.NewReader(gz)
+	for {
+		header, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		} else if err != nil {
+			return err
+		}
+		if header.Typeflag != tar.TypeDir && header.Typeflag != tar.TypeReg {
+			continue // Links could point anywhere; they are not extracted
+		}
+		target, err := archiveEntryTarget(dest, header.Name)
+		if err != nil {
+			return err
+		}
+		if header.Typeflag == tar.TypeDir {
+			if err := os.MkdirAll(target, os.ModePerm); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := writeArchiveFile(target, reader, os.FileMode(header.Mode).Perm()); err != nil {
+			return err
+		}
+	}
+}
+
+// archiveEntryTarget returns where the archive entry called name is extracted under dest. Entries
+// with absolute paths or ones leading outside dest are rejected, so a crafted archive cannot write
+// anywhere else (zip slip).
+func archiveEntryTarget(dest, name string) (string, error) {
+	cleaned := filepath.Clean(filepath.FromSlash(strings.ReplaceAll(name, "\\", "/")))
+	if filepath.IsAbs(cleaned) || filepath.VolumeName(cleaned) != "" || strings.HasPrefix(name, "/") ||
+		cleaned == ".." || strings.HasPrefix(cleaned, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("entry '%s' would be extracted outside the archive directory", name)
+	}
+	target := filepath.Join(dest, cleaned)
+	if !project.PathContains(dest, target) {
+		return "", fmt.Errorf("entry '%s' would be extracted outside the archive directory", name)
+	}
+	return target, nil
+}
+
+// writeArchiveFile writes the contents of an archive entry to target, creating its directory.
+// Files keep their permission bits, but are always readable and writable by the user.
+func writeArchiveFile(target string, contents io.Reader, perm os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(target), os.ModePerm); err != nil {
+		return err
+	}
+	out, err := os.OpenFile(target, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, perm|0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, contents); err != nil {
+		_ = out.Close()
+		return fmt.Errorf("failed to extract %s: %w", target, err)
+	}
+	return out.Close()
+}
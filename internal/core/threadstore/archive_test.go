@@ -0,0 +1,162 @@
+package threadstore
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"loom/internal/core/globalconfig"
+)
+
+// useTempCache points os.UserCacheDir at a fresh directory for the test.
+func useTempCache(t *testing.T) {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("XDG_CACHE_HOME", filepath.Join(home, "cache"))
+	t.Setenv("HOME", home)
+	t.Setenv("LocalAppData", filepath.Join(home, "cache"))
+}
+
+// writeZip creates a zip archive at path holding the given slash-separated files and contents.
+func writeZip(t *testing.T, path string, files map[string]string) {
+	t.Helper()
+	out, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writer := zip.NewWriter(out)
+	for name, content := range files {
+		entry, err := writer.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := entry.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := out.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// writeTarGz creates a gzip-compressed tar archive at path holding the given files and contents.
+func writeTarGz(t *testing.T, path string, files map[string]string) {
+	t.Helper()
+	out, err := os.Create(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz := gzip.NewWriter(out)
+	writer := tar.NewWriter(gz)
+	for name, content := range files {
+		header := &tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}
+		if err := writer.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := writer.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := out.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestArchiveStoreListsAndResolvesThreads(t *testing.T) {
+	useTempCache(t)
+	archivePath := filepath.Join(t.TempDir(), "shared.zip")
+	writeZip(t, archivePath, map[string]string{
+		"go/_thread/.editorconfig":          "root = true\n",
+		"frontend/button/_thread/button.js": "export {}\n",
+	})
+
+	resolver, err := New(globalconfig.Store{Name: "shared", Type: globalconfig.StoreTypeArchive, Path: archivePath})
+	if err != nil {
+		t.Fatalf("New(archive) error = %v", err)
+	}
+	threads, err := resolver.ListThreads()
+	if err != nil {
+		t.Fatalf("ListThreads() error = %v", err)
+	}
+	want := []ThreadRef{{Store: "shared", Name: "frontend/button"}, {Store: "shared", Name: "go"}}
+	if !reflect.DeepEqual(threads, want) {
+		t.Errorf("ListThreads() = %v, want %v", threads, want)
+	}
+	threadDir, err := resolver.ResolveThread("go")
+	if err != nil {
+		t.Fatalf("ResolveThread(go) error = %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(threadDir, ".editorconfig")); err != nil || string(data) != "root = true\n" {
+		t.Errorf("extracted .editorconfig = %q, %v, want the archived contents", data, err)
+	}
+
+	// The extraction is reused, even after the cached copy is modified, until the archive changes.
+	marker := filepath.Join(threadDir, "marker")
+	if err := os.WriteFile(marker, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	again, err := New(globalconfig.Store{Name: "shared", Type: globalconfig.StoreTypeArchive, Path: archivePath})
+	if err != nil {
+		t.Fatalf("New(archive) again error = %v", err)
+	}
+	if again.(*LocalStore).Path != resolver.(*LocalStore).Path {
+		t.Errorf("second extraction = %s, want the cached %s", again.(*LocalStore).Path, resolver.(*LocalStore).Path)
+	}
+	if _, err := os.Stat(marker); err != nil {
+		t.Errorf("cached extraction was replaced: %v", err)
+	}
+}
+
+func TestArchiveStoreOfASingleThread(t *testing.T) {
+	useTempCache(t)
+	archivePath := filepath.Join(t.TempDir(), "go-ci.tar.gz")
+	writeTarGz(t, archivePath, map[string]string{
+		"_thread/.github/workflows/ci.yml": "on: push\n",
+		"config.yml":                       "metadata: {}\n",
+	})
+
+	resolver, err := New(globalconfig.Store{Name: "ci", Type: globalconfig.StoreTypeArchive, Path: archivePath})
+	if err != nil {
+		t.Fatalf("New(archive) error = %v", err)
+	}
+	threads, err := resolver.ListThreads()
+	if err != nil {
+		t.Fatalf("ListThreads() error = %v", err)
+	}
+	if want := []ThreadRef{{Store: "ci", Name: "go-ci"}}; !reflect.DeepEqual(threads, want) {
+		t.Errorf("ListThreads() = %v, want %v", threads, want)
+	}
+}
+
+func TestArchiveStoreRejectsEntriesOutsideTheExtraction(t *testing.T) {
+	useTempCache(t)
+	dir := t.TempDir()
+	for _, name := range []string{"../evil.txt", "go/_thread/../../../evil.txt", "/etc/evil.txt"} {
+		archivePath := filepath.Join(dir, "evil.zip")
+		writeZip(t, archivePath, map[string]string{"go/_thread/ok.txt": "ok", name: "pwned"})
+		_, err := New(globalconfig.Store{Name: "evil", Type: globalconfig.StoreTypeArchive, Path: archivePath})
+		if err == nil || !strings.Contains(err.Error(), "outside the archive directory") {
+			t.Errorf("New(archive with %q) error = %v, want it rejected", name, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "evil.txt")); err == nil {
+		t.Error("an entry was written outside the extraction directory")
+	}
+	if entries, _ := os.ReadDir(ArchiveCacheDir()); len(entries) != 0 {
+		t.Errorf("archive cache holds %d entries after failed extractions, want none", len(entries))
+	}
+}
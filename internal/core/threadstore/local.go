@@ -0,0 +1,82 @@
+package threadstore
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"loom/internal/core/project"
+	"loom/internal/core/threadconfig"
+)
+
+// LocalStore is a store in a directory on this machine. Each thread is a directory holding a
+// _thread directory, a config.yml (or config.yaml), or both; other directories may group threads
+// into categories.
+type LocalStore struct {
+	Name string
+	Path string
+}
+
+// ResolveThread returns the _thread directory of the thread at threadPath. The thread must not
+// lead outside the store, even through a symlinked category directory.
+func (s *LocalStore) ResolveThread(threadPath string) (string, error) {
+	if err := project.ValidateThreadPath(threadPath); err != nil {
+		return "", err
+	}
+	sourceDir := filepath.Join(s.Path, filepath.FromSlash(threadPath), threadconfig.SourceDirName)
+	info, err := os.Stat(sourceDir)
+	if os.IsNotExist(err) {
+		return "", fmt.Errorf("thread '%s' in store '%s': %w", threadPath, s.Name, ErrThreadNotFound)
+	} else if err != nil {
+		return "", fmt.Errorf("error accessing thread '%s' in store '%s' (%s): %w", threadPath, s.Name, sourceDir, err)
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("thread path '%s' in store '%s' is a file, not a directory", sourceDir, s.Name)
+	}
+	if !project.PathContains(s.Path, sourceDir) {
+		return "", fmt.Errorf("thread '%s' in store '%s' resolves to %s, outside the store", threadPath, s.Name, sourceDir)
+	}
+	return sourceDir, nil
+}
+
+// ListThreads lists the threads in the store, including threads nested in category directories.
+// Symlinked and hidden directories are not searched.
+func (s *LocalStore) ListThreads() ([]ThreadRef, error) {
+	var threads []ThreadRef
+	if err := s.collectThreads("", &threads); err != nil {
+		return nil, fmt.Errorf("failed to read store directory '%s': %w", s.Path, err)
+	}
+	return threads, nil
+}
+
+// collectThreads appends the threads found in relDir, a slash-separated directory relative to the
+// store, to threads, descending into directories that are not threads themselves.
+func (s *LocalStore) collectThreads(relDir string, threads *[]ThreadRef) error {
+	entries, err := os.ReadDir(filepath.Join(s.Path, filepath.FromSlash(relDir)))
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		threadName := path.Join(relDir, entry.Name())
+		threadDir := filepath.Join(s.Path, filepath.FromSlash(threadName))
+		// Check for config.yml (or config.yaml) or _thread/ directory to qualify as a thread
+		_, hasConfig := threadconfig.ConfigFilePath(threadDir)
+		_, errDir := os.Stat(filepath.Join(threadDir, threadconfig.SourceDirName))
+		if hasConfig || errDir == nil { // If either exists, it's a thread
+			*threads = append(*threads, ThreadRef{Store: s.Name, Name: threadName})
+			continue
+		}
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue // Such as .git; never a category of threads
+		}
+		if err := s.collectThreads(threadName, threads); err != nil {
+			return err
+		}
+	}
+	return nil
+}
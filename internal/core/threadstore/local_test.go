@@ -0,0 +1,136 @@
+package threadstore
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"loom/internal/core/globalconfig"
+)
+
+// makeDirs creates each slash-separated directory under root.
+func makeDirs(t *testing.T, root string, dirs ...string) {
+	t.Helper()
+	for _, dir := range dirs {
+		if err := os.MkdirAll(filepath.Join(root, filepath.FromSlash(dir)), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestLocalStoreResolveThread(t *testing.T) {
+	storeDir := t.TempDir()
+	makeDirs(t, storeDir, "go/_thread", "frontend/button/_thread", "broken")
+	if err := os.WriteFile(filepath.Join(storeDir, "broken", "_thread"), []byte("not a dir"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	store := &LocalStore{Name: "company", Path: storeDir}
+
+	got, err := store.ResolveThread("go")
+	if err != nil {
+		t.Fatalf("ResolveThread(go) error = %v", err)
+	}
+	if want := filepath.Join(storeDir, "go", "_thread"); got != want {
+		t.Errorf("ResolveThread(go) = %q, want %q", got, want)
+	}
+
+	got, err = store.ResolveThread("frontend/button")
+	if err != nil {
+		t.Fatalf("ResolveThread(frontend/button) error = %v", err)
+	}
+	if want := filepath.Join(storeDir, "frontend", "button", "_thread"); got != want {
+		t.Errorf("ResolveThread(frontend/button) = %q, want %q", got, want)
+	}
+
+	if _, err := store.ResolveThread("missing"); !errors.Is(err, ErrThreadNotFound) {
+		t.Errorf("ResolveThread(missing) error = %v, want ErrThreadNotFound", err)
+	}
+	if _, err := store.ResolveThread("broken"); err == nil || errors.Is(err, ErrThreadNotFound) {
+		t.Errorf("ResolveThread(broken) error = %v, want a not-a-directory error", err)
+	}
+	if _, err := store.ResolveThread("../go"); err == nil {
+		t.Error("ResolveThread(../go) succeeded, want an invalid path error")
+	}
+}
+
+func TestLocalStoreListThreads(t *testing.T) {
+	storeDir := t.TempDir()
+	makeDirs(t, storeDir, "go/_thread", "frontend/button/_thread", "frontend/notes", ".git/hooks/_thread")
+	if err := os.WriteFile(filepath.Join(storeDir, "README.md"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(storeDir, "ci"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(storeDir, "ci", "config.yml"), []byte("metadata: {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(storeDir, "docs"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(storeDir, "docs", "config.yaml"), []byte("metadata: {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	threads, err := (&LocalStore{Name: "company", Path: storeDir}).ListThreads()
+	if err != nil {
+		t.Fatalf("ListThreads() error = %v", err)
+	}
+	want := []ThreadRef{
+		{Store: "company", Name: "ci"},
+		{Store: "company", Name: "docs"},
+		{Store: "company", Name: "frontend/button"},
+		{Store: "company", Name: "go"},
+	}
+	if !reflect.DeepEqual(threads, want) {
+		t.Errorf("ListThreads() = %v, want %v", threads, want)
+	}
+
+	if _, err := (&LocalStore{Name: "gone", Path: filepath.Join(storeDir, "missing")}).ListThreads(); err == nil {
+		t.Error("ListThreads() on a missing store succeeded, want an error")
+	}
+}
+
+func TestLocalStoreFromProjectStoreDirectory(t *testing.T) {
+	storeDir := filepath.Join(t.TempDir(), "other-project", ".loom")
+	makeDirs(t, storeDir, "ci/_thread", "lint/_thread", "frontend/button/_thread")
+	store := &LocalStore{Name: "shared", Path: storeDir}
+
+	threads, err := store.ListThreads()
+	if err != nil {
+		t.Fatalf("ListThreads() error = %v", err)
+	}
+	want := []ThreadRef{
+		{Store: "shared", Name: "ci"},
+		{Store: "shared", Name: "frontend/button"},
+		{Store: "shared", Name: "lint"},
+	}
+	if !reflect.DeepEqual(threads, want) {
+		t.Errorf("ListThreads() = %v, want %v", threads, want)
+	}
+	if sourceDir, err := store.ResolveThread("ci"); err != nil || sourceDir != filepath.Join(storeDir, "ci", "_thread") {
+		t.Errorf("ResolveThread(ci) = %s, %v, want the thread's _thread directory", sourceDir, err)
+	}
+}
+
+func TestNewChoosesResolverByType(t *testing.T) {
+	resolver, err := New(globalconfig.Store{Name: "local", Type: globalconfig.StoreTypeLocal, Path: t.TempDir()})
+	if err != nil {
+		t.Fatalf("New(local) error = %v", err)
+	}
+	if _, ok := resolver.(*LocalStore); !ok {
+		t.Errorf("New(local) = %T, want *LocalStore", resolver)
+	}
+
+	if _, err := New(globalconfig.Store{Name: "remote", Type: "git", Path: "https://example.com/threads.git"}); !errors.Is(err, ErrUnsupportedType) {
+		t.Errorf("New(git) error = %v, want ErrUnsupportedType", err)
+	}
+
+	checkout := t.TempDir()
+	path, ok := LocalPath(globalconfig.Store{Name: "tree", Type: globalconfig.StoreTypeGit, Path: "https://example.com/threads.git", Checkout: checkout})
+	if !ok || path != checkout {
+		t.Errorf("LocalPath(git with checkout) = %q, %v, want %q, true", path, ok, checkout)
+	}
+}
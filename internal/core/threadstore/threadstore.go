@@ -0,0 +1,71 @@
+// Package threadstore resolves and lists the threads offered by the stores in the global
+// configuration, independently of how each type of store keeps them.
+package threadstore
+
+import (
+	"errors"
+	"fmt"
+
+	"loom/internal/core/globalconfig"
+)
+
+// ErrThreadNotFound is returned, wrapped, by Resolver.ResolveThread when a store has no such thread.
+var ErrThreadNotFound = errors.New("thread not found")
+
+// ErrUnsupportedType is returned, wrapped, by New for stores whose type cannot be resolved yet.
+var ErrUnsupportedType = errors.New("store type cannot be resolved yet")
+
+// ThreadRef names a thread offered by a store.
+type ThreadRef struct {
+	// Store is the name of the store offering the thread.
+	Store string
+	// Name is the thread's slash-separated path in the store, such as "frontend/button" for a
+	// thread nested in a category directory.
+	Name string
+}
+
+// Resolver finds the threads of one store. LocalStore is the only implementation so far: git
+// stores with a checkout and archive stores, once extracted, are read through it as well.
+type Resolver interface {
+	// ResolveThread returns the _thread directory of the thread at threadPath, a slash-separated
+	// path that may name a nested thread. It fails with ErrThreadNotFound if the store has no
+	// such thread.
+	ResolveThread(threadPath string) (string, error)
+	// ListThreads returns every thread the store offers, nested ones included.
+	ListThreads() ([]ThreadRef, error)
+}
+
+// LocalPath returns the directory on this machine that the threads of store are read from, if any.
+func LocalPath(store globalconfig.Store) (string, bool) {
+	resolver, err := New(store)
+	if err != nil {
+		return "", false
+	}
+	local, ok := resolver.(*LocalStore)
+	if !ok {
+		return "", false
+	}
+	return local.Path, true
+}
+
+// New returns the Resolver for store, chosen by its type.
+func New(store globalconfig.Store) (Resolver, error) {
+	switch store.Type {
+	case globalconfig.StoreTypeLocal:
+		return &LocalStore{Name: store.Name, Path: store.Path}, nil
+	case globalconfig.StoreTypeGit:
+		if store.Checkout != "" {
+			// Registered from a working tree, which is read in place.
+			return &LocalStore{Name: store.Name, Path: store.Checkout}, nil
+		}
+		return nil, fmt.Errorf("store '%s' has type '%s' and no checkout: %w", store.Name, store.Type, ErrUnsupportedType)
+	case globalconfig.StoreTypeArchive:
+		dir, err := extractArchive(store.Path)
+		if err != nil {
+			return nil, fmt.Errorf("store '%s': %w", store.Name, err)
+		}
+		return &LocalStore{Name: store.Name, Path: dir}, nil
+	default:
+		return nil, fmt.Errorf("store '%s' has type '%s': %w", store.Name, store.Type, ErrUnsupportedType)
+	}
+}
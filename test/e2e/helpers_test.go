@@ -4,12 +4,64 @@ package e2e_test
 import (
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"runtime"
+	"strings"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/gexec"
 )
 
+// loomExecutablePath returns the path of the loom binary built into the repository's build directory.
+func loomExecutablePath() string {
+	basePath, err := filepath.Abs("../..")
+	Expect(err).NotTo(HaveOccurred())
+	if runtime.GOOS == "windows" {
+		return filepath.Join(basePath, "build", "loom.exe")
+	}
+	return filepath.Join(basePath, "build", "loom")
+}
+
+// runLoom starts loom with args in dir, reading its global configuration from globalDir, or from a
+// new empty directory if globalDir is "". The caller's LOOM_GLOBAL_DIR is never inherited. stdin,
+// if not empty, is fed to the command's standard input.
+func runLoom(dir, globalDir, stdin string, args ...string) *gexec.Session {
+	return runLoomWithEnv(dir, globalDir, stdin, nil, args...)
+}
+
+// runLoomWithEnv is runLoom with extra "KEY=value" environment variables, which replace any
+// inherited value of the same keys.
+func runLoomWithEnv(dir, globalDir, stdin string, env []string, args ...string) *gexec.Session {
+	if globalDir == "" {
+		globalDir = CreateTempDir()
+	}
+	env = append([]string{"LOOM_GLOBAL_DIR=" + globalDir}, env...)
+	command := exec.Command(loomExecutablePath(), args...)
+	command.Dir = dir
+	if stdin != "" {
+		command.Stdin = strings.NewReader(stdin)
+	}
+	for _, e := range os.Environ() {
+		overridden := false
+		for _, override := range env {
+			key, _, _ := strings.Cut(override, "=")
+			if strings.HasPrefix(e, key+"=") {
+				overridden = true
+				break
+			}
+		}
+		if !overridden {
+			command.Env = append(command.Env, e)
+		}
+	}
+	command.Env = append(command.Env, env...)
+	session, err := gexec.Start(command, GinkgoWriter, GinkgoWriter)
+	Expect(err).NotTo(HaveOccurred())
+	return session
+}
+
 // CreateTempDir creates a temporary directory for testing and returns its path.
 // The directory will be removed when the test completes.
 func CreateTempDir() string {
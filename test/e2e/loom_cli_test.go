@@ -2,6 +2,8 @@
 package e2e_test
 
 import (
+	"archive/zip"
+	"encoding/json"
 	"fmt"
 	"os"
 	"os/exec"
@@ -20,15 +22,7 @@ var _ = Describe("Loom CLI", func() {
 	var loomExecutable string
 
 	BeforeEach(func() {
-		basePath, err := filepath.Abs("../..")
-		Expect(err).NotTo(HaveOccurred())
-
-		if runtime.GOOS == "windows" {
-			loomExecutable = filepath.Join(basePath, "build", "loom.exe")
-		} else {
-			loomExecutable = filepath.Join(basePath, "build", "loom")
-		}
-
+		loomExecutable = loomExecutablePath()
 		Expect(loomExecutable).To(BeAnExistingFile(), "Loom executable not found at "+loomExecutable+". Make sure to build it before running tests.")
 	})
 
@@ -174,6 +168,39 @@ var _ = Describe("Loom CLI", func() {
 				Expect(string(yamlContent)).To(ContainSubstring("version: \"1\""))
 			})
 		})
+
+		Context("when seeding threads with --template and --with-thread", func() {
+			var globalDir string
+
+			BeforeEach(func() {
+				globalDir = CreateTempDir()
+				storeDir := filepath.Join(CreateTempDir(), "team")
+				CreateTempFile(filepath.Join(storeDir, "go-ci", "_thread"), "ci.yml", "ci")
+				CreateTempFile(filepath.Join(tempTestDir, ".loom", "local-thread", "_thread"), "x.txt", "x")
+				globalConfig := "version: \"1\"\nstores:\n  - name: team\n    type: local\n    path: \"" + filepath.ToSlash(storeDir) + "\"\ntemplates:\n  - name: go-service\n    threads: [team/go-ci]\n"
+				Expect(os.WriteFile(filepath.Join(globalDir, "loom.yaml"), []byte(globalConfig), 0644)).To(Succeed())
+			})
+
+			It("should list the template's threads and the --with-thread ones without writing any file", func() {
+				session := runLoom(tempTestDir, globalDir, "", "init", "--template", "go-service", "--with-thread", "local-thread", "--with-thread", "team/go-ci")
+				Eventually(session, "10s").Should(gexec.Exit(0))
+				Expect(session.Out).To(gbytes.Say(`Initialized Loom project with 2 thread\(s\) in loom.yaml`))
+
+				yamlContent, err := os.ReadFile(filepath.Join(tempTestDir, "loom.yaml"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(yamlContent)).To(MatchRegexp(`name: go-ci\s+source: team\b`))
+				Expect(string(yamlContent)).To(MatchRegexp(`name: local-thread\s+source: project:.loom/local-thread`))
+				Expect(strings.Count(string(yamlContent), "name: go-ci")).To(Equal(1))
+				Expect(filepath.Join(tempTestDir, "ci.yml")).NotTo(BeAnExistingFile())
+			})
+
+			It("should reject an unknown template and name the available ones", func() {
+				session := runLoom(tempTestDir, globalDir, "", "init", "--template", "rust-service")
+				Eventually(session, "10s").Should(gexec.Exit(2))
+				Expect(session.Err).To(gbytes.Say(`template 'rust-service' not found \(available: go-service\)`))
+				Expect(filepath.Join(tempTestDir, "loom.yaml")).NotTo(BeAnExistingFile())
+			})
+		})
 	})
 
 	Describe("loom add functionality", func() {
@@ -221,19 +248,7 @@ stores:
 				CreateTempFile(mockThreadSourceDir, "file1.txt", "content of file1")
 				CreateTempFile(filepath.Join(mockThreadSourceDir, "subdir"), "file2.txt", "content of file2")
 
-				command := exec.Command(loomExecutable, "add", mockThreadName)
-				command.Dir = tempProjectDir
-
-				env := []string{}
-				for _, e := range os.Environ() {
-					if !strings.HasPrefix(e, "LOOM_GLOBAL_DIR=") {
-						env = append(env, e)
-					}
-				}
-				command.Env = append(env, "LOOM_GLOBAL_DIR="+tempGlobalLoomDir)
-
-				session, err := gexec.Start(command, GinkgoWriter, GinkgoWriter)
-				Expect(err).NotTo(HaveOccurred())
+				session := runLoom(tempProjectDir, tempGlobalLoomDir, "", "add", mockThreadName)
 				Eventually(session, "10s").Should(gexec.Exit(0))
 
 				Expect(session.Out).To(gbytes.Say("Thread 'myTestThread' added successfully from myStore"))
@@ -255,6 +270,100 @@ stores:
 			})
 		})
 
+		Context("when reporting what happened to the thread's files", func() {
+			It("should print created/overwritten/skipped counts and a JSON result with --json", func() {
+				CreateTempFile(filepath.Join(mockStorePath, "threadA", "_thread"), "a.txt", "a")
+				CreateTempFile(filepath.Join(mockStorePath, "threadA", "_thread"), "b.txt", "b")
+
+				session := runLoom(tempProjectDir, tempGlobalLoomDir, "", "add", "threadA")
+				Eventually(session, "10s").Should(gexec.Exit(0))
+				Expect(session.Out).To(gbytes.Say("2 created, 0 overwritten, 0 skipped"))
+
+				// Only the edited file is rewritten; the other already matches the thread.
+				Expect(os.WriteFile(filepath.Join(tempProjectDir, "a.txt"), []byte("edited"), 0644)).To(Succeed())
+				session = runLoom(tempProjectDir, tempGlobalLoomDir, "", "add", "--json", "threadA")
+				Eventually(session, "10s").Should(gexec.Exit(0))
+				var result struct {
+					Added []struct {
+						Thread      string `json:"thread"`
+						Overwritten int    `json:"overwritten"`
+					} `json:"added"`
+					Totals struct {
+						Created     int `json:"created"`
+						Overwritten int `json:"overwritten"`
+						Unchanged   int `json:"unchanged"`
+					} `json:"totals"`
+				}
+				Expect(json.Unmarshal(session.Out.Contents(), &result)).To(Succeed())
+				Expect(string(session.Err.Contents())).To(ContainSubstring("Thread 'threadA' added successfully"))
+				Expect(result.Added).To(HaveLen(1))
+				Expect(result.Added[0].Thread).To(Equal("threadA"))
+				Expect(result.Totals.Created).To(Equal(0))
+				Expect(result.Totals.Overwritten).To(Equal(1))
+				Expect(result.Totals.Unchanged).To(Equal(1))
+			})
+		})
+
+		Context("when an existing file already matches the thread", func() {
+			It("should keep it without prompting and record it for the thread", func() {
+				CreateTempFile(filepath.Join(mockStorePath, "threadA", "_thread"), "a.txt", "same")
+				CreateTempFile(tempProjectDir, "a.txt", "same")
+
+				session := runLoom(tempProjectDir, tempGlobalLoomDir, "", "add", "threadA")
+				Eventually(session, "10s").Should(gexec.Exit(0))
+				Expect(string(session.Out.Contents())).NotTo(ContainSubstring("not currently owned"))
+				Expect(string(session.Out.Contents())).To(ContainSubstring("0 created, 0 overwritten, 0 skipped, 1 unchanged"))
+
+				yamlContent, err := os.ReadFile(filepath.Join(tempProjectDir, "loom.yaml"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(yamlContent)).To(ContainSubstring("- a.txt"))
+			})
+		})
+
+		Context("when adding multiple threads in one invocation", func() {
+			It("should add each thread and print a summary with file counts", func() {
+				CreateTempFile(filepath.Join(mockStorePath, "threadA", "_thread"), "a.txt", "a")
+				CreateTempFile(filepath.Join(mockStorePath, "threadB", "_thread"), "b1.txt", "b1")
+				CreateTempFile(filepath.Join(mockStorePath, "threadB", "_thread"), "b2.txt", "b2")
+
+				session := runLoom(tempProjectDir, tempGlobalLoomDir, "", "add", "threadA", "myStore/threadB")
+				Eventually(session, "10s").Should(gexec.Exit(0))
+
+				Expect(session.Out).To(gbytes.Say(regexp.QuoteMeta("threadA (from myStore): 1 file(s)")))
+				Expect(session.Out).To(gbytes.Say(regexp.QuoteMeta("myStore/threadB (from myStore): 2 file(s)")))
+
+				yamlContent, err := os.ReadFile(filepath.Join(tempProjectDir, "loom.yaml"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(yamlContent)).To(ContainSubstring("name: threadA"))
+				Expect(string(yamlContent)).To(ContainSubstring("name: threadB"))
+			})
+
+			It("should keep going with --continue-on-error and report the failed thread", func() {
+				CreateTempFile(filepath.Join(mockStorePath, "threadA", "_thread"), "a.txt", "a")
+
+				session := runLoom(tempProjectDir, tempGlobalLoomDir, "", "add", "--continue-on-error", "missingThread", "threadA")
+				// Like a single add of a missing thread, a usage error.
+				Eventually(session, "10s").Should(gexec.Exit(2))
+
+				Expect(session.Err).To(gbytes.Say("failed to add thread 'missingThread'"))
+				Expect(session.Err).To(gbytes.Say(`failed to add 1 of 2 thread\(s\): missingThread`))
+				Expect(filepath.Join(tempProjectDir, "a.txt")).To(BeAnExistingFile())
+			})
+		})
+
+		Context("when the store contains the project root", func() {
+			It("should refuse to add from it", func() {
+				projectDir := filepath.Join(mockStorePath, "myProject")
+				Expect(os.MkdirAll(projectDir, 0755)).To(Succeed())
+				CreateTempFile(filepath.Join(mockStorePath, "threadA", "_thread"), "a.txt", "a")
+
+				session := runLoom(projectDir, tempGlobalLoomDir, "", "add", "threadA")
+				Eventually(session, "10s").Should(gexec.Exit(1))
+				Expect(session.Err).To(gbytes.Say("contains the project root"))
+				Expect(filepath.Join(projectDir, "a.txt")).NotTo(BeAnExistingFile())
+			})
+		})
+
 		Context("when adding a thread that is malformed (e.g., _thread is a file)", func() {
 			It("should output an error and not add the thread", func() {
 				mockThreadName := "malformedThread"
@@ -264,19 +373,7 @@ stores:
 
 				CreateTempFile(mockThreadDir, "_thread", "this is a file, not a directory")
 
-				command := exec.Command(loomExecutable, "add", mockThreadName)
-				command.Dir = tempProjectDir
-
-				env := []string{}
-				for _, e := range os.Environ() {
-					if !strings.HasPrefix(e, "LOOM_GLOBAL_DIR=") {
-						env = append(env, e)
-					}
-				}
-				command.Env = append(env, "LOOM_GLOBAL_DIR="+tempGlobalLoomDir)
-
-				session, err := gexec.Start(command, GinkgoWriter, GinkgoWriter)
-				Expect(err).NotTo(HaveOccurred())
+				session := runLoom(tempProjectDir, tempGlobalLoomDir, "", "add", mockThreadName)
 				Eventually(session, "10s").Should(gexec.Exit(1))
 
 				rawExpectedErrorMsg := "thread path '" + filepath.Join(mockStorePath, mockThreadName, "_thread") + "' in store 'myStore' is a file, not a directory"
@@ -302,19 +399,8 @@ stores:
 		var tempProjectDir string
 		var tempGlobalLoomDir string
 		var originalLoomGlobalDirEnv string
-		var loomExecPath string
 
 		BeforeEach(func() {
-			basePath, err := filepath.Abs("../..")
-			Expect(err).NotTo(HaveOccurred())
-
-			if runtime.GOOS == "windows" {
-				loomExecPath = filepath.Join(basePath, "build", "loom.exe")
-			} else {
-				loomExecPath = filepath.Join(basePath, "build", "loom")
-			}
-			Expect(loomExecPath).To(BeAnExistingFile(), "Loom executable not found at "+loomExecPath)
-
 			tempProjectDir = CreateTempDir()
 			tempGlobalLoomDir = CreateTempDir()
 			originalLoomGlobalDirEnv, _ = os.LookupEnv("LOOM_GLOBAL_DIR")
@@ -332,27 +418,11 @@ stores:
 			Expect(err).NotTo(HaveOccurred())
 		})
 
-		runLoomAdd := func(args ...string) *gexec.Session {
-			command := exec.Command(loomExecPath, append([]string{"add"}, args...)...)
-			command.Dir = tempProjectDir
-			env := os.Environ()
-			filteredEnv := []string{}
-			for _, e := range env {
-				if !strings.HasPrefix(e, "LOOM_GLOBAL_DIR=") {
-					filteredEnv = append(filteredEnv, e)
-				}
-			}
-			command.Env = append(filteredEnv, "LOOM_GLOBAL_DIR="+tempGlobalLoomDir)
-			session, err := gexec.Start(command, GinkgoWriter, GinkgoWriter)
-			Expect(err).NotTo(HaveOccurred())
-			return session
-		}
-
 		Describe("Argument Parsing", func() {
 			Context("when running 'loom add' with no arguments", func() {
 				It("should fail with a usage message", func() {
-					session := runLoomAdd()
-					Eventually(session).Should(gexec.Exit(1))
+					session := runLoom(tempProjectDir, tempGlobalLoomDir, "", "add")
+					Eventually(session).Should(gexec.Exit(2))
 					Expect(session.Err).To(gbytes.Say("thread name or store/thread is required"))
 					Expect(session.Out.Contents()).To(BeEmpty())
 				})
@@ -360,27 +430,43 @@ stores:
 
 			Context("when running 'loom add /'", func() {
 				It("should fail due to invalid format (empty store and thread name)", func() {
-					session := runLoomAdd("/")
-					Eventually(session).Should(gexec.Exit(1))
+					session := runLoom(tempProjectDir, tempGlobalLoomDir, "", "add", "/")
+					Eventually(session).Should(gexec.Exit(2))
 					Expect(session.Err).To(gbytes.Say(regexp.QuoteMeta("invalid format for store/thread: '/'. Both store name and thread name must be specified")))
 				})
 			})
 
 			Context("when running 'loom add store/'", func() {
 				It("should fail due to invalid format (missing thread name)", func() {
-					session := runLoomAdd("storeName/")
-					Eventually(session).Should(gexec.Exit(1))
+					session := runLoom(tempProjectDir, tempGlobalLoomDir, "", "add", "storeName/")
+					Eventually(session).Should(gexec.Exit(2))
 					Expect(session.Err).To(gbytes.Say(regexp.QuoteMeta("invalid format for store/thread: 'storeName/'. Both store name and thread name must be specified")))
 				})
 			})
 
 			Context("when running 'loom add /thread'", func() {
 				It("should fail due to invalid format (missing store name)", func() {
-					session := runLoomAdd("/threadName")
-					Eventually(session).Should(gexec.Exit(1))
+					session := runLoom(tempProjectDir, tempGlobalLoomDir, "", "add", "/threadName")
+					Eventually(session).Should(gexec.Exit(2))
 					Expect(session.Err).To(gbytes.Say(regexp.QuoteMeta("invalid format for store/thread: '/threadName'. Both store name and thread name must be specified")))
 				})
 			})
+
+			Context("when the thread name would escape the store directory", func() {
+				It("should reject '..' anywhere in the thread path", func() {
+					session := runLoom(tempProjectDir, tempGlobalLoomDir, "", "add", "storeName/../../etc")
+					Eventually(session).Should(gexec.Exit(2))
+					Expect(session.Err).To(gbytes.Say(regexp.QuoteMeta("invalid thread path '../../etc'")))
+
+					session = runLoom(tempProjectDir, tempGlobalLoomDir, "", "add", "storeName/frontend/../button")
+					Eventually(session).Should(gexec.Exit(2))
+					Expect(session.Err).To(gbytes.Say(regexp.QuoteMeta("invalid thread path 'frontend/../button'")))
+
+					session = runLoom(tempProjectDir, tempGlobalLoomDir, "", "add", "..")
+					Eventually(session).Should(gexec.Exit(2))
+					Expect(session.Err).To(gbytes.Say(regexp.QuoteMeta("invalid thread name '..'")))
+				})
+			})
 		})
 
 		Describe("Thread Source and Resolution", func() {
@@ -407,7 +493,7 @@ stores:
 					err = os.WriteFile(sampleFilePath, []byte("This is a local thread."), 0644)
 					Expect(err).NotTo(HaveOccurred())
 
-					session := runLoomAdd(threadName)
+					session := runLoom(tempProjectDir, tempGlobalLoomDir, "", "add", threadName)
 					Eventually(session).Should(gexec.Exit(0))
 					Expect(session.Out).To(gbytes.Say(regexp.QuoteMeta("Thread '" + threadName + "' added successfully from project:.loom/" + threadName)))
 
@@ -444,7 +530,7 @@ stores:
 				})
 
 				It("should successfully add the thread from the specified global store", func() {
-					session := runLoomAdd("myStore/myTestThread")
+					session := runLoom(tempProjectDir, tempGlobalLoomDir, "", "add", "myStore/myTestThread")
 					Eventually(session).Should(gexec.Exit(0))
 					Expect(session.Out).To(gbytes.Say("myTestThread"))
 					Expect(session.Out).To(gbytes.Say("myStore"))
@@ -475,8 +561,8 @@ stores:
 				})
 
 				It("should fail and report that the thread was not found in the store", func() {
-					session := runLoomAdd("anotherStore/nonExistentThread")
-					Eventually(session).Should(gexec.Exit(1))
+					session := runLoom(tempProjectDir, tempGlobalLoomDir, "", "add", "anotherStore/nonExistentThread")
+					Eventually(session).Should(gexec.Exit(2))
 					Expect(session.Err).To(gbytes.Say(regexp.QuoteMeta("thread 'nonExistentThread' not found in specified store 'anotherStore'")))
 				})
 			})
@@ -487,9 +573,9 @@ stores:
 					err := os.WriteFile(globalLoomConfigPath, []byte("stores: []"), 0644)
 					Expect(err).NotTo(HaveOccurred())
 
-					session := runLoomAdd("unknownStore/anyThread")
-					Eventually(session).Should(gexec.Exit(1))
-					Expect(session.Err).To(gbytes.Say(regexp.QuoteMeta("store 'unknownStore' not found in global configuration")))
+					session := runLoom(tempProjectDir, tempGlobalLoomDir, "", "add", "unknownStore/anyThread")
+					Eventually(session).Should(gexec.Exit(2))
+					Expect(session.Err).To(gbytes.Say(regexp.QuoteMeta("store 'unknownStore' not found in loom.yaml or the global configuration")))
 				})
 			})
 
@@ -500,8 +586,8 @@ stores:
 					Expect(err).NotTo(HaveOccurred())
 				})
 				It("should fail and report that the thread could not be found", func() {
-					session := runLoomAdd("completelyMissingThread")
-					Eventually(session).Should(gexec.Exit(1))
+					session := runLoom(tempProjectDir, tempGlobalLoomDir, "", "add", "completelyMissingThread")
+					Eventually(session).Should(gexec.Exit(2))
 
 					Expect(session.Err).To(gbytes.Say("completelyMissingThread"))
 					Expect(session.Err).To(gbytes.Say("not found"))
@@ -519,4 +605,2727 @@ stores:
 		Describe("Extraneous Arguments", func() {
 		})
 	})
+
+	Describe("loom config add functionality", func() {
+		var tempProjectDir string
+		var tempGlobalLoomDir string
+		var storeDir string
+
+		BeforeEach(func() {
+			tempProjectDir = CreateTempDir()
+			tempGlobalLoomDir = CreateTempDir()
+			storeDir = filepath.Join(CreateTempDir(), "threads")
+			Expect(os.MkdirAll(storeDir, 0755)).To(Succeed())
+		})
+
+		Context("when --init is passed", func() {
+			It("should create a missing directory and register it as a local store", func() {
+				newDir := filepath.Join(CreateTempDir(), "fresh", "threads")
+				session := runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "add", newDir)
+				Eventually(session).Should(gexec.Exit(2))
+				Expect(session.Err).To(gbytes.Say(`use --init to create it`))
+
+				session = runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "add", "--init", newDir)
+				Eventually(session).Should(gexec.Exit(0))
+				Expect(session.Out).To(gbytes.Say(`Created directory "` + regexp.QuoteMeta(newDir) + `"`))
+				Expect(session.Out).To(gbytes.Say(`Successfully added local store "threads"`))
+				Expect(newDir).To(BeADirectory())
+			})
+
+			It("should refuse to create anything for a remote store", func() {
+				session := runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "add", "--init", "--type", "git", "--path", "https://example.com/threads.git")
+				Eventually(session).Should(gexec.Exit(2))
+				Expect(session.Err).To(gbytes.Say(`--init only applies to local stores`))
+			})
+		})
+
+		Context("when a store type is unknown", func() {
+			It("should refuse to add it and let set-type correct a mistyped store", func() {
+				session := runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "add", "--type", "ftp", "--path", storeDir)
+				Eventually(session).Should(gexec.Exit(2))
+				Expect(session.Err).To(gbytes.Say(`unknown store type "ftp" \(valid types: local, github, git, archive\)`))
+
+				CreateTempFile(filepath.Join(storeDir, "go-ci", "_thread"), "ci.yml", "ci")
+				globalConfig := "version: \"1\"\nstores:\n  - name: threads\n    type: locl\n    path: \"" + filepath.ToSlash(storeDir) + "\"\n"
+				Expect(os.WriteFile(filepath.Join(tempGlobalLoomDir, "loom.yaml"), []byte(globalConfig), 0644)).To(Succeed())
+				session = runLoom(tempProjectDir, tempGlobalLoomDir, "", "list", "--available")
+				Eventually(session).Should(gexec.Exit(0))
+				Expect(session.Err).To(gbytes.Say(`store 'threads' has an unknown store type "locl".*loom config set-type threads <type>`))
+
+				session = runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "set-type", "threads", "gitt")
+				Eventually(session).Should(gexec.Exit(2))
+				Expect(session.Err).To(gbytes.Say(`unknown store type "gitt"`))
+
+				session = runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "set-type", "threads", "local")
+				Eventually(session).Should(gexec.Exit(0))
+				Expect(session.Out).To(gbytes.Say(`Changed type of store "threads" from "locl" to "local"`))
+				session = runLoom(tempProjectDir, tempGlobalLoomDir, "", "list", "--available")
+				Eventually(session).Should(gexec.Exit(0))
+				Expect(session.Out).To(gbytes.Say(`- go-ci`))
+			})
+		})
+
+		Context("when --type and --path are passed", func() {
+			It("should take them as given instead of inferring the store from the path", func() {
+				session := runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "add", "--type", "git", "--path", storeDir, "--name", "mirror")
+				Eventually(session).Should(gexec.Exit(0))
+				Expect(session.Out).To(gbytes.Say(`Successfully added git store "mirror"`))
+				globalConfig, err := os.ReadFile(filepath.Join(tempGlobalLoomDir, "loom.yaml"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(globalConfig)).To(MatchRegexp(`name: mirror\s+type: git`))
+
+				session = runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "add", "--type", "local", storeDir)
+				Eventually(session).Should(gexec.Exit(2))
+				Expect(session.Err).To(gbytes.Say(`--type and --path must be used together`))
+			})
+
+			It("should register a missing local path only with --no-verify", func() {
+				laterDir := filepath.Join(CreateTempDir(), "mounted-later")
+				session := runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "add", "--type", "local", "--path", laterDir)
+				Eventually(session).Should(gexec.Exit(2))
+				Expect(session.Err).To(gbytes.Say(`does not exist \(use --no-verify to register it anyway\)`))
+
+				session = runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "add", "--type", "local", "--path", laterDir, "--no-verify")
+				Eventually(session).Should(gexec.Exit(0))
+				Expect(session.Out).To(gbytes.Say(`Successfully added local store "mounted-later"`))
+				Expect(laterDir).NotTo(BeADirectory())
+			})
+		})
+
+		Context("when a local store is added", func() {
+			It("should report how many threads it holds", func() {
+				storeDir := filepath.Join(CreateTempDir(), "threads")
+				CreateTempFile(filepath.Join(storeDir, "go-ci", "_thread"), "ci.yml", "ci")
+				CreateTempFile(filepath.Join(storeDir, "frontend", "button", "_thread"), "button.css", "button")
+				session := runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "add", storeDir)
+				Eventually(session).Should(gexec.Exit(0))
+				Expect(string(session.Out.Contents())).To(ContainSubstring(`Found 2 thread(s) in store "threads".`))
+				Expect(string(session.Err.Contents())).NotTo(ContainSubstring("appears to contain no threads"))
+			})
+
+			It("should warn when the directory holds no threads", func() {
+				storeDir := filepath.Join(CreateTempDir(), "threads")
+				CreateTempFile(filepath.Join(storeDir, "_thread"), "ci.yml", "ci") // A single thread, not a store
+				session := runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "add", storeDir)
+				Eventually(session).Should(gexec.Exit(0))
+				Expect(string(session.Out.Contents())).To(ContainSubstring(`Found 0 thread(s) in store "threads".`))
+				Expect(string(session.Err.Contents())).To(ContainSubstring(`Warning: store "threads" appears to contain no threads`))
+			})
+		})
+
+		Context("when --token-env is passed", func() {
+			It("should save the variable name, never the token, and fail clearly when it is unset", func() {
+				session := runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "add", "--type", "github", "--path", "acme/private-threads", "--token-env", "LOOM_E2E_UNSET_TOKEN")
+				Eventually(session).Should(gexec.Exit(0))
+				Expect(session.Out).To(gbytes.Say(`read from \$LOOM_E2E_UNSET_TOKEN whenever the store is fetched`))
+				Expect(session.Err).To(gbytes.Say(`environment variable LOOM_E2E_UNSET_TOKEN, which is not set`))
+
+				globalConfig, err := os.ReadFile(filepath.Join(tempGlobalLoomDir, "loom.yaml"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(globalConfig)).To(ContainSubstring("token_env: LOOM_E2E_UNSET_TOKEN"))
+
+				session = runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "test", "private-threads")
+				Eventually(session).Should(gexec.Exit(1))
+				Expect(session.Err).To(gbytes.Say(`export LOOM_E2E_UNSET_TOKEN with a token that can read acme/private-threads`))
+			})
+
+			It("should reject it for local stores and for values that are not variable names", func() {
+				session := runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "add", "--token-env", "LOOM_GH_TOKEN", storeDir)
+				Eventually(session).Should(gexec.Exit(2))
+				Expect(session.Err).To(gbytes.Say(`--token-env only applies to git and github stores`))
+
+				session = runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "add", "--type", "git", "--path", "https://example.com/threads.git", "--token-env", "ghp-0123")
+				Eventually(session).Should(gexec.Exit(2))
+				Expect(session.Err).To(gbytes.Say(`invalid token environment variable name "ghp-0123"`))
+			})
+		})
+
+		Context("when --read-only is passed", func() {
+			It("should record the store as read-only and show it in the list and the export", func() {
+				session := runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "add", "--read-only", "--name", "curated", storeDir)
+				Eventually(session).Should(gexec.Exit(0))
+				Expect(session.Out).To(gbytes.Say(`Successfully added read-only local store "curated"`))
+				Eventually(runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "add", "--name", "scratch", CreateTempDir())).Should(gexec.Exit(0))
+
+				globalConfig, err := os.ReadFile(filepath.Join(tempGlobalLoomDir, "loom.yaml"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(globalConfig)).To(ContainSubstring("read_only: true"))
+
+				session = runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "list")
+				Eventually(session).Should(gexec.Exit(0))
+				Expect(session.Out).To(gbytes.Say(`Name:     curated[\s\S]*Access:   read-only`))
+				Expect(session.Out).To(gbytes.Say(`Name:     scratch[\s\S]*Access:   writable`))
+
+				session = runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "export")
+				Eventually(session).Should(gexec.Exit(0))
+				Expect(session.Out).To(gbytes.Say(`read_only: true`))
+			})
+		})
+
+		Context("when another project's .loom directory is added", func() {
+			It("should name the store after that project and resolve its threads like any local store", func() {
+				otherProjectStore := filepath.Join(CreateTempDir(), "other-project", ".loom")
+				CreateTempFile(filepath.Join(otherProjectStore, "go-ci", "_thread"), "ci.yml", "ci")
+				session := runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "add", otherProjectStore)
+				Eventually(session).Should(gexec.Exit(0))
+				Expect(session.Out).To(gbytes.Say(`Successfully added local store "other-project"`))
+				Expect(session.Out).To(gbytes.Say(`Found 1 thread\(s\) in store "other-project"`))
+
+				session = runLoom(tempProjectDir, tempGlobalLoomDir, "", "add", "other-project/go-ci")
+				Eventually(session).Should(gexec.Exit(0))
+				Expect(filepath.Join(tempProjectDir, "ci.yml")).To(BeAnExistingFile())
+				manifest, err := os.ReadFile(filepath.Join(tempProjectDir, "loom.yaml"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(manifest)).To(ContainSubstring("source: other-project"))
+			})
+		})
+
+		Context("when a remote store is added under different spellings of its URL", func() {
+			It("should store one canonical URL, detect duplicates and remove it by any spelling", func() {
+				session := runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "add", "--type", "git", "--path", "https://GitHub.com/Org/Repo.git/")
+				Eventually(session).Should(gexec.Exit(0))
+				Expect(session.Out).To(gbytes.Say(`Successfully added git store "Repo" with path/url "https://github.com/Org/Repo"`))
+
+				session = runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "add", "--type", "git", "--name", "other", "--path", "git@github.com:Org/Repo.git")
+				Eventually(session).Should(gexec.Exit(2))
+				Expect(session.Err).To(gbytes.Say(`already registered as store "Repo"`))
+
+				session = runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "remove", "git@github.com:Org/Repo")
+				Eventually(session).Should(gexec.Exit(0))
+				Expect(session.Out).To(gbytes.Say(`Successfully removed store "Repo"`))
+			})
+		})
+
+		Context("when --name is provided", func() {
+			It("should register the store under the given name", func() {
+				session := runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "add", "--name", "company-threads", storeDir)
+				Eventually(session).Should(gexec.Exit(0))
+				Expect(session.Out).To(gbytes.Say(`store "company-threads"`))
+
+				globalConfig, err := os.ReadFile(filepath.Join(tempGlobalLoomDir, "loom.yaml"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(globalConfig)).To(ContainSubstring("name: company-threads"))
+			})
+
+			It("should fail without prompting when the name is already taken", func() {
+				otherStoreDir := CreateTempDir()
+				Eventually(runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "add", "--name", "company-threads", otherStoreDir)).Should(gexec.Exit(0))
+
+				session := runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "add", "--name", "company-threads", storeDir)
+				Eventually(session).Should(gexec.Exit(2))
+				Expect(session.Err).To(gbytes.Say(`a store named "company-threads" already exists`))
+			})
+		})
+
+		Context("when the same store is added again", func() {
+			It("should succeed without changing the configuration", func() {
+				Eventually(runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "add", "--name", "company-threads", storeDir)).Should(gexec.Exit(0))
+				configPath := filepath.Join(tempGlobalLoomDir, "loom.yaml")
+				before, err := os.ReadFile(configPath)
+				Expect(err).NotTo(HaveOccurred())
+
+				session := runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "add", "--name", "company-threads", storeDir)
+				Eventually(session).Should(gexec.Exit(0))
+				Expect(session.Out).To(gbytes.Say("already registered"))
+				after, err := os.ReadFile(configPath)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(after).To(Equal(before))
+			})
+
+			It("should still fail when the path is registered under a different name", func() {
+				Eventually(runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "add", "--name", "company-threads", storeDir)).Should(gexec.Exit(0))
+
+				session := runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "add", "--name", "other-name", storeDir)
+				Eventually(session).Should(gexec.Exit(2))
+				Expect(session.Err).To(gbytes.Say(`already registered as store "company-threads"`))
+			})
+		})
+
+		Context("when the path is already registered under another spelling", func() {
+			It("should detect a symlink to a registered store as a duplicate", func() {
+				if runtime.GOOS == "windows" {
+					Skip("creating symlinks requires extra privileges on Windows")
+				}
+				Eventually(runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "add", "--name", "company-threads", storeDir)).Should(gexec.Exit(0))
+				linkDir := filepath.Join(CreateTempDir(), "linked-threads")
+				Expect(os.Symlink(storeDir, linkDir)).To(Succeed())
+
+				session := runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "add", "--name", "linked", linkDir)
+				Eventually(session).Should(gexec.Exit(2))
+				Expect(session.Err).To(gbytes.Say(`already registered as store "company-threads"`))
+			})
+		})
+
+		Context("when testing a store", func() {
+			It("should report the thread count of a healthy local store", func() {
+				CreateTempFile(filepath.Join(storeDir, "myThread", "_thread"), "file1.txt", "content")
+				Eventually(runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "add", "--name", "company-threads", storeDir)).Should(gexec.Exit(0))
+
+				session := runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "test", "company-threads")
+				Eventually(session).Should(gexec.Exit(0))
+				Expect(session.Out).To(gbytes.Say(`Store "company-threads" is reachable: 1 thread\(s\) found`))
+			})
+
+			It("should fail when the store's path no longer exists", func() {
+				Eventually(runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "add", "--name", "company-threads", storeDir)).Should(gexec.Exit(0))
+				Expect(os.RemoveAll(storeDir)).To(Succeed())
+
+				session := runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "test", "company-threads")
+				Eventually(session).Should(gexec.Exit(1))
+				Expect(session.Err).To(gbytes.Say("does not exist"))
+			})
+		})
+
+		Context("when listing stores with --verify", func() {
+			It("should show the status of each store and fail if any is unusable", func() {
+				Eventually(runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "add", "--name", "company-threads", storeDir)).Should(gexec.Exit(0))
+				goneDir := filepath.Join(CreateTempDir(), "gone")
+				Expect(os.MkdirAll(goneDir, 0755)).To(Succeed())
+				Eventually(runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "add", "--name", "gone-threads", goneDir)).Should(gexec.Exit(0))
+				Expect(os.RemoveAll(goneDir)).To(Succeed())
+
+				plain := runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "list")
+				Eventually(plain).Should(gexec.Exit(0))
+				Expect(string(plain.Out.Contents())).NotTo(ContainSubstring("Status:"))
+
+				session := runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "list", "--verify")
+				Eventually(session).Should(gexec.Exit(1))
+				Expect(session.Out).To(gbytes.Say(`Name:     company-threads`))
+				Expect(session.Out).To(gbytes.Say(`Status:   OK`))
+				Expect(session.Out).To(gbytes.Say(`Name:     gone-threads`))
+				Expect(session.Out).To(gbytes.Say(`Status:   MISSING`))
+				Expect(session.Err).To(gbytes.Say(`1 store\(s\) failed verification`))
+			})
+		})
+
+		Context("when listing stores with --sort", func() {
+			It("should order the stores by the given key", func() {
+				Eventually(runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "add", "--name", "zeta", storeDir)).Should(gexec.Exit(0))
+				Eventually(runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "add", "--name", "Alpha", CreateTempDir())).Should(gexec.Exit(0))
+
+				session := runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "list")
+				Eventually(session).Should(gexec.Exit(0))
+				Expect(session.Out).To(gbytes.Say(`Name:     zeta`))
+				Expect(session.Out).To(gbytes.Say(`Name:     Alpha`))
+
+				session = runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "list", "--sort", "name")
+				Eventually(session).Should(gexec.Exit(0))
+				Expect(session.Out).To(gbytes.Say(`Name:     Alpha`))
+				Expect(session.Out).To(gbytes.Say(`Name:     zeta`))
+
+				session = runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "list", "--sort", "size")
+				Eventually(session).Should(gexec.Exit(2))
+				Expect(session.Err).To(gbytes.Say(`invalid --sort "size": expected name, type or path`))
+			})
+		})
+
+		Context("when the global config file is empty or corrupt", func() {
+			It("should treat an empty file as having no stores", func() {
+				Expect(os.WriteFile(filepath.Join(tempGlobalLoomDir, "loom.yaml"), nil, 0644)).To(Succeed())
+				session := runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "list")
+				Eventually(session).Should(gexec.Exit(0))
+				Expect(string(session.Err.Contents())).To(BeEmpty())
+			})
+
+			It("should name the file and suggest a reset, which starts over without stores", func() {
+				configPath := filepath.Join(tempGlobalLoomDir, "loom.yaml")
+				Expect(os.WriteFile(configPath, []byte("stores: [\n  - name: broken\n"), 0644)).To(Succeed())
+				session := runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "list")
+				Eventually(session).Should(gexec.Exit(1))
+				Expect(string(session.Err.Contents())).To(ContainSubstring("global config file " + configPath + " is corrupt"))
+				Expect(string(session.Err.Contents())).To(ContainSubstring("loom config reset"))
+
+				session = runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "reset", "--force")
+				Eventually(session).Should(gexec.Exit(0))
+				Expect(string(session.Out.Contents())).To(ContainSubstring("the previous file is at " + configPath + ".bak"))
+				Expect(configPath + ".bak").To(BeAnExistingFile())
+				Eventually(runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "add", "--name", "company", storeDir)).Should(gexec.Exit(0))
+			})
+		})
+
+		Context("when exporting and importing stores", func() {
+			It("should merge exported stores, skipping known paths and renaming taken names", func() {
+				otherStoreDir := filepath.Join(CreateTempDir(), "other")
+				Expect(os.MkdirAll(otherStoreDir, 0755)).To(Succeed())
+				Eventually(runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "add", "--name", "company", storeDir)).Should(gexec.Exit(0))
+				Eventually(runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "add", "--name", "shared", otherStoreDir)).Should(gexec.Exit(0))
+				exportFile := filepath.Join(CreateTempDir(), "stores.json")
+				session := runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "export", "--format", "json", "--output", exportFile)
+				Eventually(session).Should(gexec.Exit(0))
+				Expect(session.Out).To(gbytes.Say("Exported 2 store"))
+
+				tempGlobalLoomDir = CreateTempDir()
+				Eventually(runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "add", "--name", "company", CreateTempDir())).Should(gexec.Exit(0))
+				Eventually(runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "add", "--name", "mine", otherStoreDir)).Should(gexec.Exit(0))
+
+				session = runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "import", exportFile)
+				Eventually(session).Should(gexec.Exit(0))
+				Expect(session.Out).To(gbytes.Say(`importing it as "company-2"`))
+				Expect(session.Out).To(gbytes.Say(`Skipping store "shared".*already registered as store "mine"`))
+				Expect(session.Out).To(gbytes.Say(regexp.QuoteMeta("Imported 1 store(s), skipped 1.")))
+				Expect(session.Err).To(gbytes.Say("may not be portable"))
+
+				globalConfig, err := os.ReadFile(filepath.Join(tempGlobalLoomDir, "loom.yaml"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(globalConfig)).To(ContainSubstring("name: company-2"))
+				Expect(string(globalConfig)).To(ContainSubstring(storeDir))
+			})
+		})
+
+		Context("when migrating stores to a new location", func() {
+			var newRoot string
+
+			BeforeEach(func() {
+				CreateTempFile(filepath.Join(storeDir, "myThread", "_thread"), "file1.txt", "content")
+				Eventually(runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "add", "--name", "company-threads", storeDir)).Should(gexec.Exit(0))
+				newRoot = CreateTempDir()
+				Expect(os.Rename(storeDir, filepath.Join(newRoot, "threads"))).To(Succeed())
+			})
+
+			It("should only preview the rewrite with --dry-run", func() {
+				session := runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "migrate", "--dry-run", "--from", filepath.Dir(storeDir), "--to", newRoot)
+				Eventually(session).Should(gexec.Exit(0))
+				Expect(session.Out).To(gbytes.Say("1 store\\(s\\) would be updated"))
+
+				configContent, err := os.ReadFile(filepath.Join(tempGlobalLoomDir, "loom.yaml"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(configContent)).To(ContainSubstring(storeDir))
+			})
+
+			It("should rewrite the store path so the store works again", func() {
+				session := runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "migrate", "--from", filepath.Dir(storeDir), "--to", newRoot)
+				Eventually(session).Should(gexec.Exit(0))
+				Expect(session.Out).To(gbytes.Say(regexp.QuoteMeta("Updated 1 store(s).")))
+
+				Eventually(runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "test", "company-threads")).Should(gexec.Exit(0))
+			})
+		})
+
+		Context("when the directory is a git working tree", func() {
+			BeforeEach(func() {
+				if _, err := exec.LookPath("git"); err != nil {
+					Skip("git is not installed")
+				}
+				for _, args := range [][]string{{"init", "-q", "-b", "main"}, {"remote", "add", "origin", "https://example.com/threads.git"}} {
+					Expect(exec.Command("git", append([]string{"-C", storeDir}, args...)...).Run()).To(Succeed())
+				}
+				CreateTempFile(filepath.Join(storeDir, "ci", "_thread"), "ci.yml", "ci")
+			})
+
+			It("should register it as a git store read from the working tree with --yes", func() {
+				session := runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "add", "--yes", storeDir)
+				Eventually(session).Should(gexec.Exit(0))
+				Expect(string(session.Out.Contents())).To(ContainSubstring("is a git working tree of https://example.com/threads.git (main)"))
+				Expect(string(session.Out.Contents())).To(ContainSubstring("Successfully added git store \"threads\" with path/url \"https://example.com/threads\""))
+
+				session = runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "list")
+				Eventually(session).Should(gexec.Exit(0))
+				Expect(string(session.Out.Contents())).To(ContainSubstring("Ref:      main"))
+				Expect(string(session.Out.Contents())).To(ContainSubstring("Checkout: " + storeDir))
+
+				session = runLoom(tempProjectDir, tempGlobalLoomDir, "", "add", "threads/ci")
+				Eventually(session, "10s").Should(gexec.Exit(0))
+				Expect(filepath.Join(tempProjectDir, "ci.yml")).To(BeAnExistingFile())
+			})
+
+			It("should register a local store with --local or when stdin is not a terminal", func() {
+				session := runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "add", "--local", storeDir)
+				Eventually(session).Should(gexec.Exit(0))
+				Expect(string(session.Out.Contents())).To(ContainSubstring("Successfully added local store \"threads\""))
+				Expect(string(session.Out.Contents())).NotTo(ContainSubstring("git working tree"))
+
+				Eventually(runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "remove", "threads")).Should(gexec.Exit(0))
+				session = runLoom(tempProjectDir, tempGlobalLoomDir, "y\n", "config", "add", storeDir)
+				Eventually(session).Should(gexec.Exit(0))
+				Expect(string(session.Out.Contents())).To(ContainSubstring("Registering it as a local store, since stdin is not a terminal; pass --yes"))
+				Expect(string(session.Out.Contents())).To(ContainSubstring("Successfully added local store \"threads\""))
+			})
+		})
+	})
+
+	Describe("loom weave --check functionality", func() {
+		var tempProjectDir string
+		var threadDir string
+
+		BeforeEach(func() {
+			tempProjectDir = CreateTempDir()
+			threadDir = filepath.Join(CreateTempDir(), "myThread")
+			CreateTempFile(filepath.Join(threadDir, "_thread"), "file1.txt", "content of file1")
+			Eventually(runLoom(tempProjectDir, "", "", "add", "--from", threadDir), "10s").Should(gexec.Exit(0))
+		})
+
+		It("should pass when the project matches its threads", func() {
+			session := runLoom(tempProjectDir, "", "", "weave", "--check")
+			Eventually(session, "10s").Should(gexec.Exit(0))
+			Expect(session.Out).To(gbytes.Say("Weave check passed"))
+		})
+
+		It("should fail and list drifted files without modifying anything", func() {
+			driftedFile := filepath.Join(tempProjectDir, "file1.txt")
+			Expect(os.WriteFile(driftedFile, []byte("local edit"), 0644)).To(Succeed())
+			manifestBefore, err := os.ReadFile(filepath.Join(tempProjectDir, "loom.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+
+			session := runLoom(tempProjectDir, "", "", "weave", "--check")
+			Eventually(session, "10s").Should(gexec.Exit(1))
+			Expect(session.Out).To(gbytes.Say(regexp.QuoteMeta("overwrite file1.txt (thread 'myThread')")))
+
+			content, err := os.ReadFile(driftedFile)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal("local edit"))
+			manifestAfter, err := os.ReadFile(filepath.Join(tempProjectDir, "loom.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(manifestAfter).To(Equal(manifestBefore))
+		})
+
+		Context("with --dry-run and --json", func() {
+			It("should print the plan as JSON, resolving conflicts to prompt, and change nothing", func() {
+				Expect(os.WriteFile(filepath.Join(tempProjectDir, "file1.txt"), []byte("local edit"), 0644)).To(Succeed())
+				CreateTempFile(filepath.Join(threadDir, "_thread"), "new.txt", "new")
+				CreateTempFile(filepath.Join(threadDir, "_thread"), "notes.txt", "from the thread")
+				CreateTempFile(tempProjectDir, "notes.txt", "unmanaged")
+				manifestBefore, err := os.ReadFile(filepath.Join(tempProjectDir, "loom.yaml"))
+				Expect(err).NotTo(HaveOccurred())
+
+				session := runLoom(tempProjectDir, "", "", "weave", "--dry-run", "--json")
+				Eventually(session, "10s").Should(gexec.Exit(0))
+				var plan []map[string]string
+				Expect(json.Unmarshal(session.Out.Contents(), &plan)).To(Succeed())
+				Expect(plan).To(ConsistOf(
+					map[string]string{"thread": "myThread", "path": "file1.txt", "action": "overwrite"},
+					map[string]string{"thread": "myThread", "path": "new.txt", "action": "create"},
+					map[string]string{"thread": "myThread", "path": "notes.txt", "action": "prompt"},
+				))
+				Expect(string(session.Err.Contents())).To(ContainSubstring("Dry run: 3 file(s) would change"))
+
+				manifestAfter, err := os.ReadFile(filepath.Join(tempProjectDir, "loom.yaml"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(manifestAfter).To(Equal(manifestBefore))
+				Expect(filepath.Join(tempProjectDir, "new.txt")).NotTo(BeAnExistingFile())
+			})
+
+			It("should plan taking ownership under --yes and skipping under --overwrite-policy ours", func() {
+				CreateTempFile(filepath.Join(threadDir, "_thread"), "notes.txt", "from the thread")
+				CreateTempFile(tempProjectDir, "notes.txt", "unmanaged")
+
+				session := runLoom(tempProjectDir, "", "", "weave", "--dry-run", "--json", "--yes")
+				Eventually(session, "10s").Should(gexec.Exit(0))
+				Expect(string(session.Out.Contents())).To(ContainSubstring(`"action": "take-ownership"`))
+
+				session = runLoom(tempProjectDir, "", "", "weave", "--check", "--json", "--overwrite-policy", "ours")
+				Eventually(session, "10s").Should(gexec.Exit(0))
+				Expect(string(session.Out.Contents())).To(ContainSubstring(`"action": "skip"`))
+			})
+
+			It("should print an empty plan when nothing would change, and require --dry-run or --check", func() {
+				session := runLoom(tempProjectDir, "", "", "weave", "--dry-run", "--json")
+				Eventually(session, "10s").Should(gexec.Exit(0))
+				Expect(strings.TrimSpace(string(session.Out.Contents()))).To(Equal("[]"))
+
+				session = runLoom(tempProjectDir, "", "", "weave", "--json")
+				Eventually(session, "10s").Should(gexec.Exit(2))
+				Expect(session.Err).To(gbytes.Say(`--json prints the plan of --dry-run or --check`))
+			})
+		})
+	})
+
+	Describe("loom weave functionality", func() {
+		It("should write a byte-identical loom.yaml when weaving twice", func() {
+			tempProjectDir := CreateTempDir()
+			threadSource := filepath.Join(CreateTempDir(), "myThread", "_thread")
+			for _, name := range []string{"zeta.txt", "alpha.txt", "mid.txt", "beta.txt"} {
+				CreateTempFile(threadSource, name, name)
+				CreateTempFile(filepath.Join(threadSource, "src"), name, name)
+			}
+
+			mustRun := func(args ...string) {
+				session := runLoom(tempProjectDir, "", "", args...)
+				Eventually(session, "10s").Should(gexec.Exit(0))
+			}
+
+			mustRun("add", "--from", filepath.Dir(threadSource))
+			mustRun("weave")
+			first, err := os.ReadFile(filepath.Join(tempProjectDir, "loom.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			mustRun("weave")
+			second, err := os.ReadFile(filepath.Join(tempProjectDir, "loom.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(string(second)).To(Equal(string(first)))
+			Expect(string(first)).To(MatchRegexp(`(?s)- alpha\.txt\s+- beta\.txt\s+- mid\.txt\s+- zeta\.txt`))
+		})
+
+		It("should weave a legacy thread whose source is not a store from .loom/<name>", func() {
+			tempProjectDir := CreateTempDir()
+			CreateTempFile(filepath.Join(tempProjectDir, ".loom", "away", "_thread", "tests"), "test.xml", "<tests/>")
+			CreateTempFile(tempProjectDir, "loom.yaml", "version: \"1\"\nthreads:\n  - name: away\n    source: loom\n    files:\n      tests/:\n        - test.xml\n")
+
+			session := runLoom(tempProjectDir, "", "", "weave")
+			Eventually(session, "10s").Should(gexec.Exit(0))
+			Expect(string(session.Err.Contents())).To(ContainSubstring("using .loom/away instead. Set its source to 'project:.loom/away'"))
+			content, err := os.ReadFile(filepath.Join(tempProjectDir, "tests", "test.xml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal("<tests/>"))
+		})
+
+		It("should point at the offending line of a malformed loom.yaml", func() {
+			tempProjectDir := CreateTempDir()
+			CreateTempFile(tempProjectDir, "loom.yaml", "version: \"1\"\nthreads:\n  - name: myThread\n    source: project\n    files:\n      - file1.txt\n")
+			session := runLoom(tempProjectDir, "", "", "weave")
+			Eventually(session, "10s").Should(gexec.Exit(1))
+			Expect(string(session.Err.Contents())).To(ContainSubstring("line 6, column 7: thread 'myThread': 'files' must be a map of directories to lists of file names, found a list"))
+		})
+
+		It("should warn once about an unknown loom.yaml key and keep going", func() {
+			tempProjectDir := CreateTempDir()
+			CreateTempFile(tempProjectDir, "loom.yaml", "version: \"1\"\nthreads:\n  - name: myThread\n    source: project\n    pinned: true\n")
+			session := runLoom(tempProjectDir, "", "", "list", "--active")
+			Eventually(session, "10s").Should(gexec.Exit(0))
+			Expect(session.Out).To(gbytes.Say("myThread"))
+			stderr := string(session.Err.Contents())
+			Expect(stderr).To(ContainSubstring("line 5, column 5: thread 'myThread': unknown key 'pinned' is ignored"))
+			Expect(strings.Count(stderr, "unknown key 'pinned'")).To(Equal(1))
+		})
+
+		It("should reject a loom.yaml listing the same thread twice", func() {
+			tempProjectDir := CreateTempDir()
+			CreateTempFile(tempProjectDir, "loom.yaml", "version: \"1\"\nthreads:\n  - name: myThread\n    source: project\n  - name: myThread\n    source: project\n")
+			session := runLoom(tempProjectDir, "", "", "weave")
+			Eventually(session, "10s").Should(gexec.Exit(1))
+			Expect(string(session.Err.Contents())).To(ContainSubstring("line 5, column 11: thread 'myThread' is listed more than once (first at line 3)"))
+		})
+
+		Context("with --parallel", func() {
+			var tempProjectDir string
+
+			addThread := func(name string, files ...string) {
+				threadDir := filepath.Join(CreateTempDir(), name)
+				for _, file := range files {
+					CreateTempFile(filepath.Join(threadDir, "_thread", filepath.Dir(file)), filepath.Base(file), name+":"+file)
+				}
+				Eventually(runLoom(tempProjectDir, "", "", "add", "--yes", "--from", threadDir), "10s").Should(gexec.Exit(0))
+			}
+
+			BeforeEach(func() {
+				tempProjectDir = CreateTempDir()
+			})
+
+			It("should weave independent threads concurrently", func() {
+				addThread("alpha", "alpha/a.txt", "alpha/b.txt")
+				addThread("beta", "beta/a.txt")
+				addThread("gamma", "gamma/nested/c.txt")
+				Expect(os.RemoveAll(filepath.Join(tempProjectDir, "alpha"))).To(Succeed())
+				Expect(os.RemoveAll(filepath.Join(tempProjectDir, "gamma"))).To(Succeed())
+
+				session := runLoom(tempProjectDir, "", "", "weave", "--parallel=2", "--yes")
+				Eventually(session, "10s").Should(gexec.Exit(0))
+				Expect(string(session.Out.Contents())).To(ContainSubstring("with 2 workers"))
+				for _, file := range []string{"alpha/a.txt", "alpha/b.txt", "beta/a.txt", "gamma/nested/c.txt"} {
+					Expect(filepath.Join(tempProjectDir, file)).To(BeAnExistingFile())
+				}
+				yamlContent, err := os.ReadFile(filepath.Join(tempProjectDir, "loom.yaml"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(yamlContent)).To(ContainSubstring("c.txt"))
+			})
+
+			It("should fall back to sequential weaving when threads overlap", func() {
+				addThread("alpha", "shared.txt")
+				addThread("beta", "shared.txt", "beta.txt")
+
+				session := runLoom(tempProjectDir, "", "", "weave", "--parallel=2", "--yes")
+				Eventually(session, "10s").Should(gexec.Exit(0))
+				Expect(string(session.Out.Contents())).To(ContainSubstring("Threads overlap on 'shared.txt'"))
+			})
+
+			It("should refuse to run without --yes", func() {
+				addThread("alpha", "a.txt")
+				Eventually(runLoom(tempProjectDir, "", "", "weave", "--parallel"), "10s").Should(gexec.Exit(2))
+			})
+		})
+
+		It("should fail with --strict when a manifest file is missing from the thread source", func() {
+			tempProjectDir := CreateTempDir()
+			threadDir := filepath.Join(CreateTempDir(), "myThread")
+			CreateTempFile(filepath.Join(threadDir, "_thread"), "file1.txt", "content of file1")
+			CreateTempFile(filepath.Join(threadDir, "_thread"), "file2.txt", "content of file2")
+
+			Eventually(runLoom(tempProjectDir, "", "", "add", "--from", threadDir), "10s").Should(gexec.Exit(0))
+			missingSource := filepath.Join(threadDir, "_thread", "file2.txt")
+			Expect(os.Remove(missingSource)).To(Succeed())
+
+			session := runLoom(tempProjectDir, "", "", "weave", "--strict", "myThread")
+			Eventually(session, "10s").Should(gexec.Exit(1))
+			Expect(string(session.Err.Contents())).To(ContainSubstring(missingSource))
+			yamlContent, err := os.ReadFile(filepath.Join(tempProjectDir, "loom.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(yamlContent)).To(ContainSubstring("file2.txt"))
+
+			// Weaving every thread checks them all before writing anything.
+			Expect(os.Remove(filepath.Join(tempProjectDir, "file1.txt"))).To(Succeed())
+			session = runLoom(tempProjectDir, "", "", "weave", "--strict")
+			Eventually(session, "10s").Should(gexec.Exit(1))
+			Expect(string(session.Err.Contents())).To(ContainSubstring("thread 'myThread': 'file2.txt' is listed in loom.yaml but its source file " + missingSource + " is missing"))
+			Expect(filepath.Join(tempProjectDir, "file1.txt")).NotTo(BeAnExistingFile())
+
+			session = runLoom(tempProjectDir, "", "", "weave", "myThread")
+			Eventually(session, "10s").Should(gexec.Exit(0))
+			Expect(string(session.Out.Contents())).To(ContainSubstring("Warning"))
+		})
+
+		Context("with modes recorded by add --record-modes", func() {
+			It("should restore the recorded mode even after the source's mode drifts", func() {
+				tempProjectDir := CreateTempDir()
+				threadDir := filepath.Join(CreateTempDir(), "myThread")
+				CreateTempFile(filepath.Join(threadDir, "_thread"), "deploy.sh", "#!/bin/sh\n")
+				sourceFile := filepath.Join(threadDir, "_thread", "deploy.sh")
+				Expect(os.Chmod(sourceFile, 0755)).To(Succeed())
+				Eventually(runLoom(tempProjectDir, "", "", "add", "--record-modes", "--from", threadDir), "10s").Should(gexec.Exit(0))
+				yamlContent, err := os.ReadFile(filepath.Join(tempProjectDir, "loom.yaml"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(yamlContent)).To(ContainSubstring(`deploy.sh: "0755"`))
+
+				woven := filepath.Join(tempProjectDir, "deploy.sh")
+				Expect(os.Chmod(sourceFile, 0644)).To(Succeed())
+				Expect(os.Chmod(woven, 0644)).To(Succeed())
+				session := runLoom(tempProjectDir, "", "", "weave", "--check")
+				Eventually(session, "10s").Should(gexec.Exit(1))
+				Expect(session.Out).To(gbytes.Say("chmod deploy.sh"))
+
+				Eventually(runLoom(tempProjectDir, "", "", "weave"), "10s").Should(gexec.Exit(0))
+				info, err := os.Stat(woven)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(info.Mode().Perm()).To(Equal(os.FileMode(0755)))
+			})
+		})
+
+		Context("with --source", func() {
+			It("should weave a thread from another directory without changing its recorded source", func() {
+				tempProjectDir := CreateTempDir()
+				threadDir := filepath.Join(CreateTempDir(), "myThread")
+				CreateTempFile(filepath.Join(threadDir, "_thread"), "file1.txt", "published")
+				draftDir := filepath.Join(CreateTempDir(), "draft", "_thread")
+				CreateTempFile(draftDir, "file1.txt", "draft")
+				Eventually(runLoom(tempProjectDir, "", "", "add", "--from", threadDir), "10s").Should(gexec.Exit(0))
+
+				session := runLoom(tempProjectDir, "", "", "weave", "--source", filepath.Dir(draftDir), "myThread")
+				Eventually(session, "10s").Should(gexec.Exit(0))
+				Expect(session.Out).To(gbytes.Say("instead of source"))
+				content, err := os.ReadFile(filepath.Join(tempProjectDir, "file1.txt"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(content)).To(Equal("draft"))
+				after, err := os.ReadFile(filepath.Join(tempProjectDir, "loom.yaml"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(after)).To(ContainSubstring("source: path:" + filepath.ToSlash(threadDir)))
+
+				session = runLoom(tempProjectDir, "", "", "weave", "--source", draftDir)
+				Eventually(session, "10s").Should(gexec.Exit(2))
+				Expect(session.Err).To(gbytes.Say("--source needs the name of the thread"))
+			})
+		})
+
+		Context("with --backup", func() {
+			It("should save edited files before overwriting them", func() {
+				tempProjectDir := CreateTempDir()
+				threadDir := filepath.Join(CreateTempDir(), "myThread")
+				CreateTempFile(filepath.Join(threadDir, "_thread", "conf"), "app.conf", "original")
+				CreateTempFile(filepath.Join(threadDir, "_thread"), "untouched.txt", "same")
+				Eventually(runLoom(tempProjectDir, "", "", "add", "--from", threadDir), "10s").Should(gexec.Exit(0))
+				CreateTempFile(filepath.Join(tempProjectDir, "conf"), "app.conf", "my edits")
+
+				session := runLoom(tempProjectDir, "", "", "weave", "--backup")
+				Eventually(session, "10s").Should(gexec.Exit(0))
+				Expect(string(session.Out.Contents())).To(ContainSubstring("Backed up 1 overwritten file(s) to "))
+				backups, err := filepath.Glob(filepath.Join(tempProjectDir, ".loom-backups", "*", "conf", "app.conf"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(backups).To(HaveLen(1))
+				saved, err := os.ReadFile(backups[0])
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(saved)).To(Equal("my edits"))
+				content, err := os.ReadFile(filepath.Join(tempProjectDir, "conf", "app.conf"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(content)).To(Equal("original"))
+				Expect(filepath.Join(filepath.Dir(filepath.Dir(backups[0])), "untouched.txt")).NotTo(BeAnExistingFile())
+				ignore, err := os.ReadFile(filepath.Join(tempProjectDir, ".loom-backups", ".gitignore"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(ignore)).To(Equal("*\n"))
+				Expect(filepath.Join(tempProjectDir, ".loom")).NotTo(BeADirectory())
+
+				session = runLoom(tempProjectDir, "", "", "weave", "--backup", "--check")
+				Eventually(session, "10s").Should(gexec.Exit(2))
+			})
+		})
+
+		Context("with --changed-only", func() {
+			It("should only weave threads whose source changed since the last weave", func() {
+				tempProjectDir := CreateTempDir()
+				alphaDir := filepath.Join(CreateTempDir(), "alpha")
+				betaDir := filepath.Join(CreateTempDir(), "beta")
+				CreateTempFile(filepath.Join(alphaDir, "_thread"), "alpha.txt", "alpha v1")
+				CreateTempFile(filepath.Join(betaDir, "_thread"), "beta.txt", "beta v1")
+				Eventually(runLoom(tempProjectDir, "", "", "add", "--from", alphaDir), "10s").Should(gexec.Exit(0))
+				Eventually(runLoom(tempProjectDir, "", "", "add", "--from", betaDir), "10s").Should(gexec.Exit(0))
+				CreateTempFile(filepath.Join(betaDir, "_thread"), "beta.txt", "beta v2")
+
+				session := runLoom(tempProjectDir, "", "", "weave", "--changed-only", "--yes")
+				Eventually(session, "10s").Should(gexec.Exit(0))
+				Expect(string(session.Out.Contents())).To(ContainSubstring("Skipping thread 'alpha': unchanged since it was last woven."))
+				Expect(string(session.Out.Contents())).To(ContainSubstring("Weaving thread 'beta'"))
+				content, err := os.ReadFile(filepath.Join(tempProjectDir, "beta.txt"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(content)).To(Equal("beta v2"))
+
+				Expect(os.Remove(filepath.Join(tempProjectDir, "alpha.txt"))).To(Succeed())
+				session = runLoom(tempProjectDir, "", "", "weave", "--changed-only", "--yes")
+				Eventually(session, "10s").Should(gexec.Exit(0))
+				Expect(string(session.Out.Contents())).To(ContainSubstring("Skipping thread 'beta'"))
+				Expect(filepath.Join(tempProjectDir, "alpha.txt")).To(BeAnExistingFile())
+			})
+		})
+
+		Context("with --verify-idempotent", func() {
+			It("should weave twice and confirm that loom.yaml did not change", func() {
+				tempProjectDir := CreateTempDir()
+				alphaDir := filepath.Join(CreateTempDir(), "alpha")
+				CreateTempFile(filepath.Join(alphaDir, "_thread", "config"), "alpha.txt", "alpha")
+				CreateTempFile(filepath.Join(alphaDir, "_thread"), "README.md", "alpha readme")
+				Eventually(runLoom(tempProjectDir, "", "", "add", "--from", alphaDir), "10s").Should(gexec.Exit(0))
+				Expect(os.Remove(filepath.Join(tempProjectDir, "README.md"))).To(Succeed())
+
+				session := runLoom(tempProjectDir, "", "", "weave", "--verify-idempotent", "--yes")
+				Eventually(session, "10s").Should(gexec.Exit(0))
+				Expect(session.Out).To(gbytes.Say("Weaving again to verify that the weave is idempotent"))
+				Expect(session.Out).To(gbytes.Say("Weave is idempotent: the second weave left loom.yaml unchanged."))
+				Expect(filepath.Join(tempProjectDir, "README.md")).To(BeAnExistingFile())
+
+				session = runLoom(tempProjectDir, "", "", "weave", "--verify-idempotent", "--check")
+				Eventually(session, "10s").Should(gexec.Exit(2))
+				Expect(string(session.Err.Contents())).To(ContainSubstring("--verify-idempotent cannot be used with --check"))
+			})
+		})
+
+		Context("when two threads provide the same file", func() {
+			It("should warn naming both threads, or fail with --strict", func() {
+				tempProjectDir := CreateTempDir()
+				alphaDir := filepath.Join(CreateTempDir(), "alpha")
+				betaDir := filepath.Join(CreateTempDir(), "beta")
+				CreateTempFile(filepath.Join(alphaDir, "_thread"), "config.json", "{\"from\": \"alpha\"}")
+				CreateTempFile(filepath.Join(betaDir, "_thread"), "config.json", "{\"from\": \"beta\"}")
+				Eventually(runLoom(tempProjectDir, "", "", "add", "--from", alphaDir), "10s").Should(gexec.Exit(0))
+				Eventually(runLoom(tempProjectDir, "", "", "add", "--force", "--from", betaDir), "10s").Should(gexec.Exit(0))
+
+				session := runLoom(tempProjectDir, "", "", "weave", "--strict")
+				Eventually(session, "10s").Should(gexec.Exit(1))
+				Expect(string(session.Err.Contents())).To(ContainSubstring("threads provide the same files: 'config.json' (threads 'alpha' and 'beta')"))
+
+				session = runLoom(tempProjectDir, "", "", "weave", "--yes")
+				Eventually(session, "10s").Should(gexec.Exit(0))
+				Expect(string(session.Out.Contents())).To(ContainSubstring("Warning: Threads 'alpha' and 'beta' both provide 'config.json'"))
+
+				session = runLoom(tempProjectDir, "", "", "weave", "--strict", "beta")
+				Eventually(session, "10s").Should(gexec.Exit(0))
+			})
+		})
+
+		Context("when a file was replaced by a directory or the other way around", func() {
+			It("should skip the file with a warning, report it under --check and fail under --strict", func() {
+				tempProjectDir := CreateTempDir()
+				threadDir := filepath.Join(CreateTempDir(), "app")
+				CreateTempFile(filepath.Join(threadDir, "_thread"), "foo", "foo")
+				CreateTempFile(filepath.Join(threadDir, "_thread", "conf"), "app.yml", "port: 80")
+				Eventually(runLoom(tempProjectDir, "", "", "add", "--from", threadDir), "10s").Should(gexec.Exit(0))
+
+				// foo becomes a directory; conf, which holds app.yml, becomes a file.
+				Expect(os.Remove(filepath.Join(tempProjectDir, "foo"))).To(Succeed())
+				CreateTempFile(filepath.Join(tempProjectDir, "foo"), "notes.txt", "mine")
+				Expect(os.RemoveAll(filepath.Join(tempProjectDir, "conf"))).To(Succeed())
+				CreateTempFile(tempProjectDir, "conf", "mine")
+
+				session := runLoom(tempProjectDir, "", "", "weave", "--yes")
+				Eventually(session, "10s").Should(gexec.Exit(0))
+				Expect(string(session.Out.Contents())).To(ContainSubstring("Warning: skipping 'foo' of thread 'app': it is a directory in the project, but the thread provides a file"))
+				Expect(string(session.Out.Contents())).To(ContainSubstring("Warning: skipping 'conf/app.yml' of thread 'app': 'conf' is a file in the project, but the thread needs a directory there"))
+				Expect(filepath.Join(tempProjectDir, "foo", "notes.txt")).To(BeAnExistingFile())
+				yamlContent, err := os.ReadFile(filepath.Join(tempProjectDir, "loom.yaml"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(yamlContent)).To(ContainSubstring("- foo"))
+
+				session = runLoom(tempProjectDir, "", "", "weave", "--check")
+				Eventually(session, "10s").Should(gexec.Exit(1))
+				Expect(string(session.Out.Contents())).To(ContainSubstring("blocked foo (thread 'app')"))
+				Expect(string(session.Out.Contents())).To(ContainSubstring("blocked conf/app.yml (thread 'app')"))
+
+				session = runLoom(tempProjectDir, "", "", "weave", "--strict", "app")
+				Eventually(session, "10s").Should(gexec.Exit(1))
+				Expect(string(session.Err.Contents())).To(MatchRegexp(`cannot write '(foo|conf/app\.yml)'`))
+			})
+		})
+
+		Context("when weaving with --only", func() {
+			It("should weave only the matching files and keep the others owned as they are", func() {
+				tempProjectDir := CreateTempDir()
+				backendDir := filepath.Join(CreateTempDir(), "backend")
+				CreateTempFile(filepath.Join(backendDir, "_thread", "config"), "app.yml", "port: 80")
+				CreateTempFile(filepath.Join(backendDir, "_thread"), "README.md", "readme v1")
+				Eventually(runLoom(tempProjectDir, "", "", "add", "--from", backendDir), "10s").Should(gexec.Exit(0))
+				CreateTempFile(filepath.Join(backendDir, "_thread", "config"), "app.yml", "port: 8080")
+				CreateTempFile(filepath.Join(backendDir, "_thread"), "README.md", "readme v2")
+
+				session := runLoom(tempProjectDir, "", "", "weave", "--only", "config/**", "--yes", "backend")
+				Eventually(session, "10s").Should(gexec.Exit(0))
+				content, err := os.ReadFile(filepath.Join(tempProjectDir, "config", "app.yml"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(content)).To(Equal("port: 8080"))
+				content, err = os.ReadFile(filepath.Join(tempProjectDir, "README.md"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(content)).To(Equal("readme v1"))
+				manifest, err := os.ReadFile(filepath.Join(tempProjectDir, "loom.yaml"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(manifest)).To(ContainSubstring("README.md"))
+				Expect(string(manifest)).To(ContainSubstring("app.yml"))
+
+				session = runLoom(tempProjectDir, "", "", "weave", "--only", "*.txt", "--yes")
+				Eventually(session, "10s").Should(gexec.Exit(0))
+				Expect(string(session.Out.Contents())).To(ContainSubstring("No files of thread 'backend' match --only '*.txt'."))
+
+				session = runLoom(tempProjectDir, "", "", "weave", "--only", "config/**", "--prune")
+				Eventually(session, "10s").Should(gexec.Exit(2))
+				Expect(string(session.Err.Contents())).To(ContainSubstring("--only cannot be combined with --prune"))
+			})
+		})
+
+		Context("when progress is requested", func() {
+			It("should report progress instead of a line per file", func() {
+				tempProjectDir := CreateTempDir()
+				threadDir := filepath.Join(CreateTempDir(), "bulk")
+				for _, name := range []string{"a.txt", "b.txt", "c.txt"} {
+					CreateTempFile(filepath.Join(threadDir, "_thread"), name, name)
+				}
+				session := runLoom(tempProjectDir, "", "", "add", "--progress", "--from", threadDir)
+				Eventually(session, "10s").Should(gexec.Exit(0))
+				Expect(string(session.Out.Contents())).To(ContainSubstring("[3/3] copying c.txt"))
+
+				session = runLoom(tempProjectDir, "", "", "weave", "--progress", "--yes")
+				Eventually(session, "10s").Should(gexec.Exit(0))
+				Expect(string(session.Out.Contents())).To(ContainSubstring("[3/3] weaving c.txt"))
+				Expect(string(session.Out.Contents())).NotTo(ContainSubstring("Re-applying file"))
+
+				Expect(os.WriteFile(filepath.Join(tempProjectDir, "c.txt"), []byte("edited"), 0644)).To(Succeed())
+				session = runLoom(tempProjectDir, "", "", "weave", "--yes")
+				Eventually(session, "10s").Should(gexec.Exit(0))
+				Expect(string(session.Out.Contents())).To(ContainSubstring("Re-applying file 'c.txt'"))
+				Expect(string(session.Out.Contents())).NotTo(ContainSubstring("[3/3]"))
+			})
+		})
+
+		Context("with --locked", func() {
+			It("should write loom.lock and refuse to weave once a thread's files change", func() {
+				tempProjectDir := CreateTempDir()
+				threadDir := filepath.Join(CreateTempDir(), "myThread")
+				CreateTempFile(filepath.Join(threadDir, "_thread"), "file1.txt", "v1")
+				Eventually(runLoom(tempProjectDir, "", "", "add", "--from", threadDir), "10s").Should(gexec.Exit(0))
+				lockContent, err := os.ReadFile(filepath.Join(tempProjectDir, "loom.lock"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(lockContent)).To(ContainSubstring("file1.txt: sha256:"))
+				Eventually(runLoom(tempProjectDir, "", "", "weave", "--locked"), "10s").Should(gexec.Exit(0))
+
+				CreateTempFile(filepath.Join(threadDir, "_thread"), "file1.txt", "v2")
+				session := runLoom(tempProjectDir, "", "", "weave", "--locked")
+				Eventually(session, "10s").Should(gexec.Exit(1))
+				Expect(session.Out).To(gbytes.Say("thread 'myThread': file1.txt has changed"))
+				content, err := os.ReadFile(filepath.Join(tempProjectDir, "file1.txt"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(content)).To(Equal("v1"))
+
+				Eventually(runLoom(tempProjectDir, "", "", "weave", "--yes"), "10s").Should(gexec.Exit(0))
+				Eventually(runLoom(tempProjectDir, "", "", "weave", "--locked"), "10s").Should(gexec.Exit(0))
+			})
+		})
+	})
+
+	Describe("loom weave with a missing thread source", func() {
+		It("should weave the other threads, keep the skipped one's manifest and exit non-zero", func() {
+			tempProjectDir := CreateTempDir()
+			CreateTempFile(filepath.Join(tempProjectDir, ".loom", "gone", "_thread"), "gone.txt", "gone")
+			CreateTempFile(filepath.Join(tempProjectDir, ".loom", "kept", "_thread"), "kept.txt", "v1")
+			Eventually(runLoom(tempProjectDir, "", "", "add", "gone", "kept"), "10s").Should(gexec.Exit(0))
+			Expect(os.RemoveAll(filepath.Join(tempProjectDir, ".loom", "gone"))).To(Succeed())
+			CreateTempFile(filepath.Join(tempProjectDir, ".loom", "kept", "_thread"), "kept.txt", "v2")
+
+			session := runLoom(tempProjectDir, "", "", "weave", "--yes")
+			Eventually(session, "10s").Should(gexec.Exit(1))
+			Expect(session.Out).To(gbytes.Say("Thread source directory not found for thread 'gone'"))
+			Expect(session.Err).To(gbytes.Say(`skipped 1 thread\(s\) that could not be woven: gone`))
+			content, err := os.ReadFile(filepath.Join(tempProjectDir, "kept.txt"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal("v2"))
+			yamlContent, err := os.ReadFile(filepath.Join(tempProjectDir, "loom.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(yamlContent)).To(ContainSubstring("gone.txt"))
+		})
+	})
+
+	Describe("loom weave --prune functionality", func() {
+		var tempProjectDir string
+		var threadSource string
+
+		BeforeEach(func() {
+			tempProjectDir = CreateTempDir()
+			threadSource = filepath.Join(tempProjectDir, ".loom", "starter", "_thread")
+			CreateTempFile(threadSource, "a.txt", "a")
+			CreateTempFile(threadSource, "b.txt", "b")
+		})
+
+		It("should delete files removed from the source and drop them from the manifest", func() {
+			Eventually(runLoom(tempProjectDir, "", "", "add", "starter"), "10s").Should(gexec.Exit(0))
+			Expect(os.Remove(filepath.Join(threadSource, "b.txt"))).To(Succeed())
+
+			session := runLoom(tempProjectDir, "", "", "weave", "--prune", "--yes")
+			Eventually(session, "10s").Should(gexec.Exit(0))
+			Expect(session.Out).To(gbytes.Say("Pruned file 'b.txt'"))
+			Expect(filepath.Join(tempProjectDir, "a.txt")).To(BeAnExistingFile())
+			Expect(filepath.Join(tempProjectDir, "b.txt")).NotTo(BeAnExistingFile())
+			yamlContent, err := os.ReadFile(filepath.Join(tempProjectDir, "loom.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(yamlContent)).To(ContainSubstring("a.txt"))
+			Expect(string(yamlContent)).NotTo(ContainSubstring("b.txt"))
+		})
+
+		It("should keep the files and their manifest entries when the prompt is declined", func() {
+			Eventually(runLoom(tempProjectDir, "", "", "add", "starter"), "10s").Should(gexec.Exit(0))
+			Expect(os.Remove(filepath.Join(threadSource, "b.txt"))).To(Succeed())
+
+			session := runLoom(tempProjectDir, "", "n\n", "weave", "--prune")
+			Eventually(session, "10s").Should(gexec.Exit(0))
+			Expect(session.Out).To(gbytes.Say("Keeping 1 file\\(s\\) for thread 'starter'"))
+			Expect(filepath.Join(tempProjectDir, "b.txt")).To(BeAnExistingFile())
+			yamlContent, err := os.ReadFile(filepath.Join(tempProjectDir, "loom.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(yamlContent)).To(ContainSubstring("b.txt"))
+		})
+
+		It("should not delete files recorded outside the thread's prefix", func() {
+			Eventually(runLoom(tempProjectDir, "", "", "add", "--prefix", "web", "starter"), "10s").Should(gexec.Exit(0))
+			loomYAMLPath := filepath.Join(tempProjectDir, "loom.yaml")
+			yamlContent, err := os.ReadFile(loomYAMLPath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(os.WriteFile(loomYAMLPath, []byte(strings.Replace(string(yamlContent), "prefix: web", "prefix: app", 1)), 0644)).To(Succeed())
+			Expect(os.Remove(filepath.Join(threadSource, "b.txt"))).To(Succeed())
+
+			session := runLoom(tempProjectDir, "", "", "weave", "--prune", "--yes")
+			Eventually(session, "10s").Should(gexec.Exit(0))
+			Expect(session.Out).To(gbytes.Say("Not pruning 'web/a.txt'"))
+			Expect(filepath.Join(tempProjectDir, "web", "a.txt")).To(BeAnExistingFile())
+			Expect(filepath.Join(tempProjectDir, "web", "b.txt")).To(BeAnExistingFile())
+			Expect(filepath.Join(tempProjectDir, "app", "a.txt")).To(BeAnExistingFile())
+			yamlContent, err = os.ReadFile(loomYAMLPath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(yamlContent)).To(ContainSubstring("web/:"))
+		})
+	})
+
+	Describe("template variables", func() {
+		var tempProjectDir string
+
+		BeforeEach(func() {
+			tempProjectDir = CreateTempDir()
+			CreateTempFile(filepath.Join(tempProjectDir, ".loom", "starter", "_thread"), "app.txt", "app")
+		})
+
+		It("should keep the vars of loom.yaml when rewriting it", func() {
+			Eventually(runLoom(tempProjectDir, "", "", "add", "starter"), "10s").Should(gexec.Exit(0))
+			loomYAMLPath := filepath.Join(tempProjectDir, "loom.yaml")
+			yamlContent, err := os.ReadFile(loomYAMLPath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(os.WriteFile(loomYAMLPath, []byte(strings.Replace(string(yamlContent), "threads:", "vars:\n    APP_NAME: shop\nthreads:", 1)), 0644)).To(Succeed())
+
+			session := runLoom(tempProjectDir, "", "", "weave")
+			Eventually(session, "10s").Should(gexec.Exit(0))
+			Expect(string(session.Err.Contents())).NotTo(ContainSubstring("unknown key"))
+			yamlContent, err = os.ReadFile(loomYAMLPath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(yamlContent)).To(ContainSubstring("APP_NAME: shop"))
+		})
+	})
+
+	Describe("loom remove functionality", func() {
+		var tempProjectDir string
+
+		BeforeEach(func() {
+			tempProjectDir = CreateTempDir()
+			threadDir := filepath.Join(CreateTempDir(), "myThread")
+			CreateTempFile(filepath.Join(threadDir, "_thread"), "file1.txt", "content of file1")
+			CreateTempFile(filepath.Join(threadDir, "_thread", "generated"), "file2.txt", "content of file2")
+			Eventually(runLoom(tempProjectDir, "", "", "add", "--from", threadDir), "10s").Should(gexec.Exit(0))
+		})
+
+		It("should skip missing-file warnings and delete leftover directories with --force --yes", func() {
+			Expect(os.Remove(filepath.Join(tempProjectDir, "file1.txt"))).To(Succeed())
+			Expect(os.MkdirAll(filepath.Join(tempProjectDir, "generated", "empty"), 0755)).To(Succeed())
+
+			session := runLoom(tempProjectDir, "", "", "remove", "--force", "--yes", "myThread")
+			Eventually(session, "10s").Should(gexec.Exit(0))
+			Expect(string(session.Out.Contents())).NotTo(ContainSubstring("not found"))
+			Expect(filepath.Join(tempProjectDir, "generated")).NotTo(BeADirectory())
+
+			yamlContent, err := os.ReadFile(filepath.Join(tempProjectDir, "loom.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(yamlContent)).NotTo(ContainSubstring("myThread"))
+		})
+
+		It("should keep a leftover directory holding untracked files with --force --yes", func() {
+			CreateTempFile(filepath.Join(tempProjectDir, "generated"), "cache.tmp", "leftover")
+
+			session := runLoom(tempProjectDir, "", "", "remove", "--force", "--yes", "myThread")
+			Eventually(session, "10s").Should(gexec.Exit(0))
+			Expect(session.Out).To(gbytes.Say(`Keeping directory generated/: it holds files that were not installed by the removed thread\(s\):`))
+			Expect(session.Out).To(gbytes.Say(`- generated/cache.tmp`))
+			Expect(filepath.Join(tempProjectDir, "generated", "cache.tmp")).To(BeAnExistingFile())
+			Expect(filepath.Join(tempProjectDir, "generated", "file2.txt")).NotTo(BeAnExistingFile())
+		})
+
+		It("should keep non-empty directories without --force", func() {
+			CreateTempFile(filepath.Join(tempProjectDir, "generated"), "cache.tmp", "leftover")
+
+			session := runLoom(tempProjectDir, "", "", "remove", "myThread")
+			Eventually(session, "10s").Should(gexec.Exit(0))
+			Expect(filepath.Join(tempProjectDir, "generated", "cache.tmp")).To(BeAnExistingFile())
+		})
+
+		It("should leave emptied directories in place with --no-empty-dir-cleanup", func() {
+			session := runLoom(tempProjectDir, "", "", "remove", "--no-empty-dir-cleanup", "myThread")
+			Eventually(session, "10s").Should(gexec.Exit(0))
+			Expect(filepath.Join(tempProjectDir, "generated", "file2.txt")).NotTo(BeAnExistingFile())
+			Expect(filepath.Join(tempProjectDir, "generated")).To(BeADirectory())
+			Expect(string(session.Out.Contents())).NotTo(ContainSubstring("Removed empty directory"))
+		})
+
+		It("should leave emptied directories in place when removing all threads with --no-empty-dir-cleanup", func() {
+			session := runLoom(tempProjectDir, "", "", "remove", "--no-empty-dir-cleanup", "--yes", "*")
+			Eventually(session, "10s").Should(gexec.Exit(0))
+			Expect(filepath.Join(tempProjectDir, "file1.txt")).NotTo(BeAnExistingFile())
+			Expect(filepath.Join(tempProjectDir, "generated")).To(BeADirectory())
+		})
+
+		It("should delete the .loom source of a project thread with --purge-source but never a store's", func() {
+			for _, name := range []string{"projThread", "otherThread"} {
+				CreateTempFile(filepath.Join(tempProjectDir, ".loom", name, "_thread"), name+".txt", name)
+				Eventually(runLoom(tempProjectDir, "", "", "add", name), "10s").Should(gexec.Exit(0))
+			}
+
+			session := runLoom(tempProjectDir, "", "", "remove", "--purge-source", "projThread")
+			Eventually(session, "10s").Should(gexec.Exit(0))
+			Expect(filepath.Join(tempProjectDir, ".loom", "projThread")).NotTo(BeADirectory())
+			Expect(filepath.Join(tempProjectDir, ".loom", "otherThread")).To(BeADirectory())
+
+			session = runLoom(tempProjectDir, "", "", "remove", "--purge-source", "myThread")
+			Eventually(session, "10s").Should(gexec.Exit(0))
+			Expect(session.Out).To(gbytes.Say("leaving its source alone"))
+
+			Eventually(runLoom(tempProjectDir, "", "", "remove", "--purge-source", "otherThread"), "10s").Should(gexec.Exit(0))
+			Expect(filepath.Join(tempProjectDir, ".loom")).NotTo(BeADirectory())
+		})
+
+		It("should refuse to remove all threads without a terminal or --yes", func() {
+			session := runLoom(tempProjectDir, "", "", "remove", "*")
+			Eventually(session, "10s").Should(gexec.Exit(2))
+			Expect(session.Out).To(gbytes.Say(`- myThread: 2 file\(s\)`))
+			Expect(session.Out).To(gbytes.Say(`Total: 1 thread\(s\), 2 file\(s\)`))
+			Expect(session.Err).To(gbytes.Say("refusing to remove all threads without confirmation"))
+			Expect(filepath.Join(tempProjectDir, "file1.txt")).To(BeAnExistingFile())
+			yamlContent, err := os.ReadFile(filepath.Join(tempProjectDir, "loom.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(yamlContent)).To(ContainSubstring("myThread"))
+		})
+
+		It("should remove all threads with --yes after listing them", func() {
+			session := runLoom(tempProjectDir, "", "", "remove", "--yes", "*")
+			Eventually(session, "10s").Should(gexec.Exit(0))
+			Expect(session.Out).To(gbytes.Say(`- myThread: 2 file\(s\)`))
+			Expect(session.Out).To(gbytes.Say("All threads removed"))
+			Expect(filepath.Join(tempProjectDir, "file1.txt")).NotTo(BeAnExistingFile())
+			Expect(filepath.Join(tempProjectDir, "generated", "file2.txt")).NotTo(BeAnExistingFile())
+		})
+
+		It("should remove threads by source, requiring --all when several share it", func() {
+			CreateTempFile(filepath.Join(tempProjectDir, ".loom", "projThread", "_thread"), "proj.txt", "proj")
+			Eventually(runLoom(tempProjectDir, "", "", "add", "projThread"), "10s").Should(gexec.Exit(0))
+			Eventually(runLoom(tempProjectDir, "", "", "add", "--rename", "projCopy", "--prefix", "copy", "projThread"), "10s").Should(gexec.Exit(0))
+
+			session := runLoom(tempProjectDir, "", "", "remove", "--source", "project:.loom/missing")
+			Eventually(session, "10s").Should(gexec.Exit(2))
+			Expect(session.Err).To(gbytes.Say(`no thread in loom.yaml has source 'project:.loom/missing'`))
+
+			session = runLoom(tempProjectDir, "", "", "remove", "--source", "project:.loom/projThread")
+			Eventually(session, "10s").Should(gexec.Exit(2))
+			Expect(session.Err).To(gbytes.Say(`2 threads have source 'project:.loom/projThread' \(projThread, projCopy\); pass --all`))
+			Expect(filepath.Join(tempProjectDir, "proj.txt")).To(BeAnExistingFile())
+
+			session = runLoom(tempProjectDir, "", "", "remove", "--source", "project:.loom/projThread", "--all")
+			Eventually(session, "10s").Should(gexec.Exit(0))
+			Expect(session.Out).To(gbytes.Say(`Thread 'projThread' removed successfully`))
+			Expect(session.Out).To(gbytes.Say(`Thread 'projCopy' removed successfully`))
+			Expect(filepath.Join(tempProjectDir, "proj.txt")).NotTo(BeAnExistingFile())
+			Expect(filepath.Join(tempProjectDir, "copy", "proj.txt")).NotTo(BeAnExistingFile())
+			Expect(filepath.Join(tempProjectDir, "file1.txt")).To(BeAnExistingFile())
+
+			yamlContent, err := os.ReadFile(filepath.Join(tempProjectDir, "loom.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(yamlContent)).NotTo(ContainSubstring("projThread"))
+			Expect(string(yamlContent)).To(ContainSubstring("myThread"))
+		})
+	})
+
+	Describe("loom --config functionality", func() {
+		It("should add, weave, list and remove against the given loom.yaml from another directory", func() {
+			projectDir := CreateTempDir()
+			workDir := CreateTempDir()
+			threadDir := filepath.Join(CreateTempDir(), "myThread")
+			CreateTempFile(filepath.Join(threadDir, "_thread"), "file1.txt", "content of file1")
+			manifestPath := filepath.Join(projectDir, "loom.yaml")
+
+			runWithConfig := func(args ...string) *gexec.Session {
+				session := runLoom(workDir, "", "", append([]string{"--config", manifestPath}, args...)...)
+				Eventually(session, "10s").Should(gexec.Exit(0))
+				return session
+			}
+
+			runWithConfig("add", "--from", threadDir)
+			Expect(filepath.Join(projectDir, "file1.txt")).To(BeAnExistingFile())
+			Expect(manifestPath).To(BeAnExistingFile())
+			Expect(filepath.Join(workDir, "loom.yaml")).NotTo(BeAnExistingFile())
+
+			Expect(os.Remove(filepath.Join(projectDir, "file1.txt"))).To(Succeed())
+			runWithConfig("weave")
+			Expect(filepath.Join(projectDir, "file1.txt")).To(BeAnExistingFile())
+
+			Expect(string(runWithConfig("list", "--active").Out.Contents())).To(ContainSubstring("myThread"))
+
+			runWithConfig("remove", "myThread")
+			Expect(filepath.Join(projectDir, "file1.txt")).NotTo(BeAnExistingFile())
+			Expect(filepath.Join(workDir, "file1.txt")).NotTo(BeAnExistingFile())
+		})
+	})
+
+	Describe("loom search functionality", func() {
+		var tempProjectDir string
+		var tempGlobalLoomDir string
+
+		BeforeEach(func() {
+			tempProjectDir = CreateTempDir()
+			tempGlobalLoomDir = CreateTempDir()
+			storeDir := filepath.Join(CreateTempDir(), "shared")
+			CreateTempFile(filepath.Join(storeDir, "go-ci", "_thread"), "ci.yml", "ci")
+			CreateTempFile(filepath.Join(storeDir, "go-ci"), "config.yml", "version: 1\nmetadata:\n  description: GitHub Actions workflow for Go\n  tags: [go, ci]\n")
+			CreateTempFile(filepath.Join(storeDir, "editorconfig", "_thread"), ".editorconfig", "root = true")
+			CreateTempFile(filepath.Join(storeDir, "editorconfig"), "config.yml", "version: 1\nmetadata:\n  description: Shared editor settings\n  tags: [editor]\n")
+			Eventually(runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "add", storeDir), "10s").Should(gexec.Exit(0))
+		})
+
+		It("should match thread names, descriptions and tags", func() {
+			session := runLoom(tempProjectDir, tempGlobalLoomDir, "", "search", "actions")
+			Eventually(session, "10s").Should(gexec.Exit(0))
+			Expect(string(session.Out.Contents())).To(ContainSubstring("shared/go-ci - GitHub Actions workflow for Go"))
+			Expect(string(session.Out.Contents())).NotTo(ContainSubstring("editorconfig"))
+		})
+
+		It("should filter strictly by tag with --tag", func() {
+			session := runLoom(tempProjectDir, tempGlobalLoomDir, "", "search", "--tag", "edit")
+			Eventually(session, "10s").Should(gexec.Exit(0))
+			Expect(string(session.Out.Contents())).To(ContainSubstring("No matching threads found."))
+
+			session = runLoom(tempProjectDir, tempGlobalLoomDir, "", "search", "--tag", "EDITOR")
+			Eventually(session, "10s").Should(gexec.Exit(0))
+			Expect(string(session.Out.Contents())).To(ContainSubstring("shared/editorconfig - Shared editor settings"))
+		})
+
+		It("should fail with a usage error without a term or tag", func() {
+			Eventually(runLoom(tempProjectDir, tempGlobalLoomDir, "", "search"), "10s").Should(gexec.Exit(2))
+		})
+	})
+
+	Describe("loom owner functionality", func() {
+		var tempProjectDir string
+		var tempGlobalLoomDir string
+
+		BeforeEach(func() {
+			tempProjectDir = CreateTempDir()
+			tempGlobalLoomDir = CreateTempDir()
+			CreateTempFile(filepath.Join(tempProjectDir, ".loom", "ci", "_thread", ".github"), "ci.yml", "ci")
+			Eventually(runLoom(tempProjectDir, tempGlobalLoomDir, "", "add", "ci"), "10s").Should(gexec.Exit(0))
+			CreateTempFile(tempProjectDir, "README.md", "mine")
+		})
+
+		It("should print the owning thread and its source for each path", func() {
+			session := runLoom(tempProjectDir, tempGlobalLoomDir, "", "owner", ".github/ci.yml", "README.md")
+			Eventually(session, "10s").Should(gexec.Exit(0))
+			Expect(string(session.Out.Contents())).To(ContainSubstring(".github/ci.yml: ci (Source: project:.loom/ci)"))
+			Expect(string(session.Out.Contents())).To(ContainSubstring("README.md: not owned by any thread."))
+		})
+
+		It("should require at least one path", func() {
+			session := runLoom(tempProjectDir, tempGlobalLoomDir, "", "owner")
+			Eventually(session, "10s").Should(gexec.Exit(2))
+			Expect(string(session.Err.Contents())).To(ContainSubstring("at least one file path"))
+		})
+	})
+
+	Describe("loom manifest functionality", func() {
+		var tempProjectDir string
+		var tempGlobalLoomDir string
+
+		BeforeEach(func() {
+			tempProjectDir = CreateTempDir()
+			tempGlobalLoomDir = CreateTempDir()
+			threadDir := filepath.Join(tempProjectDir, ".loom", "ci", "_thread")
+			CreateTempFile(filepath.Join(threadDir, ".github"), "ci.yml", "ci")
+			CreateTempFile(threadDir, "Makefile", "all:")
+			Eventually(runLoom(tempProjectDir, tempGlobalLoomDir, "", "add", "ci"), "10s").Should(gexec.Exit(0))
+		})
+
+		It("should print the thread's files map as JSON", func() {
+			session := runLoom(tempProjectDir, tempGlobalLoomDir, "", "manifest", "ci")
+			Eventually(session, "10s").Should(gexec.Exit(0))
+			var files map[string][]string
+			Expect(json.Unmarshal(session.Out.Contents(), &files)).To(Succeed())
+			Expect(files).To(Equal(map[string][]string{"./": {"Makefile"}, ".github/": {"ci.yml"}}))
+		})
+
+		It("should print one project-relative path per line with --paths", func() {
+			session := runLoom(tempProjectDir, tempGlobalLoomDir, "", "manifest", "--paths", "ci")
+			Eventually(session, "10s").Should(gexec.Exit(0))
+			Expect(string(session.Out.Contents())).To(Equal(".github/ci.yml\nMakefile\n"))
+		})
+
+		It("should fail with a usage error for a thread not in loom.yaml", func() {
+			session := runLoom(tempProjectDir, tempGlobalLoomDir, "", "manifest", "missing")
+			Eventually(session, "10s").Should(gexec.Exit(2))
+			Expect(string(session.Err.Contents())).To(ContainSubstring("thread 'missing' not found in loom.yaml"))
+		})
+	})
+
+	Describe("loom tidy functionality", func() {
+		var tempProjectDir string
+		var tempGlobalLoomDir string
+
+		readManifest := func() string {
+			data, err := os.ReadFile(filepath.Join(tempProjectDir, "loom.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			return string(data)
+		}
+
+		BeforeEach(func() {
+			tempProjectDir = CreateTempDir()
+			tempGlobalLoomDir = CreateTempDir()
+			ciDir := filepath.Join(tempProjectDir, ".loom", "ci", "_thread")
+			CreateTempFile(filepath.Join(ciDir, ".github"), "ci.yml", "ci")
+			CreateTempFile(ciDir, "Makefile", "all:")
+			CreateTempFile(filepath.Join(tempProjectDir, ".loom", "docs", "_thread", "docs"), "guide.md", "guide")
+			Eventually(runLoom(tempProjectDir, tempGlobalLoomDir, "", "add", "ci"), "10s").Should(gexec.Exit(0))
+			Eventually(runLoom(tempProjectDir, tempGlobalLoomDir, "", "add", "docs"), "10s").Should(gexec.Exit(0))
+			Expect(os.Remove(filepath.Join(tempProjectDir, ".github", "ci.yml"))).To(Succeed())
+			Expect(os.Remove(filepath.Join(tempProjectDir, "docs", "guide.md"))).To(Succeed())
+		})
+
+		It("should only list the entries to drop with --dry-run", func() {
+			before := readManifest()
+			session := runLoom(tempProjectDir, tempGlobalLoomDir, "", "tidy", "--dry-run")
+			Eventually(session, "10s").Should(gexec.Exit(0))
+			Expect(session.Out).To(gbytes.Say(`Would drop missing file .github/ci.yml \(thread 'ci'\)`))
+			Expect(session.Out).To(gbytes.Say(`Would drop missing file docs/guide.md \(thread 'docs'\)`))
+			Expect(session.Out).To(gbytes.Say(`nothing was saved`))
+			Expect(readManifest()).To(Equal(before))
+		})
+
+		It("should drop missing files and empty directory keys but keep threads by default", func() {
+			session := runLoom(tempProjectDir, tempGlobalLoomDir, "", "tidy")
+			Eventually(session, "10s").Should(gexec.Exit(0))
+			Expect(session.Out).To(gbytes.Say(`dropped 2 missing file\(s\) and 0 thread\(s\)`))
+			manifest := readManifest()
+			Expect(manifest).NotTo(ContainSubstring("ci.yml"))
+			Expect(manifest).NotTo(ContainSubstring(".github/"))
+			Expect(manifest).To(ContainSubstring("Makefile"))
+			Expect(manifest).To(ContainSubstring("name: docs"))
+
+			session = runLoom(tempProjectDir, tempGlobalLoomDir, "", "tidy")
+			Eventually(session, "10s").Should(gexec.Exit(0))
+			Expect(session.Out).To(gbytes.Say(`Nothing to tidy`))
+		})
+
+		It("should drop threads left without files with --prune-threads", func() {
+			session := runLoom(tempProjectDir, tempGlobalLoomDir, "", "tidy", "--prune-threads")
+			Eventually(session, "10s").Should(gexec.Exit(0))
+			Expect(session.Out).To(gbytes.Say(`Dropped thread 'docs', which has no files left`))
+			manifest := readManifest()
+			Expect(manifest).NotTo(ContainSubstring("name: docs"))
+			Expect(manifest).To(ContainSubstring("name: ci"))
+		})
+	})
+
+	Describe("loom publish functionality", func() {
+		var tempProjectDir string
+		var tempGlobalLoomDir string
+		var storeDir string
+
+		BeforeEach(func() {
+			tempProjectDir = CreateTempDir()
+			tempGlobalLoomDir = CreateTempDir()
+			storeDir = CreateTempDir()
+			CreateTempFile(filepath.Join(tempProjectDir, "src", "lib"), "util.go", "package lib")
+			CreateTempFile(filepath.Join(tempProjectDir, "src"), "main.go", "package main")
+			CreateTempFile(tempProjectDir, "README.md", "# readme")
+			CreateTempFile(tempProjectDir, "notes.txt", "notes")
+			Eventually(runLoom(tempProjectDir, tempGlobalLoomDir, "", "init"), "10s").Should(gexec.Exit(0))
+			Eventually(runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "add", "--name", "mine", storeDir), "10s").Should(gexec.Exit(0))
+		})
+
+		It("should copy the matching files into a new thread with a minimal config.yml", func() {
+			session := runLoom(tempProjectDir, tempGlobalLoomDir, "", "publish", "--store", "mine", "--files", "src", "--files", "*.md", "starter")
+			Eventually(session, "10s").Should(gexec.Exit(0))
+			Expect(session.Out).To(gbytes.Say(`Published 3 file\(s\) as thread 'starter' in store 'mine'`))
+
+			sourceDir := filepath.Join(storeDir, "starter", "_thread")
+			Expect(filepath.Join(sourceDir, "src", "lib", "util.go")).To(BeAnExistingFile())
+			Expect(filepath.Join(sourceDir, "src", "main.go")).To(BeAnExistingFile())
+			Expect(filepath.Join(sourceDir, "README.md")).To(BeAnExistingFile())
+			Expect(filepath.Join(sourceDir, "notes.txt")).NotTo(BeAnExistingFile())
+			Expect(filepath.Join(sourceDir, "loom.yaml")).NotTo(BeAnExistingFile())
+			config, err := os.ReadFile(filepath.Join(storeDir, "starter", "config.yml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(config)).To(ContainSubstring("version: 1"))
+
+			session = runLoom(tempProjectDir, tempGlobalLoomDir, "", "add", "--manifest-only", "mine/starter")
+			Eventually(session, "10s").Should(gexec.Exit(0))
+			Expect(session.Out).To(gbytes.Say(`3 adopted`))
+		})
+
+		It("should refuse to replace an existing thread unless --force is passed", func() {
+			Eventually(runLoom(tempProjectDir, tempGlobalLoomDir, "", "publish", "--store", "mine", "--files", "src", "starter"), "10s").Should(gexec.Exit(0))
+
+			session := runLoom(tempProjectDir, tempGlobalLoomDir, "", "publish", "--store", "mine", "--files", "notes.txt", "starter")
+			Eventually(session, "10s").Should(gexec.Exit(2))
+			Expect(session.Err).To(gbytes.Say(`thread 'starter' already exists in store 'mine'; pass --force`))
+			Expect(filepath.Join(storeDir, "starter", "_thread", "src", "main.go")).To(BeAnExistingFile())
+
+			session = runLoom(tempProjectDir, tempGlobalLoomDir, "", "publish", "--store", "mine", "--files", "notes.txt", "--force", "starter")
+			Eventually(session, "10s").Should(gexec.Exit(0))
+			Expect(filepath.Join(storeDir, "starter", "_thread", "notes.txt")).To(BeAnExistingFile())
+			Expect(filepath.Join(storeDir, "starter", "_thread", "src")).NotTo(BeADirectory())
+		})
+
+		It("should reject read-only stores and patterns that match nothing", func() {
+			Eventually(runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "add", "--name", "shared", "--read-only", CreateTempDir()), "10s").Should(gexec.Exit(0))
+			session := runLoom(tempProjectDir, tempGlobalLoomDir, "", "publish", "--store", "shared", "--files", "src", "starter")
+			Eventually(session, "10s").Should(gexec.Exit(2))
+			Expect(session.Err).To(gbytes.Say(`store 'shared' is read-only`))
+
+			session = runLoom(tempProjectDir, tempGlobalLoomDir, "", "publish", "--store", "mine", "--files", "*.rs", "starter")
+			Eventually(session, "10s").Should(gexec.Exit(2))
+			Expect(session.Err).To(gbytes.Say(`no project files match --files '\*.rs'`))
+			Expect(filepath.Join(storeDir, "starter")).NotTo(BeADirectory())
+		})
+	})
+
+	Describe("loom config add-project functionality", func() {
+		var tempProjectDir string
+		var tempGlobalLoomDir string
+		var stdinInput string
+
+		BeforeEach(func() {
+			tempProjectDir = CreateTempDir()
+			tempGlobalLoomDir = CreateTempDir()
+			stdinInput = ""
+			storeDir := filepath.Join(CreateTempDir(), "shared")
+			CreateTempFile(filepath.Join(storeDir, "myThread", "_thread", "src"), "file1.txt", "content of file1")
+			CreateTempFile(filepath.Join(storeDir, "myThread"), "config.yml", "version: 1\n")
+			Eventually(runLoom(tempProjectDir, tempGlobalLoomDir, stdinInput, "config", "add", storeDir), "10s").Should(gexec.Exit(0))
+		})
+
+		It("should copy the thread into .loom so it resolves as a project source", func() {
+			Eventually(runLoom(tempProjectDir, tempGlobalLoomDir, stdinInput, "config", "add-project", "shared/myThread"), "10s").Should(gexec.Exit(0))
+			Expect(filepath.Join(tempProjectDir, ".loom", "myThread", "config.yml")).To(BeAnExistingFile())
+			Expect(filepath.Join(tempProjectDir, ".loom", "myThread", "_thread", "src", "file1.txt")).To(BeAnExistingFile())
+
+			Eventually(runLoom(tempProjectDir, tempGlobalLoomDir, stdinInput, "add", "myThread"), "10s").Should(gexec.Exit(0))
+			yamlContent, err := os.ReadFile(filepath.Join(tempProjectDir, "loom.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(yamlContent)).To(ContainSubstring("source: project:.loom/myThread"))
+		})
+
+		It("should only replace an existing project thread when confirmed or forced", func() {
+			staleFile := CreateTempFile(filepath.Join(tempProjectDir, ".loom", "myThread", "_thread"), "stale.txt", "stale")
+
+			stdinInput = "n\n"
+			session := runLoom(tempProjectDir, tempGlobalLoomDir, stdinInput, "config", "add-project", "shared/myThread")
+			Eventually(session, "10s").Should(gexec.Exit(0))
+			Expect(string(session.Out.Contents())).To(ContainSubstring("Copy cancelled."))
+			Expect(staleFile).To(BeAnExistingFile())
+
+			Eventually(runLoom(tempProjectDir, tempGlobalLoomDir, stdinInput, "config", "add-project", "--force", "shared/myThread"), "10s").Should(gexec.Exit(0))
+			Expect(staleFile).NotTo(BeAnExistingFile())
+			Expect(filepath.Join(tempProjectDir, ".loom", "myThread", "config.yml")).To(BeAnExistingFile())
+		})
+
+		It("should move a thread and its dependencies into .loom with move-to-project", func() {
+			storeDir := filepath.Join(CreateTempDir(), "common")
+			CreateTempFile(filepath.Join(storeDir, "app", "_thread"), "app.txt", "app")
+			CreateTempFile(filepath.Join(storeDir, "app"), "config.yml", "dependencies:\n  - common/lint\n")
+			CreateTempFile(filepath.Join(storeDir, "lint", "_thread"), "lint.txt", "lint")
+			CreateTempFile(filepath.Join(storeDir, "lint"), "config.yml", "dependencies:\n  - app\n")
+			Eventually(runLoom(tempProjectDir, tempGlobalLoomDir, stdinInput, "config", "add", storeDir), "10s").Should(gexec.Exit(0))
+			Eventually(runLoom(tempProjectDir, tempGlobalLoomDir, stdinInput, "init"), "10s").Should(gexec.Exit(0))
+			Eventually(runLoom(tempProjectDir, tempGlobalLoomDir, stdinInput, "add", "--no-deps", "common/lint"), "10s").Should(gexec.Exit(0))
+
+			// lint and app depend on each other; the cycle ends once both are in .loom.
+			session := runLoom(tempProjectDir, tempGlobalLoomDir, stdinInput, "config", "move-to-project", "common/app")
+			Eventually(session, "10s").Should(gexec.Exit(0))
+			Expect(filepath.Join(tempProjectDir, ".loom", "app", "_thread", "app.txt")).To(BeAnExistingFile())
+			Expect(filepath.Join(tempProjectDir, ".loom", "lint", "_thread", "lint.txt")).To(BeAnExistingFile())
+			appConfig, err := os.ReadFile(filepath.Join(tempProjectDir, ".loom", "app", "config.yml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(appConfig)).To(ContainSubstring("- lint"))
+			Expect(string(appConfig)).NotTo(ContainSubstring("common/lint"))
+
+			yamlContent, err := os.ReadFile(filepath.Join(tempProjectDir, "loom.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(yamlContent)).To(ContainSubstring("source: project:.loom/lint"))
+			Expect(string(yamlContent)).NotTo(ContainSubstring("source: common"))
+
+			// Dependencies already in .loom are kept as they are.
+			editedFile := CreateTempFile(filepath.Join(tempProjectDir, ".loom", "lint", "_thread"), "edited.txt", "local change")
+			session = runLoom(tempProjectDir, tempGlobalLoomDir, stdinInput, "config", "move-to-project", "--force", "common/app")
+			Eventually(session, "10s").Should(gexec.Exit(0))
+			Expect(string(session.Out.Contents())).To(ContainSubstring("already in the project store"))
+			Expect(editedFile).To(BeAnExistingFile())
+		})
+	})
+
+	Describe("config.yml conflict policy", func() {
+		var tempProjectDir string
+		var threadDir string
+
+		BeforeEach(func() {
+			tempProjectDir = CreateTempDir()
+			threadDir = filepath.Join(CreateTempDir(), "myThread")
+			CreateTempFile(filepath.Join(threadDir, "_thread", "db"), "schema.sql", "generated schema")
+			CreateTempFile(filepath.Join(threadDir, "_thread"), ".env.example", "TOKEN=")
+			CreateTempFile(threadDir, "config.yml", "version: 1\npolicy:\n  '*.sql': overwrite\n  .env.example: skip\n")
+			CreateTempFile(filepath.Join(tempProjectDir, "db"), "schema.sql", "stale schema")
+			CreateTempFile(tempProjectDir, ".env.example", "TOKEN=secret")
+		})
+
+		It("should resolve conflicts from the policy without prompting when adding", func() {
+			session := runLoom(tempProjectDir, "", "", "add", "--from", threadDir)
+			Eventually(session, "10s").Should(gexec.Exit(0))
+			Expect(string(session.Out.Contents())).To(ContainSubstring("(config.yml policy)"))
+
+			schema, err := os.ReadFile(filepath.Join(tempProjectDir, "db", "schema.sql"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(schema)).To(Equal("generated schema"))
+			env, err := os.ReadFile(filepath.Join(tempProjectDir, ".env.example"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(env)).To(Equal("TOKEN=secret"))
+		})
+
+		It("should keep skipping the file when weaving", func() {
+			Eventually(runLoom(tempProjectDir, "", "", "add", "--from", threadDir), "10s").Should(gexec.Exit(0))
+			Eventually(runLoom(tempProjectDir, "", "", "weave"), "10s").Should(gexec.Exit(0))
+			Eventually(runLoom(tempProjectDir, "", "", "weave", "myThread"), "10s").Should(gexec.Exit(0))
+
+			env, err := os.ReadFile(filepath.Join(tempProjectDir, ".env.example"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(env)).To(Equal("TOKEN=secret"))
+		})
+	})
+
+	Describe("--overwrite-policy", func() {
+		var tempProjectDir string
+		var threadDir string
+
+		readProjectFile := func(relPath string) string {
+			data, err := os.ReadFile(filepath.Join(tempProjectDir, relPath))
+			Expect(err).NotTo(HaveOccurred())
+			return string(data)
+		}
+
+		BeforeEach(func() {
+			tempProjectDir = CreateTempDir()
+			threadDir = filepath.Join(CreateTempDir(), "myThread")
+			CreateTempFile(filepath.Join(threadDir, "_thread", "db"), "schema.sql", "generated schema")
+			CreateTempFile(filepath.Join(threadDir, "_thread"), ".env.example", "TOKEN=")
+			CreateTempFile(threadDir, "config.yml", "version: 1\npolicy:\n  '*.sql': overwrite\n  .env.example: skip\n")
+			CreateTempFile(filepath.Join(tempProjectDir, "db"), "schema.sql", "stale schema")
+			CreateTempFile(tempProjectDir, ".env.example", "TOKEN=secret")
+		})
+
+		It("should overwrite every existing file with theirs, over config.yml policies", func() {
+			session := runLoom(tempProjectDir, "", "", "add", "--overwrite-policy", "theirs", "--from", threadDir)
+			Eventually(session, "10s").Should(gexec.Exit(0))
+			Expect(string(session.Out.Contents())).To(ContainSubstring("yes (--overwrite-policy)"))
+			Expect(readProjectFile(filepath.Join("db", "schema.sql"))).To(Equal("generated schema"))
+			Expect(readProjectFile(".env.example")).To(Equal("TOKEN="))
+		})
+
+		It("should keep every existing file with ours, over config.yml policies", func() {
+			session := runLoom(tempProjectDir, "", "", "add", "--overwrite-policy", "ours", "--from", threadDir)
+			Eventually(session, "10s").Should(gexec.Exit(0))
+			Expect(string(session.Out.Contents())).To(ContainSubstring("no (--overwrite-policy)"))
+			Expect(readProjectFile(filepath.Join("db", "schema.sql"))).To(Equal("stale schema"))
+			Expect(readProjectFile(".env.example")).To(Equal("TOKEN=secret"))
+		})
+
+		It("should keep edits to files the thread owns when weaving with ours", func() {
+			Expect(os.Remove(filepath.Join(tempProjectDir, "db", "schema.sql"))).To(Succeed())
+			Eventually(runLoom(tempProjectDir, "", "", "add", "--from", threadDir), "10s").Should(gexec.Exit(0))
+			Expect(os.WriteFile(filepath.Join(tempProjectDir, "db", "schema.sql"), []byte("edited schema"), 0o644)).To(Succeed())
+
+			Eventually(runLoom(tempProjectDir, "", "", "weave", "--overwrite-policy", "ours", "--check"), "10s").Should(gexec.Exit(0))
+			session := runLoom(tempProjectDir, "", "", "weave", "--overwrite-policy", "ours")
+			Eventually(session, "10s").Should(gexec.Exit(0))
+			Expect(string(session.Out.Contents())).To(ContainSubstring("Keeping file 'db/schema.sql' as it is (--overwrite-policy ours)."))
+			Expect(readProjectFile(filepath.Join("db", "schema.sql"))).To(Equal("edited schema"))
+
+			owner := runLoom(tempProjectDir, "", "", "owner", "db/schema.sql")
+			Eventually(owner, "10s").Should(gexec.Exit(0))
+			Expect(string(owner.Out.Contents())).To(ContainSubstring("db/schema.sql: myThread"))
+		})
+
+		It("should reject unknown policies and flags that never resolve conflicts", func() {
+			session := runLoom(tempProjectDir, "", "", "add", "--overwrite-policy", "mine", "--from", threadDir)
+			Eventually(session, "10s").Should(gexec.Exit(2))
+			Expect(string(session.Err.Contents())).To(ContainSubstring("unknown overwrite policy 'mine'"))
+
+			session = runLoom(tempProjectDir, "", "", "add", "--overwrite-policy", "ours", "--force", "--from", threadDir)
+			Eventually(session, "10s").Should(gexec.Exit(2))
+			Expect(string(session.Err.Contents())).To(ContainSubstring("--overwrite-policy cannot be combined with --force"))
+		})
+	})
+
+	Describe("config.yml files filter", func() {
+		var tempProjectDir string
+		var threadDir string
+
+		BeforeEach(func() {
+			tempProjectDir = CreateTempDir()
+			threadDir = filepath.Join(CreateTempDir(), "myThread")
+			CreateTempFile(filepath.Join(threadDir, "_thread", "src"), "main.go", "package main")
+			CreateTempFile(filepath.Join(threadDir, "_thread", "src", "tests"), "main_test.go", "package main")
+			CreateTempFile(filepath.Join(threadDir, "_thread"), "Makefile", "all:")
+			CreateTempFile(filepath.Join(threadDir, "_thread"), "README.md", "about this thread")
+			CreateTempFile(threadDir, "config.yml", "version: 1\nfiles:\n  include: [src, Makefile]\n  exclude: [tests]\n")
+		})
+
+		It("should only install and record the included files when adding", func() {
+			Eventually(runLoom(tempProjectDir, "", "", "add", "--from", threadDir), "10s").Should(gexec.Exit(0))
+
+			Expect(filepath.Join(tempProjectDir, "src", "main.go")).To(BeAnExistingFile())
+			Expect(filepath.Join(tempProjectDir, "Makefile")).To(BeAnExistingFile())
+			Expect(filepath.Join(tempProjectDir, "README.md")).NotTo(BeAnExistingFile())
+			Expect(filepath.Join(tempProjectDir, "src", "tests")).NotTo(BeAnExistingFile())
+			manifest, err := os.ReadFile(filepath.Join(tempProjectDir, "loom.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(manifest)).NotTo(ContainSubstring("README.md"))
+			Expect(string(manifest)).NotTo(ContainSubstring("main_test.go"))
+		})
+
+		It("should drop newly excluded files from loom.yaml when weaving", func() {
+			Eventually(runLoom(tempProjectDir, "", "", "add", "--from", threadDir), "10s").Should(gexec.Exit(0))
+			CreateTempFile(threadDir, "config.yml", "version: 1\nfiles:\n  include: [src, Makefile]\n  exclude: [tests, Makefile]\n")
+
+			session := runLoom(tempProjectDir, "", "", "weave", "myThread")
+			Eventually(session, "10s").Should(gexec.Exit(0))
+			Expect(string(session.Out.Contents())).To(ContainSubstring("excluded by config.yml"))
+			manifest, err := os.ReadFile(filepath.Join(tempProjectDir, "loom.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(manifest)).NotTo(ContainSubstring("Makefile"))
+			Expect(string(manifest)).To(ContainSubstring("main.go"))
+		})
+	})
+
+	Describe("config.yaml thread configuration", func() {
+		var tempProjectDir string
+		var threadDir string
+
+		BeforeEach(func() {
+			tempProjectDir = CreateTempDir()
+			threadDir = filepath.Join(CreateTempDir(), "myThread")
+			CreateTempFile(filepath.Join(threadDir, "_thread"), "Makefile", "all:")
+			CreateTempFile(filepath.Join(threadDir, "_thread"), "README.md", "about this thread")
+		})
+
+		It("should read config.yaml when the thread has no config.yml", func() {
+			CreateTempFile(threadDir, "config.yaml", "version: 1\nfiles:\n  exclude: [README.md]\n")
+			session := runLoom(tempProjectDir, "", "", "add", "--from", threadDir)
+			Eventually(session, "10s").Should(gexec.Exit(0))
+			Expect(filepath.Join(tempProjectDir, "Makefile")).To(BeAnExistingFile())
+			Expect(filepath.Join(tempProjectDir, "README.md")).NotTo(BeAnExistingFile())
+			Expect(string(session.Err.Contents())).NotTo(ContainSubstring("Warning"))
+		})
+
+		It("should prefer config.yml and warn when the thread has both", func() {
+			CreateTempFile(threadDir, "config.yaml", "version: 1\nfiles:\n  exclude: [README.md]\n")
+			CreateTempFile(threadDir, "config.yml", "version: 1\nfiles:\n  exclude: [Makefile]\n")
+			session := runLoom(tempProjectDir, "", "", "add", "--from", threadDir)
+			Eventually(session, "10s").Should(gexec.Exit(0))
+			Expect(filepath.Join(tempProjectDir, "README.md")).To(BeAnExistingFile())
+			Expect(filepath.Join(tempProjectDir, "Makefile")).NotTo(BeAnExistingFile())
+			Expect(strings.Count(string(session.Err.Contents()), "has both config.yml and config.yaml; using config.yml")).To(Equal(1))
+		})
+	})
+
+	Describe("config.yml line endings", func() {
+		var tempProjectDir string
+		var threadDir string
+		pngHeader := []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1a, '\n', 0x00, 0x00, '\r', '\n'}
+
+		BeforeEach(func() {
+			tempProjectDir = CreateTempDir()
+			threadDir = filepath.Join(CreateTempDir(), "myThread")
+			CreateTempFile(filepath.Join(threadDir, "_thread"), "run.sh", "#!/bin/sh\r\necho hi\r\n")
+			Expect(os.WriteFile(filepath.Join(threadDir, "_thread", "logo.png"), pngHeader, 0644)).To(Succeed())
+			CreateTempFile(threadDir, "config.yml", "version: 1\nline_endings: lf\n")
+		})
+
+		It("should convert text files and leave binary files untouched", func() {
+			Eventually(runLoom(tempProjectDir, "", "", "add", "--from", threadDir), "10s").Should(gexec.Exit(0))
+
+			script, err := os.ReadFile(filepath.Join(tempProjectDir, "run.sh"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(script)).To(Equal("#!/bin/sh\necho hi\n"))
+			logo, err := os.ReadFile(filepath.Join(tempProjectDir, "logo.png"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(logo).To(Equal(pngHeader))
+
+			session := runLoom(tempProjectDir, "", "", "weave", "--check")
+			Eventually(session, "10s").Should(gexec.Exit(0))
+		})
+	})
+
+	Describe("loom add --prefix functionality", func() {
+		var tempProjectDir string
+
+		BeforeEach(func() {
+			tempProjectDir = CreateTempDir()
+			CreateTempFile(filepath.Join(tempProjectDir, ".loom", "first", "_thread"), "app.txt", "first")
+			CreateTempFile(filepath.Join(tempProjectDir, ".loom", "second", "_thread"), "app.txt", "second")
+		})
+
+		It("should install under the prefix, record prefixed paths and detect conflicts there", func() {
+			Eventually(runLoom(tempProjectDir, "", "", "add", "--prefix", "packages/web", "first"), "10s").Should(gexec.Exit(0))
+			Expect(filepath.Join(tempProjectDir, "packages", "web", "app.txt")).To(BeAnExistingFile())
+			Expect(filepath.Join(tempProjectDir, "app.txt")).NotTo(BeAnExistingFile())
+			yamlContent, err := os.ReadFile(filepath.Join(tempProjectDir, "loom.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(yamlContent)).To(ContainSubstring("prefix: packages/web"))
+			Expect(string(yamlContent)).To(ContainSubstring("packages/web/:"))
+
+			session := runLoom(tempProjectDir, "", "n\n", "add", "--prefix", "packages/web", "second")
+			Eventually(session, "10s").Should(gexec.Exit(0))
+			Expect(session.Out).To(gbytes.Say("File 'packages/web/app.txt' is currently owned by thread"))
+			content, err := os.ReadFile(filepath.Join(tempProjectDir, "packages", "web", "app.txt"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal("first"))
+
+			Eventually(runLoom(tempProjectDir, "", "", "remove", "first"), "10s").Should(gexec.Exit(0))
+			Expect(filepath.Join(tempProjectDir, "packages", "web", "app.txt")).NotTo(BeAnExistingFile())
+		})
+
+		It("should reject a prefix that escapes the project root", func() {
+			session := runLoom(tempProjectDir, "", "", "add", "--prefix", "../outside", "first")
+			Eventually(session, "10s").Should(gexec.Exit(2))
+			Expect(session.Err).To(gbytes.Say("invalid prefix '../outside': must not escape the project root"))
+			Expect(filepath.Join(tempProjectDir, "loom.yaml")).NotTo(BeAnExistingFile())
+		})
+	})
+
+	Describe("loom add --force functionality", func() {
+		var tempProjectDir string
+
+		BeforeEach(func() {
+			tempProjectDir = CreateTempDir()
+			CreateTempFile(filepath.Join(tempProjectDir, ".loom", "first", "_thread"), "app.txt", "first")
+			CreateTempFile(filepath.Join(tempProjectDir, ".loom", "second", "_thread"), "app.txt", "second")
+		})
+
+		It("should overwrite existing files without prompting and still move ownership", func() {
+			CreateTempFile(tempProjectDir, "app.txt", "my own")
+			session := runLoom(tempProjectDir, "", "", "add", "--force", "first")
+			Eventually(session, "10s").Should(gexec.Exit(0))
+			Expect(string(session.Out.Contents())).NotTo(ContainSubstring("Do you want"))
+			content, err := os.ReadFile(filepath.Join(tempProjectDir, "app.txt"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal("first"))
+
+			session = runLoom(tempProjectDir, "", "", "add", "--force", "second")
+			Eventually(session, "10s").Should(gexec.Exit(0))
+			Expect(string(session.Out.Contents())).NotTo(ContainSubstring("Do you want"))
+			content, err = os.ReadFile(filepath.Join(tempProjectDir, "app.txt"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal("second"))
+			yamlContent, err := os.ReadFile(filepath.Join(tempProjectDir, "loom.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(strings.Count(string(yamlContent), "app.txt")).To(Equal(1))
+			Expect(strings.Index(string(yamlContent), "app.txt")).To(BeNumerically(">", strings.Index(string(yamlContent), "name: second")))
+		})
+	})
+
+	Describe("loom add --rename functionality", func() {
+		var tempProjectDir string
+		var tempGlobalLoomDir string
+
+		BeforeEach(func() {
+			tempProjectDir = CreateTempDir()
+			tempGlobalLoomDir = CreateTempDir()
+			for _, store := range []string{"backend", "frontend"} {
+				storeDir := filepath.Join(CreateTempDir(), store)
+				CreateTempFile(filepath.Join(storeDir, "base", "_thread", store), "base.txt", store+" base")
+				Eventually(runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "add", storeDir), "10s").Should(gexec.Exit(0))
+			}
+			Eventually(runLoom(tempProjectDir, tempGlobalLoomDir, "", "add", "backend/base"), "10s").Should(gexec.Exit(0))
+		})
+
+		It("should record the thread under the new name and weave and remove it by that name", func() {
+			Eventually(runLoom(tempProjectDir, tempGlobalLoomDir, "", "add", "--rename", "base-frontend", "frontend/base"), "10s").Should(gexec.Exit(0))
+			yamlContent, err := os.ReadFile(filepath.Join(tempProjectDir, "loom.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(yamlContent)).To(ContainSubstring("name: base-frontend"))
+			Expect(string(yamlContent)).To(ContainSubstring("source: frontend/base"))
+
+			frontendFile := filepath.Join(tempProjectDir, "frontend", "base.txt")
+			Expect(os.Remove(frontendFile)).To(Succeed())
+			Eventually(runLoom(tempProjectDir, tempGlobalLoomDir, "", "weave", "base-frontend"), "10s").Should(gexec.Exit(0))
+			Expect(frontendFile).To(BeAnExistingFile())
+
+			Eventually(runLoom(tempProjectDir, tempGlobalLoomDir, "", "remove", "base-frontend"), "10s").Should(gexec.Exit(0))
+			Expect(frontendFile).NotTo(BeAnExistingFile())
+			Expect(filepath.Join(tempProjectDir, "backend", "base.txt")).To(BeAnExistingFile())
+		})
+
+		It("should reject a rename that collides with another thread", func() {
+			session := runLoom(tempProjectDir, tempGlobalLoomDir, "", "add", "--rename", "base", "frontend/base")
+			Eventually(session, "10s").Should(gexec.Exit(2))
+			Expect(session.Err).To(gbytes.Say("a thread named 'base' from 'backend' already exists"))
+		})
+
+		Context("when the thread's config.yml declares a name", func() {
+			var storeDir string
+
+			BeforeEach(func() {
+				storeDir = filepath.Join(CreateTempDir(), "widgets")
+				threadDir := filepath.Join(storeDir, "react-button-v2")
+				CreateTempFile(filepath.Join(threadDir, "_thread"), "button.txt", "button")
+				CreateTempFile(threadDir, "config.yml", "version: 1\nname: react-button\n")
+				Eventually(runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "add", storeDir), "10s").Should(gexec.Exit(0))
+			})
+
+			It("should record the declared name while the argument still names the directory", func() {
+				session := runLoom(tempProjectDir, tempGlobalLoomDir, "", "add", "widgets/react-button-v2")
+				Eventually(session, "10s").Should(gexec.Exit(0))
+				Expect(session.Out).To(gbytes.Say(`as 'react-button', the name its config.yml declares`))
+				yamlContent, err := os.ReadFile(filepath.Join(tempProjectDir, "loom.yaml"))
+				Expect(err).NotTo(HaveOccurred())
+				Expect(string(yamlContent)).To(ContainSubstring("name: react-button\n"))
+				Expect(string(yamlContent)).To(ContainSubstring("source: widgets/react-button-v2"))
+
+				buttonFile := filepath.Join(tempProjectDir, "button.txt")
+				Expect(os.Remove(buttonFile)).To(Succeed())
+				Eventually(runLoom(tempProjectDir, tempGlobalLoomDir, "", "weave", "react-button"), "10s").Should(gexec.Exit(0))
+				Expect(buttonFile).To(BeAnExistingFile())
+				Eventually(runLoom(tempProjectDir, tempGlobalLoomDir, "", "remove", "react-button"), "10s").Should(gexec.Exit(0))
+				Expect(buttonFile).NotTo(BeAnExistingFile())
+			})
+
+			It("should refuse a name that another thread in the same store declares", func() {
+				otherDir := filepath.Join(storeDir, "react-button-v1")
+				CreateTempFile(filepath.Join(otherDir, "_thread"), "old.txt", "old")
+				CreateTempFile(otherDir, "config.yml", "version: 1\nname: react-button\n")
+
+				session := runLoom(tempProjectDir, tempGlobalLoomDir, "", "add", "widgets/react-button-v2")
+				Eventually(session, "10s").Should(gexec.Exit(2))
+				Expect(session.Err).To(gbytes.Say(`threads 'react-button-v2' and 'react-button-v1' in .* both declare the name 'react-button'`))
+				Expect(filepath.Join(tempProjectDir, "button.txt")).NotTo(BeAnExistingFile())
+			})
+		})
+	})
+
+	Describe("loom add thread dependencies", func() {
+		var tempProjectDir string
+		var tempGlobalLoomDir string
+		var storeDir string
+		var stdinInput string
+
+		BeforeEach(func() {
+			tempProjectDir = CreateTempDir()
+			tempGlobalLoomDir = CreateTempDir()
+			stdinInput = ""
+			storeDir = filepath.Join(CreateTempDir(), "company")
+			CreateTempFile(filepath.Join(storeDir, "base-js", "_thread"), "package.json", "{}")
+			CreateTempFile(filepath.Join(storeDir, "prettier", "_thread"), ".prettierrc", "{}")
+			CreateTempFile(filepath.Join(storeDir, "prettier"), "config.yml", "dependencies: [base-js]\n")
+			CreateTempFile(filepath.Join(storeDir, "eslint", "_thread"), ".eslintrc", "{}")
+			CreateTempFile(filepath.Join(storeDir, "eslint"), "config.yml", "dependencies: [base-js, prettier]\n")
+			Eventually(runLoom(tempProjectDir, tempGlobalLoomDir, stdinInput, "config", "add", storeDir), "10s").Should(gexec.Exit(0))
+		})
+
+		It("should add missing dependencies depth-first with --with-deps and record them", func() {
+			session := runLoom(tempProjectDir, tempGlobalLoomDir, stdinInput, "add", "--with-deps", "eslint")
+			Eventually(session, "10s").Should(gexec.Exit(0))
+			Expect(session.Out).To(gbytes.Say("Thread 'base-js' added successfully"))
+			Expect(session.Out).To(gbytes.Say("Thread 'prettier' added successfully"))
+			Expect(session.Out).To(gbytes.Say("Thread 'eslint' added successfully"))
+			for _, file := range []string{"package.json", ".prettierrc", ".eslintrc"} {
+				Expect(filepath.Join(tempProjectDir, file)).To(BeAnExistingFile())
+			}
+
+			yamlContent, err := os.ReadFile(filepath.Join(tempProjectDir, "loom.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(yamlContent)).To(ContainSubstring("depends_on:\n        - base-js\n        - prettier"))
+		})
+
+		It("should skip missing dependencies with --no-deps", func() {
+			session := runLoom(tempProjectDir, tempGlobalLoomDir, stdinInput, "add", "--no-deps", "eslint")
+			Eventually(session, "10s").Should(gexec.Exit(0))
+			Expect(session.Out).To(gbytes.Say("depends on 'base-js', which is not installed; skipping it"))
+			Expect(filepath.Join(tempProjectDir, ".eslintrc")).To(BeAnExistingFile())
+			Expect(filepath.Join(tempProjectDir, "package.json")).NotTo(BeAnExistingFile())
+		})
+
+		It("should prompt for each missing dependency by default", func() {
+			stdinInput = "n\n"
+			session := runLoom(tempProjectDir, tempGlobalLoomDir, stdinInput, "add", "prettier")
+			Eventually(session, "10s").Should(gexec.Exit(0))
+			Expect(session.Out).To(gbytes.Say(regexp.QuoteMeta("Thread 'prettier' depends on 'base-js', which is not installed. Add it? [Y/n]")))
+			Expect(session.Out).To(gbytes.Say("Skipping dependency 'base-js'"))
+			Expect(filepath.Join(tempProjectDir, "package.json")).NotTo(BeAnExistingFile())
+		})
+
+		It("should fail clearly on a dependency cycle or a missing dependency", func() {
+			CreateTempFile(filepath.Join(storeDir, "base-js"), "config.yml", "dependencies: [eslint]\n")
+			session := runLoom(tempProjectDir, tempGlobalLoomDir, stdinInput, "add", "--with-deps", "eslint")
+			Eventually(session, "10s").Should(gexec.Exit(1))
+			Expect(session.Err).To(gbytes.Say("dependency cycle: eslint -> base-js -> eslint"))
+			Expect(filepath.Join(tempProjectDir, "loom.yaml")).NotTo(BeAnExistingFile())
+
+			CreateTempFile(filepath.Join(storeDir, "base-js"), "config.yml", "dependencies: [missing]\n")
+			session = runLoom(tempProjectDir, tempGlobalLoomDir, stdinInput, "add", "--with-deps", "base-js")
+			Eventually(session, "10s").Should(gexec.Exit(2))
+			Expect(session.Err).To(gbytes.Say("cannot add dependency 'missing' of thread 'base-js': thread 'missing' not found"))
+		})
+	})
+
+	Describe("loom add --interactive functionality", func() {
+		var tempProjectDir string
+		var tempGlobalLoomDir string
+		var stdinInput string
+
+		BeforeEach(func() {
+			tempProjectDir = CreateTempDir()
+			tempGlobalLoomDir = CreateTempDir()
+			stdinInput = ""
+			storeDir := filepath.Join(CreateTempDir(), "company")
+			CreateTempFile(filepath.Join(storeDir, "go-ci", "_thread"), "ci.yml", "ci")
+			Eventually(runLoom(tempProjectDir, tempGlobalLoomDir, stdinInput, "config", "add", storeDir), "10s").Should(gexec.Exit(0))
+			CreateTempFile(filepath.Join(tempProjectDir, ".loom", "local-docs", "_thread"), "DOCS.md", "docs")
+		})
+
+		It("should list the available threads and add the one picked by number", func() {
+			stdinInput = "x\n2\n"
+			session := runLoom(tempProjectDir, tempGlobalLoomDir, stdinInput, "add", "--interactive")
+			Eventually(session, "10s").Should(gexec.Exit(0))
+			Expect(session.Out).To(gbytes.Say(`1\) local-docs \(project\)`))
+			Expect(session.Out).To(gbytes.Say(`2\) company/go-ci`))
+			Expect(session.Out).To(gbytes.Say("Invalid selection"))
+			Expect(session.Out).To(gbytes.Say("Thread 'company/go-ci' added successfully"))
+			Expect(filepath.Join(tempProjectDir, "ci.yml")).To(BeAnExistingFile())
+		})
+
+		It("should add nothing when the selection is cancelled", func() {
+			stdinInput = "\n"
+			session := runLoom(tempProjectDir, tempGlobalLoomDir, stdinInput, "add", "--interactive")
+			Eventually(session, "10s").Should(gexec.Exit(0))
+			Expect(session.Out).To(gbytes.Say("No thread selected."))
+			Expect(filepath.Join(tempProjectDir, "loom.yaml")).NotTo(BeAnExistingFile())
+		})
+
+		It("should still require a thread name without --interactive", func() {
+			session := runLoom(tempProjectDir, tempGlobalLoomDir, stdinInput, "add")
+			Eventually(session, "10s").Should(gexec.Exit(2))
+			Expect(session.Err).To(gbytes.Say("thread name or store/thread is required"))
+		})
+	})
+
+	Describe("loom reweave functionality", func() {
+		var tempProjectDir string
+		var tempGlobalLoomDir string
+
+		BeforeEach(func() {
+			tempProjectDir = CreateTempDir()
+			tempGlobalLoomDir = CreateTempDir()
+		})
+
+		It("should replace a thread's files with a fresh copy and keep its place in loom.yaml", func() {
+			firstDir := filepath.Join(CreateTempDir(), "first")
+			CreateTempFile(filepath.Join(firstDir, "_thread"), "keep.txt", "original")
+			CreateTempFile(filepath.Join(firstDir, "_thread"), "gone.txt", "gone")
+			CreateTempFile(filepath.Join(firstDir, "_thread", "sub"), "nested.txt", "nested")
+			secondDir := filepath.Join(CreateTempDir(), "second")
+			CreateTempFile(filepath.Join(secondDir, "_thread"), "second.txt", "second")
+			Eventually(runLoom(tempProjectDir, tempGlobalLoomDir, "", "add", "--from", firstDir), "10s").Should(gexec.Exit(0))
+			Eventually(runLoom(tempProjectDir, tempGlobalLoomDir, "", "add", "--from", secondDir), "10s").Should(gexec.Exit(0))
+
+			Expect(os.Remove(filepath.Join(firstDir, "_thread", "gone.txt"))).To(Succeed())
+			CreateTempFile(tempProjectDir, "keep.txt", "edited")
+
+			session := runLoom(tempProjectDir, tempGlobalLoomDir, "", "reweave", "first")
+			Eventually(session, "10s").Should(gexec.Exit(0))
+			Expect(session.Out).To(gbytes.Say("Removed 3 file\\(s\\) of thread 'first'."))
+
+			content, err := os.ReadFile(filepath.Join(tempProjectDir, "keep.txt"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal("original"))
+			Expect(filepath.Join(tempProjectDir, "sub", "nested.txt")).To(BeAnExistingFile())
+			Expect(filepath.Join(tempProjectDir, "gone.txt")).NotTo(BeAnExistingFile())
+
+			loomYaml, err := os.ReadFile(filepath.Join(tempProjectDir, "loom.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(loomYaml)).NotTo(ContainSubstring("gone.txt"))
+			Expect(string(loomYaml)).To(MatchRegexp(`(?s)name: first.*keep\.txt.*name: second`))
+		})
+
+		It("should re-pull a thread from the configured stores with --from-store", func() {
+			CreateTempFile(filepath.Join(tempProjectDir, ".loom", "go-ci", "_thread"), "ci.yml", "local")
+			storeDir := filepath.Join(CreateTempDir(), "company")
+			CreateTempFile(filepath.Join(storeDir, "go-ci", "_thread"), "ci.yml", "store")
+			Eventually(runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "add", storeDir), "10s").Should(gexec.Exit(0))
+			Eventually(runLoom(tempProjectDir, tempGlobalLoomDir, "", "add", "go-ci"), "10s").Should(gexec.Exit(0))
+
+			session := runLoom(tempProjectDir, tempGlobalLoomDir, "", "reweave", "--from-store", "go-ci")
+			Eventually(session, "10s").Should(gexec.Exit(0))
+			Expect(session.Out).To(gbytes.Say("Thread 'go-ci' now comes from 'company'"))
+
+			content, err := os.ReadFile(filepath.Join(tempProjectDir, "ci.yml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal("store"))
+			loomYaml, err := os.ReadFile(filepath.Join(tempProjectDir, "loom.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(loomYaml)).To(ContainSubstring("source: company"))
+		})
+
+		It("should leave the thread's files alone when its source is gone", func() {
+			threadDir := filepath.Join(CreateTempDir(), "myThread")
+			CreateTempFile(filepath.Join(threadDir, "_thread"), "file1.txt", "v1")
+			Eventually(runLoom(tempProjectDir, tempGlobalLoomDir, "", "add", "--from", threadDir), "10s").Should(gexec.Exit(0))
+			Expect(os.RemoveAll(threadDir)).To(Succeed())
+
+			session := runLoom(tempProjectDir, tempGlobalLoomDir, "", "reweave", "myThread")
+			Eventually(session, "10s").Should(gexec.Exit(1))
+			Expect(session.Err).To(gbytes.Say("nothing was removed"))
+			Expect(filepath.Join(tempProjectDir, "file1.txt")).To(BeAnExistingFile())
+		})
+
+		It("should reject a thread that is not in loom.yaml", func() {
+			Eventually(runLoom(tempProjectDir, tempGlobalLoomDir, "", "init"), "10s").Should(gexec.Exit(0))
+			session := runLoom(tempProjectDir, tempGlobalLoomDir, "", "reweave", "missing")
+			Eventually(session, "10s").Should(gexec.Exit(2))
+			Expect(session.Err).To(gbytes.Say("thread 'missing' not found"))
+		})
+	})
+
+	Describe("nested store threads", func() {
+		var tempProjectDir string
+		var tempGlobalLoomDir string
+
+		BeforeEach(func() {
+			tempProjectDir = CreateTempDir()
+			tempGlobalLoomDir = CreateTempDir()
+			storeDir := filepath.Join(CreateTempDir(), "company")
+			CreateTempFile(filepath.Join(storeDir, "frontend", "button", "_thread"), "button.css", "button")
+			CreateTempFile(filepath.Join(storeDir, "go-ci", "_thread"), "ci.yml", "ci")
+			Eventually(runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "add", storeDir), "10s").Should(gexec.Exit(0))
+		})
+
+		It("should list threads nested in category directories by their path", func() {
+			session := runLoom(tempProjectDir, tempGlobalLoomDir, "", "list", "--available")
+			Eventually(session, "10s").Should(gexec.Exit(0))
+			Expect(session.Out).To(gbytes.Say(`- frontend/button`))
+			Expect(session.Out).To(gbytes.Say(`- go-ci`))
+		})
+
+		It("should add a nested thread under its own name and weave it again", func() {
+			Eventually(runLoom(tempProjectDir, tempGlobalLoomDir, "", "add", "company/frontend/button"), "10s").Should(gexec.Exit(0))
+			Expect(filepath.Join(tempProjectDir, "button.css")).To(BeAnExistingFile())
+			loomYaml, err := os.ReadFile(filepath.Join(tempProjectDir, "loom.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(loomYaml)).To(ContainSubstring("name: button"))
+			Expect(string(loomYaml)).To(ContainSubstring("source: company/frontend/button"))
+
+			Expect(os.Remove(filepath.Join(tempProjectDir, "button.css"))).To(Succeed())
+			Eventually(runLoom(tempProjectDir, tempGlobalLoomDir, "", "weave", "button"), "10s").Should(gexec.Exit(0))
+			Expect(filepath.Join(tempProjectDir, "button.css")).To(BeAnExistingFile())
+		})
+	})
+
+	Describe("project stores in loom.yaml", func() {
+		var tempProjectDir string
+		var tempGlobalLoomDir string
+
+		BeforeEach(func() {
+			tempProjectDir = CreateTempDir()
+			tempGlobalLoomDir = CreateTempDir()
+			globalStore := filepath.Join(CreateTempDir(), "team")
+			CreateTempFile(filepath.Join(globalStore, "go-ci", "_thread"), "ci.yml", "global ci")
+			Eventually(runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "add", globalStore), "10s").Should(gexec.Exit(0))
+
+			CreateTempFile(filepath.Join(tempProjectDir, "stores", "team", "go-ci", "_thread"), "ci.yml", "project ci")
+			CreateTempFile(tempProjectDir, "loom.yaml", "version: \"1\"\nstores:\n  - name: team\n    type: local\n    path: stores/team\nthreads: []\n")
+		})
+
+		It("should resolve threads from the project's stores before global ones and weave them again", func() {
+			Eventually(runLoom(tempProjectDir, tempGlobalLoomDir, "", "add", "go-ci"), "10s").Should(gexec.Exit(0))
+			content, err := os.ReadFile(filepath.Join(tempProjectDir, "ci.yml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal("project ci"))
+
+			loomYaml, err := os.ReadFile(filepath.Join(tempProjectDir, "loom.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(loomYaml)).To(ContainSubstring("path: stores/team"))
+
+			Expect(os.Remove(filepath.Join(tempProjectDir, "ci.yml"))).To(Succeed())
+			Eventually(runLoom(tempProjectDir, tempGlobalLoomDir, "", "weave", "go-ci"), "10s").Should(gexec.Exit(0))
+			content, err = os.ReadFile(filepath.Join(tempProjectDir, "ci.yml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal("project ci"))
+		})
+
+		It("should use the stores of a manifest chosen with --config, not a loom.yaml beside it", func() {
+			CreateTempFile(filepath.Join(tempProjectDir, "stores", "decoy", "go-ci", "_thread"), "ci.yml", "decoy ci")
+			CreateTempFile(tempProjectDir, "loom.yaml", "version: \"1\"\nstores:\n  - name: team\n    type: local\n    path: stores/decoy\nthreads: []\n")
+			manifestPath := filepath.Join(tempProjectDir, "custom.yaml")
+			CreateTempFile(tempProjectDir, "custom.yaml", "version: \"1\"\nstores:\n  - name: team\n    type: local\n    path: stores/team\nthreads: []\n")
+			workDir := CreateTempDir()
+
+			Eventually(runLoom(workDir, tempGlobalLoomDir, "", "--config", manifestPath, "add", "team/go-ci"), "10s").Should(gexec.Exit(0))
+			content, err := os.ReadFile(filepath.Join(tempProjectDir, "ci.yml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal("project ci"))
+
+			Expect(os.Remove(filepath.Join(tempProjectDir, "ci.yml"))).To(Succeed())
+			Eventually(runLoom(workDir, tempGlobalLoomDir, "", "--config", manifestPath, "weave", "go-ci"), "10s").Should(gexec.Exit(0))
+			content, err = os.ReadFile(filepath.Join(tempProjectDir, "ci.yml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal("project ci"))
+
+			session := runLoom(workDir, tempGlobalLoomDir, "", "--config", manifestPath, "list", "--available", "--store", "team")
+			Eventually(session, "10s").Should(gexec.Exit(0))
+			Expect(string(session.Out.Contents())).To(ContainSubstring(filepath.Join(tempProjectDir, "stores", "team")))
+			Expect(string(session.Out.Contents())).NotTo(ContainSubstring("decoy"))
+		})
+
+		It("should reject a store entry without a valid type", func() {
+			CreateTempFile(tempProjectDir, "loom.yaml", "version: \"1\"\nstores:\n  - name: team\n    type: ftp\n    path: stores/team\nthreads: []\n")
+			session := runLoom(tempProjectDir, tempGlobalLoomDir, "", "add", "go-ci")
+			Eventually(session, "10s").Should(gexec.Exit(1))
+			Expect(string(session.Err.Contents())).To(ContainSubstring("store 'team': unknown store type \"ftp\""))
+		})
+	})
+
+	Describe("loom config default-store functionality", func() {
+		var tempProjectDir string
+		var tempGlobalLoomDir string
+
+		BeforeEach(func() {
+			tempProjectDir = CreateTempDir()
+			tempGlobalLoomDir = CreateTempDir()
+			for _, name := range []string{"first", "second"} {
+				storeDir := filepath.Join(CreateTempDir(), name)
+				CreateTempFile(filepath.Join(storeDir, "shared", "_thread"), "shared.txt", name)
+				Eventually(runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "add", storeDir), "10s").Should(gexec.Exit(0))
+			}
+		})
+
+		It("should resolve threads from the default store before the others", func() {
+			session := runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "default-store", "second")
+			Eventually(session, "10s").Should(gexec.Exit(0))
+			Expect(session.Out).To(gbytes.Say(`Default store set to "second"`))
+			session = runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "list")
+			Eventually(session, "10s").Should(gexec.Exit(0))
+			Expect(session.Out).To(gbytes.Say(`Name:     second \(default\)`))
+
+			Eventually(runLoom(tempProjectDir, tempGlobalLoomDir, "", "add", "shared"), "10s").Should(gexec.Exit(0))
+			content, err := os.ReadFile(filepath.Join(tempProjectDir, "shared.txt"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal("second"))
+		})
+
+		It("should fall back to configuration order once the default is cleared", func() {
+			Eventually(runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "default-store", "second"), "10s").Should(gexec.Exit(0))
+			Eventually(runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "default-store", "--clear"), "10s").Should(gexec.Exit(0))
+			session := runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "default-store")
+			Eventually(session, "10s").Should(gexec.Exit(0))
+			Expect(session.Out).To(gbytes.Say("No default store is set."))
+
+			Eventually(runLoom(tempProjectDir, tempGlobalLoomDir, "", "add", "shared"), "10s").Should(gexec.Exit(0))
+			content, err := os.ReadFile(filepath.Join(tempProjectDir, "shared.txt"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal("first"))
+		})
+
+		It("should reject an unknown store", func() {
+			session := runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "default-store", "missing")
+			Eventually(session, "10s").Should(gexec.Exit(2))
+			Expect(session.Err).To(gbytes.Say(`store "missing" not found`))
+		})
+
+		It("should resolve threads from higher-priority stores first", func() {
+			session := runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "set-priority", "second", "10")
+			Eventually(session, "10s").Should(gexec.Exit(0))
+			Expect(session.Out).To(gbytes.Say(`Changed priority of store "second" from 0 to 10`))
+			session = runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "list")
+			Eventually(session, "10s").Should(gexec.Exit(0))
+			Expect(session.Out).To(gbytes.Say(`Priority: 10`))
+
+			Eventually(runLoom(tempProjectDir, tempGlobalLoomDir, "", "add", "shared"), "10s").Should(gexec.Exit(0))
+			content, err := os.ReadFile(filepath.Join(tempProjectDir, "shared.txt"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal("second"))
+
+			session = runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "set-priority", "second", "high")
+			Eventually(session, "10s").Should(gexec.Exit(2))
+			Expect(session.Err).To(gbytes.Say("priority must be a whole number"))
+		})
+	})
+
+	Describe("loom add --output-dir functionality", func() {
+		var tempProjectDir string
+		var tempGlobalLoomDir string
+
+		BeforeEach(func() {
+			tempProjectDir = CreateTempDir()
+			tempGlobalLoomDir = CreateTempDir()
+			storeDir := filepath.Join(CreateTempDir(), "company")
+			CreateTempFile(filepath.Join(storeDir, "go-ci", "_thread"), "ci.yml", "store ci")
+			Eventually(runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "add", storeDir), "10s").Should(gexec.Exit(0))
+			CreateTempFile(filepath.Join(tempProjectDir, ".loom", "local-docs", "_thread"), "DOCS.md", "docs")
+			Eventually(runLoom(tempProjectDir, tempGlobalLoomDir, "", "init"), "10s").Should(gexec.Exit(0))
+			CreateTempFile(tempProjectDir, "ci.yml", "project ci")
+		})
+
+		It("should write the threads and a standalone loom.yaml into the output directory only", func() {
+			previewDir := filepath.Join(CreateTempDir(), "preview")
+			CreateTempFile(previewDir, "ci.yml", "stale preview")
+			projectYaml, err := os.ReadFile(filepath.Join(tempProjectDir, "loom.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+
+			session := runLoom(tempProjectDir, tempGlobalLoomDir, "", "add", "--output-dir", previewDir, "go-ci", "local-docs")
+			Eventually(session, "10s").Should(gexec.Exit(0))
+			Expect(session.Out).To(gbytes.Say("Wrote 2 thread\\(s\\) to"))
+
+			content, err := os.ReadFile(filepath.Join(previewDir, "ci.yml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal("store ci"))
+			Expect(filepath.Join(previewDir, "DOCS.md")).To(BeAnExistingFile())
+			previewYaml, err := os.ReadFile(filepath.Join(previewDir, "loom.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(previewYaml)).To(ContainSubstring("source: company"))
+			Expect(string(previewYaml)).To(ContainSubstring("source: path:"))
+
+			content, err = os.ReadFile(filepath.Join(tempProjectDir, "ci.yml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal("project ci"))
+			Expect(filepath.Join(tempProjectDir, "DOCS.md")).NotTo(BeAnExistingFile())
+			Expect(filepath.Join(tempProjectDir, "loom.lock")).NotTo(BeAnExistingFile())
+			after, err := os.ReadFile(filepath.Join(tempProjectDir, "loom.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(after)).To(Equal(string(projectYaml)))
+		})
+	})
+
+	Describe("loom add rollback", func() {
+		var tempProjectDir string
+		var threadDir string
+
+		BeforeEach(func() {
+			tempProjectDir = CreateTempDir()
+			threadDir = filepath.Join(CreateTempDir(), "myThread")
+			CreateTempFile(filepath.Join(threadDir, "_thread"), "a.txt", "new a")
+			CreateTempFile(filepath.Join(threadDir, "_thread", "docs"), "guide.md", "guide")
+			CreateTempFile(tempProjectDir, "a.txt", "old a")
+		})
+
+		It("should undo the copied files when loom.yaml or loom.lock cannot be saved", func() {
+			Expect(os.Mkdir(filepath.Join(tempProjectDir, "loom.lock"), 0755)).To(Succeed())
+
+			session := runLoom(tempProjectDir, "", "", "add", "--force", "--from", threadDir)
+			Eventually(session, "10s").Should(gexec.Exit(1))
+			Expect(string(session.Err.Contents())).To(ContainSubstring("Rolled back"))
+			content, err := os.ReadFile(filepath.Join(tempProjectDir, "a.txt"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal("old a"))
+			Expect(filepath.Join(tempProjectDir, "docs")).NotTo(BeAnExistingFile())
+			Expect(filepath.Join(tempProjectDir, "loom.yaml")).NotTo(BeAnExistingFile())
+		})
+
+		It("should undo a thread that fails part way through copying", func() {
+			CreateTempFile(filepath.Join(threadDir, "_thread"), "z.txt", "z")
+			Expect(os.Mkdir(filepath.Join(tempProjectDir, "z.txt"), 0755)).To(Succeed())
+
+			session := runLoom(tempProjectDir, "", "", "add", "--force", "--from", threadDir)
+			Eventually(session, "10s").Should(gexec.Exit(1))
+			content, err := os.ReadFile(filepath.Join(tempProjectDir, "a.txt"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal("old a"))
+			Expect(filepath.Join(tempProjectDir, "docs")).NotTo(BeAnExistingFile())
+		})
+	})
+
+	Describe("loom add --as-copy functionality", func() {
+		var tempProjectDir string
+		var threadDir string
+
+		BeforeEach(func() {
+			tempProjectDir = CreateTempDir()
+			threadDir = filepath.Join(CreateTempDir(), "scaffold")
+			CreateTempFile(filepath.Join(threadDir, "_thread"), "main.go", "package main")
+			CreateTempFile(filepath.Join(threadDir, "_thread", "docs"), "guide.md", "guide")
+		})
+
+		It("should copy the files without recording the thread in loom.yaml", func() {
+			session := runLoom(tempProjectDir, "", "", "add", "--as-copy", "--from", threadDir)
+			Eventually(session, "10s").Should(gexec.Exit(0))
+			Expect(string(session.Out.Contents())).To(ContainSubstring("Installed 2 file(s) from '" + threadDir + "' as untracked copies; they will not be managed by loom."))
+			Expect(filepath.Join(tempProjectDir, "main.go")).To(BeAnExistingFile())
+			Expect(filepath.Join(tempProjectDir, "docs", "guide.md")).To(BeAnExistingFile())
+			Expect(filepath.Join(tempProjectDir, "loom.yaml")).NotTo(BeAnExistingFile())
+		})
+
+		It("should take copied files away from the thread that owned them", func() {
+			Eventually(runLoom(tempProjectDir, "", "", "add", "--from", threadDir), "10s").Should(gexec.Exit(0))
+			otherDir := filepath.Join(CreateTempDir(), "other")
+			CreateTempFile(filepath.Join(otherDir, "_thread"), "main.go", "package other")
+
+			session := runLoom(tempProjectDir, "", "", "add", "--as-copy", "--force", "--from", otherDir)
+			Eventually(session, "10s").Should(gexec.Exit(0))
+			Expect(string(session.Out.Contents())).To(ContainSubstring("1 of them were owned by other threads"))
+			manifest, err := os.ReadFile(filepath.Join(tempProjectDir, "loom.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(manifest)).To(ContainSubstring("guide.md"))
+			Expect(string(manifest)).NotTo(ContainSubstring("main.go"))
+			Expect(string(manifest)).NotTo(ContainSubstring("name: other"))
+
+			session = runLoom(tempProjectDir, "", "", "owner", "main.go")
+			Eventually(session, "10s").Should(gexec.Exit(0))
+			Expect(string(session.Out.Contents())).To(ContainSubstring("main.go: not owned by any thread."))
+		})
+
+		It("should reject --rename", func() {
+			session := runLoom(tempProjectDir, "", "", "add", "--as-copy", "--rename", "app", "--from", threadDir)
+			Eventually(session, "10s").Should(gexec.Exit(2))
+			Expect(string(session.Err.Contents())).To(ContainSubstring("--as-copy cannot be combined with --rename"))
+		})
+	})
+
+	Describe("loom add --manifest-only functionality", func() {
+		var tempProjectDir string
+		var threadDir string
+
+		BeforeEach(func() {
+			tempProjectDir = CreateTempDir()
+			threadDir = filepath.Join(CreateTempDir(), "legacy")
+			CreateTempFile(filepath.Join(threadDir, "_thread"), "Makefile", "all: build")
+			CreateTempFile(filepath.Join(threadDir, "_thread", "ci"), "build.yml", "on: push")
+			CreateTempFile(tempProjectDir, "Makefile", "all: build test")
+		})
+
+		It("should record the existing files without writing anything", func() {
+			session := runLoom(tempProjectDir, "", "", "add", "--manifest-only", "--from", threadDir)
+			Eventually(session, "10s").Should(gexec.Exit(0))
+			Expect(string(session.Out.Contents())).To(ContainSubstring("Not installed: 'ci/build.yml' is not in the project."))
+			Expect(string(session.Out.Contents())).To(ContainSubstring("Thread '" + threadDir + "' registered from path:" + threadDir + " without copying files: 1 adopted, 1 not adopted."))
+
+			content, err := os.ReadFile(filepath.Join(tempProjectDir, "Makefile"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal("all: build test"))
+			Expect(filepath.Join(tempProjectDir, "ci")).NotTo(BeAnExistingFile())
+
+			session = runLoom(tempProjectDir, "", "", "owner", "Makefile")
+			Eventually(session, "10s").Should(gexec.Exit(0))
+			Expect(string(session.Out.Contents())).To(ContainSubstring("Makefile: legacy"))
+			manifest, err := os.ReadFile(filepath.Join(tempProjectDir, "loom.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(manifest)).NotTo(ContainSubstring("build.yml"))
+		})
+
+		It("should reject --as-copy", func() {
+			session := runLoom(tempProjectDir, "", "", "add", "--manifest-only", "--as-copy", "--from", threadDir)
+			Eventually(session, "10s").Should(gexec.Exit(2))
+			Expect(string(session.Err.Contents())).To(ContainSubstring("--manifest-only cannot be combined with --as-copy"))
+		})
+	})
+
+	Describe("loom list active thread status", func() {
+		var tempProjectDir string
+
+		BeforeEach(func() {
+			tempProjectDir = CreateTempDir()
+			threadDir := filepath.Join(CreateTempDir(), "myThread")
+			for _, name := range []string{"a.txt", "b.txt", "c.txt"} {
+				CreateTempFile(filepath.Join(threadDir, "_thread"), name, name)
+			}
+			Eventually(runLoom(tempProjectDir, "", "", "add", "--from", threadDir), "10s").Should(gexec.Exit(0))
+		})
+
+		It("should show how many files each thread owns", func() {
+			session := runLoom(tempProjectDir, "", "", "list", "--active")
+			Eventually(session, "10s").Should(gexec.Exit(0))
+			Expect(session.Out).To(gbytes.Say(`- myThread \(Source: path:.*\)\n`))
+			Expect(session.Out).To(gbytes.Say(`Files:     3\n`))
+		})
+
+		It("should flag missing files, and modified ones with --check", func() {
+			Expect(os.Remove(filepath.Join(tempProjectDir, "a.txt"))).To(Succeed())
+			CreateTempFile(tempProjectDir, "b.txt", "edited")
+
+			session := runLoom(tempProjectDir, "", "", "list", "--active")
+			Eventually(session, "10s").Should(gexec.Exit(0))
+			Expect(session.Out).To(gbytes.Say(`- myThread .* \[!\]`))
+			Expect(session.Out).To(gbytes.Say(`Files:     3 \(1 missing\)\n`))
+
+			session = runLoom(tempProjectDir, "", "", "list", "--active", "--check")
+			Eventually(session, "10s").Should(gexec.Exit(0))
+			Expect(session.Out).To(gbytes.Say(`Files:     3 \(1 missing, 1 modified\)`))
+		})
+
+		It("should mark project-store threads as active or available", func() {
+			CreateTempFile(filepath.Join(tempProjectDir, ".loom", "local-a", "_thread"), "x.txt", "x")
+			CreateTempFile(filepath.Join(tempProjectDir, ".loom", "local-b", "_thread"), "y.txt", "y")
+			Eventually(runLoom(tempProjectDir, "", "", "add", "local-a"), "10s").Should(gexec.Exit(0))
+
+			session := runLoom(tempProjectDir, "", "", "list", "--store", "project")
+			Eventually(session, "10s").Should(gexec.Exit(0))
+			Expect(session.Out).To(gbytes.Say(`- local-a \(active\)\n`))
+			Expect(session.Out).To(gbytes.Say(`- local-b \(available\)\n`))
+		})
+
+		It("should note thread names found in more than one store", func() {
+			globalDir := CreateTempDir()
+			for _, store := range []string{"shared", "team"} {
+				storeDir := filepath.Join(CreateTempDir(), store)
+				CreateTempFile(filepath.Join(storeDir, "go-ci", "_thread"), "ci.yml", store)
+				CreateTempFile(filepath.Join(storeDir, store+"-only", "_thread"), "x.txt", store)
+				Eventually(runLoom(tempProjectDir, globalDir, "", "config", "add", "--local", storeDir), "10s").Should(gexec.Exit(0))
+			}
+			Eventually(runLoom(tempProjectDir, globalDir, "", "config", "default-store", "team"), "10s").Should(gexec.Exit(0))
+
+			session := runLoom(tempProjectDir, globalDir, "", "list", "--available")
+			Eventually(session, "10s").Should(gexec.Exit(0))
+			Expect(string(session.Out.Contents())).To(ContainSubstring("Note: thread 'go-ci' is in stores 'shared' and 'team'; 'loom add go-ci' takes it from 'team'. Use 'loom add <store>/go-ci' to choose."))
+			Expect(string(session.Out.Contents())).NotTo(ContainSubstring("thread 'shared-only'"))
+		})
+	})
+
+	Describe("loom list filters", func() {
+		var tempProjectDir string
+		var tempGlobalLoomDir string
+
+		BeforeEach(func() {
+			tempProjectDir = CreateTempDir()
+			tempGlobalLoomDir = CreateTempDir()
+			for _, store := range []string{"alpha", "beta"} {
+				storeDir := filepath.Join(CreateTempDir(), store)
+				CreateTempFile(filepath.Join(storeDir, store+"-thread", "_thread"), "x.txt", store)
+				Eventually(runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "add", "--local", storeDir), "10s").Should(gexec.Exit(0))
+			}
+			CreateTempFile(filepath.Join(tempProjectDir, ".loom", "local-thread", "_thread"), "y.txt", "y")
+		})
+
+		It("should list only the store named by --store", func() {
+			session := runLoom(tempProjectDir, tempGlobalLoomDir, "", "list", "--store", "alpha")
+			Eventually(session, "10s").Should(gexec.Exit(0))
+			output := string(session.Out.Contents())
+			Expect(output).To(ContainSubstring("- alpha-thread"))
+			Expect(output).NotTo(ContainSubstring("beta-thread"))
+			Expect(output).NotTo(ContainSubstring("local-thread"))
+
+			session = runLoom(tempProjectDir, tempGlobalLoomDir, "", "list", "--store", "project")
+			Eventually(session, "10s").Should(gexec.Exit(0))
+			output = string(session.Out.Contents())
+			Expect(output).To(ContainSubstring("- local-thread (available)"))
+			Expect(output).NotTo(ContainSubstring("alpha-thread"))
+
+			session = runLoom(tempProjectDir, tempGlobalLoomDir, "", "list", "--store", "gamma")
+			Eventually(session, "10s").Should(gexec.Exit(2))
+			Expect(session.Err).To(gbytes.Say("store 'gamma' not found in global configuration"))
+		})
+
+		It("should split active and available threads with --active and --available", func() {
+			Eventually(runLoom(tempProjectDir, tempGlobalLoomDir, "", "add", "local-thread"), "10s").Should(gexec.Exit(0))
+
+			session := runLoom(tempProjectDir, tempGlobalLoomDir, "", "list", "--active")
+			Eventually(session, "10s").Should(gexec.Exit(0))
+			output := string(session.Out.Contents())
+			Expect(output).To(ContainSubstring("Active project threads:"))
+			Expect(output).To(ContainSubstring("- local-thread (Source: project:.loom/local-thread)"))
+			Expect(output).NotTo(ContainSubstring("Available store threads:"))
+
+			session = runLoom(tempProjectDir, tempGlobalLoomDir, "", "list", "--available")
+			Eventually(session, "10s").Should(gexec.Exit(0))
+			output = string(session.Out.Contents())
+			Expect(output).NotTo(ContainSubstring("Active project threads:"))
+			Expect(output).To(ContainSubstring("- alpha-thread"))
+			Expect(output).To(ContainSubstring("- local-thread (active)"))
+
+			session = runLoom(tempProjectDir, tempGlobalLoomDir, "", "list", "--active", "--available")
+			Eventually(session, "10s").Should(gexec.Exit(2))
+			Expect(session.Err).To(gbytes.Say("--active and --available cannot be used together"))
+		})
+	})
+
+	Describe("loom config add --relative functionality", func() {
+		var tempProjectDir string
+		var tempGlobalLoomDir string
+		var loomHome string
+
+		BeforeEach(func() {
+			tempProjectDir = CreateTempDir()
+			tempGlobalLoomDir = CreateTempDir()
+			loomHome = CreateTempDir()
+			CreateTempFile(filepath.Join(loomHome, "stores", "company", "go-ci", "_thread"), "ci.yml", "ci")
+		})
+
+		It("should record the path relative to LOOM_HOME and resolve it wherever LOOM_HOME points", func() {
+			session := runLoomWithEnv(tempProjectDir, tempGlobalLoomDir, "", []string{"LOOM_HOME=" + loomHome}, "config", "add", "--relative", filepath.Join(loomHome, "stores", "company"))
+			Eventually(session, "10s").Should(gexec.Exit(0))
+			Expect(session.Out).To(gbytes.Say(`recorded as "stores/company"`))
+			globalConfig, err := os.ReadFile(filepath.Join(tempGlobalLoomDir, "loom.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(globalConfig)).To(ContainSubstring("path: stores/company"))
+
+			movedHome := CreateTempDir()
+			Expect(os.Rename(filepath.Join(loomHome, "stores"), filepath.Join(movedHome, "stores"))).To(Succeed())
+			session = runLoomWithEnv(tempProjectDir, tempGlobalLoomDir, "", []string{"LOOM_HOME=" + loomHome}, "add", "go-ci")
+			Eventually(session, "10s").Should(gexec.Exit(2))
+			Expect(session.Err).To(gbytes.Say("relative path of store 'company' \\(stores/company\\) resolves to .* which is not accessible here"))
+
+			loomHome = movedHome
+			Eventually(runLoomWithEnv(tempProjectDir, tempGlobalLoomDir, "", []string{"LOOM_HOME=" + loomHome}, "add", "go-ci"), "10s").Should(gexec.Exit(0))
+			Expect(filepath.Join(tempProjectDir, "ci.yml")).To(BeAnExistingFile())
+		})
+	})
+
+	Describe("loom archive store functionality", func() {
+		var tempProjectDir string
+		var tempGlobalLoomDir string
+		var cacheDir string
+		var archivePath string
+
+		writeZip := func(path string, files map[string]string) {
+			out, err := os.Create(path)
+			Expect(err).NotTo(HaveOccurred())
+			writer := zip.NewWriter(out)
+			for name, content := range files {
+				entry, err := writer.Create(name)
+				Expect(err).NotTo(HaveOccurred())
+				_, err = entry.Write([]byte(content))
+				Expect(err).NotTo(HaveOccurred())
+			}
+			Expect(writer.Close()).To(Succeed())
+			Expect(out.Close()).To(Succeed())
+		}
+
+		BeforeEach(func() {
+			tempProjectDir = CreateTempDir()
+			tempGlobalLoomDir = CreateTempDir()
+			cacheDir = CreateTempDir()
+			archivePath = filepath.Join(CreateTempDir(), "shared.zip")
+			writeZip(archivePath, map[string]string{"go-ci/_thread/ci.yml": "on: push\n"})
+		})
+
+		It("should register a .zip as an archive store and add threads from its extraction", func() {
+			session := runLoomWithEnv(tempProjectDir, tempGlobalLoomDir, "", []string{"XDG_CACHE_HOME=" + cacheDir}, "config", "add", archivePath)
+			Eventually(session, "10s").Should(gexec.Exit(0))
+			Expect(string(session.Out.Contents())).To(ContainSubstring(`Successfully added archive store "shared"`))
+
+			session = runLoomWithEnv(tempProjectDir, tempGlobalLoomDir, "", []string{"XDG_CACHE_HOME=" + cacheDir}, "config", "test", "shared")
+			Eventually(session, "10s").Should(gexec.Exit(0))
+			Expect(string(session.Out.Contents())).To(ContainSubstring(`Store "shared" is readable: 1 thread(s) found`))
+
+			Eventually(runLoomWithEnv(tempProjectDir, tempGlobalLoomDir, "", []string{"XDG_CACHE_HOME=" + cacheDir}, "add", "shared/go-ci"), "10s").Should(gexec.Exit(0))
+			content, err := os.ReadFile(filepath.Join(tempProjectDir, "ci.yml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal("on: push\n"))
+			Expect(filepath.Join(cacheDir, "loom", "archives")).To(BeADirectory())
+
+			Eventually(runLoomWithEnv(tempProjectDir, tempGlobalLoomDir, "", []string{"XDG_CACHE_HOME=" + cacheDir}, "weave"), "10s").Should(gexec.Exit(0))
+		})
+
+		It("should refuse an archive with entries outside its directory", func() {
+			writeZip(archivePath, map[string]string{"go-ci/_thread/ci.yml": "ci", "../escape.txt": "pwned"})
+			Eventually(runLoomWithEnv(tempProjectDir, tempGlobalLoomDir, "", []string{"XDG_CACHE_HOME=" + cacheDir}, "config", "add", archivePath), "10s").Should(gexec.Exit(0))
+
+			session := runLoomWithEnv(tempProjectDir, tempGlobalLoomDir, "", []string{"XDG_CACHE_HOME=" + cacheDir}, "config", "test", "shared")
+			Eventually(session, "10s").Should(gexec.Exit(1))
+			Expect(string(session.Err.Contents())).To(ContainSubstring("entry '../escape.txt' would be extracted outside the archive directory"))
+			Expect(filepath.Join(filepath.Dir(archivePath), "escape.txt")).NotTo(BeAnExistingFile())
+		})
+	})
+
+	Describe("conflict prompt defaults from the global config", func() {
+		var tempProjectDir string
+		var tempGlobalLoomDir string
+		var stdinInput string
+
+		writeGlobalConfig := func(content string) {
+			Expect(os.WriteFile(filepath.Join(tempGlobalLoomDir, "loom.yaml"), []byte(content), 0644)).To(Succeed())
+		}
+
+		BeforeEach(func() {
+			tempProjectDir = CreateTempDir()
+			tempGlobalLoomDir = CreateTempDir()
+			stdinInput = ""
+			CreateTempFile(filepath.Join(tempProjectDir, ".loom", "starter", "_thread"), "app.txt", "from thread")
+			CreateTempFile(tempProjectDir, "app.txt", "my own")
+		})
+
+		It("should answer with the configured default when Enter is pressed", func() {
+			writeGlobalConfig("version: \"1\"\ndefaults:\n  on_unowned_conflict: skip\n")
+			stdinInput = "\n"
+			session := runLoom(tempProjectDir, tempGlobalLoomDir, stdinInput, "add", "starter")
+			Eventually(session, "10s").Should(gexec.Exit(0))
+			Expect(session.Out).To(gbytes.Say(`\[Y\]es/\[N\]o/\[S\]kip \[Skip\]`))
+			content, err := os.ReadFile(filepath.Join(tempProjectDir, "app.txt"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal("my own"))
+
+			session = runLoom(tempProjectDir, tempGlobalLoomDir, stdinInput, "weave")
+			Eventually(session, "10s").Should(gexec.Exit(0))
+			Expect(session.Out).To(gbytes.Say(`\[Skip\]`))
+			content, err = os.ReadFile(filepath.Join(tempProjectDir, "app.txt"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal("my own"))
+		})
+
+		It("should keep yes as the default for prompt and let --yes override the preference", func() {
+			writeGlobalConfig("version: \"1\"\ndefaults:\n  on_unowned_conflict: prompt\n")
+			stdinInput = "\n"
+			session := runLoom(tempProjectDir, tempGlobalLoomDir, stdinInput, "add", "starter")
+			Eventually(session, "10s").Should(gexec.Exit(0))
+			Expect(session.Out).To(gbytes.Say(`\[Yes\]`))
+			content, err := os.ReadFile(filepath.Join(tempProjectDir, "app.txt"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal("from thread"))
+
+			Eventually(runLoom(tempProjectDir, tempGlobalLoomDir, stdinInput, "remove", "starter"), "10s").Should(gexec.Exit(0))
+			CreateTempFile(tempProjectDir, "app.txt", "my own")
+			writeGlobalConfig("version: \"1\"\ndefaults:\n  on_unowned_conflict: skip\n")
+			Eventually(runLoom(tempProjectDir, tempGlobalLoomDir, stdinInput, "add", "--yes", "starter"), "10s").Should(gexec.Exit(0))
+			content, err = os.ReadFile(filepath.Join(tempProjectDir, "app.txt"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(content)).To(Equal("from thread"))
+		})
+
+		It("should reject an unknown default answer", func() {
+			writeGlobalConfig("version: \"1\"\ndefaults:\n  on_owned_conflict: maybe\n")
+			session := runLoom(tempProjectDir, tempGlobalLoomDir, stdinInput, "config", "list")
+			Eventually(session, "10s").Should(gexec.Exit(1))
+			Expect(session.Err).To(gbytes.Say(`defaults.on_owned_conflict must be yes, no, skip or prompt, not 'maybe'`))
+		})
+	})
+
+	Describe("dotfile handling", func() {
+		var tempProjectDir string
+		var threadDir string
+
+		BeforeEach(func() {
+			tempProjectDir = CreateTempDir()
+			threadDir = filepath.Join(tempProjectDir, ".loom", "starter")
+			CreateTempFile(filepath.Join(threadDir, "_thread"), "main.go", "package main")
+			CreateTempFile(filepath.Join(threadDir, "_thread"), ".env.example", "KEY=value")
+			CreateTempFile(filepath.Join(threadDir, "_thread", ".meta"), "notes.txt", "internal")
+		})
+
+		It("should skip dotfiles with --exclude-dotfiles and keep skipping them on weave", func() {
+			Eventually(runLoom(tempProjectDir, "", "", "add", "--exclude-dotfiles", "starter"), "10s").Should(gexec.Exit(0))
+			Expect(filepath.Join(tempProjectDir, "main.go")).To(BeAnExistingFile())
+			Expect(filepath.Join(tempProjectDir, ".env.example")).NotTo(BeAnExistingFile())
+			Expect(filepath.Join(tempProjectDir, ".meta")).NotTo(BeADirectory())
+
+			yamlContent, err := os.ReadFile(filepath.Join(tempProjectDir, "loom.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(yamlContent)).To(ContainSubstring("dotfiles: exclude"))
+			Expect(string(yamlContent)).NotTo(ContainSubstring(".env.example"))
+
+			Eventually(runLoom(tempProjectDir, "", "", "weave", "--prune"), "10s").Should(gexec.Exit(0))
+			Expect(filepath.Join(tempProjectDir, ".env.example")).NotTo(BeAnExistingFile())
+			Expect(filepath.Join(tempProjectDir, ".meta")).NotTo(BeADirectory())
+		})
+
+		It("should follow config.yml's dotfiles setting unless --include-dotfiles overrides it", func() {
+			CreateTempFile(threadDir, "config.yml", "version: 1\ndotfiles: exclude\n")
+			Eventually(runLoom(tempProjectDir, "", "", "add", "starter"), "10s").Should(gexec.Exit(0))
+			Expect(filepath.Join(tempProjectDir, ".env.example")).NotTo(BeAnExistingFile())
+
+			Eventually(runLoom(tempProjectDir, "", "", "add", "--include-dotfiles", "starter"), "10s").Should(gexec.Exit(0))
+			Expect(filepath.Join(tempProjectDir, ".env.example")).To(BeAnExistingFile())
+			Expect(filepath.Join(tempProjectDir, ".meta", "notes.txt")).To(BeAnExistingFile())
+			yamlContent, err := os.ReadFile(filepath.Join(tempProjectDir, "loom.yaml"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(yamlContent)).To(ContainSubstring("dotfiles: include"))
+			Expect(string(yamlContent)).To(ContainSubstring(".env.example"))
+		})
+
+		It("should reject --include-dotfiles together with --exclude-dotfiles", func() {
+			session := runLoom(tempProjectDir, "", "", "add", "--include-dotfiles", "--exclude-dotfiles", "starter")
+			Eventually(session, "10s").Should(gexec.Exit(2))
+			Expect(session.Err).To(gbytes.Say("cannot be used together"))
+		})
+	})
+
+	Describe("loom config validate functionality", func() {
+		var tempProjectDir string
+		var tempGlobalLoomDir string
+
+		BeforeEach(func() {
+			tempProjectDir = CreateTempDir()
+			tempGlobalLoomDir = CreateTempDir()
+		})
+
+		It("should accept a configuration whose stores are all usable", func() {
+			storeDir := CreateTempDir()
+			Eventually(runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "add", "--name", "mystore", storeDir), "10s").Should(gexec.Exit(0))
+			session := runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "validate")
+			Eventually(session, "10s").Should(gexec.Exit(0))
+			Expect(session.Out).To(gbytes.Say(`is valid: 1 store\(s\) checked`))
+		})
+
+		It("should report missing paths, unknown types, duplicates and an unknown default store", func() {
+			storeDir := CreateTempDir()
+			missingDir := filepath.Join(CreateTempDir(), "gone")
+			config := fmt.Sprintf(`version: "1"
+default_store: nope
+stores:
+  - name: first
+    type: local
+    path: %[1]s
+  - name: FIRST
+    type: local
+    path: %[1]s
+  - name: gone
+    type: local
+    path: %[2]s
+  - name: weird
+    type: ftp
+    path: somewhere
+`, storeDir, missingDir)
+			Expect(os.WriteFile(filepath.Join(tempGlobalLoomDir, "loom.yaml"), []byte(config), 0644)).To(Succeed())
+
+			session := runLoom(tempProjectDir, tempGlobalLoomDir, "", "config", "validate", "--offline")
+			Eventually(session, "10s").Should(gexec.Exit(1))
+			output := string(session.Out.Contents())
+			Expect(output).To(ContainSubstring(`store "FIRST": another store is also named "first"`))
+			Expect(output).To(ContainSubstring(`store "FIRST": path ` + storeDir + ` is also the path of store "first"`))
+			Expect(output).To(ContainSubstring(`store "gone": directory ` + missingDir + ` does not exist`))
+			Expect(output).To(ContainSubstring(`store "weird": unknown store type "ftp"`))
+			Expect(output).To(ContainSubstring(`default_store "nope" is not a configured store`))
+			Expect(session.Err).To(gbytes.Say(`found 5 problem\(s\) in global configuration`))
+		})
+	})
 })